@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
 )
 
 func TestPrintUsage(t *testing.T) {
@@ -209,4 +212,61 @@ func TestFlagValidation(t *testing.T) {
 			t.Errorf("Invalid host: %s", host)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestWatchConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "test.yaml")
+	base := `app:
+  name: "Test App"
+  version: "1.0.0"
+database:
+  type: sqlite
+  path: "./test.db"
+models:
+  User:
+    fields:
+      id:
+        type: id
+        primary: true
+`
+	if err := os.WriteFile(configFile, []byte(base), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	reloaded := make(chan *parser.Config, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go watchConfig(configFile, 10*time.Millisecond, func(config *parser.Config) error {
+		reloaded <- config
+		return nil
+	}, stop)
+
+	time.Sleep(30 * time.Millisecond)
+	os.WriteFile(configFile, []byte("models: ["), 0644)
+	os.Chtimes(configFile, time.Now().Add(time.Second), time.Now().Add(time.Second))
+
+	select {
+	case <-reloaded:
+		t.Fatal("Expected invalid config to be ignored")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	updated := base + `  Post:
+    fields:
+      id:
+        type: id
+        primary: true
+`
+	os.WriteFile(configFile, []byte(updated), 0644)
+	os.Chtimes(configFile, time.Now().Add(2*time.Second), time.Now().Add(2*time.Second))
+
+	select {
+	case config := <-reloaded:
+		if _, ok := config.Models["Post"]; !ok {
+			t.Error("Expected reloaded config to contain the new model")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected config to be reloaded after change")
+	}
+}
@@ -1,17 +1,29 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/yamlforge/yamlforge/internal/api"
+	"github.com/yamlforge/yamlforge/internal/database"
 	"github.com/yamlforge/yamlforge/internal/parser"
 	"github.com/yamlforge/yamlforge/internal/server"
+	"gopkg.in/yaml.v3"
 )
 
 const version = "0.1.0"
 
+// On SIGINT or SIGTERM, requests in flight get this long to finish.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	var (
 		port        int
@@ -40,13 +52,16 @@ func main() {
 
 	switch command {
 	case "serve":
-		if flag.NArg() < 2 {
+		serveFlags := flag.NewFlagSet("serve", flag.ExitOnError)
+		watch := serveFlags.Bool("watch", false, "Reload configuration when the file changes")
+		serveFlags.Parse(flag.Args()[1:])
+		if serveFlags.NArg() < 1 {
 			fmt.Println("Error: missing YAML configuration file")
 			printUsage()
 			os.Exit(1)
 		}
-		configFile := flag.Arg(1)
-		handleServe(configFile, port, host)
+		configFile := serveFlags.Arg(0)
+		handleServe(configFile, port, host, *watch)
 
 	case "build":
 		if flag.NArg() < 2 {
@@ -66,6 +81,31 @@ func main() {
 		configFile := flag.Arg(1)
 		handleValidate(configFile)
 
+	case "types":
+		typesFlags := flag.NewFlagSet("types", flag.ExitOnError)
+		pkg := typesFlags.String("package", "models", "Package name of the generated file")
+		typesFlags.Parse(flag.Args()[1:])
+		if typesFlags.NArg() < 1 {
+			fmt.Println("Error: missing YAML configuration file")
+			printUsage()
+			os.Exit(1)
+		}
+		handleTypes(typesFlags.Arg(0), *pkg)
+
+	case "dump":
+		dumpFlags := flag.NewFlagSet("dump", flag.ExitOnError)
+		output := dumpFlags.String("o", "", "Write the seed file here instead of stdout")
+		dumpFlags.Parse(flag.Args()[1:])
+		if dumpFlags.NArg() < 1 {
+			fmt.Println("Error: missing YAML configuration file")
+			printUsage()
+			os.Exit(1)
+		}
+		// Flags may also follow the file: dump config.yaml -o seed.yaml.
+		configFile := dumpFlags.Arg(0)
+		dumpFlags.Parse(dumpFlags.Args()[1:])
+		handleDump(configFile, *output)
+
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		printUsage()
@@ -78,14 +118,19 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  serve <config.yaml>    Start development server")
+	fmt.Println("    --watch              Reload configuration when the file changes")
 	fmt.Println("  build <config.yaml>    Generate static files")
 	fmt.Println("  validate <config.yaml> Validate configuration")
+	fmt.Println("  types <config.yaml>    Print Go types for the models")
+	fmt.Println("    --package            Package name of the generated file (default models)")
+	fmt.Println("  dump <config.yaml>     Print every record as a seed section")
+	fmt.Println("    -o <file>            Write the seed file here instead of stdout")
 	fmt.Println()
 	fmt.Println("Options:")
 	flag.PrintDefaults()
 }
 
-func handleServe(configFile string, port int, host string) {
+func handleServe(configFile string, port int, host string, watch bool) {
 	config, err := parser.ParseConfig(configFile)
 	if err != nil {
 		log.Fatalf("Failed to parse configuration: %v", err)
@@ -100,12 +145,33 @@ func handleServe(configFile string, port int, host string) {
 
 	srv := server.New(config)
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Shutdown failed: %v", err)
+		}
+	}()
+
 	fmt.Printf("Starting yamlforge server on %s:%d\n", host, port)
 	fmt.Printf("Configuration: %s\n", configFile)
 
+	if watch {
+		fmt.Println("Watching configuration for changes")
+		go watchConfig(configFile, watchDebounce, srv.Reload, ctx.Done())
+	}
+
 	if err := srv.Start(host, port); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
+	// Start returns once Shutdown closes the listener; wait for it to
+	// drain the requests still running.
+	<-shutdown
 }
 
 func handleBuild(configFile string) {
@@ -113,6 +179,82 @@ func handleBuild(configFile string) {
 	fmt.Println("Build functionality not yet implemented")
 }
 
+func handleTypes(configFile, pkg string) {
+	config, err := parser.ParseConfig(configFile)
+	if err != nil {
+		fmt.Printf("Failed to parse configuration: %v\n", err)
+		os.Exit(1)
+	}
+	schema, err := parser.LoadConfig(config)
+	if err != nil {
+		fmt.Printf("Failed to load schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	source, err := api.New(nil, config, schema, nil).GenerateGoTypes(pkg)
+	if err != nil {
+		fmt.Printf("Failed to generate types: %v\n", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(source)
+}
+
+func handleDump(configFile, output string) {
+	config, err := parser.ParseConfig(configFile)
+	if err != nil {
+		fmt.Printf("Failed to parse configuration: %v\n", err)
+		os.Exit(1)
+	}
+	schema, err := parser.LoadConfig(config)
+	if err != nil {
+		fmt.Printf("Failed to load schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	db, err := database.NewDatabase(&config.Database)
+	if err != nil {
+		fmt.Printf("Failed to create database: %v\n", err)
+		os.Exit(1)
+	}
+	if err := db.Connect(); err != nil {
+		fmt.Printf("Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	if err := db.CreateSchema(schema); err != nil {
+		fmt.Printf("Failed to load database schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	seed, omitted, err := database.Dump(db, schema)
+	if err != nil {
+		fmt.Printf("Failed to dump database: %v\n", err)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by yamlforge dump. Add this section to the config to seed an empty database.\n")
+	if len(omitted) > 0 {
+		fmt.Fprintf(&buf, "# Password values are not dumped: %s\n", strings.Join(omitted, ", "))
+		fmt.Fprintf(&buf, "# Required ones read %s and are seeded with a random value; reset them afterwards.\n", database.RedactedPassword)
+	}
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string]any{"seed": seed}); err != nil {
+		fmt.Printf("Failed to encode seed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		fmt.Printf("Failed to write %s: %v\n", output, err)
+		os.Exit(1)
+	}
+}
+
 func handleValidate(configFile string) {
 	config, err := parser.ParseConfig(configFile)
 	if err != nil {
@@ -50,7 +50,7 @@ models:
       id:
         type: id
         primary: true`,
-			expectedOutput: "app.name is required",
+			expectedOutput: "app.name cannot be empty",
 			expectError:    true,
 		},
 	}
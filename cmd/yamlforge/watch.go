@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// Editors often save in several steps (truncate, write, rename), so a
+// reload waits until the file has been quiet this long.
+const watchDebounce = 100 * time.Millisecond
+
+// watchConfig reloads configFile whenever it changes. The directory is
+// watched rather than the file so saves that replace the file through a
+// rename are still seen.
+func watchConfig(configFile string, debounce time.Duration, reload func(*parser.Config) error, stop <-chan struct{}) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Config watch disabled: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	target := filepath.Clean(configFile)
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		log.Printf("Config watch disabled: %v", err)
+		return
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != target || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Chmod) {
+				continue
+			}
+			timer.Reset(debounce)
+			continue
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Config watch error: %v", err)
+			continue
+		case <-timer.C:
+		}
+
+		config, err := parser.ParseConfig(configFile)
+		if err != nil {
+			log.Printf("Config reload failed, keeping previous configuration: %v", err)
+			continue
+		}
+		if err := reload(config); err != nil {
+			log.Printf("Config reload failed, keeping previous configuration: %v", err)
+			continue
+		}
+		log.Printf("Reloaded configuration from %s", configFile)
+	}
+}
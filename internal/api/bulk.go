@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/yamlforge/yamlforge/internal/database"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const defaultBulkMaxItems = 1000
+
+var ErrBulkTooLarge = errors.New("too many items in bulk request")
+
+type BulkRequest struct {
+	Operation string
+	Data      []map[string]any
+	IDs       []any
+}
+
+// BulkMaxItems is the most items a bulk request may carry.
+func BulkMaxItems(config *parser.Config) int {
+	if config != nil && config.Server.BulkMaxItems > 0 {
+		return config.Server.BulkMaxItems
+	}
+	return defaultBulkMaxItems
+}
+
+// DecodeBulkRequest walks the body token by token so an oversized array is
+// rejected as soon as it passes the limit instead of after it is buffered.
+func DecodeBulkRequest(dec *json.Decoder, limit int) (*BulkRequest, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	request := &BulkRequest{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		switch key {
+		case "operation":
+			if err := dec.Decode(&request.Operation); err != nil {
+				return nil, err
+			}
+		case "data":
+			err = decodeBulkArray(dec, limit, func() error {
+				var item map[string]any
+				if err := dec.Decode(&item); err != nil {
+					return err
+				}
+				request.Data = append(request.Data, item)
+				return nil
+			})
+		case "ids":
+			err = decodeBulkArray(dec, limit, func() error {
+				var id any
+				if err := dec.Decode(&id); err != nil {
+					return err
+				}
+				request.IDs = append(request.IDs, id)
+				return nil
+			})
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return request, expectDelim(dec, '}')
+}
+
+func decodeBulkArray(dec *json.Decoder, limit int, next func() error) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("expected an array")
+	}
+
+	for count := 0; dec.More(); count++ {
+		if count == limit {
+			return ErrBulkTooLarge
+		}
+		if err := next(); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %s", delim)
+	}
+	return nil
+}
+
+func (api *API) createBatch(modelName string, items []map[string]any) ([]any, error) {
+	if batch, ok := api.db.(database.BatchCreator); ok {
+		return batch.CreateBatch(modelName, items)
+	}
+
+	ids := make([]any, 0, len(items))
+	for i, item := range items {
+		id, err := api.db.Create(modelName, item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// Initialisms are upper-cased whole, as Go naming conventions expect.
+var goInitialisms = map[string]bool{
+	"api": true, "id": true, "ip": true, "json": true, "html": true,
+	"http": true, "sku": true, "uri": true, "url": true, "uuid": true,
+}
+
+// GenerateGoTypes renders a Go source file with one struct per model,
+// mirroring the OpenAPI schemas. Enum fields get a named string type with
+// a constant per option, e.g. UserRole and UserRoleAdmin.
+func (api *API) GenerateGoTypes(pkg string) ([]byte, error) {
+	names := make([]string, 0, len(api.schema.Models))
+	for name := range api.schema.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by yamlforge. DO NOT EDIT.\n\npackage %s\n", pkg)
+
+	for _, name := range names {
+		model := api.schema.Models[name]
+		typeName := goName(name)
+
+		for _, field := range model.Fields {
+			if field.Type != parser.FieldTypeEnum {
+				continue
+			}
+			enumType := typeName + goName(field.Name)
+			fmt.Fprintf(&buf, "\ntype %s string\n\nconst (\n", enumType)
+			for i, constName := range enumConstNames(field.Options) {
+				fmt.Fprintf(&buf, "\t%s%s %s = %s\n", enumType, constName, enumType, strconv.Quote(field.Options[i]))
+			}
+			buf.WriteString(")\n")
+		}
+
+		fmt.Fprintf(&buf, "\ntype %s struct {\n", typeName)
+		for _, field := range model.Fields {
+			goType := api.goType(api.fieldToSchema(field))
+			if field.Type == parser.FieldTypeEnum {
+				goType = typeName + goName(field.Name)
+			}
+			optional := !field.Primary && (field.Nullable || !field.Required)
+			tag := field.Name
+			if optional {
+				goType = nullableGoType(goType)
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&buf, "\t%s %s `json:%q`\n", goName(field.Name), goType, tag)
+		}
+		buf.WriteString("}\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+func (api *API) goType(schema *Schema) string {
+	switch schema.Type {
+	case "integer":
+		return "int64"
+	case "number":
+		if schema.Format == "double" {
+			return "float64"
+		}
+		return "int64"
+	case "boolean":
+		return "bool"
+	case "array":
+		if schema.Items != nil {
+			return "[]" + api.goType(schema.Items)
+		}
+		return "[]any"
+	case "object":
+		return "map[string]any"
+	}
+	return "string"
+}
+
+// Optional scalars become pointers so a missing value differs from the
+// zero value; maps and slices are already nilable.
+func nullableGoType(goType string) string {
+	if strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") {
+		return goType
+	}
+	return "*" + goType
+}
+
+// goName turns a model, field or option name such as "user_id" or
+// "in-progress" into an exported identifier ("UserID", "InProgress").
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if goInitialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// enumConstNames names each option's constant, numbering options that
+// have no usable name or that clash with an earlier one.
+func enumConstNames(options []string) []string {
+	names := make([]string, len(options))
+	seen := make(map[string]bool, len(options))
+	for i, option := range options {
+		name := goName(option)
+		if name == "" || seen[name] {
+			name += strconv.Itoa(i + 1)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	goparser "go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+func TestGenerateGoTypes(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+
+	source, err := api.GenerateGoTypes("models")
+	if err != nil {
+		t.Fatalf("Failed to generate types: %v", err)
+	}
+	if _, err := goparser.ParseFile(token.NewFileSet(), "models.go", source, 0); err != nil {
+		t.Fatalf("Expected valid Go source, got %v:\n%s", err, source)
+	}
+
+	code := string(source)
+	for _, want := range []string{
+		"package models",
+		"type UserRole string",
+		`UserRoleUser  UserRole = "user"`,
+		`UserRoleAdmin UserRole = "admin"`,
+		"type User struct {",
+		"ID        int64",
+		"Role      *UserRole",
+		"`json:\"role,omitempty\"`",
+		"Name      string",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestEnumConstNames(t *testing.T) {
+	got := enumConstNames([]string{"in-progress", "done", "in_progress", "!", "api"})
+	want := []string{"InProgress", "Done", "InProgress3", "4", "API"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestFieldToSchema_EnumVarNames(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+
+	schema := api.fieldToSchema(parser.Field{Name: "role", Type: parser.FieldTypeEnum, Options: []string{"user", "super-admin"}})
+	if len(schema.EnumVarNames) != 2 || schema.EnumVarNames[1] != "SuperAdmin" {
+		t.Errorf("Expected x-enum-varnames [User SuperAdmin], got %v", schema.EnumVarNames)
+	}
+}
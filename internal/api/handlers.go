@@ -2,7 +2,9 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
@@ -67,6 +69,10 @@ func (api *API) handleList(modelName string) http.HandlerFunc {
 		}
 
 		params := api.parseQueryParams(r)
+		if err := api.checkQueryParams(modelName, params); err != nil {
+			api.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
 		results, err := api.db.Query(modelName, params)
 		if err != nil {
@@ -80,20 +86,10 @@ func (api *API) handleList(modelName string) http.HandlerFunc {
 			return
 		}
 
-		totalPages := int(total) / params.PageSize
-		if int(total)%params.PageSize > 0 {
-			totalPages++
-		}
-
 		api.sendResponse(w, http.StatusOK, parser.APIResponse{
 			Success: true,
 			Data:    results,
-			Meta: &parser.Meta{
-				Page:       params.Page,
-				PageSize:   params.PageSize,
-				TotalCount: total,
-				TotalPages: totalPages,
-			},
+			Meta:    parser.NewMeta(params.Page, params.PageSize, total),
 		})
 	}
 }
@@ -134,12 +130,22 @@ func (api *API) handleCreate(modelName string) http.HandlerFunc {
 			return
 		}
 
+		if !api.jsonBody(r) {
+			api.sendError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+			return
+		}
+
 		var data map[string]any
 		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
 			api.sendError(w, http.StatusBadRequest, "Invalid JSON")
 			return
 		}
 
+		if err := api.validator.Transform(modelName, data); err != nil {
+			api.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		api.validator.Normalize(modelName, data)
 		if err := api.validator.ValidateCreate(modelName, data); err != nil {
 			api.sendError(w, http.StatusBadRequest, err.Error())
 			return
@@ -175,6 +181,11 @@ func (api *API) handleUpdate(modelName string) http.HandlerFunc {
 		vars := mux.Vars(r)
 		id := vars["id"]
 
+		if !api.jsonBody(r) {
+			api.sendError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+			return
+		}
+
 		var data map[string]any
 		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
 			api.sendError(w, http.StatusBadRequest, "Invalid JSON")
@@ -183,10 +194,19 @@ func (api *API) handleUpdate(modelName string) http.HandlerFunc {
 
 		data = api.filterEmptyPasswordFields(modelName, data)
 
+		if err := api.validator.Transform(modelName, data); err != nil {
+			api.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		api.validator.Normalize(modelName, data)
 		if err := api.validator.ValidateUpdate(modelName, data); err != nil {
 			api.sendError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		if err := api.validator.ValidateChanges(modelName, id, data, api.db); err != nil {
+			api.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
 		if err := api.db.Update(modelName, id, data); err != nil {
 			api.sendError(w, http.StatusInternalServerError, err.Error())
@@ -230,38 +250,55 @@ func (api *API) handleDelete(modelName string) http.HandlerFunc {
 
 func (api *API) handleBulk(modelName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var request struct {
-			Operation string           `json:"operation"`
-			Data      []map[string]any `json:"data"`
-			IDs       []any            `json:"ids"`
+		_, err := api.checkPermission(r, modelName, true)
+		if err != nil {
+			api.sendError(w, http.StatusForbidden, err.Error())
+			return
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		if !api.jsonBody(r) {
+			api.sendError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+			return
+		}
+
+		limit := BulkMaxItems(api.config)
+		request, err := DecodeBulkRequest(json.NewDecoder(r.Body), limit)
+		if errors.Is(err, ErrBulkTooLarge) {
+			api.sendError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("bulk requests are limited to %d items", limit))
+			return
+		}
+		if err != nil {
 			api.sendError(w, http.StatusBadRequest, "Invalid JSON")
 			return
 		}
 
 		switch request.Operation {
 		case "create":
-			results := []any{}
-			for _, item := range request.Data {
-				if err := api.validator.ValidateCreate(modelName, item); err != nil {
-					api.sendError(w, http.StatusBadRequest, err.Error())
+			for i, item := range request.Data {
+				if err := api.validator.Transform(modelName, item); err != nil {
+					api.sendError(w, http.StatusBadRequest, fmt.Sprintf("item %d: %v", i, err))
 					return
 				}
-
-				id, err := api.db.Create(modelName, item)
-				if err != nil {
-					api.sendError(w, http.StatusInternalServerError, err.Error())
+				api.validator.Normalize(modelName, item)
+				if err := api.validator.ValidateCreate(modelName, item); err != nil {
+					api.sendError(w, http.StatusBadRequest, fmt.Sprintf("item %d: %v", i, err))
 					return
 				}
+			}
 
+			ids, err := api.createBatch(modelName, request.Data)
+			if err != nil {
+				api.sendError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			results := make([]any, 0, len(ids))
+			for _, id := range ids {
 				result, err := api.db.Get(modelName, id)
 				if err != nil {
 					api.sendError(w, http.StatusInternalServerError, err.Error())
 					return
 				}
-
 				results = append(results, result)
 			}
 
@@ -289,9 +326,10 @@ func (api *API) handleBulk(modelName string) http.HandlerFunc {
 }
 
 func (api *API) parseQueryParams(r *http.Request) parser.QueryParams {
+	defaultSize, maxSize := api.config.Server.Pagination.Limits()
 	params := parser.QueryParams{
 		Page:     1,
-		PageSize: 20,
+		PageSize: defaultSize,
 	}
 
 	if page := r.URL.Query().Get("page"); page != "" {
@@ -301,7 +339,7 @@ func (api *API) parseQueryParams(r *http.Request) parser.QueryParams {
 	}
 
 	if pageSize := r.URL.Query().Get("page_size"); pageSize != "" {
-		if ps, err := strconv.Atoi(pageSize); err == nil && ps > 0 && ps <= 100 {
+		if ps, err := strconv.Atoi(pageSize); err == nil && ps > 0 && ps <= maxSize {
 			params.PageSize = ps
 		}
 	}
@@ -336,6 +374,35 @@ func (api *API) parseQueryParams(r *http.Request) parser.QueryParams {
 	return params
 }
 
+func (api *API) checkQueryParams(modelName string, params parser.QueryParams) error {
+	for _, sort := range params.Sort {
+		if _, ok := api.schema.GetField(modelName, sort.Field); !ok && sort.Field != "id" {
+			return fmt.Errorf("unknown sort field '%s'", sort.Field)
+		}
+	}
+	model, _ := api.schema.GetModel(modelName)
+	for _, filter := range params.Filters {
+		if model != nil && model.HasJSONPath(filter.Field) {
+			continue
+		}
+		if _, ok := api.schema.GetField(modelName, filter.Field); !ok && filter.Field != "id" {
+			return fmt.Errorf("unknown filter field '%s'", filter.Field)
+		}
+	}
+	return nil
+}
+
+// jsonBody reports whether a write is sent as JSON. A request without a
+// Content-Type is read as JSON unless server.strict_content_type is set.
+func (api *API) jsonBody(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return api.config == nil || !api.config.Server.StrictContentType
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
 func (api *API) sendResponse(w http.ResponseWriter, status int, response parser.APIResponse) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
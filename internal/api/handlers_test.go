@@ -136,6 +136,41 @@ func (m *MockDatabase) Delete(model string, id interface{}) error {
 	return nil
 }
 
+func (m *MockDatabase) Restore(model string, id interface{}) error {
+	if m.shouldError {
+		return parser.ValidationError{Message: "restore failed"}
+	}
+	return nil
+}
+
+func (m *MockDatabase) Aggregate(model string, function string, field string, filters []parser.Filter) (float64, error) {
+	if m.shouldError {
+		return 0, parser.ValidationError{Message: "aggregate failed"}
+	}
+	
+	var total, count float64
+	for _, record := range m.data {
+		if record["_model"] != model {
+			continue
+		}
+		count++
+		if value, ok := record[field].(float64); ok {
+			total += value
+		}
+	}
+	
+	switch function {
+	case "count":
+		return count, nil
+	case "avg":
+		if count == 0 {
+			return 0, nil
+		}
+		return total / count, nil
+	}
+	return total, nil
+}
+
 func (m *MockDatabase) Count(model string, filters []parser.Filter) (int64, error) {
 	if m.shouldError {
 		return 0, parser.ValidationError{Message: "count failed"}
@@ -377,6 +412,20 @@ func TestAPI_HandleCreate_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestAPI_HandleCreate_UnsupportedMediaType(t *testing.T) {
+	api := createTestAPI()
+
+	req := httptest.NewRequest("POST", "/api/user", strings.NewReader(`{"name": "Jane Doe"}`))
+	req.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+
+	api.handleCreate("User")(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status %d, got %d", http.StatusUnsupportedMediaType, w.Code)
+	}
+}
+
 func TestAPI_HandleCreate_ValidationError(t *testing.T) {
 	api := createTestAPI()
 
@@ -531,6 +580,85 @@ func TestAPI_HandleBulk_Delete(t *testing.T) {
 	}
 }
 
+func TestAPI_HandleBulk_TooManyItems(t *testing.T) {
+	api := createTestAPI()
+
+	var body strings.Builder
+	body.WriteString(`{"operation":"create","data":[`)
+	for i := 0; i < 100000; i++ {
+		if i > 0 {
+			body.WriteString(",")
+		}
+		fmt.Fprintf(&body, `{"name":"User %d","email":"user%d@example.com"}`, i, i)
+	}
+	body.WriteString("]}")
+
+	req := httptest.NewRequest("POST", "/api/user/bulk", strings.NewReader(body.String()))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	api.handleBulk("User")(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+
+	mockDB := api.db.(*MockDatabase)
+	if count, _ := mockDB.Count("User", nil); count != 0 {
+		t.Errorf("Expected no records to be created, got %d", count)
+	}
+}
+
+func TestAPI_HandleBulk_ConfiguredLimit(t *testing.T) {
+	api := createTestAPI()
+	api.config.Server.BulkMaxItems = 2
+
+	bulkData := map[string]interface{}{
+		"operation": "delete",
+		"ids":       []interface{}{1, 2, 3},
+	}
+
+	jsonData, _ := json.Marshal(bulkData)
+	req := httptest.NewRequest("POST", "/api/user/bulk", bytes.NewReader(jsonData))
+	w := httptest.NewRecorder()
+
+	api.handleBulk("User")(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+}
+
+func TestAPI_HandleBulk_InvalidItemCreatesNothing(t *testing.T) {
+	api := createTestAPI()
+
+	bulkData := map[string]interface{}{
+		"operation": "create",
+		"data": []map[string]interface{}{
+			{"name": "User 1", "email": "user1@example.com"},
+			{"name": "User 2"},
+		},
+	}
+
+	jsonData, _ := json.Marshal(bulkData)
+	req := httptest.NewRequest("POST", "/api/user/bulk", bytes.NewReader(jsonData))
+	w := httptest.NewRecorder()
+
+	api.handleBulk("User")(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "item 1") {
+		t.Errorf("Expected error to name item 1, got %s", w.Body.String())
+	}
+
+	mockDB := api.db.(*MockDatabase)
+	if count, _ := mockDB.Count("User", nil); count != 0 {
+		t.Errorf("Expected no records to be created, got %d", count)
+	}
+}
+
 func TestAPI_HandleBulk_InvalidOperation(t *testing.T) {
 	api := createTestAPI()
 
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
+	"github.com/yamlforge/yamlforge/internal/auth"
 	"github.com/yamlforge/yamlforge/internal/parser"
 )
 
@@ -13,10 +15,16 @@ type OpenAPISpec struct {
 	OpenAPI    string              `json:"openapi"`
 	Info       OpenAPIInfo         `json:"info"`
 	Servers    []OpenAPIServer     `json:"servers"`
+	Tags       []OpenAPITag        `json:"tags,omitempty"`
 	Paths      map[string]PathItem `json:"paths"`
 	Components OpenAPIComponents   `json:"components"`
 }
 
+type OpenAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
 type OpenAPIInfo struct {
 	Title       string `json:"title"`
 	Description string `json:"description,omitempty"`
@@ -39,6 +47,7 @@ type Operation struct {
 	RequestBody *RequestBody          `json:"requestBody,omitempty"`
 	Responses   map[string]Response   `json:"responses"`
 	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
 }
 
 type Parameter struct {
@@ -78,6 +87,14 @@ type Schema struct {
 	Maximum     *int               `json:"maximum,omitempty"`
 	MinLength   *int               `json:"minLength,omitempty"`
 	MaxLength   *int               `json:"maxLength,omitempty"`
+	ReadOnly    bool               `json:"readOnly,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	Deprecated  bool               `json:"deprecated,omitempty"`
+
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+	// EnumVarNames names each Enum value for client generators, matching
+	// the constants of the Go types export.
+	EnumVarNames []string `json:"x-enum-varnames,omitempty"`
 }
 
 type OpenAPIComponents struct {
@@ -94,15 +111,23 @@ type SecurityScheme struct {
 	Name         string `json:"name,omitempty"`
 }
 
-func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
+func BaseURL(config *parser.Config, r *http.Request) string {
+	if config.Server.BaseURL != "" {
+		return strings.TrimRight(config.Server.BaseURL, "/")
+	}
+
 	scheme := "http"
 	if r.TLS != nil {
 		scheme = "https"
 	}
 	host := r.Host
 	if host == "" {
-		host = fmt.Sprintf("%s:%d", api.config.Server.Host, api.config.Server.Port)
+		host = fmt.Sprintf("%s:%d", config.Server.Host, config.Server.Port)
 	}
+	return fmt.Sprintf("%s://%s", scheme, host)
+}
+
+func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 
 	spec := &OpenAPISpec{
 		OpenAPI: "3.0.3",
@@ -113,18 +138,27 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 		},
 		Servers: []OpenAPIServer{
 			{
-				URL:         fmt.Sprintf("%s://%s/api", scheme, host),
+				URL:         BaseURL(api.config, r) + "/api",
 				Description: "API Server",
 			},
 		},
 		Paths: make(map[string]PathItem),
 		Components: OpenAPIComponents{
-			Schemas:         make(map[string]*Schema),
+			Schemas: map[string]*Schema{
+				"Error": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"success": {Type: "boolean"},
+						"error":   {Type: "string"},
+					},
+					Required: []string{"success", "error"},
+				},
+			},
 			SecuritySchemes: make(map[string]SecurityScheme),
 		},
 	}
 
-	if api.config.Server.Auth.Type == "jwt" {
+	if api.usesLogin() {
 		spec.Components.SecuritySchemes["bearerAuth"] = SecurityScheme{
 			Type:         "http",
 			Scheme:       "bearer",
@@ -138,40 +172,56 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 			Description: "Cookie-based JWT authentication",
 		}
 	}
+	if api.config.Server.Auth.Type == "apikey" {
+		spec.Components.SecuritySchemes["apiKeyAuth"] = SecurityScheme{
+			Type:        "apiKey",
+			In:          "header",
+			Name:        auth.APIKeyHeader,
+			Description: "Per-user API key from the server.auth.users config",
+		}
+	}
 
 	var securityReq []map[string][]string
-	if api.config.Server.Auth.Type == "jwt" {
+	if api.usesLogin() {
 		securityReq = []map[string][]string{
 			{"bearerAuth": {}},
 			{"cookieAuth": {}},
 		}
 	}
+	if api.config.Server.Auth.Type == "apikey" {
+		securityReq = append(securityReq, map[string][]string{"apiKeyAuth": {}})
+	}
+
+	minPage := 1
+	defaultSize, maxSize := api.config.Server.Pagination.Limits()
 
 	for modelName, model := range api.schema.Models {
 		spec.Components.Schemas[modelName] = api.generateModelSchema(model)
 		spec.Components.Schemas[modelName+"Input"] = api.generateInputSchema(model)
 
 		basePath := "/" + strings.ToLower(modelName)
+		tags := []string{modelTag(modelName, model)}
 
 		spec.Paths[basePath] = PathItem{
 			"get": Operation{
-				Tags:        []string{modelName},
+				Tags:        tags,
 				Summary:     fmt.Sprintf("List %s", modelName),
 				Description: fmt.Sprintf("Get a paginated list of %s", modelName),
 				OperationID: fmt.Sprintf("list%s", modelName),
 				Security:    securityReq,
+				Deprecated:  model.Deprecated,
 				Parameters: []Parameter{
 					{
 						Name:        "page",
 						In:          "query",
 						Description: "Page number",
-						Schema:      &Schema{Type: "integer", Default: 1},
+						Schema:      &Schema{Type: "integer", Default: 1, Minimum: &minPage},
 					},
 					{
 						Name:        "page_size",
 						In:          "query",
 						Description: "Items per page",
-						Schema:      &Schema{Type: "integer", Default: 20},
+						Schema:      &Schema{Type: "integer", Default: defaultSize, Minimum: &minPage, Maximum: &maxSize},
 					},
 					{
 						Name:        "search",
@@ -185,6 +235,12 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 						Description: "Sort fields (prefix with - for descending)",
 						Schema:      &Schema{Type: "string"},
 					},
+					{
+						Name:        "count",
+						In:          "query",
+						Description: "Set to false to skip counting the total",
+						Schema:      &Schema{Type: "boolean", Default: true},
+					},
 				},
 				Responses: map[string]Response{
 					"200": {
@@ -204,8 +260,9 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 											Properties: map[string]*Schema{
 												"page":        {Type: "integer"},
 												"page_size":   {Type: "integer"},
-												"total_count": {Type: "integer"},
-												"total_pages": {Type: "integer"},
+												"total_count": {Type: "integer", Nullable: true},
+												"total_pages": {Type: "integer", Nullable: true},
+												"estimated":   {Type: "boolean"},
 											},
 										},
 									},
@@ -216,11 +273,12 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 				},
 			},
 			"post": Operation{
-				Tags:        []string{modelName},
+				Tags:        tags,
 				Summary:     fmt.Sprintf("Create %s", modelName),
 				Description: fmt.Sprintf("Create a new %s", modelName),
 				OperationID: fmt.Sprintf("create%s", modelName),
 				Security:    securityReq,
+				Deprecated:  model.Deprecated,
 				RequestBody: &RequestBody{
 					Required: true,
 					Content: map[string]MediaType{
@@ -244,36 +302,24 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 							},
 						},
 					},
-					"400": {
-						Description: "Bad request",
-						Content: map[string]MediaType{
-							"application/json": {
-								Schema: &Schema{
-									Type: "object",
-									Properties: map[string]*Schema{
-										"success": {Type: "boolean"},
-										"error":   {Type: "string"},
-									},
-								},
-							},
-						},
-					},
+					"400": errorResponse("Bad request"),
 				},
 			},
 		}
 
 		spec.Paths[basePath+"/{id}"] = PathItem{
 			"get": Operation{
-				Tags:        []string{modelName},
+				Tags:        tags,
 				Summary:     fmt.Sprintf("Get %s", modelName),
 				Description: fmt.Sprintf("Get a single %s by ID", modelName),
 				OperationID: fmt.Sprintf("get%s", modelName),
 				Security:    securityReq,
+				Deprecated:  model.Deprecated,
 				Parameters: []Parameter{
 					{
 						Name:        "id",
 						In:          "path",
-						Description: fmt.Sprintf("%s ID", modelName),
+						Description: idDescription(model),
 						Required:    true,
 						Schema:      &Schema{Type: "string"},
 					},
@@ -293,33 +339,21 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 							},
 						},
 					},
-					"404": {
-						Description: "Not found",
-						Content: map[string]MediaType{
-							"application/json": {
-								Schema: &Schema{
-									Type: "object",
-									Properties: map[string]*Schema{
-										"success": {Type: "boolean"},
-										"error":   {Type: "string"},
-									},
-								},
-							},
-						},
-					},
+					"404": errorResponse("Not found"),
 				},
 			},
 			"put": Operation{
-				Tags:        []string{modelName},
+				Tags:        tags,
 				Summary:     fmt.Sprintf("Update %s", modelName),
 				Description: fmt.Sprintf("Update an existing %s", modelName),
 				OperationID: fmt.Sprintf("update%s", modelName),
 				Security:    securityReq,
+				Deprecated:  model.Deprecated,
 				Parameters: []Parameter{
 					{
 						Name:        "id",
 						In:          "path",
-						Description: fmt.Sprintf("%s ID", modelName),
+						Description: idDescription(model),
 						Required:    true,
 						Schema:      &Schema{Type: "string"},
 					},
@@ -347,33 +381,21 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 							},
 						},
 					},
-					"404": {
-						Description: "Not found",
-						Content: map[string]MediaType{
-							"application/json": {
-								Schema: &Schema{
-									Type: "object",
-									Properties: map[string]*Schema{
-										"success": {Type: "boolean"},
-										"error":   {Type: "string"},
-									},
-								},
-							},
-						},
-					},
+					"404": errorResponse("Not found"),
 				},
 			},
 			"delete": Operation{
-				Tags:        []string{modelName},
+				Tags:        tags,
 				Summary:     fmt.Sprintf("Delete %s", modelName),
 				Description: fmt.Sprintf("Delete a %s by ID", modelName),
 				OperationID: fmt.Sprintf("delete%s", modelName),
 				Security:    securityReq,
+				Deprecated:  model.Deprecated,
 				Parameters: []Parameter{
 					{
 						Name:        "id",
 						In:          "path",
-						Description: fmt.Sprintf("%s ID", modelName),
+						Description: idDescription(model),
 						Required:    true,
 						Schema:      &Schema{Type: "string"},
 					},
@@ -392,26 +414,16 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 							},
 						},
 					},
-					"404": {
-						Description: "Not found",
-						Content: map[string]MediaType{
-							"application/json": {
-								Schema: &Schema{
-									Type: "object",
-									Properties: map[string]*Schema{
-										"success": {Type: "boolean"},
-										"error":   {Type: "string"},
-									},
-								},
-							},
-						},
-					},
+					"404": errorResponse("Not found"),
 				},
 			},
 		}
 	}
 
-	if api.config.Server.Auth.Type == "jwt" {
+	spec.Tags = api.generateTags()
+
+	if api.usesLogin() {
+		spec.Tags = append(spec.Tags, OpenAPITag{Name: "Authentication", Description: "Login and logout"})
 		spec.Paths["/auth/login"] = PathItem{
 			"post": Operation{
 				Tags:        []string{"Authentication"},
@@ -457,20 +469,7 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 							},
 						},
 					},
-					"401": {
-						Description: "Invalid credentials",
-						Content: map[string]MediaType{
-							"application/json": {
-								Schema: &Schema{
-									Type: "object",
-									Properties: map[string]*Schema{
-										"success": {Type: "boolean"},
-										"error":   {Type: "string"},
-									},
-								},
-							},
-						},
-					},
+					"401": errorResponse("Invalid credentials"),
 				},
 			},
 		}
@@ -531,20 +530,7 @@ func (api *API) GenerateOpenAPI(r *http.Request) *OpenAPISpec {
 							},
 						},
 					},
-					"401": {
-						Description: "Not authenticated",
-						Content: map[string]MediaType{
-							"application/json": {
-								Schema: &Schema{
-									Type: "object",
-									Properties: map[string]*Schema{
-										"success": {Type: "boolean"},
-										"error":   {Type: "string"},
-									},
-								},
-							},
-						},
-					},
+					"401": errorResponse("Not authenticated"),
 				},
 			},
 		}
@@ -558,10 +544,12 @@ func (api *API) generateModelSchema(model *parser.Model) *Schema {
 		Type:       "object",
 		Properties: make(map[string]*Schema),
 		Required:   []string{},
+		Deprecated: model.Deprecated,
 	}
 
 	for _, field := range model.Fields {
 		fieldSchema := api.fieldToSchema(field)
+		fieldSchema.ReadOnly = field.Primary || field.AutoNow || field.AutoNowAdd
 		schema.Properties[field.Name] = fieldSchema
 
 		if field.Required && !field.AutoNow && !field.AutoNowAdd {
@@ -572,11 +560,51 @@ func (api *API) generateModelSchema(model *parser.Model) *Schema {
 	return schema
 }
 
+// The apikey type keeps the JWT login for the web UI alongside the keys.
+func (api *API) usesLogin() bool {
+	return api.config.Server.Auth.Type == "jwt" || api.config.Server.Auth.Type == "apikey"
+}
+
+func modelTag(modelName string, model *parser.Model) string {
+	if model.Tag != "" {
+		return model.Tag
+	}
+	return modelName
+}
+
+func (api *API) generateTags() []OpenAPITag {
+	descriptions := make(map[string][]string)
+	for modelName, model := range api.schema.Models {
+		tag := modelTag(modelName, model)
+		if _, ok := descriptions[tag]; !ok {
+			descriptions[tag] = nil
+		}
+		if model.Description != "" {
+			descriptions[tag] = append(descriptions[tag], model.Description)
+		}
+	}
+
+	var tags []OpenAPITag
+	for name, descs := range descriptions {
+		sort.Strings(descs)
+		tags = append(tags, OpenAPITag{
+			Name:        name,
+			Description: strings.Join(descs, " "),
+		})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return tags
+}
+
 func (api *API) generateInputSchema(model *parser.Model) *Schema {
+	strict := false
 	schema := &Schema{
-		Type:       "object",
-		Properties: make(map[string]*Schema),
-		Required:   []string{},
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		Required:             []string{},
+		Deprecated:           model.Deprecated,
+		AdditionalProperties: &strict,
 	}
 
 	for _, field := range model.Fields {
@@ -599,9 +627,17 @@ func (api *API) fieldToSchema(field parser.Field) *Schema {
 	schema := &Schema{}
 
 	switch field.Type {
-	case parser.FieldTypeID:
-		schema.Type = "integer"
-		schema.Format = "int64"
+	case parser.FieldTypeID, parser.FieldTypeRelation:
+		if field.KeyType.IsString() {
+			schema.Type = "string"
+			schema.Format = string(field.KeyType)
+		} else if api.config != nil && api.config.Server.StringIDs {
+			schema.Type = "string"
+			schema.Format = "int64"
+		} else {
+			schema.Type = "integer"
+			schema.Format = "int64"
+		}
 	case parser.FieldTypeText, parser.FieldTypeEmail, parser.FieldTypePhone,
 		parser.FieldTypeURL, parser.FieldTypeSlug, parser.FieldTypePassword,
 		parser.FieldTypeColor, parser.FieldTypeMarkdown, parser.FieldTypeJSON,
@@ -621,6 +657,9 @@ func (api *API) fieldToSchema(field parser.Field) *Schema {
 		schema.MaxLength = field.Max
 	case parser.FieldTypeNumber:
 		schema.Type = "number"
+		if field.Decimal {
+			schema.Format = "double"
+		}
 		schema.Minimum = field.Min
 		schema.Maximum = field.Max
 	case parser.FieldTypeBoolean:
@@ -637,26 +676,34 @@ func (api *API) fieldToSchema(field parser.Field) *Schema {
 	case parser.FieldTypeEnum:
 		schema.Type = "string"
 		schema.Enum = field.Options
+		schema.EnumVarNames = enumConstNames(field.Options)
 	case parser.FieldTypeArray:
 		schema.Type = "array"
 		schema.Items = &Schema{Type: "string"}
 	case parser.FieldTypeFile, parser.FieldTypeImage:
 		schema.Type = "string"
 		schema.Format = "binary"
-	case parser.FieldTypeRelation:
-		schema.Type = "integer"
-		schema.Format = "int64"
 	case parser.FieldTypeLocation:
 		schema.Type = "object"
 		schema.Properties = map[string]*Schema{
 			"lat": {Type: "number"},
 			"lng": {Type: "number"},
 		}
+	case parser.FieldTypeObject:
+		schema.Type = "object"
+		schema.Properties = make(map[string]*Schema, len(field.Properties))
+		for _, property := range field.Properties {
+			schema.Properties[property.Name] = api.fieldToSchema(property)
+			if property.Required {
+				schema.Required = append(schema.Required, property.Name)
+			}
+		}
 	}
 
 	if field.Default != nil {
 		schema.Default = field.Default
 	}
+	schema.Deprecated = field.Deprecated
 
 	return schema
 }
@@ -713,3 +760,22 @@ func (api *API) HandleSwaggerUI() http.HandlerFunc {
 	}
 }
 
+// errorResponse describes a failure; every error body has the shared
+// Error shape.
+func errorResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content: map[string]MediaType{
+			"application/json": {
+				Schema: &Schema{Ref: "#/components/schemas/Error"},
+			},
+		},
+	}
+}
+
+func idDescription(model *parser.Model) string {
+	if model.HasCompositeKey() {
+		return fmt.Sprintf("%s ID (%s)", model.Name, strings.Join(model.PrimaryKey, parser.KeySeparator))
+	}
+	return fmt.Sprintf("%s ID", model.Name)
+}
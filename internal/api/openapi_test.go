@@ -145,6 +145,36 @@ func TestGenerateOpenAPI_AuthenticationSchemes(t *testing.T) {
 	}
 }
 
+func TestGenerateOpenAPI_APIKeyScheme(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+	api.config.Server.Auth.Type = "apikey"
+
+	req := httptest.NewRequest("GET", "/api/openapi", nil)
+	spec := api.GenerateOpenAPI(req)
+
+	apiKeyAuth, exists := spec.Components.SecuritySchemes["apiKeyAuth"]
+	if !exists {
+		t.Fatal("Expected apiKeyAuth security scheme")
+	}
+	if apiKeyAuth.Type != "apiKey" || apiKeyAuth.In != "header" || apiKeyAuth.Name != "X-API-Key" {
+		t.Errorf("Expected apiKey scheme in header X-API-Key, got: %+v", apiKeyAuth)
+	}
+	if _, exists := spec.Components.SecuritySchemes["bearerAuth"]; !exists {
+		t.Error("Expected bearerAuth to remain available for the login flow")
+	}
+
+	op := spec.Paths["/user"]["get"]
+	found := false
+	for _, requirement := range op.Security {
+		if _, ok := requirement["apiKeyAuth"]; ok {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected list operation to accept apiKeyAuth, got: %v", op.Security)
+	}
+}
+
 func TestGenerateOpenAPI_NoAuth(t *testing.T) {
 	api := createTestAPIForOpenAPI()
 	api.config.Server.Auth.Type = "none"
@@ -284,6 +314,13 @@ func TestGenerateModelSchema(t *testing.T) {
 	if requiredCount != 3 {
 		t.Errorf("Expected 3 required fields, found %d", requiredCount)
 	}
+
+	if !schema.Properties["id"].ReadOnly || !schema.Properties["created_at"].ReadOnly {
+		t.Error("Expected id and created_at to be read-only")
+	}
+	if nameProperty.ReadOnly {
+		t.Error("Expected name to be writable")
+	}
 }
 
 func TestGenerateInputSchema(t *testing.T) {
@@ -304,6 +341,10 @@ func TestGenerateInputSchema(t *testing.T) {
 	if _, exists := schema.Properties["name"]; !exists {
 		t.Error("Expected input schema to include name field")
 	}
+
+	if schema.AdditionalProperties == nil || *schema.AdditionalProperties {
+		t.Error("Expected input schema to forbid unknown properties")
+	}
 }
 
 func TestFieldToSchema_AllTypes(t *testing.T) {
@@ -341,6 +382,10 @@ func TestFieldToSchema_AllTypes(t *testing.T) {
 			field:    parser.Field{Type: parser.FieldTypeNumber},
 			expected: map[string]string{"type": "number"},
 		},
+		{
+			field:    parser.Field{Type: parser.FieldTypeNumber, Decimal: true},
+			expected: map[string]string{"type": "number", "format": "double"},
+		},
 		{
 			field:    parser.Field{Type: parser.FieldTypeBoolean},
 			expected: map[string]string{"type": "boolean"},
@@ -369,6 +414,14 @@ func TestFieldToSchema_AllTypes(t *testing.T) {
 			field:    parser.Field{Type: parser.FieldTypeRelation},
 			expected: map[string]string{"type": "integer", "format": "int64"},
 		},
+		{
+			field:    parser.Field{Type: parser.FieldTypeID, KeyType: parser.KeyTypeULID},
+			expected: map[string]string{"type": "string", "format": "ulid"},
+		},
+		{
+			field:    parser.Field{Type: parser.FieldTypeRelation, KeyType: parser.KeyTypeUUID},
+			expected: map[string]string{"type": "string", "format": "uuid"},
+		},
 		{
 			field:    parser.Field{Type: parser.FieldTypeLocation},
 			expected: map[string]string{"type": "object"},
@@ -620,4 +673,180 @@ func TestOpenAPISpec_CompleteStructure(t *testing.T) {
 	if !hasPageSizeParam {
 		t.Error("Expected page_size parameter")
 	}
-}
\ No newline at end of file
+}
+
+func TestFieldToSchema_StringIDs(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+	api.config.Server.StringIDs = true
+
+	schema := api.fieldToSchema(parser.Field{Type: parser.FieldTypeID})
+	if schema.Type != "string" || schema.Format != "int64" {
+		t.Errorf("Expected string/int64 id schema, got %s/%s", schema.Type, schema.Format)
+	}
+}
+
+func TestGenerateOpenAPI_Tags(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+	api.schema.Models["User"].Tag = "Accounts"
+	api.schema.Models["User"].Description = "People who can sign in."
+
+	req := httptest.NewRequest("GET", "/api/openapi", nil)
+	spec := api.GenerateOpenAPI(req)
+
+	tags := map[string]string{}
+	for _, tag := range spec.Tags {
+		tags[tag.Name] = tag.Description
+	}
+
+	if tags["Accounts"] != "People who can sign in." {
+		t.Errorf("Expected Accounts tag with description, got %v", spec.Tags)
+	}
+	if _, ok := tags["Post"]; !ok {
+		t.Errorf("Expected untagged model to fall back to its name, got %v", spec.Tags)
+	}
+	if _, ok := tags["User"]; ok {
+		t.Error("Expected User to be grouped under its configured tag")
+	}
+
+	op := spec.Paths["/user"]["get"]
+	if len(op.Tags) != 1 || op.Tags[0] != "Accounts" {
+		t.Errorf("Expected list operation to use the Accounts tag, got %v", op.Tags)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	config := &parser.Config{Server: parser.ServerConfig{Host: "localhost", Port: 8080}}
+
+	req := httptest.NewRequest("GET", "/api/openapi", nil)
+	req.Host = "internal:8080"
+	if got := BaseURL(config, req); got != "http://internal:8080" {
+		t.Errorf("Expected URL inferred from the request, got %s", got)
+	}
+
+	config.Server.BaseURL = "https://api.example.com/"
+	if got := BaseURL(config, req); got != "https://api.example.com" {
+		t.Errorf("Expected configured base URL, got %s", got)
+	}
+}
+
+func TestGenerateOpenAPI_BaseURL(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+	api.config.Server.BaseURL = "https://api.example.com"
+
+	spec := api.GenerateOpenAPI(httptest.NewRequest("GET", "/api/openapi", nil))
+	if spec.Servers[0].URL != "https://api.example.com/api" {
+		t.Errorf("Expected server URL from base_url, got %s", spec.Servers[0].URL)
+	}
+}
+
+func TestFieldToSchema_ObjectField(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+
+	field := parser.Field{
+		Type: parser.FieldTypeObject,
+		Properties: []parser.Field{
+			{Name: "city", Type: parser.FieldTypeText, Required: true},
+			{Name: "zip", Type: parser.FieldTypeText, Max: &[]int{10}[0]},
+		},
+	}
+
+	schema := api.fieldToSchema(field)
+
+	if schema.Type != "object" {
+		t.Errorf("Expected object type, got: %s", schema.Type)
+	}
+	if schema.Properties["city"] == nil || schema.Properties["city"].Type != "string" {
+		t.Error("Expected nested city property")
+	}
+	if schema.Properties["zip"] == nil || schema.Properties["zip"].MaxLength == nil {
+		t.Error("Expected nested zip property with maxLength")
+	}
+	if len(schema.Required) != 1 || schema.Required[0] != "city" {
+		t.Errorf("Expected required [city], got %v", schema.Required)
+	}
+}
+
+func TestGenerateOpenAPI_PaginationLimits(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+
+	pageSize := func() *Schema {
+		for _, param := range api.GenerateOpenAPI(httptest.NewRequest("GET", "/openapi.json", nil)).Paths["/user"]["get"].Parameters {
+			if param.Name == "page_size" {
+				return param.Schema
+			}
+		}
+		t.Fatal("Expected page_size parameter")
+		return nil
+	}
+
+	schema := pageSize()
+	if schema.Default != parser.DefaultPageSize || schema.Maximum == nil || *schema.Maximum != parser.MaxPageSize {
+		t.Errorf("Expected default %d and maximum %d, got %v and %v", parser.DefaultPageSize, parser.MaxPageSize, schema.Default, schema.Maximum)
+	}
+
+	api.config.Server.Pagination = parser.PaginationConfig{DefaultPageSize: 10, MaxPageSize: 50}
+	schema = pageSize()
+	if schema.Default != 10 || schema.Maximum == nil || *schema.Maximum != 50 {
+		t.Errorf("Expected default 10 and maximum 50, got %v and %v", schema.Default, schema.Maximum)
+	}
+}
+
+func TestGenerateOpenAPI_ErrorComponent(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+	spec := api.GenerateOpenAPI(httptest.NewRequest("GET", "/openapi.json", nil))
+
+	errorSchema, ok := spec.Components.Schemas["Error"]
+	if !ok {
+		t.Fatal("Expected an Error component schema")
+	}
+	if errorSchema.Properties["error"] == nil || errorSchema.Properties["success"] == nil {
+		t.Errorf("Expected Error to declare success and error, got %v", errorSchema.Properties)
+	}
+
+	found := 0
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			for code, response := range op.Responses {
+				if code != "400" && code != "401" && code != "404" {
+					continue
+				}
+				found++
+				if ref := response.Content["application/json"].Schema.Ref; ref != "#/components/schemas/Error" {
+					t.Errorf("Expected %s %s %s to reference the Error schema, got %q", method, path, code, ref)
+				}
+			}
+		}
+	}
+	if found == 0 {
+		t.Error("Expected error responses in the spec")
+	}
+}
+
+func TestGenerateOpenAPI_Deprecated(t *testing.T) {
+	api := createTestAPIForOpenAPI()
+	user := api.schema.Models["User"]
+	user.Deprecated = true
+	for i := range user.Fields {
+		if user.Fields[i].Name == "role" {
+			user.Fields[i].Deprecated = true
+		}
+	}
+	spec := api.GenerateOpenAPI(httptest.NewRequest("GET", "/openapi.json", nil))
+
+	if !spec.Components.Schemas["User"].Properties["role"].Deprecated {
+		t.Error("Expected the role property to be deprecated")
+	}
+	if spec.Components.Schemas["User"].Properties["name"].Deprecated {
+		t.Error("Expected the name property not to be deprecated")
+	}
+	if !spec.Components.Schemas["User"].Deprecated || !spec.Components.Schemas["UserInput"].Deprecated {
+		t.Error("Expected the User schemas to be deprecated")
+	}
+	for _, path := range []string{"/user", "/user/{id}"} {
+		for method, op := range spec.Paths[path] {
+			if !op.Deprecated {
+				t.Errorf("Expected %s %s to be deprecated", method, path)
+			}
+		}
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -17,21 +21,41 @@ import (
 
 type AuthManager struct {
 	config      *parser.AuthConfig
-	db          *sql.DB
+	store       AuthStore
 	jwtKey      []byte
 	expires     time.Duration
 	permissions map[string]map[string]parser.EntityPermission // username -> model -> permissions
+	apiKeys     map[string]string                             // hashed key -> username
+	mailer      Mailer
+	baseURL     string
 }
 
+const APIKeyHeader = "X-API-Key"
+
+const minPasswordLength = 8
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrWeakPassword       = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	ErrPasswordUnchanged  = errors.New("new password must differ from the current one")
+	ErrUserExists         = errors.New("a user with this username or email already exists")
+)
+
+// adminPasswordOutput receives the generated default admin password; it
+// is written once, when the admin is created, and never stored in clear.
+var adminPasswordOutput io.Writer = os.Stdout
+
 type User struct {
-	ID          int64                                        `json:"id"`
-	Username    string                                       `json:"username"`
-	Email       string                                       `json:"email"`
-	Password    string                                       `json:"-"`
-	Role        string                                       `json:"role"`
-	Active      bool                                         `json:"active"`
-	CreatedAt   time.Time                                    `json:"created_at"`
-	Permissions map[string]parser.EntityPermission          `json:"permissions,omitempty"`
+	ID                 int64                              `json:"id"`
+	Username           string                             `json:"username"`
+	Email              string                             `json:"email"`
+	Password           string                             `json:"-"`
+	Role               string                             `json:"role"`
+	Active             bool                               `json:"active"`
+	MustChangePassword bool                               `json:"must_change_password,omitempty"`
+	EmailVerified      bool                               `json:"email_verified"`
+	CreatedAt          time.Time                          `json:"created_at"`
+	Permissions        map[string]parser.EntityPermission `json:"permissions,omitempty"`
 }
 
 type Claims struct {
@@ -43,11 +67,20 @@ type Claims struct {
 }
 
 func New(config *parser.AuthConfig, db *sql.DB) (*AuthManager, error) {
-	if config.Type != "jwt" {
+	return NewWithStore(config, NewSQLiteStore(db))
+}
+
+func NewWithStore(config *parser.AuthConfig, store AuthStore) (*AuthManager, error) {
+	if config.Type != "jwt" && config.Type != "apikey" {
 		return nil, fmt.Errorf("unsupported auth type: %s", config.Type)
 	}
 
 	if config.Secret == "" {
+		if config.RequireSecret {
+			return nil, errors.New("auth secret is required but not configured")
+		}
+		// Tokens signed with a generated secret stop verifying on restart.
+		log.Println("Warning: no auth secret configured; generated a temporary one, so sessions will not survive a restart")
 		config.Secret = generateRandomSecret()
 	}
 
@@ -61,13 +94,22 @@ func New(config *parser.AuthConfig, db *sql.DB) (*AuthManager, error) {
 
 	am := &AuthManager{
 		config:      config,
-		db:          db,
+		store:       store,
 		jwtKey:      []byte(config.Secret),
 		expires:     expires,
 		permissions: make(map[string]map[string]parser.EntityPermission),
+		apiKeys:     make(map[string]string),
+		mailer:      newMailer(config.EmailVerification),
 	}
 
 	for _, user := range config.Users {
+		if user.APIKey != "" {
+			hashed := hashPassword(user.APIKey)
+			if _, exists := am.apiKeys[hashed]; exists {
+				return nil, fmt.Errorf("user %s reuses another user's api_key", user.Username)
+			}
+			am.apiKeys[hashed] = user.Username
+		}
 		if user.Permissions != nil {
 			for entity, perm := range user.Permissions {
 				if !perm.Read && !perm.Write {
@@ -86,23 +128,11 @@ func New(config *parser.AuthConfig, db *sql.DB) (*AuthManager, error) {
 }
 
 func (am *AuthManager) initAuthTables() error {
-	createUserTable := `
-	CREATE TABLE IF NOT EXISTS auth_users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		username TEXT UNIQUE NOT NULL,
-		email TEXT UNIQUE NOT NULL,
-		password TEXT NOT NULL,
-		role TEXT DEFAULT 'user',
-		active BOOLEAN DEFAULT 1,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	)`
-
-	if _, err := am.db.Exec(createUserTable); err != nil {
+	if err := am.store.Init(); err != nil {
 		return err
 	}
 
-	var count int
-	err := am.db.QueryRow("SELECT COUNT(*) FROM auth_users").Scan(&count)
+	count, err := am.store.CountUsers()
 	if err != nil {
 		return err
 	}
@@ -123,24 +153,22 @@ func (am *AuthManager) initAuthTables() error {
 				if !user.Active && user.Username != "" {
 					active = true
 				}
-				
-				_, err = am.db.Exec(
-					"INSERT INTO auth_users (username, email, password, role, active) VALUES (?, ?, ?, ?, ?)",
-					user.Username, email, hashedPassword, role, active,
-				)
-				if err != nil {
+
+				if err := am.store.CreateUser(user.Username, email, hashedPassword, role, active); err != nil {
 					return fmt.Errorf("failed to create user %s: %w", user.Username, err)
 				}
 			}
-		} else {
-			hashedPassword := hashPassword("admin123")
-			_, err = am.db.Exec(
-				"INSERT INTO auth_users (username, email, password, role) VALUES (?, ?, ?, ?)",
-				"admin", "admin@example.com", hashedPassword, "admin",
-			)
-			if err != nil {
+		} else if am.config.DefaultAdmin {
+			password := generateRandomPassword()
+			if err := am.store.CreateUser("admin", "admin@example.com", hashPassword(password), "admin", true); err != nil {
 				return err
 			}
+			if err := am.store.RequirePasswordChange("admin"); err != nil {
+				return err
+			}
+			fmt.Fprintf(adminPasswordOutput, "Created default admin user \"admin\" with password: %s\nThis password is shown only once and must be changed at first login.\n", password)
+		} else {
+			log.Println("Warning: no users configured and default_admin is disabled; nobody can log in")
 		}
 	}
 
@@ -148,36 +176,23 @@ func (am *AuthManager) initAuthTables() error {
 }
 
 func (am *AuthManager) Authenticate(username, password string) (*User, error) {
-	var user User
-	var hashedPassword string
-
-	query := `
-		SELECT id, username, email, password, role, active, created_at 
-		FROM auth_users 
-		WHERE (username = ? OR email = ?) AND active = 1
-	`
-
-	err := am.db.QueryRow(query, username, username).Scan(
-		&user.ID, &user.Username, &user.Email, &hashedPassword,
-		&user.Role, &user.Active, &user.CreatedAt,
-	)
-
+	user, hashedPassword, err := am.store.FindActiveUser(username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("invalid credentials")
+			return nil, ErrInvalidCredentials
 		}
 		return nil, err
 	}
 
 	if !verifyPassword(password, hashedPassword) {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	if perms, exists := am.permissions[user.Username]; exists {
 		user.Permissions = perms
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (am *AuthManager) GenerateToken(user *User) (string, error) {
@@ -263,45 +278,64 @@ func (am *AuthManager) ClearAuthCookie(w http.ResponseWriter) {
 	})
 }
 
+// CreateUser adds an active user. With email verification on, the user
+// starts unverified and is mailed a token to confirm their address.
 func (am *AuthManager) CreateUser(username, email, password, role string) error {
+	if len(password) < minPasswordLength {
+		return ErrWeakPassword
+	}
 	hashedPassword := hashPassword(password)
-	
-	_, err := am.db.Exec(
-		"INSERT INTO auth_users (username, email, password, role) VALUES (?, ?, ?, ?)",
-		username, email, hashedPassword, role,
-	)
-	
-	return err
+
+	if err := am.store.CreateUser(username, email, hashedPassword, role, true); err != nil {
+		if isDuplicate(err) {
+			return ErrUserExists
+		}
+		return err
+	}
+	if am.VerificationEnabled() {
+		return am.SendVerification(username, email)
+	}
+	return nil
+}
+
+// isDuplicate reports a unique constraint violation from SQLite or
+// PostgreSQL, whose drivers share no error type.
+func isDuplicate(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "UNIQUE constraint failed") || strings.Contains(message, "duplicate key")
+}
+
+// ChangePassword replaces a user's password after checking the current
+// one, and clears any pending requirement to change it.
+func (am *AuthManager) ChangePassword(username, current, next string) error {
+	user, err := am.Authenticate(username, current)
+	if err != nil {
+		return err
+	}
+	if len(next) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	if next == current {
+		return ErrPasswordUnchanged
+	}
+	return am.store.ChangePassword(user.ID, hashPassword(next))
 }
 
 func (am *AuthManager) GetUserByID(id int64) (*User, error) {
-	var user User
-	
-	query := `
-		SELECT id, username, email, role, active, created_at 
-		FROM auth_users 
-		WHERE id = ?
-	`
-	
-	err := am.db.QueryRow(query, id).Scan(
-		&user.ID, &user.Username, &user.Email,
-		&user.Role, &user.Active, &user.CreatedAt,
-	)
-	
+	user, err := am.store.GetUserByID(id)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if perms, exists := am.permissions[user.Username]; exists {
 		user.Permissions = perms
 	}
-	
-	return &user, nil
+
+	return user, nil
 }
 
 func (am *AuthManager) CheckPermission(username, modelName string, write bool) bool {
-	var role string
-	err := am.db.QueryRow("SELECT role FROM auth_users WHERE username = ?", username).Scan(&role)
+	role, err := am.store.GetUserRole(username)
 	if err == nil && role == "admin" {
 		return true
 	}
@@ -318,7 +352,33 @@ func (am *AuthManager) CheckPermission(username, modelName string, write bool) b
 	return false
 }
 
+func (am *AuthManager) UsesAPIKeys() bool {
+	return am.config.Type == "apikey"
+}
+
+func (am *AuthManager) GetUserFromAPIKey(key string) (*User, error) {
+	username, ok := am.apiKeys[hashPassword(key)]
+	if !ok {
+		return nil, errors.New("invalid api key")
+	}
+
+	user, _, err := am.store.FindActiveUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if perms, exists := am.permissions[user.Username]; exists {
+		user.Permissions = perms
+	}
+
+	return user, nil
+}
+
 func (am *AuthManager) GetUserFromToken(r *http.Request) (*User, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" && am.UsesAPIKeys() {
+		return am.GetUserFromAPIKey(key)
+	}
+
 	token, err := am.GetTokenFromRequest(r)
 	if err != nil {
 		return nil, err
@@ -346,6 +406,12 @@ func verifyPassword(password, hashedPassword string) bool {
 	return hashPassword(password) == hashedPassword
 }
 
+func generateRandomPassword() string {
+	bytes := make([]byte, 18)
+	rand.Read(bytes)
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
 func generateRandomSecret() string {
 	bytes := make([]byte, 32)
 	rand.Read(bytes)
@@ -2,10 +2,14 @@ package auth
 
 import (
 	"database/sql"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -95,6 +99,23 @@ func TestNew_AutoGenerateSecret(t *testing.T) {
 	}
 }
 
+func TestNew_RequireSecret(t *testing.T) {
+	config := &parser.AuthConfig{
+		Type:          "jwt",
+		RequireSecret: true,
+	}
+
+	db := createTestDB(t)
+	defer db.Close()
+
+	if _, err := New(config, db); err == nil {
+		t.Error("Expected error when require_secret is set without a secret")
+	}
+	if config.Secret != "" {
+		t.Error("Expected no secret to be generated")
+	}
+}
+
 func TestNew_ParseExpiry(t *testing.T) {
 	config := &parser.AuthConfig{
 		Type:    "jwt",
@@ -225,7 +246,7 @@ func TestInitAuthTables(t *testing.T) {
 
 	authManager := &AuthManager{
 		config: config,
-		db:     db,
+		store:  NewSQLiteStore(db),
 	}
 
 	err := authManager.initAuthTables()
@@ -255,16 +276,20 @@ func TestInitAuthTables(t *testing.T) {
 
 func TestInitAuthTables_DefaultAdmin(t *testing.T) {
 	config := &parser.AuthConfig{
-		Type:   "jwt",
-		Secret: "test-secret",
+		Type:         "jwt",
+		Secret:       "test-secret",
+		DefaultAdmin: true,
 	}
 
+	adminPasswordOutput = io.Discard
+	defer func() { adminPasswordOutput = os.Stdout }()
+
 	db := createTestDB(t)
 	defer db.Close()
 
 	authManager := &AuthManager{
 		config: config,
-		db:     db,
+		store:  NewSQLiteStore(db),
 	}
 
 	err := authManager.initAuthTables()
@@ -556,6 +581,55 @@ func TestGetTokenFromRequest_NoToken(t *testing.T) {
 	}
 }
 
+func TestGetUserFromToken_APIKey(t *testing.T) {
+	config := &parser.AuthConfig{
+		Type:   "apikey",
+		Secret: "test-secret",
+		Users: []parser.UserConfig{
+			{Username: "bot", Password: "bot123", Role: "user", APIKey: "key-123"},
+		},
+	}
+	db := createTestDB(t)
+	defer db.Close()
+
+	authManager, err := New(config, db)
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(APIKeyHeader, "key-123")
+
+	user, err := authManager.GetUserFromToken(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if user.Username != "bot" {
+		t.Errorf("Expected user 'bot', got: %s", user.Username)
+	}
+
+	req.Header.Set(APIKeyHeader, "wrong")
+	if _, err := authManager.GetUserFromToken(req); err == nil {
+		t.Error("Expected error for unknown api key")
+	}
+}
+
+func TestNew_DuplicateAPIKey(t *testing.T) {
+	config := &parser.AuthConfig{
+		Type: "apikey",
+		Users: []parser.UserConfig{
+			{Username: "a", Password: "a", APIKey: "same"},
+			{Username: "b", Password: "b", APIKey: "same"},
+		},
+	}
+	db := createTestDB(t)
+	defer db.Close()
+
+	if _, err := New(config, db); err == nil {
+		t.Error("Expected error for duplicate api keys")
+	}
+}
+
 func TestSetAuthCookie(t *testing.T) {
 	config := &parser.AuthConfig{Type: "jwt", Secret: "test-secret"}
 	db := createTestDB(t)
@@ -867,4 +941,233 @@ models:
 
 func containsString(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0
-}
\ No newline at end of file
+}
+
+func TestNewStore(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	if _, err := NewStore("sqlite", db); err != nil {
+		t.Errorf("Expected SQLite store, got error: %v", err)
+	}
+	for _, dbType := range []string{"postgresql", "oracle"} {
+		if _, err := NewStore(dbType, db); err == nil {
+			t.Errorf("Expected error for unsupported database type %s", dbType)
+		}
+	}
+}
+
+func TestNewWithStore(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	var out strings.Builder
+	adminPasswordOutput = &out
+	defer func() { adminPasswordOutput = os.Stdout }()
+
+	config := &parser.AuthConfig{Type: "jwt", Secret: "test-secret", DefaultAdmin: true}
+	authManager, err := NewWithStore(config, NewSQLiteStore(db))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	match := regexp.MustCompile(`password: (\S+)`).FindStringSubmatch(out.String())
+	if match == nil {
+		t.Fatalf("Expected the generated admin password to be printed, got %q", out.String())
+	}
+	password := match[1]
+	if len(password) < minPasswordLength {
+		t.Errorf("Expected a strong generated password, got %q", password)
+	}
+
+	if _, err := authManager.Authenticate("admin", "admin123"); err == nil {
+		t.Error("Expected the old fixed default password to be rejected")
+	}
+
+	user, err := authManager.Authenticate("admin", password)
+	if err != nil {
+		t.Fatalf("Expected default admin to authenticate, got: %v", err)
+	}
+	if user.Role != "admin" {
+		t.Errorf("Expected admin role, got %s", user.Role)
+	}
+	if !user.MustChangePassword {
+		t.Error("Expected default admin to be required to change the password")
+	}
+
+	if err := authManager.ChangePassword("admin", password, "short"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("Expected ErrWeakPassword, got %v", err)
+	}
+	if err := authManager.ChangePassword("admin", password, "a-new-secret"); err != nil {
+		t.Fatalf("Expected password change to succeed, got: %v", err)
+	}
+	user, err = authManager.Authenticate("admin", "a-new-secret")
+	if err != nil {
+		t.Fatalf("Expected the new password to authenticate, got: %v", err)
+	}
+	if user.MustChangePassword {
+		t.Error("Expected the password change requirement to be cleared")
+	}
+
+	out.Reset()
+	if _, err := NewWithStore(config, NewSQLiteStore(db)); err != nil {
+		t.Fatalf("Expected no error on restart, got: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected the admin password to be printed only once, got %q", out.String())
+	}
+}
+
+func TestNewWithStore_DefaultAdminDisabled(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	store := NewSQLiteStore(db)
+	if _, err := NewWithStore(&parser.AuthConfig{Type: "jwt", Secret: "test-secret"}, store); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	count, err := store.CountUsers()
+	if err != nil {
+		t.Fatalf("Failed to count users: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no default admin, got %d users", count)
+	}
+}
+
+func TestSQLiteStore_InitAddsPasswordChangeColumn(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	if _, err := db.Exec(`CREATE TABLE auth_users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT UNIQUE NOT NULL,
+		email TEXT UNIQUE NOT NULL,
+		password TEXT NOT NULL,
+		role TEXT DEFAULT 'user',
+		active BOOLEAN DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		t.Fatalf("Failed to create legacy table: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO auth_users (username, email, password) VALUES ('old', 'old@example.com', 'x')"); err != nil {
+		t.Fatalf("Failed to insert legacy user: %v", err)
+	}
+
+	store := NewSQLiteStore(db)
+	for i := 0; i < 2; i++ {
+		if err := store.Init(); err != nil {
+			t.Fatalf("Expected Init to upgrade the table, got: %v", err)
+		}
+	}
+	if err := store.RequirePasswordChange("nobody"); err != nil {
+		t.Errorf("Expected must_change_password column to exist, got: %v", err)
+	}
+	user, _, err := store.FindActiveUser("old")
+	if err != nil {
+		t.Fatalf("Expected legacy user to be readable, got: %v", err)
+	}
+	if !user.EmailVerified {
+		t.Error("Expected existing users to count as verified")
+	}
+}
+
+type recordingMailer struct {
+	to, body []string
+}
+
+func (m *recordingMailer) Send(to, subject, body string) error {
+	m.to = append(m.to, to)
+	m.body = append(m.body, body)
+	return nil
+}
+
+func TestCreateUser_EmailVerification(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	config := &parser.AuthConfig{
+		Type:              "jwt",
+		Secret:            "test-secret",
+		Users:             []parser.UserConfig{{Username: "seed", Password: "seed-password"}},
+		EmailVerification: &parser.EmailVerificationConfig{VerifyURL: "https://app.example.com/verify?from=mail"},
+		RequireVerified:   true,
+	}
+	authManager, err := NewWithStore(config, NewSQLiteStore(db))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	mailer := &recordingMailer{}
+	authManager.SetMailer(mailer)
+
+	seed, err := authManager.Authenticate("seed", "seed-password")
+	if err != nil {
+		t.Fatalf("Expected seeded user to authenticate, got: %v", err)
+	}
+	if !seed.EmailVerified || authManager.RequiresVerification(seed) {
+		t.Error("Expected configured users to be verified")
+	}
+
+	if err := authManager.CreateUser("newbie", "newbie@example.com", "password123", "user"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	user, err := authManager.Authenticate("newbie", "password123")
+	if err != nil {
+		t.Fatalf("Expected new user to authenticate, got: %v", err)
+	}
+	if user.EmailVerified || !authManager.RequiresVerification(user) {
+		t.Fatal("Expected a new user to start unverified")
+	}
+
+	if len(mailer.to) != 1 || mailer.to[0] != "newbie@example.com" {
+		t.Fatalf("Expected one verification mail to newbie@example.com, got %v", mailer.to)
+	}
+	match := regexp.MustCompile(`https://app\.example\.com/verify\?from=mail&token=(\S+)`).FindStringSubmatch(mailer.body[0])
+	if match == nil {
+		t.Fatalf("Expected a verification link in the mail, got %q", mailer.body[0])
+	}
+
+	if err := authManager.VerifyEmail("bogus"); err != ErrInvalidVerificationToken {
+		t.Errorf("Expected an unknown token to be rejected, got: %v", err)
+	}
+	if err := authManager.VerifyEmail(match[1]); err != nil {
+		t.Fatalf("Expected the mailed token to verify, got: %v", err)
+	}
+	if err := authManager.VerifyEmail(match[1]); err != ErrInvalidVerificationToken {
+		t.Errorf("Expected a used token to be rejected, got: %v", err)
+	}
+
+	user, _ = authManager.GetUserByID(user.ID)
+	if !user.EmailVerified {
+		t.Error("Expected the user to be verified after confirming")
+	}
+}
+
+func TestVerificationLink_BaseURLFallback(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	authManager, err := NewWithStore(&parser.AuthConfig{
+		Type:              "jwt",
+		Secret:            "test-secret",
+		EmailVerification: &parser.EmailVerificationConfig{},
+	}, NewSQLiteStore(db))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if link := authManager.verificationLink("abc"); link != "" {
+		t.Errorf("Expected no link without verify_url or base_url, got %q", link)
+	}
+
+	authManager.SetBaseURL("https://app.example.com/")
+	if link := authManager.verificationLink("abc"); link != "https://app.example.com/api/auth/verify-email?token=abc" {
+		t.Errorf("Expected a link under base_url, got %q", link)
+	}
+
+	authManager.config.EmailVerification.VerifyURL = "https://app.example.com/verify"
+	if link := authManager.verificationLink("abc"); link != "https://app.example.com/verify?token=abc" {
+		t.Errorf("Expected verify_url to take precedence, got %q", link)
+	}
+}
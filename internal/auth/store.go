@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+type AuthStore interface {
+	Init() error
+	CountUsers() (int, error)
+	CreateUser(username, email, passwordHash, role string, active bool) error
+	FindActiveUser(login string) (*User, string, error)
+	GetUserByID(id int64) (*User, error)
+	GetUserRole(username string) (string, error)
+	RequirePasswordChange(username string) error
+	ChangePassword(id int64, passwordHash string) error
+	CreateVerification(username, tokenHash string, expires time.Time) error
+	VerifyEmail(tokenHash string, now time.Time) (bool, error)
+}
+
+type sqlAuthStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(db *sql.DB) AuthStore {
+	return &sqlAuthStore{db: db}
+}
+
+func NewStore(dbType string, db *sql.DB) (AuthStore, error) {
+	switch parser.DatabaseType(dbType) {
+	case parser.DatabaseSQLite:
+		return NewSQLiteStore(db), nil
+	default:
+		return nil, fmt.Errorf("authentication is not supported for database type: %s", dbType)
+	}
+}
+
+func (s *sqlAuthStore) Init() error {
+	createUserTable := `
+	CREATE TABLE IF NOT EXISTS auth_users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT UNIQUE NOT NULL,
+		email TEXT UNIQUE NOT NULL,
+		password TEXT NOT NULL,
+		role TEXT DEFAULT 'user',
+		active BOOLEAN DEFAULT 1,
+		must_change_password BOOLEAN DEFAULT 0,
+		email_verified BOOLEAN DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	if _, err := s.db.Exec(createUserTable); err != nil {
+		return err
+	}
+
+	// Tables created before these columns existed need them added; SQLite
+	// has no IF NOT EXISTS for columns. Existing users count as verified.
+	columns := []string{
+		"must_change_password BOOLEAN DEFAULT 0",
+		"email_verified BOOLEAN DEFAULT 1",
+	}
+	for _, column := range columns {
+		_, err := s.db.Exec("ALTER TABLE auth_users ADD COLUMN " + column)
+		if err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			return err
+		}
+	}
+
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS auth_email_verifications (
+		token_hash TEXT PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		expires_at BIGINT NOT NULL
+	)`)
+	return err
+}
+
+func (s *sqlAuthStore) CountUsers() (int, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM auth_users").Scan(&count)
+	return count, err
+}
+
+func (s *sqlAuthStore) CreateUser(username, email, passwordHash, role string, active bool) error {
+	_, err := s.db.Exec(
+		"INSERT INTO auth_users (username, email, password, role, active) VALUES (?, ?, ?, ?, ?)",
+		username, email, passwordHash, role, active,
+	)
+	return err
+}
+
+func (s *sqlAuthStore) FindActiveUser(login string) (*User, string, error) {
+	var user User
+	var hashedPassword string
+
+	query := `
+		SELECT id, username, email, password, role, active, must_change_password, email_verified, created_at
+		FROM auth_users
+		WHERE (username = ? OR email = ?) AND active = ?
+	`
+
+	err := s.db.QueryRow(query, login, login, true).Scan(
+		&user.ID, &user.Username, &user.Email, &hashedPassword,
+		&user.Role, &user.Active, &user.MustChangePassword, &user.EmailVerified, &user.CreatedAt,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &user, hashedPassword, nil
+}
+
+func (s *sqlAuthStore) GetUserByID(id int64) (*User, error) {
+	var user User
+
+	query := `
+		SELECT id, username, email, role, active, must_change_password, email_verified, created_at
+		FROM auth_users
+		WHERE id = ?
+	`
+
+	err := s.db.QueryRow(query, id).Scan(
+		&user.ID, &user.Username, &user.Email,
+		&user.Role, &user.Active, &user.MustChangePassword, &user.EmailVerified, &user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (s *sqlAuthStore) GetUserRole(username string) (string, error) {
+	var role string
+	err := s.db.QueryRow("SELECT role FROM auth_users WHERE username = ?", username).Scan(&role)
+	return role, err
+}
+
+func (s *sqlAuthStore) RequirePasswordChange(username string) error {
+	_, err := s.db.Exec("UPDATE auth_users SET must_change_password = ? WHERE username = ?", true, username)
+	return err
+}
+
+func (s *sqlAuthStore) ChangePassword(id int64, passwordHash string) error {
+	_, err := s.db.Exec(
+		"UPDATE auth_users SET password = ?, must_change_password = ? WHERE id = ?",
+		passwordHash, false, id,
+	)
+	return err
+}
+
+// CreateVerification marks the user unverified and records a token that
+// verifies them again until it expires.
+func (s *sqlAuthStore) CreateVerification(username, tokenHash string, expires time.Time) error {
+	if _, err := s.db.Exec("UPDATE auth_users SET email_verified = ? WHERE username = ?", false, username); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(
+		"INSERT INTO auth_email_verifications (token_hash, user_id, expires_at) SELECT ?, id, ? FROM auth_users WHERE username = ?",
+		tokenHash, expires.Unix(), username,
+	)
+	return err
+}
+
+// VerifyEmail marks the token's user verified and drops their outstanding
+// tokens. It reports false when the token is unknown or expired.
+func (s *sqlAuthStore) VerifyEmail(tokenHash string, now time.Time) (bool, error) {
+	var userID int64
+	err := s.db.QueryRow(
+		"SELECT user_id FROM auth_email_verifications WHERE token_hash = ? AND expires_at > ?",
+		tokenHash, now.Unix(),
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := s.db.Exec("UPDATE auth_users SET email_verified = ? WHERE id = ?", true, userID); err != nil {
+		return false, err
+	}
+	_, err = s.db.Exec("DELETE FROM auth_email_verifications WHERE user_id = ?", userID)
+	return err == nil, err
+}
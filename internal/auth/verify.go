@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const defaultVerificationExpiry = 48 * time.Hour
+
+var ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+// VerifyEmailPath is where mailed tokens are posted back, or opened with
+// ?token=... from a verification link.
+const VerifyEmailPath = "/api/auth/verify-email"
+
+// Mailer delivers verification mails.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+type smtpMailer struct {
+	config *parser.EmailVerificationConfig
+}
+
+func (m *smtpMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		host, _, _ := net.SplitHostPort(m.config.SMTPAddr)
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, host)
+	}
+	msg := "From: " + m.config.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n\r\n" +
+		body
+	return smtp.SendMail(m.config.SMTPAddr, auth, m.config.From, []string{to}, []byte(msg))
+}
+
+// logMailer stands in when no SMTP server is configured, so the link can
+// still be picked up from the log during development.
+type logMailer struct{}
+
+func (logMailer) Send(to, subject, body string) error {
+	log.Printf("Email to %s: %s\n%s", to, subject, body)
+	return nil
+}
+
+func newMailer(config *parser.EmailVerificationConfig) Mailer {
+	if config == nil || config.SMTPAddr == "" {
+		return logMailer{}
+	}
+	return &smtpMailer{config: config}
+}
+
+// SetMailer replaces the mailer used for verification mails.
+func (am *AuthManager) SetMailer(m Mailer) {
+	am.mailer = m
+}
+
+// SetBaseURL sets the public URL verification links fall back to when
+// email_verification.verify_url is not configured.
+func (am *AuthManager) SetBaseURL(baseURL string) {
+	am.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// VerificationEnabled reports whether new users must confirm their email.
+func (am *AuthManager) VerificationEnabled() bool {
+	return am.config.EmailVerification != nil
+}
+
+// RequiresVerification reports whether user is held back from writes
+// until they verify their email address.
+func (am *AuthManager) RequiresVerification(user *User) bool {
+	return am.config.RequireVerified && !user.EmailVerified
+}
+
+// SendVerification issues a fresh verification token for username and
+// mails it to email. Earlier tokens stay valid until they expire.
+func (am *AuthManager) SendVerification(username, email string) error {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(bytes)
+
+	expires := defaultVerificationExpiry
+	if d, err := time.ParseDuration(am.config.EmailVerification.Expires); err == nil {
+		expires = d
+	}
+	if err := am.store.CreateVerification(username, hashPassword(token), time.Now().Add(expires)); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Confirm your email address with this token:\n\n%s\n", token)
+	if link := am.verificationLink(token); link != "" {
+		body = fmt.Sprintf("Confirm your email address by opening:\n\n%s\n", link)
+	}
+	return am.mailer.Send(email, "Verify your email address", body)
+}
+
+func (am *AuthManager) verificationLink(token string) string {
+	verifyURL := am.config.EmailVerification.VerifyURL
+	if verifyURL == "" {
+		if am.baseURL == "" {
+			return ""
+		}
+		verifyURL = am.baseURL + VerifyEmailPath
+	}
+	sep := "?"
+	if strings.Contains(verifyURL, "?") {
+		sep = "&"
+	}
+	return verifyURL + sep + url.Values{"token": {token}}.Encode()
+}
+
+// VerifyEmail marks the account holding token as verified.
+func (am *AuthManager) VerifyEmail(token string) error {
+	if token == "" {
+		return ErrInvalidVerificationToken
+	}
+	ok, err := am.store.VerifyEmail(hashPassword(token), time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidVerificationToken
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const auditTable = "_audit_log"
+
+type AuditEntry struct {
+	ID       int64     `json:"id"`
+	Model    string    `json:"model"`
+	RecordID string    `json:"record_id"`
+	Action   string    `json:"action"`
+	User     string    `json:"user"`
+	At       time.Time `json:"at"`
+}
+
+// AuditFilter narrows an audit query. Zero values match everything.
+type AuditFilter struct {
+	Model string
+	User  string
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+type AuditLog interface {
+	RecordAudit(entry AuditEntry) error
+	QueryAudit(filter AuditFilter) ([]AuditEntry, error)
+	PruneAudit(before time.Time) (int64, error)
+}
+
+func (db *SQLiteDB) createAuditTable() error {
+	table := db.quote(auditTable)
+	_, err := db.conn.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (id INTEGER PRIMARY KEY AUTOINCREMENT, model TEXT NOT NULL, record_id TEXT NOT NULL, action TEXT NOT NULL, username TEXT NOT NULL, created_at INTEGER NOT NULL)",
+		table,
+	))
+	if err != nil {
+		return err
+	}
+	_, err = db.conn.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (created_at)", db.quote("idx_audit_created_at"), table))
+	return err
+}
+
+func (db *SQLiteDB) RecordAudit(entry AuditEntry) error {
+	if entry.At.IsZero() {
+		entry.At = time.Now()
+	}
+	_, err := db.execWrite(
+		fmt.Sprintf("INSERT INTO %s (model, record_id, action, username, created_at) VALUES (?, ?, ?, ?, ?)", db.quote(auditTable)),
+		entry.Model, entry.RecordID, entry.Action, entry.User, entry.At.UnixNano(),
+	)
+	return err
+}
+
+func (db *SQLiteDB) QueryAudit(filter AuditFilter) ([]AuditEntry, error) {
+	var where []string
+	var args []any
+	if filter.Model != "" {
+		where = append(where, "model = ?")
+		args = append(args, filter.Model)
+	}
+	if filter.User != "" {
+		where = append(where, "username = ?")
+		args = append(args, filter.User)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until.UnixNano())
+	}
+
+	query := fmt.Sprintf("SELECT id, model, record_id, action, username, created_at FROM %s", db.quote(auditTable))
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := db.reader().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		var at int64
+		if err := rows.Scan(&entry.ID, &entry.Model, &entry.RecordID, &entry.Action, &entry.User, &at); err != nil {
+			return nil, err
+		}
+		entry.At = time.Unix(0, at).UTC()
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (db *SQLiteDB) PruneAudit(before time.Time) (int64, error) {
+	result, err := db.execWrite(
+		fmt.Sprintf("DELETE FROM %s WHERE created_at < ?", db.quote(auditTable)),
+		before.UnixNano(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type BatchCreator interface {
+	CreateBatch(model string, items []map[string]any) ([]any, error)
+}
+
+// CreateBatch inserts every item in a single transaction; if any insert
+// fails nothing is kept and the error names the offending item.
+func (db *SQLiteDB) CreateBatch(model string, items []map[string]any) ([]any, error) {
+	var ids []any
+	err := db.inTx(func(tx *sql.Tx) error {
+		ids = make([]any, 0, len(items))
+		for i, item := range items {
+			id, err := db.insert(txExec(tx), model, item)
+			if err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
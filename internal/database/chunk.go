@@ -0,0 +1,210 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// SQLite rejects statements with more than 999 bound variables on older
+// builds, so long IN lists are split into several queries.
+const defaultMaxInValues = 500
+
+func (db *SQLiteDB) maxInValues() int {
+	if db.config != nil && db.config.MaxInValues > 0 {
+		return db.config.MaxInValues
+	}
+	return defaultMaxInValues
+}
+
+func (db *SQLiteDB) oversizedInFilter(filters []parser.Filter) int {
+	for i, filter := range filters {
+		if filter.Operator != "in" {
+			continue
+		}
+		if values, ok := filter.Value.([]any); ok && len(values) > db.maxInValues() {
+			return i
+		}
+	}
+	return -1
+}
+
+func (db *SQLiteDB) chunkFilters(filters []parser.Filter, index int) [][]parser.Filter {
+	values := uniqueValues(filters[index].Value.([]any))
+	size := db.maxInValues()
+
+	var chunks [][]parser.Filter
+	for start := 0; start < len(values); start += size {
+		end := start + size
+		if end > len(values) {
+			end = len(values)
+		}
+		chunk := append([]parser.Filter{}, filters...)
+		chunk[index].Value = values[start:end]
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// Columns the chunked selects add for a search without an explicit sort,
+// so the merged rows can be ranked the way a single query orders them.
+const (
+	relevanceColumn = "_relevance"
+	matchesColumn   = "_matches"
+)
+
+func (db *SQLiteDB) queryChunked(model string, params parser.QueryParams, index int) ([]map[string]any, error) {
+	m, _ := db.getModel(model)
+	var rank, matches string
+	var scoreArgs []any
+	if m != nil && len(params.Sort) == 0 {
+		rank, matches, scoreArgs = db.relevanceScores(m, params.Search)
+	}
+
+	results := []map[string]any{}
+	for _, filters := range db.chunkFilters(params.Filters, index) {
+		chunk := params
+		chunk.Filters = filters
+		chunk.Page = 1
+		chunk.PageSize = 0
+
+		var rows []map[string]any
+		var err error
+		if rank != "" {
+			query, args := db.buildSelectQuery(model, chunk)
+			query = fmt.Sprintf("SELECT *, %s AS %s, %s AS %s%s", rank, db.quote(relevanceColumn), matches, db.quote(matchesColumn), strings.TrimPrefix(query, "SELECT *"))
+			rows, err = db.queryRows(model, query, append(append([]any{}, scoreArgs...), args...))
+		} else {
+			rows, err = db.Query(model, chunk)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rows...)
+	}
+
+	if rank != "" {
+		sorts := []parser.SortField{{Field: relevanceColumn, Desc: true}, {Field: matchesColumn, Desc: true}}
+		sortRecords(results, append(sorts, db.expandKeySort(model, []parser.SortField{{Field: "id", Desc: true}})...))
+		for _, record := range results {
+			delete(record, relevanceColumn)
+			delete(record, matchesColumn)
+		}
+	} else {
+		sortRecords(results, params.Sort)
+	}
+
+	if params.PageSize > 0 {
+		offset := (params.Page - 1) * params.PageSize
+		if offset < 0 {
+			offset = 0
+		}
+		if offset >= len(results) {
+			return []map[string]any{}, nil
+		}
+		end := offset + params.PageSize
+		if end > len(results) {
+			end = len(results)
+		}
+		results = results[offset:end]
+	}
+
+	return results, nil
+}
+
+func (db *SQLiteDB) countChunked(model string, filters []parser.Filter, index int) (int64, error) {
+	var total int64
+	for _, chunk := range db.chunkFilters(filters, index) {
+		count, err := db.Count(model, chunk)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+func uniqueValues(values []any) []any {
+	seen := make(map[string]bool, len(values))
+	unique := make([]any, 0, len(values))
+	for _, value := range values {
+		key := fmt.Sprint(value)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
+
+func sortRecords(records []map[string]any, fields []parser.SortField) {
+	if len(fields) == 0 {
+		fields = []parser.SortField{{Field: "id", Desc: true}}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		for _, field := range fields {
+			c := compareValues(records[i][field.Field], records[j][field.Field])
+			if c == 0 {
+				continue
+			}
+			if field.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if x, ok := numericValue(a); ok {
+		if y, ok := numericValue(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func numericValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
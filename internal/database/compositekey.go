@@ -0,0 +1,120 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// compositeModel returns the model when it is keyed by several columns
+// rather than an id column.
+func (db *DB) compositeModel(model string) (*parser.Model, bool) {
+	m, ok := db.getModel(model)
+	if !ok || !m.HasCompositeKey() {
+		return nil, false
+	}
+	return m, true
+}
+
+// keyCondition matches the record identified by id. A composite id such
+// as "5,3" is split across the key columns; one with the wrong number of
+// parts matches nothing.
+func (db *DB) keyCondition(model string, id any) (string, []any) {
+	m, ok := db.compositeModel(model)
+	if !ok {
+		return "id = ?", []any{id}
+	}
+	values, err := m.SplitKey(id)
+	if err != nil {
+		return "0 = 1", nil
+	}
+	clauses := make([]string, len(m.PrimaryKey))
+	for i, column := range m.PrimaryKey {
+		clauses[i] = db.quote(column) + " = ?"
+	}
+	return strings.Join(clauses, " AND "), values
+}
+
+// keyOrder is the default ordering, newest key first.
+func (db *DB) keyOrder(model string) string {
+	columns := []string{"id"}
+	if m, ok := db.compositeModel(model); ok {
+		columns = m.PrimaryKey
+	}
+	clauses := make([]string, len(columns))
+	for i, column := range columns {
+		clauses[i] = db.quote(column) + " DESC"
+	}
+	return strings.Join(clauses, ", ")
+}
+
+// expandKeySort rewrites a sort on "id" into a sort on the key columns.
+func (db *DB) expandKeySort(model string, sorts []parser.SortField) []parser.SortField {
+	m, ok := db.compositeModel(model)
+	if !ok {
+		return sorts
+	}
+	var expanded []parser.SortField
+	for _, sort := range sorts {
+		if sort.Field != "id" {
+			expanded = append(expanded, sort)
+			continue
+		}
+		for _, column := range m.PrimaryKey {
+			expanded = append(expanded, parser.SortField{Field: column, Desc: sort.Desc})
+		}
+	}
+	return expanded
+}
+
+// expandKeyFilters rewrites an equality filter on "id" into one filter
+// per key column.
+func (db *DB) expandKeyFilters(model string, filters []parser.Filter) []parser.Filter {
+	m, ok := db.compositeModel(model)
+	if !ok {
+		return filters
+	}
+	var expanded []parser.Filter
+	for _, filter := range filters {
+		if filter.Field != "id" || filter.Operator != "=" {
+			expanded = append(expanded, filter)
+			continue
+		}
+		values, err := m.SplitKey(filter.Value)
+		if err != nil {
+			// Key columns are NOT NULL, so a malformed id matches nothing.
+			expanded = append(expanded, parser.Filter{Field: m.PrimaryKey[0], Operator: "is_null"})
+			continue
+		}
+		for i, column := range m.PrimaryKey {
+			expanded = append(expanded, parser.Filter{Field: column, Operator: "=", Value: values[i]})
+		}
+	}
+	return expanded
+}
+
+// withCompositeID gives a composite-key record the id its path uses.
+func (db *DB) withCompositeID(model string, row map[string]any) map[string]any {
+	if m, ok := db.compositeModel(model); ok && row != nil {
+		row["id"] = m.CompositeID(row)
+	}
+	return row
+}
+
+// withoutCompositeID drops the derived id before a write, since there is
+// no column to store it in.
+func (db *DB) withoutCompositeID(model string, data map[string]any) map[string]any {
+	if _, ok := db.compositeModel(model); !ok {
+		return data
+	}
+	if _, ok := data["id"]; !ok {
+		return data
+	}
+	stripped := make(map[string]any, len(data))
+	for k, v := range data {
+		if k != "id" {
+			stripped[k] = v
+		}
+	}
+	return stripped
+}
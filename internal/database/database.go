@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/yamlforge/yamlforge/internal/parser"
 )
@@ -17,15 +18,25 @@ type Database interface {
 	Create(model string, data map[string]any) (any, error)
 	Update(model string, id any, data map[string]any) error
 	Delete(model string, id any) error
+	Restore(model string, id any) error
 	Count(model string, filters []parser.Filter) (int64, error)
+	Aggregate(model string, function string, field string, filters []parser.Filter) (float64, error)
 	BeginTx() (*sql.Tx, error)
 }
 
+type LimitedCounter interface {
+	CountUpTo(model string, filters []parser.Filter, limit int) (int64, error)
+}
+
 type DB struct {
-	config *parser.DatabaseConfig
-	conn   *sql.DB
-	dbType parser.DatabaseType
-	schema *parser.Schema
+	config      *parser.DatabaseConfig
+	conn        *sql.DB
+	replicas    []*sql.DB
+	nextReplica uint32
+	dbType      parser.DatabaseType
+
+	// schema is swapped by CreateSchema on reload while requests run.
+	schema atomic.Pointer[parser.Schema]
 }
 
 func New(config *parser.DatabaseConfig) (Database, error) {
@@ -35,6 +46,14 @@ func New(config *parser.DatabaseConfig) (Database, error) {
 	}, nil
 }
 
+func (db *DB) getModel(name string) (*parser.Model, bool) {
+	schema := db.schema.Load()
+	if schema == nil {
+		return nil, false
+	}
+	return schema.GetModel(name)
+}
+
 func (db *DB) getDriverName() string {
 	switch db.dbType {
 	case parser.DatabaseSQLite:
@@ -53,12 +72,31 @@ func (db *DB) getConnectionString() string {
 	}
 }
 
+func (db *DB) reader() *sql.DB {
+	if len(db.replicas) == 0 {
+		return db.conn
+	}
+	n := atomic.AddUint32(&db.nextReplica, 1)
+	return db.replicas[(n-1)%uint32(len(db.replicas))]
+}
+
+func (db *DB) closeReplicas() error {
+	var firstErr error
+	for _, replica := range db.replicas {
+		if err := replica.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	db.replicas = nil
+	return firstErr
+}
+
 func (db *DB) BeginTx() (*sql.Tx, error) {
 	return db.conn.Begin()
 }
 
 func (db *DB) buildSelectQuery(model string, params parser.QueryParams) (string, []any) {
-	m, ok := db.schema.GetModel(model)
+	m, ok := db.getModel(model)
 	if !ok {
 		return "", nil
 	}
@@ -73,7 +111,7 @@ func (db *DB) buildSelectQuery(model string, params parser.QueryParams) (string,
 		for _, filter := range params.Filters {
 			clause, arg := db.buildWhereClause(filter)
 			whereClauses = append(whereClauses, clause)
-			args = append(args, arg)
+			args = appendFilterArgs(args, arg)
 		}
 		parts = append(parts, "WHERE "+strings.Join(whereClauses, " AND "))
 	}
@@ -101,6 +139,9 @@ func (db *DB) buildSelectQuery(model string, params parser.QueryParams) (string,
 			orderClauses = append(orderClauses, db.quote(sort.Field)+" "+order)
 		}
 		parts = append(parts, "ORDER BY "+strings.Join(orderClauses, ", "))
+	} else if relevance, relevanceArgs := db.buildRelevanceOrder(m, params.Search); relevance != "" {
+		parts = append(parts, "ORDER BY "+relevance)
+		args = append(args, relevanceArgs...)
 	}
 
 	if params.PageSize > 0 {
@@ -112,6 +153,52 @@ func (db *DB) buildSelectQuery(model string, params parser.QueryParams) (string,
 	return strings.Join(parts, " "), args
 }
 
+// buildRelevanceOrder ranks search results by their best match in any
+// searchable field (exact, then prefix, then substring), then by the
+// weighted number of matching fields, with the id as a final tiebreaker.
+func (db *DB) buildRelevanceOrder(m *parser.Model, search string) (string, []any) {
+	rank, matches, args := db.relevanceScores(m, search)
+	if rank == "" {
+		return "", nil
+	}
+	return rank + " DESC, " + matches + " DESC, " + db.keyOrder(m.Name), args
+}
+
+// relevanceScores returns the two expressions buildRelevanceOrder sorts
+// by, with their arguments in that order, or "" when nothing is searched.
+func (db *DB) relevanceScores(m *parser.Model, search string) (string, string, []any) {
+	if search == "" {
+		return "", "", nil
+	}
+
+	var ranks, terms []string
+	var rankArgs, termArgs []any
+	for _, field := range m.UI.List.Searchable {
+		column := db.quote(field)
+		ranks = append(ranks, fmt.Sprintf("CASE WHEN %s LIKE ? THEN 3 WHEN %s LIKE ? THEN 2 WHEN %s LIKE ? THEN 1 ELSE 0 END", column, column, column))
+		rankArgs = append(rankArgs, search, search+"%", "%"+search+"%")
+
+		weight, ok := m.UI.List.SearchWeights[field]
+		if !ok {
+			weight = 1
+		}
+		terms = append(terms, fmt.Sprintf("CASE WHEN %s LIKE ? THEN %d ELSE 0 END", column, weight))
+		termArgs = append(termArgs, "%"+search+"%")
+	}
+
+	if len(ranks) == 0 {
+		return "", "", nil
+	}
+
+	// A single-argument MAX is SQLite's aggregate, not the scalar.
+	rank := ranks[0]
+	if len(ranks) > 1 {
+		rank = "MAX(" + strings.Join(ranks, ", ") + ")"
+	}
+
+	return rank, "(" + strings.Join(terms, " + ") + ")", append(rankArgs, termArgs...)
+}
+
 func (db *DB) buildWhereClause(filter parser.Filter) (string, any) {
 	operator := filter.Operator
 	if operator == "" {
@@ -128,15 +215,26 @@ func (db *DB) buildWhereClause(filter parser.Filter) (string, any) {
 			placeholders[i] = "?"
 		}
 		return db.quote(filter.Field) + " IN (" + strings.Join(placeholders, ",") + ")", values
+	case "is_null":
+		return db.quote(filter.Field) + " IS NULL", []any{}
+	case "not_null":
+		return db.quote(filter.Field) + " IS NOT NULL", []any{}
 	default:
 		return db.quote(filter.Field) + " " + operator + " ?", filter.Value
 	}
 }
 
+func appendFilterArgs(args []any, arg any) []any {
+	if values, ok := arg.([]any); ok {
+		return append(args, values...)
+	}
+	return append(args, arg)
+}
+
 func (db *DB) quote(name string) string {
 	switch db.dbType {
 	case parser.DatabaseSQLite:
-		return "\"" + name + "\""
+		return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
 	default:
 		return name
 	}
@@ -172,20 +270,23 @@ func (db *DB) buildUpdateQuery(model string, id any, data map[string]any) (strin
 		args = append(args, val)
 	}
 
-	args = append(args, id)
+	where, keyArgs := db.keyCondition(model, id)
+	args = append(args, keyArgs...)
 
 	query := fmt.Sprintf(
-		"UPDATE %s SET %s WHERE id = ?",
+		"UPDATE %s SET %s WHERE %s",
 		db.quote(model),
 		strings.Join(setClauses, ", "),
+		where,
 	)
 
 	return query, args
 }
 
 func (db *DB) buildDeleteQuery(model string, id any) (string, []any) {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = ?", db.quote(model))
-	return query, []any{id}
+	where, args := db.keyCondition(model, id)
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", db.quote(model), where)
+	return query, args
 }
 
 func (db *DB) scanRow(rows *sql.Rows) (map[string]any, error) {
@@ -259,6 +360,7 @@ func (db *DB) Connect() error {
 }
 
 func (db *DB) Close() error {
+	db.closeReplicas()
 	if db.conn != nil {
 		return db.conn.Close()
 	}
@@ -289,7 +391,15 @@ func (db *DB) Delete(model string, id any) error {
 	return fmt.Errorf("Delete not implemented for base DB type")
 }
 
+func (db *DB) Restore(model string, id any) error {
+	return fmt.Errorf("Restore not implemented for base DB type")
+}
+
 func (db *DB) Count(model string, filters []parser.Filter) (int64, error) {
 	return 0, fmt.Errorf("Count not implemented for base DB type")
 }
 
+func (db *DB) Aggregate(model string, function string, field string, filters []parser.Filter) (float64, error) {
+	return 0, fmt.Errorf("Aggregate not implemented for base DB type")
+}
+
@@ -79,9 +79,9 @@ func TestDB_BuildSelectQuery(t *testing.T) {
 	}
 
 	db := &DB{
-		schema: schema,
 		dbType: parser.DatabaseSQLite,
 	}
+	db.schema.Store(schema)
 
 	params := parser.QueryParams{
 		Page:     1,
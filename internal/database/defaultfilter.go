@@ -0,0 +1,45 @@
+package database
+
+import "github.com/yamlforge/yamlforge/internal/parser"
+
+// RoleScoper hands out a view of the database that applies each model's
+// default_filter for a role, so every read and write made through it
+// treats filtered-out records as missing.
+type RoleScoper interface {
+	ForRole(role string) Database
+}
+
+// ForRole returns a view sharing db's connections. The database itself
+// stays unfiltered for seeding, dumps and internal lookups.
+func (db *SQLiteDB) ForRole(role string) Database {
+	return &SQLiteDB{DB: db.DB, role: role, scoped: true}
+}
+
+func (db *SQLiteDB) defaultFilters(model string) []parser.Filter {
+	if !db.scoped {
+		return nil
+	}
+	m, ok := db.getModel(model)
+	if !ok {
+		return nil
+	}
+	return m.DefaultFilters(db.role)
+}
+
+// filterCondition appends the default_filter to a WHERE condition built
+// by keyCondition.
+func (db *SQLiteDB) filterCondition(model, where string, args []any) (string, []any) {
+	for _, filter := range db.defaultFilters(model) {
+		clause, arg := db.buildWhereClause(filter)
+		where += " AND " + clause
+		args = appendFilterArgs(args, arg)
+	}
+	return where, args
+}
+
+func (db *SQLiteDB) unscoped() *SQLiteDB {
+	if !db.scoped {
+		return db
+	}
+	return &SQLiteDB{DB: db.DB}
+}
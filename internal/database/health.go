@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	pingTimeout = 5 * time.Second
+
+	// defaultMaxIdleConns matches database/sql's own default.
+	defaultMaxIdleConns = 2
+)
+
+// HealthChecker is implemented by databases whose connection can be
+// checked and re-established while the server runs.
+type HealthChecker interface {
+	Ping() error
+	Reconnect() error
+}
+
+func (db *SQLiteDB) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	return db.conn.PingContext(ctx)
+}
+
+// Reconnect drops the idle pooled connections, which an outage may have
+// left broken, and pings so the pool dials a fresh one. The *sql.DB itself
+// is kept, since the auth store and in-flight requests hold on to it.
+func (db *SQLiteDB) Reconnect() error {
+	db.conn.SetMaxIdleConns(0)
+	db.conn.SetMaxIdleConns(defaultMaxIdleConns)
+	return db.Ping()
+}
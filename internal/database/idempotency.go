@@ -0,0 +1,86 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const idempotencyTable = "_idempotency_keys"
+
+// IdempotencyStore remembers which record an Idempotency-Key created. A
+// key is reserved before the write, so a concurrent retry finds it
+// pending (found with a nil id) instead of creating a second record.
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(key, model string, ttl time.Duration) (reserved bool, err error)
+	LookupIdempotencyKey(key string, ttl time.Duration) (model string, id any, found bool, err error)
+	SaveIdempotencyKey(key, model string, id any, ttl time.Duration) error
+	// ReleaseIdempotencyKey drops a reservation whose write failed, so the
+	// request can be retried.
+	ReleaseIdempotencyKey(key string) error
+}
+
+func (db *SQLiteDB) createIdempotencyTable() error {
+	_, err := db.conn.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (key TEXT PRIMARY KEY, model TEXT NOT NULL, record_id TEXT NOT NULL, created_at INTEGER NOT NULL)",
+		db.quote(idempotencyTable),
+	))
+	return err
+}
+
+// ReserveIdempotencyKey relies on the key's primary key: of two
+// concurrent reservations only one insert succeeds.
+func (db *SQLiteDB) ReserveIdempotencyKey(key, model string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	if _, err := db.execWrite(
+		fmt.Sprintf("DELETE FROM %s WHERE created_at <= ?", db.quote(idempotencyTable)),
+		now.Add(-ttl).Unix(),
+	); err != nil {
+		return false, err
+	}
+
+	_, err := db.execWrite(
+		fmt.Sprintf("INSERT INTO %s (key, model, record_id, created_at) VALUES (?, ?, '', ?)", db.quote(idempotencyTable)),
+		key, model, now.Unix(),
+	)
+	if errors.Is(err, ErrUniqueViolation) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (db *SQLiteDB) LookupIdempotencyKey(key string, ttl time.Duration) (string, any, bool, error) {
+	var model, id string
+	err := db.conn.QueryRow(
+		fmt.Sprintf("SELECT model, record_id FROM %s WHERE key = ? AND created_at > ?", db.quote(idempotencyTable)),
+		key, time.Now().Add(-ttl).Unix(),
+	).Scan(&model, &id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil, false, nil
+	}
+	if err != nil {
+		return "", nil, false, err
+	}
+	if id == "" {
+		return model, nil, true, nil
+	}
+	return model, id, true, nil
+}
+
+func (db *SQLiteDB) SaveIdempotencyKey(key, model string, id any, ttl time.Duration) error {
+	now := time.Now()
+	_, err := db.execWrite(
+		fmt.Sprintf("INSERT OR REPLACE INTO %s (key, model, record_id, created_at) VALUES (?, ?, ?, ?)", db.quote(idempotencyTable)),
+		key, model, fmt.Sprint(id), now.Unix(),
+	)
+	return err
+}
+
+func (db *SQLiteDB) ReleaseIdempotencyKey(key string) error {
+	_, err := db.execWrite(
+		fmt.Sprintf("DELETE FROM %s WHERE key = ? AND record_id = ''", db.quote(idempotencyTable)),
+		key,
+	)
+	return err
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func newID(keyType parser.KeyType) (string, error) {
+	switch keyType {
+	case parser.KeyTypeUUID:
+		return newUUID()
+	case parser.KeyTypeULID:
+		return newULID(time.Now())
+	default:
+		return "", fmt.Errorf("cannot generate id for key type: %s", keyType)
+	}
+}
+
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func newULID(t time.Time) (string, error) {
+	var b [16]byte
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixMilli()))
+	copy(b[:6], ms[2:])
+
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+
+	out := make([]byte, 26)
+	var acc uint32
+	bits := 0
+	pos := 25
+
+	// Encode the 128 bits from the least significant end: 26 base32
+	// characters hold 130 bits, so the leading character carries only 3.
+	for i := 15; i >= 0; i-- {
+		acc |= uint32(b[i]) << bits
+		bits += 8
+		for bits >= 5 && pos >= 0 {
+			out[pos] = crockford[acc&0x1f]
+			acc >>= 5
+			bits -= 5
+			pos--
+		}
+	}
+	if pos >= 0 {
+		out[pos] = crockford[acc&0x1f]
+	}
+
+	return string(out), nil
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+func TestNewUUID(t *testing.T) {
+	id, err := newUUID()
+	if err != nil {
+		t.Fatalf("Failed to generate UUID: %v", err)
+	}
+
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !pattern.MatchString(id) {
+		t.Errorf("Expected a version 4 UUID, got %s", id)
+	}
+}
+
+func TestNewULID(t *testing.T) {
+	earlier, err := newULID(time.UnixMilli(1700000000000))
+	if err != nil {
+		t.Fatalf("Failed to generate ULID: %v", err)
+	}
+	later, _ := newULID(time.UnixMilli(1700000000001))
+
+	pattern := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+	if !pattern.MatchString(earlier) {
+		t.Errorf("Expected a 26 character ULID, got %s", earlier)
+	}
+	if earlier[:10] != "01HF7YAT00" {
+		t.Errorf("Unexpected timestamp encoding: %s", earlier[:10])
+	}
+	if earlier >= later {
+		t.Errorf("Expected ULIDs to sort by time: %s >= %s", earlier, later)
+	}
+}
+
+func TestNewID_IntKey(t *testing.T) {
+	if _, err := newID(parser.KeyTypeInt); err == nil {
+		t.Error("Expected error generating an id for integer keys")
+	}
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// planMigrations lists the ALTER TABLE statements that add fields missing
+// from existing tables. CREATE TABLE IF NOT EXISTS leaves those tables as
+// they are, so without this a field added to the config would fail every
+// write. SQLite can only add nullable, non-unique columns with a constant
+// default; any other new field is refused before anything is changed.
+func (db *SQLiteDB) planMigrations(schema *parser.Schema) ([]string, error) {
+	names := make([]string, 0, len(schema.Models))
+	for name := range schema.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var statements []string
+	for _, name := range names {
+		columns, err := db.tableColumns(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect table %s: %w", name, err)
+		}
+		if len(columns) == 0 {
+			continue
+		}
+
+		for _, field := range schema.Models[name].Fields {
+			if columns[field.Name] {
+				continue
+			}
+			if reason := addColumnBlocker(field); reason != "" {
+				return nil, fmt.Errorf("cannot add field %s.%s to the existing table: %s; migrate the table by hand", name, field.Name, reason)
+			}
+
+			column := db.buildColumnDefinition(field)
+			if field.Type == parser.FieldTypeRelation && field.RelatedTo != "" {
+				column += fmt.Sprintf(" REFERENCES %s(id)%s", db.quote(field.RelatedTo), onDeleteClause(field))
+			}
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", db.quote(name), column))
+		}
+	}
+	return statements, nil
+}
+
+func addColumnBlocker(field parser.Field) string {
+	switch {
+	case field.Primary:
+		return "SQLite can't add a primary key column"
+	case field.Unique:
+		return "SQLite can't add a unique column"
+	case field.Default == "CURRENT_TIMESTAMP":
+		return "SQLite can't add a column defaulting to the current time"
+	case field.Required && field.Default == nil:
+		return "a required field needs a default to fill existing rows"
+	}
+	return ""
+}
+
+// tableColumns returns the table's column names, or none when the table
+// doesn't exist yet.
+func (db *SQLiteDB) tableColumns(table string) (map[string]bool, error) {
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", db.quote(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, kind       string
+			defaultValue     any
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &defaultValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const redactedArg = "[redacted]"
+
+// logQuery prints a statement with its duration when database.log_queries
+// is set. Args matching a sensitive value are masked.
+func (db *SQLiteDB) logQuery(start time.Time, query string, args []any, sensitive []any) {
+	if db.config == nil || !db.config.LogQueries {
+		return
+	}
+
+	logged := make([]any, len(args))
+	for i, arg := range args {
+		logged[i] = arg
+		for _, value := range sensitive {
+			if fmt.Sprint(arg) == fmt.Sprint(value) {
+				logged[i] = redactedArg
+				break
+			}
+		}
+	}
+
+	log.Printf("[query] %s %v (%s)", query, logged, time.Since(start).Round(time.Microsecond))
+}
+
+// sensitiveValues collects the values written to password fields so they
+// never reach the query log.
+func (db *SQLiteDB) sensitiveValues(model string, data map[string]any) []any {
+	if db.config == nil || !db.config.LogQueries {
+		return nil
+	}
+	m, ok := db.getModel(model)
+	if !ok {
+		return nil
+	}
+
+	var values []any
+	for _, field := range m.Fields {
+		if field.Type != parser.FieldTypePassword {
+			continue
+		}
+		if value, ok := data[field.Name]; ok && value != nil && value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
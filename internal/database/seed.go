@@ -0,0 +1,191 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// RedactedPassword stands in for required password values in a dump, so
+// the dump can be seeded again. Seed replaces it with a random value
+// nobody knows; those passwords have to be reset after seeding.
+const RedactedPassword = "<redacted>"
+
+// Seed inserts the seed records of every model whose table is still
+// empty, so restarting against a populated database leaves it alone.
+// Models are seeded after the models they relate to, and records keep
+// their ids so relations between them hold.
+func Seed(db Database, schema *parser.Schema, seed map[string][]map[string]any) error {
+	for _, name := range seedOrder(schema) {
+		records := seed[name]
+		if len(records) == 0 {
+			continue
+		}
+		model, _ := schema.GetModel(name)
+		records, err := replaceRedacted(model, records)
+		if err != nil {
+			return err
+		}
+
+		count, err := countAll(db, model)
+		if err != nil {
+			return fmt.Errorf("failed to count %s: %w", name, err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		if creator, ok := db.(BatchCreator); ok {
+			if _, err := creator.CreateBatch(name, records); err != nil {
+				return fmt.Errorf("failed to seed %s: %w", name, err)
+			}
+			continue
+		}
+		for i, record := range records {
+			if _, err := db.Create(name, record); err != nil {
+				return fmt.Errorf("failed to seed %s: item %d: %w", name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Dump reads every record of every model, soft-deleted ones included, in
+// the shape Seed takes. Password values are never written: optional ones
+// are left out and required ones become RedactedPassword. Their names are
+// returned as "Model.field" so callers can flag them.
+func Dump(db Database, schema *parser.Schema) (map[string][]map[string]any, []string, error) {
+	seed := make(map[string][]map[string]any)
+	var omitted []string
+
+	for _, name := range seedOrder(schema) {
+		model, _ := schema.GetModel(name)
+
+		var passwords []parser.Field
+		for _, field := range model.Fields {
+			if field.Type == parser.FieldTypePassword {
+				passwords = append(passwords, field)
+				omitted = append(omitted, name+"."+field.Name)
+			}
+		}
+
+		scopes := [][]parser.Filter{nil}
+		if model.SoftDelete {
+			scopes = append(scopes, []parser.Filter{{Field: parser.SoftDeleteField, Operator: "not_null"}})
+		}
+
+		var records []map[string]any
+		for _, filters := range scopes {
+			rows, err := db.Query(name, parser.QueryParams{
+				Filters: filters,
+				Sort:    []parser.SortField{{Field: "id"}},
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			records = append(records, rows...)
+		}
+
+		for _, record := range records {
+			for _, field := range passwords {
+				if field.Required {
+					record[field.Name] = RedactedPassword
+				} else {
+					delete(record, field.Name)
+				}
+			}
+			if model.HasCompositeKey() {
+				delete(record, "id")
+			}
+		}
+		if len(records) > 0 {
+			seed[name] = records
+		}
+	}
+	return seed, omitted, nil
+}
+
+// replaceRedacted swaps RedactedPassword for a random value, copying the
+// records it changes so the caller's seed is left alone.
+func replaceRedacted(model *parser.Model, records []map[string]any) ([]map[string]any, error) {
+	replaced := make([]map[string]any, len(records))
+	for i, record := range records {
+		replaced[i] = record
+		copied := false
+		for _, field := range model.Fields {
+			if field.Type != parser.FieldTypePassword || record[field.Name] != RedactedPassword {
+				continue
+			}
+			if !copied {
+				replaced[i] = make(map[string]any, len(record))
+				for k, v := range record {
+					replaced[i][k] = v
+				}
+				copied = true
+			}
+			value, err := newUUID()
+			if err != nil {
+				return nil, err
+			}
+			replaced[i][field.Name] = value
+		}
+	}
+	return replaced, nil
+}
+
+func countAll(db Database, model *parser.Model) (int64, error) {
+	count, err := db.Count(model.Name, nil)
+	if err != nil || !model.SoftDelete {
+		return count, err
+	}
+	trashed, err := db.Count(model.Name, []parser.Filter{{Field: parser.SoftDeleteField, Operator: "not_null"}})
+	return count + trashed, err
+}
+
+// seedOrder lists model names so that each comes after the models its
+// relations point to. Relation cycles fall back to name order.
+func seedOrder(schema *parser.Schema) []string {
+	names := make([]string, 0, len(schema.Models))
+	for name := range schema.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	placed := make(map[string]bool, len(names))
+	var order []string
+	for len(order) < len(names) {
+		progress := false
+		for _, name := range names {
+			if placed[name] || !relationsPlaced(schema, schema.Models[name], placed) {
+				continue
+			}
+			placed[name] = true
+			order = append(order, name)
+			progress = true
+		}
+		if progress {
+			continue
+		}
+		for _, name := range names {
+			if !placed[name] {
+				placed[name] = true
+				order = append(order, name)
+				break
+			}
+		}
+	}
+	return order
+}
+
+func relationsPlaced(schema *parser.Schema, model *parser.Model, placed map[string]bool) bool {
+	for _, field := range model.Fields {
+		if field.Type != parser.FieldTypeRelation || field.RelatedTo == model.Name || placed[field.RelatedTo] {
+			continue
+		}
+		if _, ok := schema.Models[field.RelatedTo]; ok {
+			return false
+		}
+	}
+	return true
+}
@@ -2,17 +2,37 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/yamlforge/yamlforge/internal/parser"
 )
 
 type SQLiteDB struct {
 	*DB
+
+	// Views returned by ForRole apply default_filter for role.
+	role   string
+	scoped bool
 }
 
+var (
+	ErrDatabaseBusy    = errors.New("database is busy")
+	ErrUniqueViolation = errors.New("unique constraint violated")
+)
+
+const (
+	busyRetries = 5
+	busyBackoff = 10 * time.Millisecond
+
+	sqliteTimestampLayout = "2006-01-02 15:04:05"
+)
+
 func NewSQLite(config *parser.DatabaseConfig) (Database, error) {
 	db := &SQLiteDB{
 		DB: &DB{
@@ -35,10 +55,20 @@ func (db *SQLiteDB) Connect() error {
 		return fmt.Errorf("failed to enable foreign keys: %w", err)
 	}
 
+	for _, path := range db.config.ReadReplicas {
+		replica, err := sql.Open("sqlite3", path)
+		if err != nil {
+			db.closeReplicas()
+			return fmt.Errorf("failed to connect to read replica %s: %w", path, err)
+		}
+		db.replicas = append(db.replicas, replica)
+	}
+
 	return nil
 }
 
 func (db *SQLiteDB) Close() error {
+	db.closeReplicas()
 	if db.conn != nil {
 		return db.conn.Close()
 	}
@@ -46,7 +76,10 @@ func (db *SQLiteDB) Close() error {
 }
 
 func (db *SQLiteDB) CreateSchema(schema *parser.Schema) error {
-	db.schema = schema
+	migrations, err := db.planMigrations(schema)
+	if err != nil {
+		return err
+	}
 
 	for modelName, model := range schema.Models {
 		if err := db.createTable(modelName, model); err != nil {
@@ -54,12 +87,27 @@ func (db *SQLiteDB) CreateSchema(schema *parser.Schema) error {
 		}
 	}
 
+	for _, statement := range migrations {
+		if _, err := db.conn.Exec(statement); err != nil {
+			return fmt.Errorf("failed to migrate schema: %w", err)
+		}
+	}
+
 	for modelName, model := range schema.Models {
 		if err := db.createIndexes(modelName, model); err != nil {
 			return fmt.Errorf("failed to create indexes for %s: %w", modelName, err)
 		}
 	}
 
+	if err := db.createIdempotencyTable(); err != nil {
+		return fmt.Errorf("failed to create idempotency table: %w", err)
+	}
+
+	if err := db.createAuditTable(); err != nil {
+		return fmt.Errorf("failed to create audit table: %w", err)
+	}
+
+	db.schema.Store(schema)
 	return nil
 }
 
@@ -78,21 +126,20 @@ func (db *SQLiteDB) createTable(name string, model *parser.Model) error {
 				db.quote(field.RelatedTo),
 			)
 
-			if field.OnDelete != "" {
-				switch field.OnDelete {
-				case "cascade":
-					constraint += " ON DELETE CASCADE"
-				case "restrict":
-					constraint += " ON DELETE RESTRICT"
-				case "set_null":
-					constraint += " ON DELETE SET NULL"
-				}
-			}
+			constraint += onDeleteClause(field)
 
 			constraints = append(constraints, constraint)
 		}
 	}
 
+	if model.HasCompositeKey() {
+		keys := make([]string, len(model.PrimaryKey))
+		for i, name := range model.PrimaryKey {
+			keys[i] = db.quote(name)
+		}
+		constraints = append([]string{"PRIMARY KEY (" + strings.Join(keys, ", ") + ")"}, constraints...)
+	}
+
 	parts := append(columns, constraints...)
 
 	query := fmt.Sprintf(
@@ -105,6 +152,18 @@ func (db *SQLiteDB) createTable(name string, model *parser.Model) error {
 	return err
 }
 
+func onDeleteClause(field parser.Field) string {
+	switch field.OnDelete {
+	case "cascade":
+		return " ON DELETE CASCADE"
+	case "restrict":
+		return " ON DELETE RESTRICT"
+	case "set_null":
+		return " ON DELETE SET NULL"
+	}
+	return ""
+}
+
 func (db *SQLiteDB) buildColumnDefinition(field parser.Field) string {
 	parts := []string{
 		db.quote(field.Name),
@@ -112,7 +171,7 @@ func (db *SQLiteDB) buildColumnDefinition(field parser.Field) string {
 	}
 
 	if field.Primary {
-		if field.Type == parser.FieldTypeID {
+		if field.Type == parser.FieldTypeID && !field.KeyType.IsString() {
 			parts = append(parts, "PRIMARY KEY AUTOINCREMENT")
 		} else {
 			parts = append(parts, "PRIMARY KEY")
@@ -127,8 +186,15 @@ func (db *SQLiteDB) buildColumnDefinition(field parser.Field) string {
 		parts = append(parts, "UNIQUE")
 	}
 
+	if field.Type == parser.FieldTypeSlug || field.IgnoreCase {
+		parts = append(parts, "COLLATE NOCASE")
+	}
+
 	if field.Default != nil {
 		defaultValue := db.formatDefaultValue(field.Default, field.Type)
+		if field.Decimal {
+			defaultValue = db.formatDecimalDefault(field.Default, field.Scale, defaultValue)
+		}
 		parts = append(parts, "DEFAULT "+defaultValue)
 	}
 
@@ -136,10 +202,20 @@ func (db *SQLiteDB) buildColumnDefinition(field parser.Field) string {
 }
 
 func (db *SQLiteDB) getSQLiteType(field parser.Field) string {
+	if field.KeyType.IsString() && (field.Type == parser.FieldTypeID || field.Type == parser.FieldTypeRelation) {
+		return "TEXT"
+	}
+
 	switch field.Type {
 	case parser.FieldTypeID:
 		return "INTEGER"
 	case parser.FieldTypeNumber:
+		if field.Precision > 0 {
+			return fmt.Sprintf("NUMERIC(%d,%d)", field.Precision, field.Scale)
+		}
+		if field.Decimal {
+			return "REAL"
+		}
 		return "INTEGER"
 	case parser.FieldTypeText, parser.FieldTypeEmail, parser.FieldTypePassword,
 		parser.FieldTypePhone, parser.FieldTypeURL, parser.FieldTypeSlug,
@@ -160,7 +236,7 @@ func (db *SQLiteDB) getSQLiteType(field parser.Field) string {
 		return "TEXT"
 	case parser.FieldTypeRelation:
 		return "INTEGER"
-	case parser.FieldTypeLocation:
+	case parser.FieldTypeLocation, parser.FieldTypeObject:
 		return "TEXT"
 	default:
 		return "TEXT"
@@ -187,9 +263,42 @@ func (db *SQLiteDB) formatDefaultValue(value any, fieldType parser.FieldType) st
 	}
 }
 
+func (db *SQLiteDB) formatDecimalDefault(value any, scale int, fallback string) string {
+	var f float64
+	switch v := value.(type) {
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		return fallback
+	}
+
+	if scale > 0 {
+		return strconv.FormatFloat(f, 'f', scale, 64)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
 func (db *SQLiteDB) createIndexes(modelName string, model *parser.Model) error {
+	// database.auto_index also covers foreign keys and the columns the list
+	// view sorts and filters on.
+	autoIndex := db.config != nil && db.config.AutoIndex
+	listed := make(map[string]bool)
+	if autoIndex {
+		for _, name := range model.UI.List.Sortable {
+			listed[name] = true
+		}
+		for _, name := range model.UI.List.Filterable {
+			listed[name] = true
+		}
+	}
+
 	for _, field := range model.Fields {
-		if field.Index && !field.Primary && !field.Unique {
+		index := field.Index || autoIndex && (field.Type == parser.FieldTypeRelation || listed[field.Name])
+		if index && !field.Primary && !field.Unique {
 			indexName := fmt.Sprintf("idx_%s_%s", modelName, field.Name)
 			query := fmt.Sprintf(
 				"CREATE INDEX IF NOT EXISTS %s ON %s (%s)",
@@ -204,13 +313,61 @@ func (db *SQLiteDB) createIndexes(modelName string, model *parser.Model) error {
 		}
 	}
 
+	// unique_within is a unique index over the field and its scope. The
+	// field comes first so a violation names it rather than the scope.
+	for _, field := range model.Fields {
+		if field.UniqueWithin == "" {
+			continue
+		}
+		indexName := fmt.Sprintf("idx_%s_%s_%s", modelName, field.Name, field.UniqueWithin)
+		query := fmt.Sprintf(
+			"CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s, %s)",
+			db.quote(indexName),
+			db.quote(modelName),
+			db.quote(field.Name),
+			db.quote(field.UniqueWithin),
+		)
+
+		if _, err := db.conn.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	for _, index := range model.Indexes {
+		// The path is inlined into the statement, so re-check it here.
+		_, path, ok := parser.JSONPath(index.JSONField + strings.TrimPrefix(index.Path, "$"))
+		if !ok {
+			return fmt.Errorf("invalid JSON index path %q on %s.%s", index.Path, modelName, index.JSONField)
+		}
+		indexName := fmt.Sprintf("idx_%s_%s_%s", modelName, index.JSONField, strings.ReplaceAll(strings.TrimPrefix(path, "$."), ".", "_"))
+		query := fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS %s ON %s (%s)",
+			db.quote(indexName),
+			db.quote(modelName),
+			db.jsonExtract(index.JSONField, path),
+		)
+
+		if _, err := db.conn.Exec(query); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (db *SQLiteDB) Query(model string, params parser.QueryParams) ([]map[string]any, error) {
+	if index := db.oversizedInFilter(params.Filters); index >= 0 {
+		return db.queryChunked(model, params, index)
+	}
+
 	query, args := db.buildSelectQuery(model, params)
+	return db.queryRows(model, query, args)
+}
 
-	rows, err := db.conn.Query(query, args...)
+func (db *SQLiteDB) queryRows(model, query string, args []any) ([]map[string]any, error) {
+	start := time.Now()
+	rows, err := db.reader().Query(query, args...)
+	db.logQuery(start, query, args, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +379,7 @@ func (db *SQLiteDB) Query(model string, params parser.QueryParams) ([]map[string
 		if err != nil {
 			return nil, err
 		}
-		results = append(results, row)
+		results = append(results, db.decodeObjects(model, row))
 	}
 
 	if results == nil {
@@ -233,14 +390,77 @@ func (db *SQLiteDB) Query(model string, params parser.QueryParams) ([]map[string
 }
 
 func (db *SQLiteDB) Get(model string, id any) (map[string]any, error) {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id = ?", db.quote(model))
-	return db.executeQueryRow(query, []any{id})
+	query, args := db.getQuery(model, id)
+	row, err := db.executeQueryRow(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return db.decodeObjects(model, row), nil
+}
+
+func (db *SQLiteDB) getQuery(model string, id any) (string, []any) {
+	where, args := db.keyCondition(model, id)
+	where, args = db.filterCondition(model, where, args)
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s", db.quote(model), where)
+	if db.isSoftDelete(model) {
+		query += " AND " + db.quote(parser.SoftDeleteField) + " IS NULL"
+	}
+	return query, args
 }
 
 func (db *SQLiteDB) Create(model string, data map[string]any) (any, error) {
+	return db.insert(db.execWrite, model, data)
+}
+
+func (db *SQLiteDB) insert(exec func(query string, args ...any) (sql.Result, error), model string, data map[string]any) (any, error) {
+	data, err := db.encodeObjects(model, data)
+	if err != nil {
+		return nil, err
+	}
+	sensitive := db.sensitiveValues(model, data)
+
+	if m, ok := db.compositeModel(model); ok {
+		query, args := db.buildInsertQuery(model, data)
+		start := time.Now()
+		_, err := exec(query, args...)
+		db.logQuery(start, query, args, sensitive)
+		if err != nil {
+			return nil, err
+		}
+		return m.CompositeID(data), nil
+	}
+
+	if keyType := db.keyType(model); keyType.IsString() {
+		id, ok := data["id"]
+		if !ok || id == nil || id == "" {
+			generated, err := newID(keyType)
+			if err != nil {
+				return nil, err
+			}
+			id = generated
+		}
+
+		record := make(map[string]any, len(data)+1)
+		for k, v := range data {
+			record[k] = v
+		}
+		record["id"] = id
+
+		query, args := db.buildInsertQuery(model, record)
+		start := time.Now()
+		_, err := exec(query, args...)
+		db.logQuery(start, query, args, sensitive)
+		if err != nil {
+			return nil, err
+		}
+		return id, nil
+	}
+
 	query, args := db.buildInsertQuery(model, data)
 
-	result, err := db.conn.Exec(query, args...)
+	start := time.Now()
+	result, err := exec(query, args...)
+	db.logQuery(start, query, args, sensitive)
 	if err != nil {
 		return nil, err
 	}
@@ -254,63 +474,382 @@ func (db *SQLiteDB) Create(model string, data map[string]any) (any, error) {
 }
 
 func (db *SQLiteDB) Update(model string, id any, data map[string]any) error {
+	return db.update(db.execWrite, model, id, data)
+}
+
+func (db *SQLiteDB) update(exec func(query string, args ...any) (sql.Result, error), model string, id any, data map[string]any) error {
+	data, err := db.encodeObjects(model, data)
+	if err != nil {
+		return err
+	}
+	data = db.touchAutoNow(model, data)
+
 	query, args := db.buildUpdateQuery(model, id, data)
+	filters := db.defaultFilters(model)
+	if len(filters) > 0 {
+		query, args = db.filterCondition(model, query, args)
+	}
 
-	_, err := db.conn.Exec(query, args...)
-	return err
+	start := time.Now()
+	result, err := exec(query, args...)
+	db.logQuery(start, query, args, db.sensitiveValues(model, data))
+	if err != nil || len(filters) == 0 {
+		return err
+	}
+	return requireAffected(result)
 }
 
 func (db *SQLiteDB) Delete(model string, id any) error {
+	return db.remove(db.execWrite, model, id)
+}
+
+func (db *SQLiteDB) remove(exec func(query string, args ...any) (sql.Result, error), model string, id any) error {
 	query, args := db.buildDeleteQuery(model, id)
+	query, args = db.filterCondition(model, query, args)
+	if db.isSoftDelete(model) {
+		where, keyArgs := db.keyCondition(model, id)
+		where, args = db.filterCondition(model, where, keyArgs)
+		query = fmt.Sprintf(
+			"UPDATE %s SET %s = CURRENT_TIMESTAMP WHERE %s AND %s IS NULL",
+			db.quote(model),
+			db.quote(parser.SoftDeleteField),
+			where,
+			db.quote(parser.SoftDeleteField),
+		)
+	}
+
+	start := time.Now()
+	result, err := exec(query, args...)
+	db.logQuery(start, query, args, nil)
+	if err != nil || len(db.defaultFilters(model)) == 0 {
+		return err
+	}
+	return requireAffected(result)
+}
 
-	_, err := db.conn.Exec(query, args...)
-	return err
+func (db *SQLiteDB) Restore(model string, id any) error {
+	return db.restore(db.execWrite, model, id)
+}
+
+func (db *SQLiteDB) restore(exec func(query string, args ...any) (sql.Result, error), model string, id any) error {
+	if !db.isSoftDelete(model) {
+		return fmt.Errorf("model %s does not use soft delete", model)
+	}
+
+	where, args := db.keyCondition(model, id)
+	where, args = db.filterCondition(model, where, args)
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s = NULL WHERE %s AND %s IS NOT NULL",
+		db.quote(model),
+		db.quote(parser.SoftDeleteField),
+		where,
+		db.quote(parser.SoftDeleteField),
+	)
+
+	start := time.Now()
+	result, err := exec(query, args...)
+	db.logQuery(start, query, args, nil)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+// requireAffected reports a write that matched no row as sql.ErrNoRows.
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (db *SQLiteDB) execWrite(query string, args ...any) (sql.Result, error) {
+	backoff := busyBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := db.conn.Exec(query, args...)
+		if isUniqueViolation(err) {
+			return nil, newUniqueViolation(err)
+		}
+		if err == nil || !isBusy(err) {
+			return result, err
+		}
+		if attempt == busyRetries {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseBusy, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+// UniqueViolation reports the table and column of a broken unique
+// constraint. It matches ErrUniqueViolation with errors.Is.
+type UniqueViolation struct {
+	Table string
+	Field string
+	err   error
+}
+
+func newUniqueViolation(err error) *UniqueViolation {
+	violation := &UniqueViolation{err: err}
+	// SQLite reports "UNIQUE constraint failed: table.column[, ...]".
+	if _, columns, ok := strings.Cut(err.Error(), "constraint failed: "); ok {
+		first, _, _ := strings.Cut(columns, ",")
+		violation.Table, violation.Field, _ = strings.Cut(strings.TrimSpace(first), ".")
+	}
+	return violation
+}
+
+func (e *UniqueViolation) Error() string {
+	return fmt.Sprintf("%v: %v", ErrUniqueViolation, e.err)
+}
+
+func (e *UniqueViolation) Unwrap() []error {
+	return []error{ErrUniqueViolation, e.err}
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
+
+func (db *SQLiteDB) objectFields(model string) []string {
+	m, ok := db.getModel(model)
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, field := range m.Fields {
+		if field.Type == parser.FieldTypeObject {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
+
+func (db *SQLiteDB) encodeObjects(model string, data map[string]any) (map[string]any, error) {
+	data = db.withoutCompositeID(model, data)
+	fields := db.objectFields(model)
+	if len(fields) == 0 {
+		return data, nil
+	}
+
+	encoded := make(map[string]any, len(data))
+	for k, v := range data {
+		encoded[k] = v
+	}
+	for _, name := range fields {
+		value, ok := encoded[name]
+		if !ok || value == nil {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		encoded[name] = string(raw)
+	}
+	return encoded, nil
+}
+
+func (db *SQLiteDB) decodeObjects(model string, row map[string]any) map[string]any {
+	row = db.withCompositeID(model, row)
+	for _, name := range db.objectFields(model) {
+		raw, ok := row[name].(string)
+		if !ok {
+			continue
+		}
+		var value map[string]any
+		if err := json.Unmarshal([]byte(raw), &value); err == nil {
+			row[name] = value
+		}
+	}
+	return row
+}
+
+func (db *SQLiteDB) keyType(model string) parser.KeyType {
+	if m, ok := db.getModel(model); ok {
+		return m.KeyType()
+	}
+	return parser.KeyTypeInt
+}
+
+func (db *SQLiteDB) touchAutoNow(model string, data map[string]any) map[string]any {
+	m, ok := db.getModel(model)
+	if !ok {
+		return data
+	}
+
+	// Column defaults only apply on INSERT, so auto_now fields are set here
+	// and override whatever the client sent.
+	touched := make(map[string]any, len(data))
+	for k, v := range data {
+		touched[k] = v
+	}
+	now := time.Now().UTC().Format(sqliteTimestampLayout)
+	for _, field := range m.Fields {
+		if field.AutoNow && field.Type == parser.FieldTypeDatetime {
+			touched[field.Name] = now
+		}
+	}
+	return touched
+}
+
+func (db *SQLiteDB) isSoftDelete(model string) bool {
+	m, ok := db.getModel(model)
+	return ok && m.SoftDelete
+}
+
+func (db *SQLiteDB) scopeFilters(model string, filters []parser.Filter) []parser.Filter {
+	filters = db.expandKeyFilters(model, filters)
+	if defaults := db.defaultFilters(model); len(defaults) > 0 {
+		filters = append(append([]parser.Filter{}, filters...), defaults...)
+	}
+	if !db.isSoftDelete(model) {
+		return filters
+	}
+
+	for _, filter := range filters {
+		if filter.Field == parser.SoftDeleteField {
+			return filters
+		}
+	}
+
+	scoped := append([]parser.Filter{}, filters...)
+	return append(scoped, parser.Filter{Field: parser.SoftDeleteField, Operator: "is_null"})
 }
 
 func (db *SQLiteDB) Count(model string, filters []parser.Filter) (int64, error) {
+	return db.CountUpTo(model, filters, 0)
+}
+
+// CountUpTo stops counting once limit rows match, so totals on huge tables
+// stay cheap. A limit of 0 counts every row.
+func (db *SQLiteDB) CountUpTo(model string, filters []parser.Filter, limit int) (int64, error) {
+	if index := db.oversizedInFilter(filters); index >= 0 {
+		count, err := db.countChunked(model, filters, index)
+		if limit > 0 && count > int64(limit) {
+			count = int64(limit)
+		}
+		return count, err
+	}
+
 	var parts []string
 	var args []any
 
 	tableName := strings.ToLower(model)
-	parts = append(parts, "SELECT COUNT(*) FROM "+db.quote(tableName))
+	if limit > 0 {
+		parts = append(parts, "SELECT COUNT(*) FROM (SELECT 1 FROM "+db.quote(tableName))
+	} else {
+		parts = append(parts, "SELECT COUNT(*) FROM "+db.quote(tableName))
+	}
+
+	filters = db.scopeFilters(model, filters)
 
 	if len(filters) > 0 {
 		whereClauses := []string{}
 		for _, filter := range filters {
 			clause, arg := db.buildWhereClause(filter)
 			whereClauses = append(whereClauses, clause)
-			args = append(args, arg)
+			args = appendFilterArgs(args, arg)
 		}
 		parts = append(parts, "WHERE "+strings.Join(whereClauses, " AND "))
 	}
 
+	if limit > 0 {
+		parts = append(parts, fmt.Sprintf("LIMIT %d)", limit))
+	}
+
 	query := strings.Join(parts, " ")
 
 	var count int64
-	row := db.conn.QueryRow(query, args...)
-	err := row.Scan(&count)
+	start := time.Now()
+	err := db.reader().QueryRow(query, args...).Scan(&count)
+	db.logQuery(start, query, args, nil)
 
 	return count, err
 }
 
+func (db *SQLiteDB) Aggregate(model string, function string, field string, filters []parser.Filter) (float64, error) {
+	var expr string
+	switch function {
+	case "count":
+		expr = "COUNT(*)"
+		if field != "" {
+			expr = "COUNT(" + db.quote(field) + ")"
+		}
+	case "sum":
+		expr = "COALESCE(SUM(" + db.quote(field) + "), 0)"
+	case "avg":
+		expr = "COALESCE(AVG(" + db.quote(field) + "), 0)"
+	default:
+		return 0, fmt.Errorf("unsupported aggregate: %s", function)
+	}
+
+	var parts []string
+	var args []any
+
+	tableName := strings.ToLower(model)
+	parts = append(parts, "SELECT "+expr+" FROM "+db.quote(tableName))
+
+	filters = db.scopeFilters(model, filters)
+
+	if len(filters) > 0 {
+		whereClauses := []string{}
+		for _, filter := range filters {
+			clause, arg := db.buildWhereClause(filter)
+			whereClauses = append(whereClauses, clause)
+			args = appendFilterArgs(args, arg)
+		}
+		parts = append(parts, "WHERE "+strings.Join(whereClauses, " AND "))
+	}
+
+	query := strings.Join(parts, " ")
+
+	var value float64
+	start := time.Now()
+	err := db.reader().QueryRow(query, args...).Scan(&value)
+	db.logQuery(start, query, args, nil)
+
+	return value, err
+}
+
 func (db *SQLiteDB) buildSelectQuery(model string, params parser.QueryParams) (string, []any) {
 	var parts []string
 	var args []any
 
 	parts = append(parts, "SELECT * FROM "+db.quote(model))
 
+	params.Filters = db.scopeFilters(model, params.Filters)
+
 	if len(params.Filters) > 0 {
 		whereClauses := []string{}
 		for _, filter := range params.Filters {
 			clause, arg := db.buildWhereClause(filter)
 			whereClauses = append(whereClauses, clause)
-			args = append(args, arg)
+			args = appendFilterArgs(args, arg)
 		}
 		parts = append(parts, "WHERE "+strings.Join(whereClauses, " AND "))
 	}
 
-	if params.Search != "" && db.schema != nil {
-		if m, ok := db.schema.GetModel(model); ok && len(m.UI.List.Searchable) > 0 {
+	if params.Search != "" {
+		if m, ok := db.getModel(model); ok && len(m.UI.List.Searchable) > 0 {
 			searchClauses := []string{}
 			for _, field := range m.UI.List.Searchable {
 				searchClauses = append(searchClauses, db.quote(field)+" LIKE ?")
@@ -324,9 +863,9 @@ func (db *SQLiteDB) buildSelectQuery(model string, params parser.QueryParams) (s
 		}
 	}
 
-	if len(params.Sort) > 0 {
+	if sorts := db.expandKeySort(model, params.Sort); len(sorts) > 0 {
 		orderClauses := []string{}
-		for _, sort := range params.Sort {
+		for _, sort := range sorts {
 			order := "ASC"
 			if sort.Desc {
 				order = "DESC"
@@ -334,8 +873,11 @@ func (db *SQLiteDB) buildSelectQuery(model string, params parser.QueryParams) (s
 			orderClauses = append(orderClauses, db.quote(sort.Field)+" "+order)
 		}
 		parts = append(parts, "ORDER BY "+strings.Join(orderClauses, ", "))
+	} else if relevance, relevanceArgs := db.searchRelevance(model, params.Search); relevance != "" {
+		parts = append(parts, "ORDER BY "+relevance)
+		args = append(args, relevanceArgs...)
 	} else {
-		parts = append(parts, "ORDER BY "+db.quote("id")+" DESC")
+		parts = append(parts, "ORDER BY "+db.keyOrder(model))
 	}
 
 	if params.PageSize > 0 {
@@ -347,7 +889,29 @@ func (db *SQLiteDB) buildSelectQuery(model string, params parser.QueryParams) (s
 	return strings.Join(parts, " "), args
 }
 
+func (db *SQLiteDB) searchRelevance(model, search string) (string, []any) {
+	m, ok := db.getModel(model)
+	if !ok {
+		return "", nil
+	}
+	return db.buildRelevanceOrder(m, search)
+}
+
+// filterColumn quotes a column name, or turns "settings.plan" into the
+// json_extract expression a declared JSON index is built on.
+func (db *SQLiteDB) filterColumn(field string) string {
+	if column, path, ok := parser.JSONPath(field); ok {
+		return db.jsonExtract(column, path)
+	}
+	return db.quote(field)
+}
+
+func (db *SQLiteDB) jsonExtract(column, path string) string {
+	return fmt.Sprintf("json_extract(%s, '%s')", db.quote(column), path)
+}
+
 func (db *SQLiteDB) buildWhereClause(filter parser.Filter) (string, any) {
+	column := db.filterColumn(filter.Field)
 	operator := filter.Operator
 	if operator == "" {
 		operator = "="
@@ -355,16 +919,20 @@ func (db *SQLiteDB) buildWhereClause(filter parser.Filter) (string, any) {
 
 	switch operator {
 	case "like":
-		return db.quote(filter.Field) + " LIKE ?", "%" + fmt.Sprint(filter.Value) + "%"
+		return column + " LIKE ?", "%" + fmt.Sprint(filter.Value) + "%"
 	case "in":
 		values := filter.Value.([]any)
 		placeholders := make([]string, len(values))
 		for i := range values {
 			placeholders[i] = "?"
 		}
-		return db.quote(filter.Field) + " IN (" + strings.Join(placeholders, ",") + ")", values
+		return column + " IN (" + strings.Join(placeholders, ",") + ")", values
+	case "is_null":
+		return column + " IS NULL", []any{}
+	case "not_null":
+		return column + " IS NOT NULL", []any{}
 	default:
-		return db.quote(filter.Field) + " " + operator + " ?", filter.Value
+		return column + " " + operator + " ?", filter.Value
 	}
 }
 
@@ -398,7 +966,9 @@ func (db *SQLiteDB) scanRow(rows *sql.Rows) (map[string]any, error) {
 }
 
 func (db *SQLiteDB) executeQueryRow(query string, args []any) (map[string]any, error) {
-	rows, err := db.conn.Query(query, args...)
+	start := time.Now()
+	rows, err := db.reader().Query(query, args...)
+	db.logQuery(start, query, args, nil)
 	if err != nil {
 		return nil, err
 	}
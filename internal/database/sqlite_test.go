@@ -1,10 +1,14 @@
 package database
 
 import (
+	"bytes"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -199,6 +203,14 @@ func TestSQLiteDB_BuildColumnDefinition(t *testing.T) {
 			field:    parser.Field{Name: "count", Type: parser.FieldTypeNumber, Default: 0},
 			expected: "\"count\" INTEGER DEFAULT 0",
 		},
+		{
+			field:    parser.Field{Name: "price", Type: parser.FieldTypeNumber, Decimal: true, Precision: 10, Scale: 2, Default: 5},
+			expected: "\"price\" NUMERIC(10,2) DEFAULT 5.00",
+		},
+		{
+			field:    parser.Field{Name: "ratio", Type: parser.FieldTypeNumber, Decimal: true, Default: 0.5},
+			expected: "\"ratio\" REAL DEFAULT 0.5",
+		},
 		{
 			field:    parser.Field{Name: "active", Type: parser.FieldTypeBoolean, Default: true},
 			expected: "\"active\" BOOLEAN DEFAULT 1",
@@ -227,6 +239,8 @@ func TestSQLiteDB_GetSQLiteType(t *testing.T) {
 	}{
 		{parser.Field{Type: parser.FieldTypeID}, "INTEGER"},
 		{parser.Field{Type: parser.FieldTypeNumber}, "INTEGER"},
+		{parser.Field{Type: parser.FieldTypeNumber, Decimal: true}, "REAL"},
+		{parser.Field{Type: parser.FieldTypeNumber, Decimal: true, Precision: 10, Scale: 2}, "NUMERIC(10,2)"},
 		{parser.Field{Type: parser.FieldTypeText}, "TEXT"},
 		{parser.Field{Type: parser.FieldTypeText, Max: &[]int{50}[0]}, "VARCHAR(50)"},
 		{parser.Field{Type: parser.FieldTypeBoolean}, "BOOLEAN"},
@@ -486,6 +500,57 @@ func TestSQLiteDB_Count_WithFilters(t *testing.T) {
 	}
 }
 
+func TestSQLiteDB_Aggregate(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.CreateSchema(createTestSchema()); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	users := []map[string]interface{}{
+		{"name": "Alice", "email": "alice@example.com", "age": 30, "role": "user"},
+		{"name": "Bob", "email": "bob@example.com", "age": 40, "role": "admin"},
+		{"name": "Charlie", "email": "charlie@example.com", "age": 50, "role": "user"},
+	}
+	for _, user := range users {
+		if _, err := db.Create("User", user); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	filters := []parser.Filter{{Field: "role", Operator: "=", Value: "user"}}
+	tests := []struct {
+		function string
+		field    string
+		filters  []parser.Filter
+		want     float64
+	}{
+		{"count", "", nil, 3},
+		{"sum", "age", nil, 120},
+		{"sum", "age", filters, 80},
+		{"avg", "age", filters, 40},
+	}
+	for _, tt := range tests {
+		got, err := db.Aggregate("User", tt.function, tt.field, tt.filters)
+		if err != nil {
+			t.Fatalf("Failed to aggregate %s(%s): %v", tt.function, tt.field, err)
+		}
+		if got != tt.want {
+			t.Errorf("Expected %s(%s) = %v, got %v", tt.function, tt.field, tt.want, got)
+		}
+	}
+
+	if _, err := db.Aggregate("User", "median", "age", nil); err == nil {
+		t.Error("Expected error for unsupported aggregate")
+	}
+}
+
 func TestSQLiteDB_GetConnection(t *testing.T) {
 	db, dbPath := createTestSQLiteDB(t)
 	defer os.Remove(dbPath)
@@ -940,4 +1005,1313 @@ models:
 	if count != int64(itemCount) {
 		t.Errorf("Expected count %d, got %d", itemCount, count)
 	}
-}
\ No newline at end of file
+}
+
+func TestSQLiteDB_Query_InFilter(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.CreateSchema(createTestSchema()); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	for _, name := range []string{"Alice", "Bob", "Charlie"} {
+		if _, err := db.Create("User", map[string]interface{}{"name": name, "email": name + "@example.com"}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	filters := []parser.Filter{{Field: "id", Operator: "in", Value: []any{"1", "3"}}}
+
+	results, err := db.Query("User", parser.QueryParams{Filters: filters})
+	if err != nil {
+		t.Fatalf("Failed to query with in filter: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 users, got: %d", len(results))
+	}
+
+	count, err := db.Count("User", filters)
+	if err != nil {
+		t.Fatalf("Failed to count with in filter: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected count 2, got: %d", count)
+	}
+}
+
+func TestSQLiteDB_Query_InFilterChunked(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+	db.config.MaxInValues = 2
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.CreateSchema(createTestSchema()); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	for _, name := range []string{"Alice", "Bob", "Charlie", "Dave", "Eve"} {
+		if _, err := db.Create("User", map[string]interface{}{"name": name, "email": name + "@example.com"}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	ids := []any{"1", "2", "4", "5", "4"}
+	for i := 0; i < 2000; i++ {
+		ids = append(ids, fmt.Sprint(1000+i))
+	}
+	filters := []parser.Filter{{Field: "id", Operator: "in", Value: ids}}
+
+	results, err := db.Query("User", parser.QueryParams{
+		Filters:  filters,
+		Sort:     []parser.SortField{{Field: "name"}},
+		Page:     1,
+		PageSize: 3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to query with chunked in filter: %v", err)
+	}
+	var names []string
+	for _, result := range results {
+		names = append(names, result["name"].(string))
+	}
+	if fmt.Sprint(names) != "[Alice Bob Dave]" {
+		t.Errorf("Expected first page [Alice Bob Dave], got %v", names)
+	}
+
+	count, err := db.Count("User", filters)
+	if err != nil {
+		t.Fatalf("Failed to count with chunked in filter: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected count 4, got: %d", count)
+	}
+
+	// A search without a sort keeps the relevance order across chunks.
+	searched := func() []string {
+		results, err := db.Query("User", parser.QueryParams{Filters: filters, Search: "e"})
+		if err != nil {
+			t.Fatalf("Failed to search with chunked in filter: %v", err)
+		}
+		var names []string
+		for _, result := range results {
+			names = append(names, result["name"].(string))
+			if _, ok := result["_relevance"]; ok {
+				t.Errorf("Expected the relevance score to be dropped, got %v", result)
+			}
+		}
+		return names
+	}
+	chunked := searched()
+	db.config.MaxInValues = len(ids)
+	if single := searched(); fmt.Sprint(chunked) != fmt.Sprint(single) || fmt.Sprint(single) != "[Eve Dave Alice Bob]" {
+		t.Errorf("Expected chunked search %v to match single query %v in relevance order", chunked, single)
+	}
+
+	db.config.MaxInValues = 0
+	results, err = db.Query("User", parser.QueryParams{Filters: filters})
+	if err != nil {
+		t.Fatalf("Failed to query 2000 ids with the default limit: %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("Expected 4 users, got: %d", len(results))
+	}
+}
+
+func TestSQLiteDB_DecimalRoundTrip(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Product": {
+				Name: "Product",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "price", Type: parser.FieldTypeNumber, Decimal: true, Precision: 10, Scale: 2},
+				},
+			},
+		},
+	}
+
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	id, err := db.Create("Product", map[string]any{"price": 29.99})
+	if err != nil {
+		t.Fatalf("Failed to create product: %v", err)
+	}
+
+	record, err := db.Get("Product", id)
+	if err != nil {
+		t.Fatalf("Failed to get product: %v", err)
+	}
+
+	if record["price"] != 29.99 {
+		t.Errorf("Expected price 29.99, got %v (%T)", record["price"], record["price"])
+	}
+}
+
+func TestSQLiteDB_UpdateTouchesAutoNow(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name: "Note",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: "updated_at", Type: parser.FieldTypeDatetime, AutoNow: true, Default: "CURRENT_TIMESTAMP"},
+				},
+			},
+		},
+	}
+
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	id, err := db.Create("Note", map[string]any{"title": "Draft", "updated_at": "2000-01-01 00:00:00"})
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	before := time.Now().UTC().Add(-time.Second)
+	if err := db.Update("Note", id, map[string]any{"title": "Final", "updated_at": "1999-01-01 00:00:00"}); err != nil {
+		t.Fatalf("Failed to update note: %v", err)
+	}
+
+	record, err := db.Get("Note", id)
+	if err != nil {
+		t.Fatalf("Failed to get note: %v", err)
+	}
+
+	var updatedAt time.Time
+	switch v := record["updated_at"].(type) {
+	case time.Time:
+		updatedAt = v
+	case string:
+		updatedAt, _ = time.Parse(sqliteTimestampLayout, v)
+	}
+	if updatedAt.Before(before) {
+		t.Errorf("Expected updated_at to be bumped to now, got %v", record["updated_at"])
+	}
+}
+
+func TestSQLiteDB_SoftDeleteAndRestore(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name:       "Note",
+				SoftDelete: true,
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: parser.SoftDeleteField, Type: parser.FieldTypeDatetime, Nullable: true},
+				},
+			},
+		},
+	}
+
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	id, err := db.Create("Note", map[string]any{"title": "Draft"})
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+
+	if err := db.Delete("Note", id); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+
+	if _, err := db.Get("Note", id); err != sql.ErrNoRows {
+		t.Errorf("Expected deleted note to be hidden, got: %v", err)
+	}
+
+	count, err := db.Count("Note", nil)
+	if err != nil || count != 0 {
+		t.Errorf("Expected 0 live notes, got %d (%v)", count, err)
+	}
+
+	trashed, err := db.Query("Note", parser.QueryParams{
+		Filters: []parser.Filter{{Field: parser.SoftDeleteField, Operator: "not_null"}},
+	})
+	if err != nil || len(trashed) != 1 {
+		t.Fatalf("Expected 1 trashed note, got %d (%v)", len(trashed), err)
+	}
+
+	if err := db.Restore("Note", id); err != nil {
+		t.Fatalf("Failed to restore note: %v", err)
+	}
+
+	if _, err := db.Get("Note", id); err != nil {
+		t.Errorf("Expected restored note to be visible, got: %v", err)
+	}
+
+	if err := db.Restore("Note", id); err != sql.ErrNoRows {
+		t.Errorf("Expected ErrNoRows restoring a live note, got: %v", err)
+	}
+}
+
+func TestSQLiteDB_ULIDPrimaryKey(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Event": {
+				Name: "Event",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true, KeyType: parser.KeyTypeULID},
+					{Name: "name", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+
+	if got := db.buildColumnDefinition(schema.Models["Event"].Fields[0]); got != "\"id\" TEXT PRIMARY KEY" {
+		t.Errorf("Unexpected id column definition: %s", got)
+	}
+
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	first, err := db.Create("Event", map[string]any{"name": "first"})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	id, ok := first.(string)
+	if !ok || len(id) != 26 {
+		t.Fatalf("Expected a ULID string id, got %v (%T)", first, first)
+	}
+
+	record, err := db.Get("Event", id)
+	if err != nil {
+		t.Fatalf("Failed to get event by ULID: %v", err)
+	}
+	if record["id"] != id || record["name"] != "first" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+}
+
+func TestSQLiteDB_ReadReplicas(t *testing.T) {
+	tmpDir := t.TempDir()
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Item": {
+				Name: "Item",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "source", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+
+	var replicaPaths []string
+	for _, name := range []string{"replica1", "replica2"} {
+		path := filepath.Join(tmpDir, name+".db")
+		replica, _ := NewSQLite(&parser.DatabaseConfig{Type: "sqlite", Path: path})
+		if err := replica.Connect(); err != nil {
+			t.Fatalf("Failed to connect replica: %v", err)
+		}
+		if err := replica.CreateSchema(schema); err != nil {
+			t.Fatalf("Failed to create replica schema: %v", err)
+		}
+		if _, err := replica.Create("Item", map[string]any{"source": name}); err != nil {
+			t.Fatalf("Failed to seed replica: %v", err)
+		}
+		replica.Close()
+		replicaPaths = append(replicaPaths, path)
+	}
+
+	primary, _ := NewSQLite(&parser.DatabaseConfig{
+		Type:         "sqlite",
+		Path:         filepath.Join(tmpDir, "primary.db"),
+		ReadReplicas: replicaPaths,
+	})
+	if err := primary.Connect(); err != nil {
+		t.Fatalf("Failed to connect primary: %v", err)
+	}
+	defer primary.Close()
+	if err := primary.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create primary schema: %v", err)
+	}
+	if _, err := primary.Create("Item", map[string]any{"source": "primary"}); err != nil {
+		t.Fatalf("Failed to write to primary: %v", err)
+	}
+
+	seen := map[any]int{}
+	for i := 0; i < 4; i++ {
+		rows, err := primary.Query("Item", parser.QueryParams{})
+		if err != nil || len(rows) != 1 {
+			t.Fatalf("Expected one row from a replica, got %v (%v)", rows, err)
+		}
+		seen[rows[0]["source"]]++
+	}
+
+	if seen["replica1"] != 2 || seen["replica2"] != 2 {
+		t.Errorf("Expected reads to alternate between replicas, got %v", seen)
+	}
+
+	var source string
+	conn := primary.(*SQLiteDB).GetConnection()
+	if err := conn.QueryRow(`SELECT source FROM "Item"`).Scan(&source); err != nil || source != "primary" {
+		t.Errorf("Expected the write to land on the primary, got %q (%v)", source, err)
+	}
+}
+
+func TestSQLiteDB_WeightedSearch(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Author": {
+				Name: "Author",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "bio", Type: parser.FieldTypeText},
+				},
+				UI: parser.UIModel{
+					List: parser.UIList{
+						Searchable:    []string{"name", "bio"},
+						SearchWeights: map[string]int{"name": 2},
+					},
+				},
+			},
+		},
+	}
+
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	db.Create("Author", map[string]any{"name": "Ada", "bio": "Worked with Grace"})
+	db.Create("Author", map[string]any{"name": "Grace", "bio": "Compiler pioneer"})
+	db.Create("Author", map[string]any{"name": "Alan", "bio": "Admired Grace"})
+
+	results, err := db.Query("Author", parser.QueryParams{Search: "Grace", Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	if len(results) != 3 || results[0]["name"] != "Grace" {
+		t.Errorf("Expected the name match first, got %v", results)
+	}
+}
+
+func TestSQLiteDB_BusyRetry(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "busy.db")
+	conn, _ := NewSQLite(&parser.DatabaseConfig{Type: "sqlite", Path: dbPath + "?_busy_timeout=0"})
+	db := conn.(*SQLiteDB)
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := createTestSchema()
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	locker, err := sql.Open("sqlite3", dbPath+"?_busy_timeout=0")
+	if err != nil {
+		t.Fatalf("Failed to open locking connection: %v", err)
+	}
+	defer locker.Close()
+
+	lock := func() *sql.Tx {
+		tx, err := locker.Begin()
+		if err != nil {
+			t.Fatalf("Failed to begin: %v", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO "User" (name, email) VALUES ('lock', 'lock@example.com')`); err != nil {
+			t.Fatalf("Failed to take write lock: %v", err)
+		}
+		return tx
+	}
+
+	tx := lock()
+	go func() {
+		time.Sleep(25 * time.Millisecond)
+		tx.Rollback()
+	}()
+
+	if _, err := db.Create("User", map[string]any{"name": "John", "email": "john@example.com"}); err != nil {
+		t.Errorf("Expected transient lock to be retried, got: %v", err)
+	}
+
+	// Transactional writes are replayed as a whole.
+	tx = lock()
+	go func() {
+		time.Sleep(25 * time.Millisecond)
+		tx.Rollback()
+	}()
+
+	id, record, err := db.CreateRecord("User", map[string]any{"name": "Ada", "email": "ada@example.com"})
+	if err != nil || record["name"] != "Ada" {
+		t.Errorf("Expected transient lock to be retried for CreateRecord, got: %v, %v", record, err)
+	}
+
+	tx = lock()
+	go func() {
+		time.Sleep(25 * time.Millisecond)
+		tx.Rollback()
+	}()
+
+	if record, err := db.UpdateRecord("User", id, map[string]any{"name": "Ada L."}); err != nil || record["name"] != "Ada L." {
+		t.Errorf("Expected transient lock to be retried for UpdateRecord, got: %v, %v", record, err)
+	}
+
+	tx = lock()
+	go func() {
+		time.Sleep(25 * time.Millisecond)
+		tx.Rollback()
+	}()
+
+	if ids, err := db.CreateBatch("User", []map[string]any{{"name": "Bob", "email": "bob@example.com"}}); err != nil || len(ids) != 1 {
+		t.Errorf("Expected transient lock to be retried for CreateBatch, got: %v, %v", ids, err)
+	}
+
+	tx = lock()
+	defer tx.Rollback()
+
+	_, err = db.Create("User", map[string]any{"name": "Jane", "email": "jane@example.com"})
+	if !errors.Is(err, ErrDatabaseBusy) {
+		t.Errorf("Expected ErrDatabaseBusy for a persistent lock, got: %v", err)
+	}
+	if _, _, err := db.CreateRecord("User", map[string]any{"name": "Jane", "email": "jane@example.com"}); !errors.Is(err, ErrDatabaseBusy) {
+		t.Errorf("Expected ErrDatabaseBusy from CreateRecord for a persistent lock, got: %v", err)
+	}
+}
+
+func TestSQLiteDB_CreateBatch(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Tag": {
+				Name: "Tag",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText, Unique: true},
+				},
+			},
+		},
+	}
+
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	ids, err := db.CreateBatch("Tag", []map[string]any{{"name": "go"}, {"name": "sql"}})
+	if err != nil {
+		t.Fatalf("Failed to create batch: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("Expected 2 ids, got %d", len(ids))
+	}
+
+	_, err = db.CreateBatch("Tag", []map[string]any{{"name": "yaml"}, {"name": "go"}})
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("Expected unique violation, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "item 1") {
+		t.Errorf("Expected error to name the failing item, got %v", err)
+	}
+
+	count, err := db.Count("Tag", nil)
+	if err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected failed batch to be rolled back leaving 2 rows, got %d", count)
+	}
+}
+
+func TestSQLiteDB_UniqueEmailIgnoresCase(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Account": {
+				Name: "Account",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "email", Type: parser.FieldTypeEmail, Unique: true, IgnoreCase: true},
+				},
+			},
+			"Contact": {
+				Name: "Contact",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "email", Type: parser.FieldTypeEmail, Unique: true},
+				},
+			},
+		},
+	}
+
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	// Without conventions.email_unique a unique email keeps comparing case.
+	if _, err := db.Create("Contact", map[string]any{"email": "jane@example.com"}); err != nil {
+		t.Fatalf("Failed to create contact: %v", err)
+	}
+	if _, err := db.Create("Contact", map[string]any{"email": "Jane@Example.com"}); err != nil {
+		t.Errorf("Expected a differently-cased email to be accepted, got %v", err)
+	}
+
+	if _, err := db.Create("Account", map[string]any{"email": "jane@example.com"}); err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+	_, err := db.Create("Account", map[string]any{"email": "Jane@Example.com"})
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Errorf("Expected unique violation for a differently-cased email, got %v", err)
+	}
+	var violation *UniqueViolation
+	if !errors.As(err, &violation) || !strings.EqualFold(violation.Table, "account") || violation.Field != "email" {
+		t.Errorf("Expected the violation to name account.email, got %+v", violation)
+	}
+}
+
+func TestSQLiteDB_LogQueries(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+	db.config.LogQueries = true
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name:       "User",
+				SoftDelete: true,
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "password", Type: parser.FieldTypePassword},
+					{Name: parser.SoftDeleteField, Type: parser.FieldTypeDatetime, Nullable: true},
+				},
+			},
+		},
+	}
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	id, err := db.Create("User", map[string]any{"name": "ann", "password": "hunter22"})
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if _, err := db.Get("User", id); err != nil {
+		t.Fatalf("Failed to get: %v", err)
+	}
+	if err := db.Delete("User", id); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if err := db.Restore("User", id); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if _, err := db.Aggregate("User", "sum", "id", nil); err != nil {
+		t.Fatalf("Failed to aggregate: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "INSERT INTO") || !strings.Contains(output, "SELECT * FROM") {
+		t.Errorf("Expected statements to be logged, got %q", output)
+	}
+	if !strings.Contains(output, "= NULL WHERE") || !strings.Contains(output, "SUM(") {
+		t.Errorf("Expected restores and aggregates to be logged, got %q", output)
+	}
+	if strings.Contains(output, "hunter22") || !strings.Contains(output, redactedArg) {
+		t.Errorf("Expected the password to be redacted, got %q", output)
+	}
+	if !strings.Contains(output, "ann") {
+		t.Errorf("Expected other args to be logged, got %q", output)
+	}
+}
+
+func TestSQLiteDB_AutoIndex(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+	db.config.AutoIndex = true
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name:   "User",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: "status", Type: parser.FieldTypeText},
+					{Name: "body", Type: parser.FieldTypeText},
+					{Name: "author_id", Type: parser.FieldTypeRelation, RelatedTo: "User"},
+				},
+				UI: parser.UIModel{List: parser.UIList{Sortable: []string{"title"}, Filterable: []string{"status"}}},
+			},
+		},
+	}
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	indexes := map[string]bool{}
+	rows, err := db.conn.Query("SELECT name FROM sqlite_master WHERE type='index' AND tbl_name='Post'")
+	if err != nil {
+		t.Fatalf("Failed to list indexes: %v", err)
+	}
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		indexes[name] = true
+	}
+	rows.Close()
+
+	for _, want := range []string{"idx_Post_title", "idx_Post_status", "idx_Post_author_id"} {
+		if !indexes[want] {
+			t.Errorf("Expected index %s, got %v", want, indexes)
+		}
+	}
+	if indexes["idx_Post_body"] {
+		t.Error("Expected no index on a column that is neither sorted nor filtered")
+	}
+
+	var id, parent, notused int
+	var detail string
+	if err := db.conn.QueryRow(`EXPLAIN QUERY PLAN SELECT * FROM "Post" ORDER BY "title"`).Scan(&id, &parent, &notused, &detail); err != nil {
+		t.Fatalf("Failed to explain query: %v", err)
+	}
+	if !strings.Contains(detail, "idx_Post_title") {
+		t.Errorf("Expected sorting by title to use its index, got %q", detail)
+	}
+}
+
+func TestSQLiteDB_CreateRecordReadsOwnWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Item": {
+				Name: "Item",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText, Unique: true},
+				},
+			},
+			"Note": {
+				Name:       "Note",
+				SoftDelete: true,
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: parser.SoftDeleteField, Type: parser.FieldTypeDatetime, Nullable: true},
+				},
+			},
+		},
+	}
+
+	// The replica never receives the write, so reading the record back
+	// through it would fail.
+	replicaPath := filepath.Join(tmpDir, "replica.db")
+	replica, _ := NewSQLite(&parser.DatabaseConfig{Type: "sqlite", Path: replicaPath})
+	if err := replica.Connect(); err != nil {
+		t.Fatalf("Failed to connect replica: %v", err)
+	}
+	if err := replica.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create replica schema: %v", err)
+	}
+	replica.Close()
+
+	primary, _ := NewSQLite(&parser.DatabaseConfig{
+		Type:         "sqlite",
+		Path:         filepath.Join(tmpDir, "primary.db"),
+		ReadReplicas: []string{replicaPath},
+	})
+	if err := primary.Connect(); err != nil {
+		t.Fatalf("Failed to connect primary: %v", err)
+	}
+	defer primary.Close()
+	if err := primary.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create primary schema: %v", err)
+	}
+
+	writer := primary.(RecordWriter)
+	id, record, err := writer.CreateRecord("Item", map[string]any{"name": "first"})
+	if err != nil {
+		t.Fatalf("Failed to create record: %v", err)
+	}
+	if record["name"] != "first" || fmt.Sprint(record["id"]) != fmt.Sprint(id) {
+		t.Errorf("Expected the created record back, got %v", record)
+	}
+
+	record, err = writer.UpdateRecord("Item", id, map[string]any{"name": "second"})
+	if err != nil {
+		t.Fatalf("Failed to update record: %v", err)
+	}
+	if record["name"] != "second" {
+		t.Errorf("Expected the updated record back, got %v", record)
+	}
+
+	if _, _, err := writer.CreateRecord("Item", map[string]any{"name": "second"}); !errors.Is(err, ErrUniqueViolation) {
+		t.Errorf("Expected unique violation, got %v", err)
+	}
+
+	ids, records, err := writer.CreateRecords("Item", []map[string]any{{"name": "third"}, {"name": "fourth"}})
+	if err != nil {
+		t.Fatalf("Failed to create records: %v", err)
+	}
+	if len(ids) != 2 || len(records) != 2 || records[1]["name"] != "fourth" {
+		t.Errorf("Expected both created records back, got %v", records)
+	}
+
+	_, _, err = writer.CreateRecords("Item", []map[string]any{{"name": "fifth"}, {"name": "third"}})
+	if !errors.Is(err, ErrUniqueViolation) || !strings.Contains(err.Error(), "item 1") {
+		t.Errorf("Expected a unique violation naming item 1, got %v", err)
+	}
+
+	// Counted on the primary, since the replica never sees the writes.
+	count := func() int {
+		var n int
+		primary.(*SQLiteDB).conn.QueryRow(`SELECT COUNT(*) FROM "Item"`).Scan(&n)
+		return n
+	}
+	if n := count(); n != 3 {
+		t.Errorf("Expected the failed batch to add nothing, got %d items", n)
+	}
+
+	if err := writer.DeleteRecords("Item", ids); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("Expected only the first item left, got %d", n)
+	}
+
+	noteID, _, err := writer.CreateRecord("Note", map[string]any{"title": "draft"})
+	if err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := primary.Delete("Note", noteID); err != nil {
+		t.Fatalf("Failed to delete note: %v", err)
+	}
+	record, err = writer.RestoreRecord("Note", noteID)
+	if err != nil {
+		t.Fatalf("Failed to restore note: %v", err)
+	}
+	if record["title"] != "draft" || record[parser.SoftDeleteField] != nil {
+		t.Errorf("Expected the restored note back, got %v", record)
+	}
+	if _, err := writer.RestoreRecord("Note", noteID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected restoring a live note to find nothing, got %v", err)
+	}
+}
+
+func TestSQLiteDB_QuoteEscapesIdentifiers(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if got := db.quote(`name"; DROP TABLE item; --`); got != `"name""; DROP TABLE item; --"` {
+		t.Errorf("Expected embedded quotes to be doubled, got %s", got)
+	}
+}
+
+func TestSQLiteDB_CountUpTo(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.CreateSchema(createTestSchema()); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	for _, name := range []string{"Alice", "Bob", "Charlie"} {
+		if _, err := db.Create("User", map[string]interface{}{"name": name, "email": name + "@example.com"}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	if count, err := db.CountUpTo("User", nil, 2); err != nil || count != 2 {
+		t.Errorf("Expected the count to stop at 2, got %d (%v)", count, err)
+	}
+
+	filters := []parser.Filter{{Field: "name", Operator: "!=", Value: "Alice"}}
+	if count, err := db.CountUpTo("User", filters, 5); err != nil || count != 2 {
+		t.Errorf("Expected 2 matching users below the limit, got %d (%v)", count, err)
+	}
+}
+
+func TestSQLiteDB_SearchRanksExactAndPrefixMatchesFirst(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Person": {
+				Name: "Person",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "email", Type: parser.FieldTypeEmail},
+				},
+				UI: parser.UIModel{
+					List: parser.UIList{Searchable: []string{"name", "email"}},
+				},
+			},
+		},
+	}
+
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	for _, person := range [][2]string{
+		{"Major Jones", "mj@x.com"},
+		{"Bob", "jo@x.com"},
+		{"Ann", "ann.jo@x.com"},
+		{"John", "john@x.com"},
+		{"Jo", "j@x.com"},
+	} {
+		db.Create("Person", map[string]any{"name": person[0], "email": person[1]})
+	}
+
+	results, err := db.Query("Person", parser.QueryParams{Search: "jo", Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	var names []string
+	for _, result := range results {
+		names = append(names, fmt.Sprint(result["name"]))
+	}
+	expected := "Jo,John,Bob,Ann,Major Jones"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("Expected %s, got %s", expected, strings.Join(names, ","))
+	}
+}
+
+func TestSQLiteDB_JSONPathIndex(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Account": {
+				Name: "Account",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "settings", Type: parser.FieldTypeJSON},
+				},
+				Indexes: []parser.IndexConfig{{JSONField: "settings", Path: "$.billing.plan"}},
+			},
+		},
+	}
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	for _, settings := range []string{`{"billing":{"plan":"pro"}}`, `{"billing":{"plan":"free"}}`, `{}`} {
+		if _, err := db.Create("Account", map[string]any{"settings": settings}); err != nil {
+			t.Fatalf("Failed to create account: %v", err)
+		}
+	}
+
+	params := parser.QueryParams{Filters: []parser.Filter{{Field: "settings.billing.plan", Operator: "=", Value: "pro"}}}
+	results, err := db.Query("Account", params)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 account on the pro plan, got %d", len(results))
+	}
+
+	query, args := db.buildSelectQuery("Account", params)
+	rows, err := db.conn.Query("EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		t.Fatalf("Failed to explain query: %v", err)
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var id, parent, notused int
+		var detail string
+		rows.Scan(&id, &parent, &notused, &detail)
+		plan = append(plan, detail)
+	}
+	if !strings.Contains(strings.Join(plan, "\n"), "idx_Account_settings_billing_plan") {
+		t.Errorf("Expected the JSON path filter to use its index, got %q", plan)
+	}
+}
+
+func TestSQLiteDB_QueryStream(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+	db.config.MaxInValues = 2
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+	if err := db.CreateSchema(createTestSchema()); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := db.Create("User", map[string]any{"name": fmt.Sprintf("user%d", i), "email": fmt.Sprintf("user%d@example.com", i)}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	for _, params := range []parser.QueryParams{
+		{Page: 1, PageSize: 3},
+		{Filters: []parser.Filter{{Field: "id", Operator: "in", Value: []any{"1", "2", "3"}}}},
+	} {
+		want, err := db.Query("User", params)
+		if err != nil {
+			t.Fatalf("Failed to query: %v", err)
+		}
+
+		rows, err := db.QueryStream("User", params)
+		if err != nil {
+			t.Fatalf("Failed to stream: %v", err)
+		}
+		var got []map[string]any
+		for rows.Next() {
+			got = append(got, rows.Record())
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatalf("Stream failed: %v", err)
+		}
+		rows.Close()
+
+		if len(got) != len(want) {
+			t.Fatalf("Expected %d streamed records, got %d", len(want), len(got))
+		}
+		for i := range want {
+			if got[i]["id"] != want[i]["id"] {
+				t.Errorf("Record %d: expected id %v, got %v", i, want[i]["id"], got[i]["id"])
+			}
+		}
+	}
+}
+
+func TestSQLiteDB_CompositePrimaryKey(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"UserRole": {
+				Name: "UserRole",
+				Fields: []parser.Field{
+					{Name: "user_id", Type: parser.FieldTypeNumber, Required: true},
+					{Name: "role_id", Type: parser.FieldTypeNumber, Required: true},
+					{Name: "note", Type: parser.FieldTypeText},
+				},
+				PrimaryKey: []string{"user_id", "role_id"},
+			},
+		},
+	}
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	id, err := db.Create("UserRole", map[string]any{"user_id": 5, "role_id": 3, "note": "a"})
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if id != "5,3" {
+		t.Fatalf("Expected composite id 5,3, got %v", id)
+	}
+	if _, err := db.Create("UserRole", map[string]any{"user_id": 5, "role_id": 4}); err != nil {
+		t.Fatalf("Failed to create second row: %v", err)
+	}
+	if _, err := db.Create("UserRole", map[string]any{"user_id": 5, "role_id": 3}); !errors.Is(err, ErrUniqueViolation) {
+		t.Errorf("Expected a duplicate key to be rejected, got: %v", err)
+	}
+
+	record, err := db.Get("UserRole", "5,3")
+	if err != nil {
+		t.Fatalf("Failed to get: %v", err)
+	}
+	if record["id"] != "5,3" || record["note"] != "a" {
+		t.Errorf("Expected the 5,3 record with its composite id, got %v", record)
+	}
+
+	if err := db.Update("UserRole", "5,3", map[string]any{"id": "5,3", "note": "b"}); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	if record, _ := db.Get("UserRole", "5,3"); record["note"] != "b" {
+		t.Errorf("Expected the note to be updated, got %v", record["note"])
+	}
+	if other, _ := db.Get("UserRole", "5,4"); other["note"] != nil {
+		t.Errorf("Expected the other row to be untouched, got %v", other["note"])
+	}
+
+	results, err := db.Query("UserRole", parser.QueryParams{})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if len(results) != 2 || results[0]["id"] != "5,4" {
+		t.Errorf("Expected rows newest key first, got %v", results)
+	}
+	results, err = db.Query("UserRole", parser.QueryParams{Filters: []parser.Filter{{Field: "id", Operator: "=", Value: "5,4"}}})
+	if err != nil || len(results) != 1 {
+		t.Errorf("Expected an id filter to match one row, got %v (%v)", results, err)
+	}
+
+	if _, err := db.Get("UserRole", "5"); err != sql.ErrNoRows {
+		t.Errorf("Expected a partial id to match nothing, got: %v", err)
+	}
+
+	if err := db.Delete("UserRole", "5,3"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, err := db.Get("UserRole", "5,3"); err != sql.ErrNoRows {
+		t.Errorf("Expected the deleted row to be gone, got: %v", err)
+	}
+	if count, _ := db.Count("UserRole", nil); count != 1 {
+		t.Errorf("Expected one row left, got %d", count)
+	}
+}
+
+func TestSQLiteDB_PingReconnect(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		t.Fatalf("Expected ping to succeed, got: %v", err)
+	}
+	if err := db.Reconnect(); err != nil {
+		t.Fatalf("Expected reconnect to succeed, got: %v", err)
+	}
+
+	db.conn.Close()
+	if err := db.Ping(); err == nil {
+		t.Error("Expected ping on a closed connection to fail")
+	}
+}
+
+func TestSQLiteDB_DumpAndSeed(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "author_id", Type: parser.FieldTypeRelation, RelatedTo: "User"},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: "deleted_at", Type: parser.FieldTypeDatetime},
+				},
+				SoftDelete: true,
+			},
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "password", Type: parser.FieldTypePassword, Required: true},
+					{Name: "pin", Type: parser.FieldTypePassword},
+				},
+			},
+		},
+	}
+
+	open := func() *SQLiteDB {
+		db, dbPath := createTestSQLiteDB(t)
+		t.Cleanup(func() { os.Remove(dbPath) })
+		if err := db.Connect(); err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if err := db.CreateSchema(schema); err != nil {
+			t.Fatalf("Failed to create schema: %v", err)
+		}
+		return db
+	}
+
+	source := open()
+	source.Create("User", map[string]any{"id": 7, "name": "Ann", "password": "secret", "pin": "1234"})
+	source.Create("Post", map[string]any{"author_id": 7, "title": "Live"})
+	trashed, _ := source.Create("Post", map[string]any{"author_id": 7, "title": "Trashed"})
+	source.Delete("Post", trashed)
+
+	seed, omitted, err := Dump(source, schema)
+	if err != nil {
+		t.Fatalf("Failed to dump: %v", err)
+	}
+	if len(omitted) != 2 || omitted[0] != "User.password" || omitted[1] != "User.pin" {
+		t.Errorf("Expected User.password and User.pin to be reported as omitted, got %v", omitted)
+	}
+	if seed["User"][0]["password"] != RedactedPassword {
+		t.Errorf("Expected the required password to be redacted, got %v", seed["User"][0]["password"])
+	}
+	if _, ok := seed["User"][0]["pin"]; ok {
+		t.Error("Expected optional password values to be left out of the dump")
+	}
+
+	target := open()
+	if err := Seed(target, schema, seed); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	user, err := target.Get("User", 7)
+	if err != nil || user["name"] != "Ann" {
+		t.Errorf("Expected the user to keep its id, got %v (%v)", user, err)
+	}
+	if password, _ := user["password"].(string); password == "" || password == RedactedPassword || password == "secret" {
+		t.Errorf("Expected the redacted password to be replaced with a random value, got %q", password)
+	}
+	if seed["User"][0]["password"] != RedactedPassword {
+		t.Error("Expected seeding to leave the caller's records unchanged")
+	}
+	if posts, _ := target.Query("Post", parser.QueryParams{}); len(posts) != 1 || posts[0]["title"] != "Live" {
+		t.Errorf("Expected the live post to be seeded, got %v", posts)
+	}
+	if count, _ := countAll(target, schema.Models["Post"]); count != 2 {
+		t.Errorf("Expected the soft-deleted post to be seeded too, got %d posts", count)
+	}
+
+	if err := Seed(target, schema, seed); err != nil {
+		t.Fatalf("Expected seeding a populated database to be skipped, got %v", err)
+	}
+	if count, _ := target.Count("User", nil); count != 1 {
+		t.Errorf("Expected seeding twice to leave one user, got %d", count)
+	}
+}
+
+func TestSQLiteDB_UniqueWithin(t *testing.T) {
+	db, dbPath := createTestSQLiteDB(t)
+	defer os.Remove(dbPath)
+
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "author_id", Type: parser.FieldTypeNumber},
+					{Name: "slug", Type: parser.FieldTypeSlug, UniqueWithin: "author_id"},
+				},
+			},
+		},
+	}
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	if _, err := db.Create("Post", map[string]any{"author_id": 1, "slug": "intro"}); err != nil {
+		t.Fatalf("Failed to create post: %v", err)
+	}
+	if _, err := db.Create("Post", map[string]any{"author_id": 2, "slug": "intro"}); err != nil {
+		t.Errorf("Expected another author to reuse the slug, got %v", err)
+	}
+
+	_, err := db.Create("Post", map[string]any{"author_id": 1, "slug": "Intro"})
+	var violation *UniqueViolation
+	if !errors.As(err, &violation) || violation.Field != "slug" {
+		t.Errorf("Expected a unique violation on slug for the same author, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// RecordStreamer runs a list query and hands the rows back one at a time,
+// so callers can encode a large page without holding all of it in memory.
+type RecordStreamer interface {
+	QueryStream(model string, params parser.QueryParams) (*RecordIterator, error)
+}
+
+// RecordIterator walks the rows of a QueryStream. Close must be called
+// once the caller is done, even if Next returned false.
+type RecordIterator struct {
+	next   func() (map[string]any, bool, error)
+	close  func() error
+	record map[string]any
+	err    error
+}
+
+func (it *RecordIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	record, ok, err := it.next()
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.record = record
+	return ok
+}
+
+func (it *RecordIterator) Record() map[string]any {
+	return it.record
+}
+
+func (it *RecordIterator) Err() error {
+	return it.err
+}
+
+func (it *RecordIterator) Close() error {
+	if it.close == nil {
+		return nil
+	}
+	return it.close()
+}
+
+func sliceIterator(records []map[string]any) *RecordIterator {
+	i := 0
+	return &RecordIterator{next: func() (map[string]any, bool, error) {
+		if i >= len(records) {
+			return nil, false, nil
+		}
+		i++
+		return records[i-1], true, nil
+	}}
+}
+
+func (db *SQLiteDB) QueryStream(model string, params parser.QueryParams) (*RecordIterator, error) {
+	// Chunked IN queries are merged and sorted in memory anyway.
+	if db.oversizedInFilter(params.Filters) >= 0 {
+		records, err := db.Query(model, params)
+		if err != nil {
+			return nil, err
+		}
+		return sliceIterator(records), nil
+	}
+
+	query, args := db.buildSelectQuery(model, params)
+
+	start := time.Now()
+	rows, err := db.reader().Query(query, args...)
+	db.logQuery(start, query, args, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordIterator{
+		next: func() (map[string]any, bool, error) {
+			if !rows.Next() {
+				return nil, false, rows.Err()
+			}
+			row, err := db.scanRow(rows)
+			if err != nil {
+				return nil, false, err
+			}
+			return db.decodeObjects(model, row), true, nil
+		},
+		close: rows.Close,
+	}, nil
+}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RecordWriter writes a record and reads it back in the same transaction,
+// so the result is exactly what was stored, even with read replicas or a
+// concurrent writer. The batch variants write every record or none.
+type RecordWriter interface {
+	CreateRecord(model string, data map[string]any) (any, map[string]any, error)
+	UpdateRecord(model string, id any, data map[string]any) (map[string]any, error)
+	RestoreRecord(model string, id any) (map[string]any, error)
+	CreateRecords(model string, items []map[string]any) ([]any, []map[string]any, error)
+	DeleteRecords(model string, ids []any) error
+}
+
+func (db *SQLiteDB) CreateRecord(model string, data map[string]any) (any, map[string]any, error) {
+	var id any
+	var record map[string]any
+	err := db.inTx(func(tx *sql.Tx) error {
+		var err error
+		if id, err = db.insert(txExec(tx), model, data); err != nil {
+			return err
+		}
+		record, err = db.getTx(tx, model, id)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, record, nil
+}
+
+func (db *SQLiteDB) UpdateRecord(model string, id any, data map[string]any) (map[string]any, error) {
+	var record map[string]any
+	err := db.inTx(func(tx *sql.Tx) error {
+		if err := db.update(txExec(tx), model, id, data); err != nil {
+			return err
+		}
+		var err error
+		record, err = db.getTx(tx, model, id)
+		return err
+	})
+	return record, err
+}
+
+func (db *SQLiteDB) RestoreRecord(model string, id any) (map[string]any, error) {
+	var record map[string]any
+	err := db.inTx(func(tx *sql.Tx) error {
+		if err := db.restore(txExec(tx), model, id); err != nil {
+			return err
+		}
+		var err error
+		record, err = db.getTx(tx, model, id)
+		return err
+	})
+	return record, err
+}
+
+// CreateRecords inserts every item and reads them back in one
+// transaction; the error of a failing insert names its item.
+func (db *SQLiteDB) CreateRecords(model string, items []map[string]any) ([]any, []map[string]any, error) {
+	var ids []any
+	var records []map[string]any
+	err := db.inTx(func(tx *sql.Tx) error {
+		ids = make([]any, 0, len(items))
+		records = make([]map[string]any, 0, len(items))
+		for i, item := range items {
+			id, err := db.insert(txExec(tx), model, item)
+			if err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+			record, err := db.getTx(tx, model, id)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ids, records, nil
+}
+
+// DeleteRecords deletes every id in one transaction, so a failing delete
+// leaves all of them in place.
+func (db *SQLiteDB) DeleteRecords(model string, ids []any) error {
+	return db.inTx(func(tx *sql.Tx) error {
+		for _, id := range ids {
+			if err := db.remove(txExec(tx), model, id); err != nil {
+				return fmt.Errorf("id %v: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// inTx runs fn in a transaction. A busy database can't be waited out
+// statement by statement once the transaction holds locks, so the whole
+// transaction is rolled back and replayed with the same backoff as
+// execWrite. fn must therefore be safe to run more than once.
+func (db *SQLiteDB) inTx(fn func(tx *sql.Tx) error) error {
+	backoff := busyBackoff
+	for attempt := 0; ; attempt++ {
+		err := db.runTx(fn)
+		if err == nil || !(isBusy(err) || errors.Is(err, ErrDatabaseBusy)) {
+			return err
+		}
+		if attempt == busyRetries {
+			if errors.Is(err, ErrDatabaseBusy) {
+				return err
+			}
+			return fmt.Errorf("%w: %v", ErrDatabaseBusy, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (db *SQLiteDB) runTx(fn func(tx *sql.Tx) error) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// getTx reads the record back without the default_filter, so a write
+// that moves a record out of the caller's view still succeeds.
+func (db *SQLiteDB) getTx(tx *sql.Tx, model string, id any) (map[string]any, error) {
+	query, args := db.unscoped().getQuery(model, id)
+	start := time.Now()
+	rows, err := tx.Query(query, args...)
+	db.logQuery(start, query, args, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, sql.ErrNoRows
+	}
+	row, err := db.scanRow(rows)
+	if err != nil {
+		return nil, err
+	}
+	return db.decodeObjects(model, row), nil
+}
+
+// txExec maps SQLite errors the same way execWrite does, minus the busy
+// retries, which inTx does for the whole transaction instead.
+func txExec(tx *sql.Tx) func(query string, args ...any) (sql.Result, error) {
+	return func(query string, args ...any) (sql.Result, error) {
+		result, err := tx.Exec(query, args...)
+		if isUniqueViolation(err) {
+			return nil, newUniqueViolation(err)
+		}
+		if isBusy(err) {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseBusy, err)
+		}
+		return result, err
+	}
+}
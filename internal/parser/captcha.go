@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CaptchaWidget is what a browser needs to show a provider's CAPTCHA: the
+// script to load, the class its element is marked with, and the global
+// object the script installs.
+type CaptchaWidget struct {
+	Script string
+	Class  string
+	Global string
+}
+
+var captchaWidgets = map[string]CaptchaWidget{
+	"hcaptcha.com":              {Script: "https://js.hcaptcha.com/1/api.js", Class: "h-captcha", Global: "hcaptcha"},
+	"api.hcaptcha.com":          {Script: "https://js.hcaptcha.com/1/api.js", Class: "h-captcha", Global: "hcaptcha"},
+	"www.google.com":            {Script: "https://www.google.com/recaptcha/api.js", Class: "g-recaptcha", Global: "grecaptcha"},
+	"www.recaptcha.net":         {Script: "https://www.recaptcha.net/recaptcha/api.js", Class: "g-recaptcha", Global: "grecaptcha"},
+	"challenges.cloudflare.com": {Script: "https://challenges.cloudflare.com/turnstile/v0/api.js", Class: "cf-turnstile", Global: "turnstile"},
+}
+
+// Widget returns the login widget of the provider VerifyURL points at.
+func (c *CaptchaConfig) Widget() (CaptchaWidget, bool) {
+	u, err := url.Parse(c.VerifyURL)
+	if err != nil {
+		return CaptchaWidget{}, false
+	}
+	widget, ok := captchaWidgets[strings.ToLower(u.Hostname())]
+	return widget, ok
+}
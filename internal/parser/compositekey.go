@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeySeparator joins the parts of a composite primary key into the id used
+// in record paths, e.g. /api/user_role/5,3 for primary: [user_id, role_id].
+const KeySeparator = ","
+
+// HasCompositeKey reports whether the model is keyed by several fields
+// instead of an id column.
+func (m *Model) HasCompositeKey() bool {
+	return len(m.PrimaryKey) > 1
+}
+
+// KeyColumns lists the columns that identify a record.
+func (m *Model) KeyColumns() []string {
+	if m.HasCompositeKey() {
+		return m.PrimaryKey
+	}
+	return []string{"id"}
+}
+
+// SplitKey breaks a composite id into one value per key column.
+func (m *Model) SplitKey(id any) ([]any, error) {
+	parts := strings.Split(fmt.Sprint(id), KeySeparator)
+	if len(parts) != len(m.PrimaryKey) {
+		return nil, fmt.Errorf("%s ids have the form %s", m.Name, strings.Join(m.PrimaryKey, KeySeparator))
+	}
+	values := make([]any, len(parts))
+	for i, part := range parts {
+		values[i] = part
+	}
+	return values, nil
+}
+
+// CompositeID joins a record's key values into its composite id.
+func (m *Model) CompositeID(record map[string]any) string {
+	parts := make([]string, len(m.PrimaryKey))
+	for i, name := range m.PrimaryKey {
+		parts[i] = fmt.Sprint(record[name])
+	}
+	return strings.Join(parts, KeySeparator)
+}
+
+func validatePrimaryKey(name string, model ModelConfig) error {
+	if len(model.Primary) < 2 {
+		return fmt.Errorf("primary of model %s must list at least two fields (mark a single key field primary instead)", name)
+	}
+	seen := make(map[string]bool, len(model.Primary))
+	for _, fieldName := range model.Primary {
+		field, ok := model.Fields[fieldName]
+		if !ok {
+			return fmt.Errorf("primary of model %s references unknown field %s", name, fieldName)
+		}
+		if seen[fieldName] {
+			return fmt.Errorf("primary of model %s lists %s twice", name, fieldName)
+		}
+		seen[fieldName] = true
+		switch FieldType(field.Type) {
+		case FieldTypeJSON, FieldTypeObject, FieldTypeArray, FieldTypePassword:
+			return fmt.Errorf("field %s.%s of type %s cannot be part of a primary key", name, fieldName, field.Type)
+		}
+	}
+	for fieldName, field := range model.Fields {
+		if field.Primary || FieldType(field.Type) == FieldTypeID {
+			return fmt.Errorf("model %s has a composite primary key, so %s cannot be a primary key too", name, fieldName)
+		}
+	}
+	return nil
+}
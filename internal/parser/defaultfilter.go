@@ -0,0 +1,66 @@
+package parser
+
+import "fmt"
+
+// FilterConfig is one condition of a model's default_filter, e.g.
+// {field: published, value: true}. Operator defaults to "=".
+type FilterConfig struct {
+	Field    string `yaml:"field"`
+	Operator string `yaml:"operator"`
+	Value    any    `yaml:"value"`
+}
+
+// Operators are spelled into SQL as-is, so only those the query builders
+// understand are accepted.
+var defaultFilterOperators = map[string]bool{
+	"=": true, "!=": true, ">": true, ">=": true, "<": true, "<=": true,
+	"like": true, "in": true, "is_null": true, "not_null": true,
+}
+
+func (c FilterConfig) filter() Filter {
+	operator := c.Operator
+	if operator == "" {
+		operator = "="
+	}
+	return Filter{Field: c.Field, Operator: operator, Value: c.Value}
+}
+
+func validateDefaultFilter(name string, model ModelConfig) error {
+	for i, config := range model.DefaultFilter {
+		field, ok := model.Fields[config.Field]
+		if !ok && config.Field != "id" {
+			return fmt.Errorf("default_filter %d of model %s references unknown field '%s'", i+1, name, config.Field)
+		}
+		if field.Type == string(FieldTypePassword) {
+			return fmt.Errorf("default_filter %d of model %s cannot filter on password field %s", i+1, name, config.Field)
+		}
+
+		filter := config.filter()
+		if !defaultFilterOperators[filter.Operator] {
+			return fmt.Errorf("default_filter %d of model %s has invalid operator '%s'", i+1, name, filter.Operator)
+		}
+		switch filter.Operator {
+		case "is_null", "not_null":
+		case "in":
+			if values, ok := filter.Value.([]any); !ok || len(values) == 0 {
+				return fmt.Errorf("default_filter %d of model %s needs a list value for 'in'", i+1, name)
+			}
+		default:
+			if filter.Value == nil {
+				return fmt.Errorf("default_filter %d of model %s needs a value", i+1, name)
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultFilters returns the model's default_filter, unless role is one
+// of the filter_bypass roles.
+func (m *Model) DefaultFilters(role string) []Filter {
+	for _, bypass := range m.FilterBypass {
+		if bypass == role {
+			return nil
+		}
+	}
+	return m.DefaultFilter
+}
@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Hook events run before the write they are named after.
+const (
+	HookBeforeCreate = "before_create"
+	HookBeforeUpdate = "before_update"
+	HookBeforeDelete = "before_delete"
+)
+
+// HookConfig is one step of a lifecycle hook. Set assigns the rendered
+// Value template to a field; Reject aborts the write with the rendered
+// message unless it renders blank. Templates see the record as `.field`.
+type HookConfig struct {
+	Set    string `yaml:"set"`
+	Value  string `yaml:"value"`
+	Reject string `yaml:"reject"`
+
+	// tmpl is the step's template, parsed once when the schema is built.
+	tmpl *template.Template
+}
+
+func (h HookConfig) text() string {
+	if h.Reject != "" {
+		return h.Reject
+	}
+	return h.Value
+}
+
+// compileHooks parses every step's template up front; validateHooks has
+// already rejected the ones that don't parse.
+func compileHooks(hooks map[string][]HookConfig) map[string][]HookConfig {
+	if hooks == nil {
+		return nil
+	}
+	compiled := make(map[string][]HookConfig, len(hooks))
+	for event, steps := range hooks {
+		compiled[event] = make([]HookConfig, len(steps))
+		for i, step := range steps {
+			step.tmpl, _ = parseHookTemplate(step.text())
+			compiled[event][i] = step
+		}
+	}
+	return compiled
+}
+
+// HookRejection is returned when a reject step renders a message.
+type HookRejection struct {
+	Message string
+}
+
+func (e *HookRejection) Error() string {
+	return e.Message
+}
+
+var hookFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+}
+
+func parseHookTemplate(text string) (*template.Template, error) {
+	return template.New("hook").Funcs(hookFuncs).Parse(text)
+}
+
+func validateHooks(name string, model ModelConfig) error {
+	for event, steps := range model.Hooks {
+		switch event {
+		case HookBeforeCreate, HookBeforeUpdate, HookBeforeDelete:
+		default:
+			return fmt.Errorf("model %s has invalid hook '%s' (expected before_create, before_update or before_delete)", name, event)
+		}
+
+		for i, step := range steps {
+			if (step.Set == "") == (step.Reject == "") {
+				return fmt.Errorf("step %d of %s hook on model %s must set either 'set' or 'reject'", i+1, event, name)
+			}
+			if step.Set != "" {
+				if event == HookBeforeDelete {
+					return fmt.Errorf("step %d of %s hook on model %s cannot set fields", i+1, event, name)
+				}
+				if _, ok := model.Fields[step.Set]; !ok {
+					return fmt.Errorf("step %d of %s hook on model %s sets unknown field %s", i+1, event, name, step.Set)
+				}
+			}
+			if _, err := parseHookTemplate(step.Value + step.Reject); err != nil {
+				return fmt.Errorf("step %d of %s hook on model %s: %w", i+1, event, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RunHooks runs the model's steps for event in order. Set steps write into
+// data; templates see current (the stored record, if any) overlaid with
+// data, so an update can refer to fields it doesn't change.
+func (m *Model) RunHooks(event string, data, current map[string]any) error {
+	for _, step := range m.Hooks[event] {
+		view := make(map[string]any, len(m.Fields))
+		for _, field := range m.Fields {
+			view[field.Name] = ""
+		}
+		for k, v := range current {
+			if v != nil {
+				view[k] = v
+			}
+		}
+		for k, v := range data {
+			if v != nil {
+				view[k] = v
+			}
+		}
+
+		tmpl := step.tmpl
+		if tmpl == nil {
+			var err error
+			if tmpl, err = parseHookTemplate(step.text()); err != nil {
+				return err
+			}
+		}
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, view); err != nil {
+			return fmt.Errorf("%s hook: %w", event, err)
+		}
+
+		if step.Reject != "" {
+			if message := strings.TrimSpace(buf.String()); message != "" {
+				return &HookRejection{Message: message}
+			}
+			continue
+		}
+		value, err := m.hookValue(step.Set, buf.String())
+		if err != nil {
+			return fmt.Errorf("%s hook: %w", event, err)
+		}
+		data[step.Set] = value
+	}
+	return nil
+}
+
+// hookValue converts a rendered template to the type of the field it is
+// assigned to, the way form values are, so validation sees a number or a
+// boolean rather than its spelling. A blank render clears the field.
+func (m *Model) hookValue(name, rendered string) (any, error) {
+	var field *Field
+	for i := range m.Fields {
+		if m.Fields[i].Name == name {
+			field = &m.Fields[i]
+			break
+		}
+	}
+	if field == nil {
+		return rendered, nil
+	}
+
+	raw := strings.TrimSpace(rendered)
+	switch field.Type {
+	case FieldTypeNumber:
+		if raw == "" {
+			return nil, nil
+		}
+		if !field.Decimal {
+			if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%s must be a number, got '%s'", name, raw)
+		}
+		return f, nil
+	case FieldTypeBoolean:
+		if raw == "" {
+			return nil, nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got '%s'", name, raw)
+		}
+		return b, nil
+	}
+	return rendered, nil
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IndexConfig declares an expression index on a key inside a json or
+// object field, e.g. {json_field: settings, path: "$.plan"}.
+type IndexConfig struct {
+	JSONField string `yaml:"json_field"`
+	Path      string `yaml:"path"`
+}
+
+// Paths are inlined into SQL, since SQLite only uses an expression index
+// when the query spells the expression the same way, so only plain
+// dotted keys are accepted.
+var jsonPathPattern = regexp.MustCompile(`^\$(\.[A-Za-z_][A-Za-z0-9_]*)+$`)
+
+// JSONPath splits a filter field such as "settings.plan" into the column
+// and the JSON path ("$.plan") it addresses.
+func JSONPath(field string) (column, path string, ok bool) {
+	column, rest, found := strings.Cut(field, ".")
+	if !found || column == "" {
+		return "", "", false
+	}
+	path = "$." + rest
+	if !jsonPathPattern.MatchString(path) {
+		return "", "", false
+	}
+	return column, path, true
+}
+
+// HasJSONPath reports whether field addresses a key inside one of the
+// model's json or object fields.
+func (m *Model) HasJSONPath(field string) bool {
+	column, _, ok := JSONPath(field)
+	if !ok {
+		return false
+	}
+	for _, f := range m.Fields {
+		if f.Name == column {
+			return f.Type == FieldTypeJSON || f.Type == FieldTypeObject
+		}
+	}
+	return false
+}
+
+func validateIndexes(name string, model ModelConfig) error {
+	for i, index := range model.Indexes {
+		field, ok := model.Fields[index.JSONField]
+		if !ok {
+			return fmt.Errorf("index %d of model %s references unknown field '%s'", i+1, name, index.JSONField)
+		}
+		if t := FieldType(field.Type); t != FieldTypeJSON && t != FieldTypeObject {
+			return fmt.Errorf("index %d of model %s must use a json or object field, got %s", i+1, name, field.Type)
+		}
+		if !jsonPathPattern.MatchString(index.Path) {
+			return fmt.Errorf("index %d of model %s has invalid path '%s' (expected e.g. $.plan)", i+1, name, index.Path)
+		}
+	}
+	return nil
+}
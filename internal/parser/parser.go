@@ -2,8 +2,16 @@ package parser
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,6 +27,10 @@ func ParseConfig(filename string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
+	// Timestamp fields are added before validation so the rest of the
+	// config, e.g. api versions or webhook fields, can refer to them.
+	addTimestamps(config)
+
 	if err := validateConfig(config); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
@@ -28,9 +40,19 @@ func ParseConfig(filename string) (*Config, error) {
 	return config, nil
 }
 
+// Settings left out of the YAML, or under an empty section such as a bare
+// `app:`, keep their defaults. An explicit empty value, e.g. `name: ""`, is
+// an error rather than a silent fallback, and says which default removing
+// the key would give.
 func validateConfig(config *Config) error {
+	defaults := DefaultConfig()
+
 	if config.App.Name == "" {
-		return fmt.Errorf("app.name is required")
+		return fmt.Errorf("app.name cannot be empty; omit it to use the default %q", defaults.App.Name)
+	}
+
+	if config.Database.Type == "" {
+		return fmt.Errorf("database.type cannot be empty; omit it to use the default %q", defaults.Database.Type)
 	}
 
 	if config.Database.Type != "sqlite" && config.Database.Type != "postgresql" && config.Database.Type != "mysql" {
@@ -38,19 +60,235 @@ func validateConfig(config *Config) error {
 	}
 
 	if config.Database.Type == "sqlite" && config.Database.Path == "" {
-		return fmt.Errorf("database.path is required for SQLite")
+		return fmt.Errorf("database.path cannot be empty for SQLite; omit it to use the default %q", defaults.Database.Path)
+	}
+
+	if config.Database.MaxInValues < 0 {
+		return fmt.Errorf("database.max_in_values cannot be negative")
+	}
+
+	if config.Database.HealthCheck != "" {
+		if d, err := time.ParseDuration(config.Database.HealthCheck); err != nil || d <= 0 {
+			return fmt.Errorf("invalid database.health_check '%s'", config.Database.HealthCheck)
+		}
 	}
 
 	if config.Database.Type != "sqlite" && config.Database.Connection == "" {
 		return fmt.Errorf("database.connection is required for %s", config.Database.Type)
 	}
 
+	if config.Server.BaseURL != "" {
+		u, err := url.Parse(config.Server.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("server.base_url must be an absolute http(s) URL: %s", config.Server.BaseURL)
+		}
+	}
+
+	if config.Server.RedisURL != "" {
+		u, err := url.Parse(config.Server.RedisURL)
+		if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+			return fmt.Errorf("server.redis_url must be a redis:// or rediss:// URL: %s", config.Server.RedisURL)
+		}
+	}
+
+	switch config.Server.Auth.APIUnauthorized {
+	case "", APIUnauthorizedJSON, APIUnauthorizedPage:
+	default:
+		return fmt.Errorf("invalid server.auth.api_unauthorized '%s' (expected json or 401page)", config.Server.Auth.APIUnauthorized)
+	}
+
+	if config.Server.Auth.RequireSecret && config.Server.Auth.Type != "none" && config.Server.Auth.Secret == "" {
+		return fmt.Errorf("server.auth.secret is required when server.auth.require_secret is set")
+	}
+
+	if captcha := config.Server.Auth.Captcha; captcha != nil {
+		if captcha.After < 0 {
+			return fmt.Errorf("server.auth.captcha.after cannot be negative")
+		}
+		if captcha.Window != "" {
+			if d, err := time.ParseDuration(captcha.Window); err != nil || d <= 0 {
+				return fmt.Errorf("invalid server.auth.captcha.window '%s'", captcha.Window)
+			}
+		}
+		u, err := url.Parse(captcha.VerifyURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("server.auth.captcha.verify_url must be an absolute http(s) URL: %s", captcha.VerifyURL)
+		}
+		if _, ok := captcha.Widget(); !ok {
+			return fmt.Errorf("server.auth.captcha.verify_url must be a reCAPTCHA, hCaptcha or Turnstile siteverify endpoint so the login page can show the widget: %s", captcha.VerifyURL)
+		}
+		if captcha.SiteKey == "" {
+			return fmt.Errorf("server.auth.captcha.site_key is required so the login page can show the widget")
+		}
+	}
+
+	if verification := config.Server.Auth.EmailVerification; verification != nil {
+		if verification.SMTPAddr != "" {
+			if _, _, err := net.SplitHostPort(verification.SMTPAddr); err != nil {
+				return fmt.Errorf("server.auth.email_verification.smtp_addr must be host:port: %s", verification.SMTPAddr)
+			}
+			if verification.From == "" {
+				return fmt.Errorf("server.auth.email_verification.from is required when smtp_addr is set")
+			}
+		}
+		if verification.Expires != "" {
+			if d, err := time.ParseDuration(verification.Expires); err != nil || d <= 0 {
+				return fmt.Errorf("invalid server.auth.email_verification.expires '%s'", verification.Expires)
+			}
+		}
+		if verification.VerifyURL != "" {
+			u, err := url.Parse(verification.VerifyURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("server.auth.email_verification.verify_url must be an absolute http(s) URL: %s", verification.VerifyURL)
+			}
+		}
+	}
+
+	if config.Server.Audit.Retention != "" {
+		if d, err := time.ParseDuration(config.Server.Audit.Retention); err != nil || d <= 0 {
+			return fmt.Errorf("invalid server.audit.retention '%s'", config.Server.Audit.Retention)
+		}
+	}
+
+	if config.Server.Pagination.DefaultPageSize < 0 || config.Server.Pagination.MaxPageSize < 0 {
+		return fmt.Errorf("server.pagination sizes cannot be negative")
+	}
+	if defaultSize, maxSize := config.Server.Pagination.Limits(); defaultSize > maxSize {
+		return fmt.Errorf("server.pagination.default_page_size (%d) exceeds max_page_size (%d)", defaultSize, maxSize)
+	}
+
+	if config.Server.MaxIncludeDepth < 0 {
+		return fmt.Errorf("server.max_include_depth cannot be negative")
+	}
+
+	if config.Server.Compression.MinSize < 0 {
+		return fmt.Errorf("server.compression.min_size cannot be negative")
+	}
+
+	if config.Server.BulkMaxItems < 0 {
+		return fmt.Errorf("server.bulk_max_items cannot be negative")
+	}
+
+	if config.UI.Dashboard.Recent < 0 {
+		return fmt.Errorf("ui.dashboard.recent cannot be negative")
+	}
+
+	for i, widget := range config.UI.Dashboard.Widgets {
+		if err := validateWidget(config, widget); err != nil {
+			return fmt.Errorf("ui.dashboard.widgets[%d]: %w", i, err)
+		}
+	}
+
 	for modelName, model := range config.Models {
 		if err := validateModel(modelName, model); err != nil {
 			return err
 		}
 	}
 
+	for modelName := range config.Seed {
+		if _, ok := config.Models[modelName]; !ok {
+			return fmt.Errorf("seed references unknown model %s", modelName)
+		}
+	}
+
+	return validateRoutes(config.Models)
+}
+
+// reservedRoutes are the top-level paths the server registers for itself;
+// a model routed there would be shadowed or shadow them.
+var reservedRoutes = map[string]bool{
+	"api":    true,
+	"login":  true,
+	"logout": true,
+	"readyz": true,
+}
+
+// validateRoutes rejects models that would be served from the same path.
+// Routes are the lowercased model name, so User and user collide, and an
+// API version such as v1 claims /api/v1/ for itself.
+func validateRoutes(models map[string]ModelConfig) error {
+	names := make([]string, 0, len(models))
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	routes := make(map[string]string, len(names))
+	for _, name := range names {
+		route := strings.ToLower(name)
+		if reservedRoutes[route] {
+			return fmt.Errorf("model %s maps to the reserved route /%s; rename it", name, route)
+		}
+		if other, ok := routes[route]; ok {
+			return fmt.Errorf("models %s and %s both map to the route /%s; rename one of them", other, name, route)
+		}
+		routes[route] = name
+	}
+
+	for _, name := range names {
+		if models[name].API == nil {
+			continue
+		}
+		for version := range models[name].API.Versions {
+			if other, ok := routes[version]; ok {
+				return fmt.Errorf("api version %s of model %s collides with the route of model %s", version, name, other)
+			}
+		}
+	}
+	return nil
+}
+
+func validateWidget(config *Config, widget UIWidgetConfig) error {
+	if widget.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+
+	model, ok := config.Models[widget.Model]
+	if !ok {
+		return fmt.Errorf("unknown model '%s'", widget.Model)
+	}
+
+	switch widget.Aggregate {
+	case "count":
+		if widget.Field != "" {
+			if _, ok := model.Fields[widget.Field]; !ok {
+				return fmt.Errorf("unknown field '%s' on %s", widget.Field, widget.Model)
+			}
+		}
+	case "sum", "avg":
+		field, ok := model.Fields[widget.Field]
+		if !ok {
+			return fmt.Errorf("%s requires a field of %s", widget.Aggregate, widget.Model)
+		}
+		if FieldType(field.Type) != FieldTypeNumber {
+			return fmt.Errorf("%s field %s.%s must be a number", widget.Aggregate, widget.Model, widget.Field)
+		}
+	default:
+		return fmt.Errorf("invalid aggregate '%s' (expected count, sum or avg)", widget.Aggregate)
+	}
+
+	for fieldName := range widget.Filters {
+		if _, ok := model.Fields[fieldName]; !ok {
+			return fmt.Errorf("unknown filter field '%s' on %s", fieldName, widget.Model)
+		}
+	}
+
+	return nil
+}
+
+func validateOneToOneRelations(name string, model ModelConfig) error {
+	targets := make(map[string]string)
+	for fieldName, field := range model.Fields {
+		if FieldType(field.Type) != FieldTypeRelation || RelationKind(field.RelationType) != RelationOneToOne {
+			continue
+		}
+
+		if other, exists := targets[field.To]; exists {
+			return fmt.Errorf("model %s has multiple one_to_one relations to %s (%s, %s)", name, field.To, other, fieldName)
+		}
+		targets[field.To] = fieldName
+	}
+
 	return nil
 }
 
@@ -59,7 +297,13 @@ func validateModel(name string, model ModelConfig) error {
 		return fmt.Errorf("model %s has no fields", name)
 	}
 
-	hasPrimary := false
+	hasPrimary := len(model.Primary) > 0
+	if hasPrimary {
+		if err := validatePrimaryKey(name, model); err != nil {
+			return err
+		}
+	}
+
 	for fieldName, field := range model.Fields {
 		if field.Primary {
 			if hasPrimary {
@@ -71,12 +315,213 @@ func validateModel(name string, model ModelConfig) error {
 		if err := validateField(name, fieldName, field); err != nil {
 			return err
 		}
+
+		if field.UniqueWithin != "" {
+			if err := validateUniqueWithin(name, fieldName, field, model); err != nil {
+				return err
+			}
+		}
 	}
 
 	if !hasPrimary {
 		return fmt.Errorf("model %s has no primary key", name)
 	}
 
+	if model.UI != nil && model.UI.List != nil {
+		for _, fieldName := range model.UI.List.Filterable {
+			field, ok := model.Fields[fieldName]
+			if !ok {
+				return fmt.Errorf("model %s lists unknown filterable field %s", name, fieldName)
+			}
+			switch FieldType(field.Type) {
+			case FieldTypeEnum, FieldTypeBoolean, FieldTypeNumber, FieldTypeDate, FieldTypeDatetime:
+			default:
+				return fmt.Errorf("filterable field %s.%s must be an enum, boolean, number, date or datetime", name, fieldName)
+			}
+		}
+	}
+
+	if model.UI != nil && model.UI.Form != nil {
+		for _, section := range model.UI.Form.Sections {
+			if section.Title == "" {
+				return fmt.Errorf("model %s has a form section without a title", name)
+			}
+			for _, fieldName := range section.Fields {
+				if _, ok := model.Fields[fieldName]; !ok {
+					return fmt.Errorf("form section '%s' of model %s references unknown field %s", section.Title, name, fieldName)
+				}
+			}
+		}
+
+		switch model.UI.Form.OnSave {
+		case "", "list", "view", "new", "stay":
+		default:
+			return fmt.Errorf("invalid ui.form.on_save '%s' for model %s (expected list, view, new or stay)", model.UI.Form.OnSave, name)
+		}
+	}
+
+	if model.DisplayName != "" {
+		if _, err := template.New(name).Parse(model.DisplayName); err != nil {
+			return fmt.Errorf("model %s has invalid display_name: %w", name, err)
+		}
+	}
+
+	if model.RestoreWindow != "" {
+		if !model.SoftDelete {
+			return fmt.Errorf("model %s sets restore_window but not soft_delete", name)
+		}
+		if d, err := time.ParseDuration(model.RestoreWindow); err != nil || d < 0 {
+			return fmt.Errorf("model %s has invalid restore_window '%s'", name, model.RestoreWindow)
+		}
+	}
+
+	if model.API != nil {
+		if model.API.RateLimit != "" {
+			if _, err := ParseRateLimit(model.API.RateLimit); err != nil {
+				return fmt.Errorf("model %s has invalid api.rate_limit: %w", name, err)
+			}
+		}
+		if model.API.ExportRateLimit != "" {
+			if _, err := ParseRateLimit(model.API.ExportRateLimit); err != nil {
+				return fmt.Errorf("model %s has invalid api.export_rate_limit: %w", name, err)
+			}
+		}
+		if model.API.DailyQuota < 0 {
+			return fmt.Errorf("model %s has negative api.daily_quota", name)
+		}
+		if model.API.CountLimit < 0 {
+			return fmt.Errorf("model %s has negative api.count_limit", name)
+		}
+		for version, fields := range model.API.Versions {
+			if !versionPattern.MatchString(version) {
+				return fmt.Errorf("model %s has invalid api version '%s' (expected e.g. v1)", name, version)
+			}
+			for _, fieldName := range fields {
+				if _, ok := model.Fields[fieldName]; !ok {
+					return fmt.Errorf("api version %s of model %s lists unknown field %s", version, name, fieldName)
+				}
+			}
+		}
+	}
+
+	for _, group := range model.RequireOneOf {
+		if len(group) < 2 {
+			return fmt.Errorf("model %s has a require_one_of group with fewer than two fields", name)
+		}
+		for _, fieldName := range group {
+			if _, ok := model.Fields[fieldName]; !ok {
+				return fmt.Errorf("require_one_of of model %s references unknown field %s", name, fieldName)
+			}
+		}
+	}
+
+	if err := validateHooks(name, model); err != nil {
+		return err
+	}
+
+	if err := validateIndexes(name, model); err != nil {
+		return err
+	}
+
+	if err := validateDefaultFilter(name, model); err != nil {
+		return err
+	}
+
+	if model.OnChange != "" {
+		u, err := url.Parse(model.OnChange)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("on_change of model %s must be an absolute http(s) url", name)
+		}
+	}
+
+	for i, webhook := range model.Webhooks {
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook %d of model %s must have an absolute http(s) url", i+1, name)
+		}
+		for _, event := range webhook.Events {
+			switch event {
+			case WebhookCreate, WebhookUpdate, WebhookDelete, WebhookRestore:
+			default:
+				return fmt.Errorf("webhook %d of model %s has invalid event '%s' (expected create, update, delete or restore)", i+1, name, event)
+			}
+		}
+		for _, fieldName := range webhook.Fields {
+			field, ok := model.Fields[fieldName]
+			if !ok && fieldName != "id" {
+				return fmt.Errorf("webhook %d of model %s references unknown field %s", i+1, name, fieldName)
+			}
+			if field.Type == string(FieldTypePassword) {
+				return fmt.Errorf("webhook %d of model %s cannot send password field %s", i+1, name, fieldName)
+			}
+		}
+	}
+
+	return validateOneToOneRelations(name, model)
+}
+
+// validateDefault checks that a default could be stored in its field, so
+// a bad value fails at load time rather than on the first insert.
+func validateDefault(modelName, fieldName string, field FieldConfig) error {
+	if field.Default == nil {
+		return nil
+	}
+
+	fieldType := FieldType(field.Type)
+	switch {
+	case fieldType == FieldTypeEnum:
+		value, ok := field.Default.(string)
+		if !ok || !containsString(field.Options, value) {
+			return fmt.Errorf("default '%v' for %s.%s is not one of its options", field.Default, modelName, fieldName)
+		}
+	case fieldType == FieldTypeNumber:
+		switch field.Default.(type) {
+		case int, int64, float64:
+		default:
+			return fmt.Errorf("default '%v' for %s.%s must be a number", field.Default, modelName, fieldName)
+		}
+	case fieldType == FieldTypeBoolean:
+		if _, ok := field.Default.(bool); !ok {
+			return fmt.Errorf("default '%v' for %s.%s must be true or false", field.Default, modelName, fieldName)
+		}
+	case fieldType.IsString(), fieldType == FieldTypeDatetime, fieldType == FieldTypeDate, fieldType == FieldTypeTime:
+		value, ok := field.Default.(string)
+		if !ok {
+			return fmt.Errorf("default '%v' for %s.%s must be a string", field.Default, modelName, fieldName)
+		}
+		if fieldType.IsString() && field.Max > 0 && len(value) > field.Max {
+			return fmt.Errorf("default for %s.%s is longer than its max of %d", modelName, fieldName, field.Max)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validateUniqueWithin checks a field that is unique only among records
+// sharing the value of another field, e.g. a slug per author_id.
+func validateUniqueWithin(modelName, fieldName string, field FieldConfig, model ModelConfig) error {
+	if field.Primary || field.Unique {
+		return fmt.Errorf("field %s.%s sets unique_within but is already unique", modelName, fieldName)
+	}
+	if field.UniqueWithin == fieldName {
+		return fmt.Errorf("field %s.%s cannot be unique_within itself", modelName, fieldName)
+	}
+	scope, ok := model.Fields[field.UniqueWithin]
+	if !ok {
+		return fmt.Errorf("field %s.%s is unique_within unknown field %s", modelName, fieldName, field.UniqueWithin)
+	}
+	switch FieldType(scope.Type) {
+	case FieldTypeJSON, FieldTypeObject, FieldTypeArray, FieldTypePassword:
+		return fmt.Errorf("field %s.%s cannot be unique_within %s field %s", modelName, fieldName, scope.Type, field.UniqueWithin)
+	}
 	return nil
 }
 
@@ -94,22 +539,113 @@ func validateField(modelName, fieldName string, field FieldConfig) error {
 		return fmt.Errorf("relation field %s.%s must specify 'to' model", modelName, fieldName)
 	}
 
+	if field.RelationType != "" {
+		if fieldType != FieldTypeRelation {
+			return fmt.Errorf("field %s.%s sets relation_type but is not a relation", modelName, fieldName)
+		}
+		switch RelationKind(field.RelationType) {
+		case RelationManyToOne, RelationOneToOne:
+		default:
+			return fmt.Errorf("invalid relation_type '%s' for %s.%s", field.RelationType, modelName, fieldName)
+		}
+	}
+
+	if field.KeyType != "" {
+		if fieldType != FieldTypeID {
+			return fmt.Errorf("field %s.%s sets key_type but is not an id", modelName, fieldName)
+		}
+		if !KeyType(field.KeyType).IsValid() {
+			return fmt.Errorf("invalid key_type '%s' for %s.%s", field.KeyType, modelName, fieldName)
+		}
+	}
+
 	if fieldType == FieldTypeArray && field.Items == "" {
 		return fmt.Errorf("array field %s.%s must specify 'items' type", modelName, fieldName)
 	}
 
+	if fieldType == FieldTypeObject {
+		if len(field.Properties) == 0 {
+			return fmt.Errorf("object field %s.%s must define properties", modelName, fieldName)
+		}
+		for name, property := range field.Properties {
+			switch FieldType(property.Type) {
+			case FieldTypeID, FieldTypeRelation:
+				return fmt.Errorf("object field %s.%s cannot contain %s property %s", modelName, fieldName, property.Type, name)
+			}
+			if property.Primary || property.Unique || property.Index {
+				return fmt.Errorf("object property %s.%s.%s cannot be primary, unique or indexed", modelName, fieldName, name)
+			}
+			if err := validateField(modelName, fieldName+"."+name, property); err != nil {
+				return err
+			}
+		}
+	} else if len(field.Properties) > 0 {
+		return fmt.Errorf("field %s.%s sets properties but is not an object", modelName, fieldName)
+	}
+
+	if err := validateDefault(modelName, fieldName, field); err != nil {
+		return err
+	}
+
+	if strings.Trim(field.Mask, "*#") != "" {
+		return fmt.Errorf("invalid mask '%s' for %s.%s (use * to hide and # to show characters)", field.Mask, modelName, fieldName)
+	}
+
+	switch field.Normalize {
+	case "":
+	case NormalizeLower, NormalizeUpper, NormalizeTrim:
+		if !fieldType.IsString() {
+			return fmt.Errorf("field %s.%s sets normalize but is not a text field", modelName, fieldName)
+		}
+	default:
+		return fmt.Errorf("invalid normalize '%s' for %s.%s (use lower, upper or trim)", field.Normalize, modelName, fieldName)
+	}
+
+	for _, name := range field.Transform {
+		if _, ok := LookupTransform(name); !ok {
+			return fmt.Errorf("unknown transform '%s' for %s.%s", name, modelName, fieldName)
+		}
+	}
+
+	if len(field.ReadRoles) > 0 && field.Primary {
+		return fmt.Errorf("primary key %s.%s cannot set read_roles", modelName, fieldName)
+	}
+
+	if field.Decimal || field.Precision != 0 || field.Scale != 0 {
+		if fieldType != FieldTypeNumber {
+			return fmt.Errorf("field %s.%s sets decimal options but is not a number", modelName, fieldName)
+		}
+		if field.Precision < 0 || field.Scale < 0 {
+			return fmt.Errorf("field %s.%s has negative precision or scale", modelName, fieldName)
+		}
+		if field.Precision > 0 && field.Scale > field.Precision {
+			return fmt.Errorf("field %s.%s has scale > precision", modelName, fieldName)
+		}
+	}
+
 	if field.Min > field.Max && field.Max > 0 {
 		return fmt.Errorf("field %s.%s has min > max", modelName, fieldName)
 	}
 
+	if field.MaxChangePct != 0 {
+		if fieldType != FieldTypeNumber {
+			return fmt.Errorf("field %s.%s sets max_change_pct but is not a number", modelName, fieldName)
+		}
+		if field.MaxChangePct < 0 {
+			return fmt.Errorf("field %s.%s has negative max_change_pct", modelName, fieldName)
+		}
+	}
+
 	return nil
 }
 
 func processConfig(config *Config) *Config {
+	addTimestamps(config)
+
 	for modelName, model := range config.Models {
 		processedModel := model
 
-		for fieldName, field := range model.Fields {
+		for fieldName, field := range processedModel.Fields {
 			processedField := field
 
 			if field.Type == "id" && !field.Primary {
@@ -127,6 +663,27 @@ func processConfig(config *Config) *Config {
 				processedField.Unique = true
 			}
 
+			if slices.Contains(model.Primary, fieldName) {
+				processedField.Required = true
+			}
+
+			if field.Precision > 0 || field.Scale > 0 {
+				processedField.Decimal = true
+			}
+
+			if RelationKind(field.RelationType) == RelationOneToOne {
+				processedField.Unique = true
+			}
+
+			if config.Conventions.EmailUnique && field.Type == "email" && !field.UniqueSet && field.UniqueWithin == "" {
+				processedField.Unique = true
+			}
+			// Only the convention compares emails case-insensitively, so
+			// existing unique: true fields keep their behaviour without it.
+			if config.Conventions.EmailUnique && field.Type == "email" && processedField.Unique {
+				processedField.IgnoreCase = true
+			}
+
 			processedModel.Fields[fieldName] = processedField
 		}
 
@@ -149,15 +706,47 @@ func processConfig(config *Config) *Config {
 	return config
 }
 
+// addTimestampField adds a field the model did not declare itself, keeping
+// declared definitions untouched.
+// addTimestamps adds created_at and updated_at to models with timestamps
+// on. Declared fields are kept, so running it twice changes nothing.
+func addTimestamps(config *Config) {
+	for modelName, model := range config.Models {
+		timestamps := config.Conventions.Timestamps
+		if model.Timestamps != nil {
+			timestamps = *model.Timestamps
+		}
+		if !timestamps || len(model.Fields) == 0 {
+			continue
+		}
+		addTimestampField(&model, "created_at", FieldConfig{Type: "datetime", AutoNowAdd: true})
+		addTimestampField(&model, "updated_at", FieldConfig{Type: "datetime", AutoNow: true})
+		config.Models[modelName] = model
+	}
+}
+
+func addTimestampField(model *ModelConfig, name string, field FieldConfig) {
+	if _, exists := model.Fields[name]; exists {
+		return
+	}
+	model.Fields[name] = field
+	if len(model.FieldOrder) > 0 {
+		model.FieldOrder = append(model.FieldOrder, name)
+	}
+}
+
 func generateDefaultUI(model ModelConfig) *UIModelConfig {
 	var columns []string
 	var sortable []string
 	var searchable []string
 	var formFields []string
 
-	for fieldName, field := range model.Fields {
+	for _, fieldName := range model.FieldNames() {
+		field := model.Fields[fieldName]
 		if field.Type != "password" && !field.Primary {
-			columns = append(columns, fieldName)
+			if !hidden(field.List) {
+				columns = append(columns, fieldName)
+			}
 
 			if field.Type == "text" || field.Type == "email" || field.Type == "number" {
 				searchable = append(searchable, fieldName)
@@ -168,7 +757,7 @@ func generateDefaultUI(model ModelConfig) *UIModelConfig {
 			}
 		}
 
-		if !field.Primary && !field.AutoNow && !field.AutoNowAdd {
+		if !field.Primary && !field.AutoNow && !field.AutoNowAdd && !hidden(field.Form) {
 			formFields = append(formFields, fieldName)
 		}
 	}
@@ -196,40 +785,65 @@ func LoadConfig(config *Config) (*Schema, error) {
 
 	for modelName, modelConfig := range config.Models {
 		model := &Model{
-			Name:   modelName,
-			Fields: []Field{},
+			Name:         modelName,
+			Fields:       []Field{},
+			Description:  modelConfig.Description,
+			Tag:          modelConfig.Tag,
+			DisplayName:  modelConfig.DisplayName,
+			Webhooks:     modelConfig.Webhooks,
+			OnChange:     modelConfig.OnChange,
+			Deprecated:   modelConfig.Deprecated,
+			FilterBypass: modelConfig.FilterBypass,
+			RequireOneOf: modelConfig.RequireOneOf,
+			Hooks:        compileHooks(modelConfig.Hooks),
+			Indexes:      modelConfig.Indexes,
+			PrimaryKey:   modelConfig.Primary,
 		}
 
-		for fieldName, fieldConfig := range modelConfig.Fields {
-			field := Field{
-				Name:       fieldName,
-				Type:       FieldType(fieldConfig.Type),
-				Primary:    fieldConfig.Primary,
-				Required:   fieldConfig.Required,
-				Unique:     fieldConfig.Unique,
-				Default:    fieldConfig.Default,
-				AutoNow:    fieldConfig.AutoNow,
-				AutoNowAdd: fieldConfig.AutoNowAdd,
-				Nullable:   fieldConfig.Nullable,
-				Index:      fieldConfig.Index,
-				RelatedTo:  fieldConfig.To,
-				OnDelete:   fieldConfig.OnDelete,
-				ArrayType:  fieldConfig.Items,
-			}
+		for _, fieldName := range modelConfig.FieldNames() {
+			model.Fields = append(model.Fields, buildField(fieldName, modelConfig.Fields[fieldName]))
+		}
 
-			if fieldConfig.Min > 0 {
-				min := fieldConfig.Min
-				field.Min = &min
+		for _, filter := range modelConfig.DefaultFilter {
+			model.DefaultFilter = append(model.DefaultFilter, filter.filter())
+		}
+
+		if modelConfig.SoftDelete {
+			model.SoftDelete = true
+			if modelConfig.RestoreWindow != "" {
+				window, err := time.ParseDuration(modelConfig.RestoreWindow)
+				if err != nil {
+					return nil, fmt.Errorf("invalid restore_window for %s: %w", modelName, err)
+				}
+				model.RestoreWindow = window
 			}
-			if fieldConfig.Max > 0 {
-				max := fieldConfig.Max
-				field.Max = &max
+			if _, exists := modelConfig.Fields[SoftDeleteField]; !exists {
+				model.Fields = append(model.Fields, Field{
+					Name:     SoftDeleteField,
+					Type:     FieldTypeDatetime,
+					Nullable: true,
+				})
 			}
+		}
 
-			field.Pattern = fieldConfig.Pattern
-			field.Options = fieldConfig.Options
-
-			model.Fields = append(model.Fields, field)
+		if modelConfig.API != nil {
+			if modelConfig.API.RateLimit != "" {
+				limit, err := ParseRateLimit(modelConfig.API.RateLimit)
+				if err != nil {
+					return nil, fmt.Errorf("invalid api.rate_limit for %s: %w", modelName, err)
+				}
+				model.RateLimit = limit
+			}
+			if modelConfig.API.ExportRateLimit != "" {
+				limit, err := ParseRateLimit(modelConfig.API.ExportRateLimit)
+				if err != nil {
+					return nil, fmt.Errorf("invalid api.export_rate_limit for %s: %w", modelName, err)
+				}
+				model.ExportRateLimit = limit
+			}
+			model.DailyQuota = modelConfig.API.DailyQuota
+			model.CountLimit = modelConfig.API.CountLimit
+			model.Versions = modelConfig.API.Versions
 		}
 
 		if modelConfig.Permissions != nil {
@@ -244,22 +858,202 @@ func LoadConfig(config *Config) (*Schema, error) {
 		if modelConfig.UI != nil {
 			model.UI = UIModel{
 				List: UIList{
-					Columns:    modelConfig.UI.List.Columns,
-					Sortable:   modelConfig.UI.List.Sortable,
-					Searchable: modelConfig.UI.List.Searchable,
+					Columns:       modelConfig.UI.List.Columns,
+					Sortable:      modelConfig.UI.List.Sortable,
+					Searchable:    modelConfig.UI.List.Searchable,
+					SearchWeights: modelConfig.UI.List.SearchWeights,
+					Filterable:    modelConfig.UI.List.Filterable,
 				},
 				Form: UIForm{
-					Fields: modelConfig.UI.Form.Fields,
+					Fields:         modelConfig.UI.Form.Fields,
+					OnSave:         modelConfig.UI.Form.OnSave,
+					SuccessMessage: modelConfig.UI.Form.SuccessMessage,
 				},
 			}
+			for _, section := range modelConfig.UI.Form.Sections {
+				model.UI.Form.Sections = append(model.UI.Form.Sections, UIFormSection{
+					Title:  section.Title,
+					Fields: section.Fields,
+				})
+			}
 		}
 
 		schema.Models[modelName] = model
 	}
 
+	for _, model := range schema.Models {
+		for i, field := range model.Fields {
+			if field.Type != FieldTypeRelation {
+				continue
+			}
+			related, ok := schema.GetModelByRoute(field.RelatedTo)
+			if !ok {
+				continue
+			}
+			if field.DisplayField == "" {
+				model.Fields[i].DisplayField = related.DefaultDisplayField()
+			}
+			model.Fields[i].DisplayName = related.DisplayName
+			model.Fields[i].KeyType = related.KeyType()
+		}
+	}
+
 	return schema, nil
 }
 
+func ParseRateLimit(value string) (RateLimit, error) {
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("expected <requests>/<period>, got '%s'", value)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || requests <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid request count '%s'", count)
+	}
+
+	period = strings.TrimSpace(period)
+	var window time.Duration
+	switch period {
+	case "s", "second":
+		window = time.Second
+	case "m", "minute":
+		window = time.Minute
+	case "h", "hour":
+		window = time.Hour
+	case "d", "day":
+		window = 24 * time.Hour
+	default:
+		window, err = time.ParseDuration(period)
+		if err != nil || window <= 0 {
+			return RateLimit{}, fmt.Errorf("invalid period '%s'", period)
+		}
+	}
+
+	return RateLimit{Requests: requests, Window: window}, nil
+}
+
+// hidden reports whether a field's list/detail/form flag was explicitly
+// set to false; unset flags leave the surface's defaults alone.
+func hidden(flag *bool) bool {
+	return flag != nil && !*flag
+}
+
+func buildField(fieldName string, fieldConfig FieldConfig) Field {
+	field := Field{
+		Name:          fieldName,
+		Type:          FieldType(fieldConfig.Type),
+		Primary:       fieldConfig.Primary,
+		Required:      fieldConfig.Required,
+		Unique:        fieldConfig.Unique,
+		Default:       fieldConfig.Default,
+		AutoNow:       fieldConfig.AutoNow,
+		AutoNowAdd:    fieldConfig.AutoNowAdd,
+		Decimal:       fieldConfig.Decimal || fieldConfig.Precision > 0 || fieldConfig.Scale > 0,
+		Precision:     fieldConfig.Precision,
+		Scale:         fieldConfig.Scale,
+		MaxChangePct:  fieldConfig.MaxChangePct,
+		Nullable:      fieldConfig.Nullable,
+		AllowEmpty:    fieldConfig.AllowEmpty,
+		Index:         fieldConfig.Index,
+		RelatedTo:     fieldConfig.To,
+		OnDelete:      fieldConfig.OnDelete,
+		RelationType:  fieldConfig.RelationType,
+		DisplayField:  fieldConfig.Display,
+		Label:         fieldConfig.Label,
+		Help:          fieldConfig.Help,
+		Placeholder:   fieldConfig.Placeholder,
+		UniqueMessage: fieldConfig.UniqueMessage,
+		UniqueWithin:  fieldConfig.UniqueWithin,
+		IgnoreCase:    fieldConfig.IgnoreCase,
+		Mask:          fieldConfig.Mask,
+		Normalize:     fieldConfig.Normalize,
+		Transform:     fieldConfig.Transform,
+		ReadRoles:     fieldConfig.ReadRoles,
+		KeyType:       KeyType(fieldConfig.KeyType),
+		ArrayType:     fieldConfig.Items,
+		HideInList:    hidden(fieldConfig.List),
+		HideInDetail:  hidden(fieldConfig.Detail),
+		HideInForm:    hidden(fieldConfig.Form),
+		Deprecated:    fieldConfig.Deprecated,
+	}
+
+	if fieldConfig.Min > 0 {
+		min := fieldConfig.Min
+		field.Min = &min
+	}
+	if fieldConfig.Max > 0 {
+		max := fieldConfig.Max
+		field.Max = &max
+	}
+
+	field.Pattern = fieldConfig.Pattern
+	field.Options = fieldConfig.Options
+
+	if len(fieldConfig.Properties) > 0 {
+		names := make([]string, 0, len(fieldConfig.Properties))
+		for name := range fieldConfig.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			field.Properties = append(field.Properties, buildField(name, fieldConfig.Properties[name]))
+		}
+	}
+
+	return field
+}
+
+func (m *Model) PublicRead() bool {
+	return m.Permissions.Read == PermissionPublic
+}
+
+func (m *Model) DefaultDisplayField() string {
+	for _, candidate := range []string{"name", "title", "username", "email"} {
+		for _, field := range m.Fields {
+			if field.Name == candidate {
+				return field.Name
+			}
+		}
+	}
+
+	display := ""
+	for _, field := range m.Fields {
+		if field.Type == FieldTypeText && !field.Primary && (display == "" || field.Name < display) {
+			display = field.Name
+		}
+	}
+	if display != "" {
+		return display
+	}
+
+	return "id"
+}
+
+// RecordTitle renders the display_name template against a record, falling
+// back to "Model #id" when no template is set or a key is missing.
+func (m *Model) RecordTitle(record map[string]any) string {
+	if m.DisplayName != "" {
+		tmpl, err := template.New(m.Name).Option("missingkey=error").Parse(m.DisplayName)
+		if err == nil {
+			var buf strings.Builder
+			if err := tmpl.Execute(&buf, record); err == nil && strings.TrimSpace(buf.String()) != "" {
+				return buf.String()
+			}
+		}
+	}
+	return fmt.Sprintf("%s #%v", m.Name, record["id"])
+}
+
+func (m *Model) KeyType() KeyType {
+	for _, field := range m.Fields {
+		if field.Primary && field.KeyType != "" {
+			return field.KeyType
+		}
+	}
+	return KeyTypeInt
+}
+
 type Schema struct {
 	Models map[string]*Model
 }
@@ -284,6 +1078,27 @@ func (s *Schema) GetField(modelName, fieldName string) (*Field, bool) {
 	return nil, false
 }
 
+func (s *Schema) GetModelByRoute(route string) (*Model, bool) {
+	for name, model := range s.Models {
+		if strings.EqualFold(name, route) {
+			return model, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Schema) ReverseRelations(modelName string) map[string][]Field {
+	relations := make(map[string][]Field)
+	for name, model := range s.Models {
+		for _, field := range model.Fields {
+			if field.Type == FieldTypeRelation && strings.EqualFold(field.RelatedTo, modelName) {
+				relations[name] = append(relations[name], field)
+			}
+		}
+	}
+	return relations
+}
+
 func SaveConfig(config *Config, filename string) error {
 	data, err := yaml.Marshal(config)
 	if err != nil {
@@ -68,6 +68,45 @@ models:
 	}
 }
 
+func TestParseConfig_EmailUniqueConvention(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `app:
+  name: "Test App"
+database:
+  type: sqlite
+  path: "./test.db"
+conventions:
+  email_unique: true
+models:
+  User:
+    fields:
+      id:
+        type: id
+        primary: true
+      email:
+        type: email
+      backup_email:
+        type: email
+        unique: false`
+
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	config, err := ParseConfig(configFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	fields := config.Models["User"].Fields
+	if !fields["email"].Unique || !fields["email"].IgnoreCase {
+		t.Error("Expected email to be unique, ignoring case, by convention")
+	}
+	if fields["backup_email"].Unique {
+		t.Error("Expected explicit unique: false to override the convention")
+	}
+}
+
 func TestParseConfig_InvalidFile(t *testing.T) {
 	_, err := ParseConfig("nonexistent_file.yaml")
 	if err == nil {
@@ -104,8 +143,8 @@ func TestValidateConfig_MissingAppName(t *testing.T) {
 	if err == nil {
 		t.Fatal("Expected error for missing app name")
 	}
-	if err.Error() != "app.name is required" {
-		t.Errorf("Expected 'app.name is required', got: %s", err.Error())
+	if err.Error() != `app.name cannot be empty; omit it to use the default "My Application"` {
+		t.Errorf("Expected an empty app.name error naming the default, got: %s", err.Error())
 	}
 }
 
@@ -134,8 +173,8 @@ func TestValidateConfig_SQLiteMissingPath(t *testing.T) {
 	if err == nil {
 		t.Fatal("Expected error for missing SQLite path")
 	}
-	if err.Error() != "database.path is required for SQLite" {
-		t.Errorf("Expected 'database.path is required for SQLite', got: %s", err.Error())
+	if err.Error() != `database.path cannot be empty for SQLite; omit it to use the default "./data.db"` {
+		t.Errorf("Expected an empty database.path error naming the default, got: %s", err.Error())
 	}
 }
 
@@ -375,6 +414,34 @@ func TestProcessConfig_DefaultPermissions(t *testing.T) {
 	}
 }
 
+func TestGenerateDefaultUI_VisibilityFlags(t *testing.T) {
+	hide := false
+	model := ModelConfig{
+		Fields: map[string]FieldConfig{
+			"id":    {Type: "id", Primary: true},
+			"name":  {Type: "text"},
+			"notes": {Type: "text", List: &hide},
+			"token": {Type: "text", Form: &hide},
+		},
+	}
+
+	ui := generateDefaultUI(model)
+	for _, col := range ui.List.Columns {
+		if col == "notes" {
+			t.Error("Expected list: false to keep notes out of the list columns")
+		}
+	}
+	for _, field := range ui.Form.Fields {
+		if field == "token" {
+			t.Error("Expected form: false to keep token out of the form")
+		}
+	}
+
+	if f := buildField("notes", model.Fields["notes"]); !f.HideInList || f.HideInForm || f.HideInDetail {
+		t.Errorf("Expected only HideInList to be set, got %+v", f)
+	}
+}
+
 func TestGenerateDefaultUI(t *testing.T) {
 	model := ModelConfig{
 		Fields: map[string]FieldConfig{
@@ -1019,6 +1086,51 @@ models:
 	}
 }
 
+func TestParseConfig_EmptyValues(t *testing.T) {
+	models := `
+models:
+  User:
+    fields:
+      id:
+        type: id
+        primary: true`
+
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{"missing sections", "", ""},
+		{"empty sections", "app:\ndatabase:\n", ""},
+		{"null app name", "app:\n  name: ~\n", ""},
+		{"empty app name", "app:\n  name: \"\"\n", "app.name cannot be empty"},
+		{"empty database type", "database:\n  type: \"\"\n", "database.type cannot be empty"},
+		{"empty database path", "database:\n  path: \"\"\n", "database.path cannot be empty for SQLite"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(tt.yaml+models), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		config, err := ParseConfig(path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected defaults, got error: %v", tt.name, err)
+			continue
+		}
+		if config.App.Name != "My Application" || config.Database.Type != "sqlite" || config.Database.Path != "./data.db" {
+			t.Errorf("%s: expected default app and database settings, got %+v and %+v", tt.name, config.App, config.Database)
+		}
+	}
+}
+
 func TestParseConfig_InvalidFieldTypes(t *testing.T) {
 	tmpDir := t.TempDir()
 	invalidFieldConfigPath := filepath.Join(tmpDir, "invalid_field.yaml")
@@ -1323,4 +1435,1113 @@ models:
 	if err == nil {
 		t.Error("Expected error for model with no primary key")
 	}
-}
\ No newline at end of file
+}
+
+func TestValidateField_InvalidRelationType(t *testing.T) {
+	field := FieldConfig{Type: "relation", To: "User", RelationType: "many_to_many"}
+
+	err := validateField("Profile", "user_id", field)
+	if err == nil {
+		t.Fatal("Expected error for invalid relation_type")
+	}
+	if err.Error() != "invalid relation_type 'many_to_many' for Profile.user_id" {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+
+	field = FieldConfig{Type: "text", RelationType: "one_to_one"}
+	if err := validateField("Profile", "name", field); err == nil {
+		t.Error("Expected error for relation_type on a non-relation field")
+	}
+}
+
+func TestValidateModel_SharedOneToOne(t *testing.T) {
+	model := ModelConfig{
+		Fields: map[string]FieldConfig{
+			"id":       {Type: "id", Primary: true},
+			"user_id":  {Type: "relation", To: "User", RelationType: "one_to_one"},
+			"owner_id": {Type: "relation", To: "User", RelationType: "one_to_one"},
+		},
+	}
+
+	err := validateModel("Profile", model)
+	if err == nil {
+		t.Fatal("Expected error for multiple one_to_one relations to the same model")
+	}
+	if !strings.Contains(err.Error(), "multiple one_to_one relations to User") {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+}
+
+func TestProcessConfig_OneToOneUnique(t *testing.T) {
+	config := &Config{
+		Models: map[string]ModelConfig{
+			"Profile": {
+				Fields: map[string]FieldConfig{
+					"id":      {Type: "id", Primary: true},
+					"user_id": {Type: "relation", To: "User", RelationType: "one_to_one"},
+				},
+			},
+		},
+	}
+
+	processed := processConfig(config)
+
+	if !processed.Models["Profile"].Fields["user_id"].Unique {
+		t.Error("Expected one_to_one foreign key to be unique")
+	}
+
+	schema, _ := LoadConfig(processed)
+	field, _ := schema.GetField("Profile", "user_id")
+	if !field.IsOneToOne() {
+		t.Error("Expected loaded field to be one_to_one")
+	}
+}
+
+func TestSchema_ReverseRelations(t *testing.T) {
+	schema := &Schema{
+		Models: map[string]*Model{
+			"User": {Name: "User", Fields: []Field{{Name: "id", Type: FieldTypeID, Primary: true}}},
+			"Profile": {Name: "Profile", Fields: []Field{
+				{Name: "id", Type: FieldTypeID, Primary: true},
+				{Name: "user_id", Type: FieldTypeRelation, RelatedTo: "user", RelationType: "one_to_one"},
+			}},
+		},
+	}
+
+	relations := schema.ReverseRelations("User")
+	if len(relations["Profile"]) != 1 || relations["Profile"][0].Name != "user_id" {
+		t.Errorf("Expected Profile.user_id reverse relation, got %v", relations)
+	}
+
+	if _, ok := schema.GetModelByRoute("profile"); !ok {
+		t.Error("Expected to find Profile by route name")
+	}
+}
+
+func TestLoadConfig_RelationDisplayField(t *testing.T) {
+	config := &Config{
+		Models: map[string]ModelConfig{
+			"User": {
+				Fields: map[string]FieldConfig{
+					"id":   {Type: "id", Primary: true},
+					"bio":  {Type: "text"},
+					"name": {Type: "text"},
+				},
+			},
+			"Post": {
+				Fields: map[string]FieldConfig{
+					"id":        {Type: "id", Primary: true},
+					"author_id": {Type: "relation", To: "User"},
+					"editor_id": {Type: "relation", To: "User", Display: "bio"},
+				},
+			},
+		},
+	}
+
+	schema, err := LoadConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	author, _ := schema.GetField("Post", "author_id")
+	if author.DisplayField != "name" {
+		t.Errorf("Expected default display field 'name', got %s", author.DisplayField)
+	}
+
+	editor, _ := schema.GetField("Post", "editor_id")
+	if editor.DisplayField != "bio" {
+		t.Errorf("Expected configured display field 'bio', got %s", editor.DisplayField)
+	}
+}
+
+func TestValidateModel_RestoreWindow(t *testing.T) {
+	model := ModelConfig{
+		Fields:        map[string]FieldConfig{"id": {Type: "id", Primary: true}},
+		RestoreWindow: "24h",
+	}
+
+	if err := validateModel("Note", model); err == nil {
+		t.Error("Expected error for restore_window without soft_delete")
+	}
+
+	model.SoftDelete = true
+	if err := validateModel("Note", model); err != nil {
+		t.Errorf("Expected valid restore_window, got: %v", err)
+	}
+
+	model.RestoreWindow = "soon"
+	if err := validateModel("Note", model); err == nil {
+		t.Error("Expected error for invalid restore_window")
+	}
+}
+
+func TestLoadConfig_SoftDelete(t *testing.T) {
+	config := &Config{
+		Models: map[string]ModelConfig{
+			"Note": {
+				Fields:        map[string]FieldConfig{"id": {Type: "id", Primary: true}},
+				SoftDelete:    true,
+				RestoreWindow: "30m",
+			},
+		},
+	}
+
+	schema, err := LoadConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	model, _ := schema.GetModel("Note")
+	if !model.SoftDelete || model.RestoreWindow != 30*time.Minute {
+		t.Errorf("Expected soft delete with 30m window, got %v/%v", model.SoftDelete, model.RestoreWindow)
+	}
+
+	field, ok := schema.GetField("Note", SoftDeleteField)
+	if !ok {
+		t.Fatal("Expected deleted_at field to be added")
+	}
+	if field.Type != FieldTypeDatetime || !field.Nullable {
+		t.Errorf("Expected nullable datetime deleted_at, got %+v", field)
+	}
+}
+
+func TestValidateField_KeyType(t *testing.T) {
+	if err := validateField("Event", "id", FieldConfig{Type: "id", Primary: true, KeyType: "ulid"}); err != nil {
+		t.Errorf("Expected ulid key_type to be valid, got: %v", err)
+	}
+	if err := validateField("Event", "id", FieldConfig{Type: "id", Primary: true, KeyType: "snowflake"}); err == nil {
+		t.Error("Expected error for unknown key_type")
+	}
+	if err := validateField("Event", "name", FieldConfig{Type: "text", KeyType: "uuid"}); err == nil {
+		t.Error("Expected error for key_type on a non-id field")
+	}
+}
+
+func TestLoadConfig_RelationInheritsKeyType(t *testing.T) {
+	config := &Config{
+		Models: map[string]ModelConfig{
+			"Event": {
+				Fields: map[string]FieldConfig{
+					"id": {Type: "id", Primary: true, KeyType: "uuid"},
+				},
+			},
+			"Ticket": {
+				Fields: map[string]FieldConfig{
+					"id":       {Type: "id", Primary: true},
+					"event_id": {Type: "relation", To: "Event"},
+				},
+			},
+		},
+	}
+
+	schema, err := LoadConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if kt := schema.Models["Event"].KeyType(); kt != KeyTypeUUID {
+		t.Errorf("Expected Event key type uuid, got %s", kt)
+	}
+	if kt := schema.Models["Ticket"].KeyType(); kt != KeyTypeInt {
+		t.Errorf("Expected Ticket key type int, got %s", kt)
+	}
+
+	field, _ := schema.GetField("Ticket", "event_id")
+	if field.KeyType != KeyTypeUUID {
+		t.Errorf("Expected relation to inherit uuid key type, got %s", field.KeyType)
+	}
+}
+
+func TestValidateConfig_BaseURL(t *testing.T) {
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Server.BaseURL = "api.example.com"
+
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for base_url without a scheme")
+	}
+
+	config.Server.BaseURL = "https://api.example.com"
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected valid base_url, got: %v", err)
+	}
+}
+
+func TestValidateConfig_RedisURL(t *testing.T) {
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Server.RedisURL = "localhost:6379"
+
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for redis_url without a redis scheme")
+	}
+
+	config.Server.RedisURL = "redis://:secret@localhost:6379/1"
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected valid redis_url, got: %v", err)
+	}
+}
+
+func TestValidateConfig_AuditRetention(t *testing.T) {
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Server.Audit = AuditConfig{Enabled: true, Retention: "30 days"}
+
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for an unparseable audit retention")
+	}
+
+	config.Server.Audit.Retention = "720h"
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected valid audit retention, got: %v", err)
+	}
+}
+
+func TestValidateConfig_APIUnauthorized(t *testing.T) {
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Server.Auth.APIUnauthorized = "redirect"
+
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for unknown api_unauthorized mode")
+	}
+
+	config.Server.Auth.APIUnauthorized = APIUnauthorizedPage
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected valid api_unauthorized, got: %v", err)
+	}
+}
+
+func TestValidateModel_Filterable(t *testing.T) {
+	model := ModelConfig{
+		Fields: map[string]FieldConfig{
+			"id":   {Type: "id", Primary: true},
+			"role": {Type: "enum", Options: []string{"user", "admin"}},
+			"name": {Type: "text"},
+		},
+		UI: &UIModelConfig{List: &UIListConfig{Filterable: []string{"role"}}},
+	}
+
+	if err := validateModel("User", model); err != nil {
+		t.Errorf("Expected valid filterable field, got: %v", err)
+	}
+
+	model.UI.List.Filterable = []string{"name"}
+	if err := validateModel("User", model); err == nil {
+		t.Error("Expected error for filterable text field")
+	}
+
+	model.UI.List.Filterable = []string{"missing"}
+	if err := validateModel("User", model); err == nil {
+		t.Error("Expected error for unknown filterable field")
+	}
+}
+
+func TestValidateModel_FormOnSave(t *testing.T) {
+	model := ModelConfig{
+		Fields: map[string]FieldConfig{"id": {Type: "id", Primary: true}},
+		UI:     &UIModelConfig{Form: &UIFormConfig{OnSave: "new"}},
+	}
+
+	if err := validateModel("User", model); err != nil {
+		t.Errorf("Expected valid on_save, got: %v", err)
+	}
+
+	model.UI.Form.OnSave = "home"
+	if err := validateModel("User", model); err == nil {
+		t.Error("Expected error for unknown on_save")
+	}
+}
+
+func TestValidateConfig_DashboardWidgets(t *testing.T) {
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Models = map[string]ModelConfig{
+		"Order": {Fields: map[string]FieldConfig{
+			"id":     {Type: "id", Primary: true},
+			"total":  {Type: "number"},
+			"status": {Type: "text"},
+		}},
+	}
+	config.UI.Dashboard.Widgets = []UIWidgetConfig{
+		{Title: "Total Revenue", Model: "Order", Aggregate: "sum", Field: "total", Filters: map[string]any{"status": "paid"}},
+	}
+
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected valid widget, got: %v", err)
+	}
+
+	config.UI.Dashboard.Widgets[0].Field = "status"
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for sum over a non-number field")
+	}
+
+	config.UI.Dashboard.Widgets[0] = UIWidgetConfig{Title: "Orders", Model: "Order", Aggregate: "median"}
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for unknown aggregate")
+	}
+
+	config.UI.Dashboard.Widgets[0] = UIWidgetConfig{Title: "Orders", Model: "Missing", Aggregate: "count"}
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for unknown model")
+	}
+}
+
+func TestValidateModel_FormSections(t *testing.T) {
+	model := ModelConfig{
+		Fields: map[string]FieldConfig{
+			"id":   {Type: "id", Primary: true},
+			"name": {Type: "text"},
+		},
+		UI: &UIModelConfig{
+			Form: &UIFormConfig{
+				Sections: []UIFormSectionConfig{{Title: "Account", Fields: []string{"name"}}},
+			},
+		},
+	}
+
+	if err := validateModel("User", model); err != nil {
+		t.Errorf("Expected valid form sections, got: %v", err)
+	}
+
+	model.UI.Form.Sections[0].Fields = []string{"missing"}
+	if err := validateModel("User", model); err == nil {
+		t.Error("Expected error for unknown field in form section")
+	}
+
+	model.UI.Form.Sections[0] = UIFormSectionConfig{Fields: []string{"name"}}
+	if err := validateModel("User", model); err == nil {
+		t.Error("Expected error for form section without title")
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected RateLimit
+		wantErr  bool
+	}{
+		{"10/m", RateLimit{Requests: 10, Window: time.Minute}, false},
+		{"100/hour", RateLimit{Requests: 100, Window: time.Hour}, false},
+		{"5/30s", RateLimit{Requests: 5, Window: 30 * time.Second}, false},
+		{"10", RateLimit{}, true},
+		{"0/m", RateLimit{}, true},
+		{"10/fortnight", RateLimit{}, true},
+	}
+
+	for _, tt := range tests {
+		limit, err := ParseRateLimit(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRateLimit(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if limit != tt.expected {
+			t.Errorf("ParseRateLimit(%q) = %+v, want %+v", tt.input, limit, tt.expected)
+		}
+	}
+}
+
+func TestValidateField_Object(t *testing.T) {
+	field := FieldConfig{
+		Type: "object",
+		Properties: map[string]FieldConfig{
+			"city": {Type: "text", Required: true},
+			"zip":  {Type: "text", Max: 10},
+		},
+	}
+	if err := validateField("User", "address", field); err != nil {
+		t.Errorf("Expected valid object field, got: %v", err)
+	}
+
+	if err := validateField("User", "address", FieldConfig{Type: "object"}); err == nil {
+		t.Error("Expected error for object without properties")
+	}
+
+	field.Properties["country"] = FieldConfig{Type: "bogus"}
+	if err := validateField("User", "address", field); err == nil {
+		t.Error("Expected error for invalid nested property type")
+	}
+
+	if err := validateField("User", "name", FieldConfig{Type: "text", Properties: map[string]FieldConfig{"x": {Type: "text"}}}); err == nil {
+		t.Error("Expected error for properties on a non-object field")
+	}
+}
+
+func TestLoadConfig_ObjectField(t *testing.T) {
+	config := &Config{
+		Models: map[string]ModelConfig{
+			"User": {
+				Fields: map[string]FieldConfig{
+					"id": {Type: "id", Primary: true},
+					"address": {Type: "object", Properties: map[string]FieldConfig{
+						"zip":  {Type: "text", Max: 10},
+						"city": {Type: "text", Required: true},
+					}},
+				},
+			},
+		},
+	}
+
+	schema, err := LoadConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	field, ok := schema.GetField("User", "address")
+	if !ok {
+		t.Fatal("Expected address field")
+	}
+	if len(field.Properties) != 2 || field.Properties[0].Name != "city" || field.Properties[1].Name != "zip" {
+		t.Fatalf("Expected sorted properties [city zip], got %+v", field.Properties)
+	}
+	if !field.Properties[0].Required || field.Properties[1].Max == nil || *field.Properties[1].Max != 10 {
+		t.Errorf("Expected property options to be copied, got %+v", field.Properties)
+	}
+}
+
+func TestLoadConfig_HelpAndPlaceholder(t *testing.T) {
+	config := &Config{
+		Models: map[string]ModelConfig{
+			"User": {
+				Fields: map[string]FieldConfig{
+					"id":       {Type: "id", Primary: true},
+					"password": {Type: "password", Help: "At least 8 characters", Placeholder: "********"},
+				},
+			},
+		},
+	}
+
+	schema, err := LoadConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	field, _ := schema.GetField("User", "password")
+	if field.Help != "At least 8 characters" || field.Placeholder != "********" {
+		t.Errorf("Expected help and placeholder to be copied, got %q/%q", field.Help, field.Placeholder)
+	}
+}
+
+func TestLoadConfig_FieldOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "order.yaml")
+	content := `
+app:
+  name: "Order"
+models:
+  User:
+    fields:
+      id:
+        type: id
+        primary: true
+      name:
+        type: text
+      email:
+        type: email
+      age:
+        type: number
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := ParseConfig(configFile)
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	schema, err := LoadConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	model, _ := schema.GetModel("User")
+	var names []string
+	for _, field := range model.Fields {
+		names = append(names, field.Name)
+	}
+	if got := strings.Join(names, ","); got != "id,name,email,age" {
+		t.Errorf("Expected fields in declaration order, got %s", got)
+	}
+	if got := strings.Join(model.UI.Form.Fields, ","); got != "name,email,age" {
+		t.Errorf("Expected form fields in declaration order, got %s", got)
+	}
+}
+
+func TestValidateConfig_Captcha(t *testing.T) {
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Server.Auth.Captcha = &CaptchaConfig{After: 3}
+
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for missing captcha verify_url")
+	}
+
+	config.Server.Auth.Captcha.VerifyURL = "https://hcaptcha.com/siteverify"
+	config.Server.Auth.Captcha.Window = "soon"
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for invalid captcha window")
+	}
+
+	config.Server.Auth.Captcha.Window = "10m"
+	if err := validateConfig(config); err == nil || !strings.Contains(err.Error(), "site_key") {
+		t.Errorf("Expected error for missing captcha site_key, got: %v", err)
+	}
+
+	config.Server.Auth.Captcha.SiteKey = "site-key"
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected valid captcha config, got: %v", err)
+	}
+
+	config.Server.Auth.Captcha.VerifyURL = "https://captcha.example.com/siteverify"
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for a provider whose widget is unknown")
+	}
+}
+
+func TestModel_RecordTitle(t *testing.T) {
+	model := &Model{Name: "User", DisplayName: "{{.name}} ({{.email}})"}
+
+	record := map[string]any{"id": 5, "name": "John Doe", "email": "john@example.com"}
+	if got := model.RecordTitle(record); got != "John Doe (john@example.com)" {
+		t.Errorf("Expected rendered display_name, got %q", got)
+	}
+	if got := model.RecordTitle(map[string]any{"id": 5, "name": "John Doe"}); got != "User #5" {
+		t.Errorf("Expected fallback for a missing key, got %q", got)
+	}
+
+	model.DisplayName = ""
+	if got := model.RecordTitle(record); got != "User #5" {
+		t.Errorf("Expected fallback without display_name, got %q", got)
+	}
+
+	err := validateModel("User", ModelConfig{
+		Fields:      map[string]FieldConfig{"id": {Type: "id", Primary: true}},
+		DisplayName: "{{.name",
+	})
+	if err == nil {
+		t.Error("Expected error for an invalid display_name template")
+	}
+}
+
+func TestProcessConfig_Timestamps(t *testing.T) {
+	on, off := true, false
+	config := DefaultConfig()
+	config.Models = map[string]ModelConfig{
+		"Post": {
+			Timestamps: &on,
+			Fields:     map[string]FieldConfig{"id": {Type: "id", Primary: true}, "title": {Type: "text"}},
+			FieldOrder: []string{"id", "title"},
+		},
+		"Tag": {
+			Fields: map[string]FieldConfig{"id": {Type: "id", Primary: true}},
+		},
+		"Note": {
+			Timestamps: &off,
+			Fields:     map[string]FieldConfig{"id": {Type: "id", Primary: true}},
+		},
+	}
+
+	config = processConfig(config)
+
+	post := config.Models["Post"]
+	if created := post.Fields["created_at"]; created.Type != "datetime" || !created.AutoNowAdd {
+		t.Errorf("Expected created_at to be an auto_now_add datetime, got %+v", created)
+	}
+	if updated := post.Fields["updated_at"]; updated.Type != "datetime" || !updated.AutoNow {
+		t.Errorf("Expected updated_at to be an auto_now datetime, got %+v", updated)
+	}
+	if got := strings.Join(post.FieldNames(), ","); got != "id,title,created_at,updated_at" {
+		t.Errorf("Expected timestamps after the declared fields, got %s", got)
+	}
+	if strings.Contains(strings.Join(post.UI.Form.Fields, ","), "_at") {
+		t.Errorf("Expected timestamps to stay out of the form, got %v", post.UI.Form.Fields)
+	}
+	if _, ok := config.Models["Tag"].Fields["created_at"]; ok {
+		t.Error("Expected no timestamps without the flag")
+	}
+
+	config.Conventions.Timestamps = true
+	config.Models["Tag"] = ModelConfig{Fields: map[string]FieldConfig{"id": {Type: "id", Primary: true}, "created_at": {Type: "date"}}}
+	config = processConfig(config)
+	if config.Models["Tag"].Fields["created_at"].Type != "date" {
+		t.Error("Expected a declared created_at to be kept")
+	}
+	if _, ok := config.Models["Tag"].Fields["updated_at"]; !ok {
+		t.Error("Expected conventions.timestamps to add updated_at")
+	}
+	if _, ok := config.Models["Note"].Fields["created_at"]; ok {
+		t.Error("Expected timestamps: false to opt out of the convention")
+	}
+}
+
+func TestParseConfig_TimestampsReferenced(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `app:
+  name: "Test App"
+database:
+  type: sqlite
+  path: "./test.db"
+models:
+  Post:
+    timestamps: true
+    fields:
+      id:
+        type: id
+        primary: true
+      title:
+        type: text
+    api:
+      versions:
+        v1: [title, updated_at]
+    webhooks:
+      - url: "https://example.com/hook"
+        fields: [id, created_at]`
+
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	config, err := ParseConfig(configFile)
+	if err != nil {
+		t.Fatalf("Expected config referring to timestamp fields to be valid, got: %v", err)
+	}
+	if _, ok := config.Models["Post"].Fields["created_at"]; !ok {
+		t.Error("Expected created_at to be added")
+	}
+}
+
+func TestValidateConfig_RequireSecret(t *testing.T) {
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Server.Auth = AuthConfig{Type: "jwt", RequireSecret: true}
+
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for a missing secret with require_secret")
+	}
+
+	config.Server.Auth.Secret = "s3cret"
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected valid config, got: %v", err)
+	}
+}
+
+func TestValidateModel_Webhooks(t *testing.T) {
+	fields := map[string]FieldConfig{
+		"id":       {Type: "id", Primary: true},
+		"email":    {Type: "email"},
+		"password": {Type: "password"},
+	}
+
+	tests := []struct {
+		name    string
+		webhook WebhookConfig
+		wantErr bool
+	}{
+		{"valid", WebhookConfig{URL: "https://example.com/hook", Events: []string{"create"}, Fields: []string{"id", "email"}}, false},
+		{"restore event", WebhookConfig{URL: "https://example.com/hook", Events: []string{"restore"}}, false},
+		{"relative url", WebhookConfig{URL: "/hook"}, true},
+		{"unknown event", WebhookConfig{URL: "https://example.com/hook", Events: []string{"read"}}, true},
+		{"unknown field", WebhookConfig{URL: "https://example.com/hook", Fields: []string{"phone"}}, true},
+		{"password field", WebhookConfig{URL: "https://example.com/hook", Fields: []string{"password"}}, true},
+	}
+
+	for _, tt := range tests {
+		err := validateModel("User", ModelConfig{Fields: fields, Webhooks: []WebhookConfig{tt.webhook}})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestValidateModel_OnChange(t *testing.T) {
+	fields := map[string]FieldConfig{"id": {Type: "id", Primary: true}}
+
+	if err := validateModel("User", ModelConfig{Fields: fields, OnChange: "https://cdn.example.com/purge"}); err != nil {
+		t.Errorf("Expected valid on_change, got: %v", err)
+	}
+	if err := validateModel("User", ModelConfig{Fields: fields, OnChange: "cdn.example.com/purge"}); err == nil {
+		t.Error("Expected error for an on_change url without a scheme")
+	}
+}
+
+func TestValidateModel_DefaultFilter(t *testing.T) {
+	fields := map[string]FieldConfig{
+		"id":        {Type: "id", Primary: true},
+		"published": {Type: "boolean"},
+		"status":    {Type: "text"},
+		"password":  {Type: "password"},
+	}
+
+	tests := []struct {
+		name    string
+		filter  FilterConfig
+		wantErr bool
+	}{
+		{"equality", FilterConfig{Field: "published", Value: true}, false},
+		{"in", FilterConfig{Field: "status", Operator: "in", Value: []any{"live", "archived"}}, false},
+		{"is_null", FilterConfig{Field: "status", Operator: "is_null"}, false},
+		{"unknown field", FilterConfig{Field: "visible", Value: true}, true},
+		{"password field", FilterConfig{Field: "password", Value: "x"}, true},
+		{"unknown operator", FilterConfig{Field: "status", Operator: "; DROP", Value: "x"}, true},
+		{"in without list", FilterConfig{Field: "status", Operator: "in", Value: "live"}, true},
+		{"missing value", FilterConfig{Field: "status"}, true},
+	}
+
+	for _, tt := range tests {
+		err := validateModel("Post", ModelConfig{Fields: fields, DefaultFilter: []FilterConfig{tt.filter}})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestValidateField_Mask(t *testing.T) {
+	if err := validateField("User", "phone", FieldConfig{Type: "text", Mask: "*####"}); err != nil {
+		t.Errorf("Expected valid mask, got: %v", err)
+	}
+	if err := validateField("User", "phone", FieldConfig{Type: "text", Mask: "last4"}); err == nil {
+		t.Error("Expected error for a mask with unknown symbols")
+	}
+}
+
+func TestValidateField_Normalize(t *testing.T) {
+	if err := validateField("User", "username", FieldConfig{Type: "text", Normalize: NormalizeLower}); err != nil {
+		t.Errorf("Expected valid normalize, got: %v", err)
+	}
+	if err := validateField("User", "username", FieldConfig{Type: "text", Normalize: "title"}); err == nil {
+		t.Error("Expected error for an unknown normalize mode")
+	}
+	if err := validateField("User", "age", FieldConfig{Type: "number", Normalize: NormalizeTrim}); err == nil {
+		t.Error("Expected error for normalize on a non-text field")
+	}
+}
+
+func TestValidateField_Transform(t *testing.T) {
+	if err := validateField("User", "name", FieldConfig{Type: "text", Transform: []string{"trim", "lower"}}); err != nil {
+		t.Errorf("Expected built-in transforms to be valid, got: %v", err)
+	}
+	if err := validateField("User", "name", FieldConfig{Type: "text", Transform: []string{"reverse"}}); err == nil {
+		t.Error("Expected error for an unregistered transform")
+	}
+
+	RegisterTransform("reverse", func(value any) (any, error) {
+		runes := []rune(value.(string))
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	})
+	if err := validateField("User", "name", FieldConfig{Type: "text", Transform: []string{"reverse"}}); err != nil {
+		t.Errorf("Expected registered transform to be valid, got: %v", err)
+	}
+
+	value, err := ApplyTransforms([]string{"trim", "reverse", "upper"}, "  abc ")
+	if err != nil || value != "CBA" {
+		t.Errorf("Expected transforms to run in order, got %v (%v)", value, err)
+	}
+}
+
+func TestValidateModel_Hooks(t *testing.T) {
+	fields := map[string]FieldConfig{
+		"id":    {Type: "id", Primary: true},
+		"title": {Type: "text"},
+		"slug":  {Type: "slug"},
+	}
+
+	valid := ModelConfig{Fields: fields, Hooks: map[string][]HookConfig{
+		HookBeforeCreate: {{Set: "slug", Value: "{{.title}}"}},
+		HookBeforeDelete: {{Reject: "{{if .slug}}published posts can't be deleted{{end}}"}},
+	}}
+	if err := validateModel("Post", valid); err != nil {
+		t.Errorf("Expected valid hooks, got: %v", err)
+	}
+
+	for name, hooks := range map[string]map[string][]HookConfig{
+		"unknown event":   {"after_create": {{Set: "slug", Value: "x"}}},
+		"unknown field":   {HookBeforeCreate: {{Set: "missing", Value: "x"}}},
+		"set and reject":  {HookBeforeCreate: {{Set: "slug", Value: "x", Reject: "no"}}},
+		"empty step":      {HookBeforeCreate: {{}}},
+		"set on delete":   {HookBeforeDelete: {{Set: "slug", Value: "x"}}},
+		"broken template": {HookBeforeCreate: {{Set: "slug", Value: "{{.title"}}},
+	} {
+		if err := validateModel("Post", ModelConfig{Fields: fields, Hooks: hooks}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestModel_RunHooks_TypedValues(t *testing.T) {
+	model := &Model{
+		Name: "Post",
+		Fields: []Field{
+			{Name: "title", Type: FieldTypeText},
+			{Name: "words", Type: FieldTypeNumber},
+			{Name: "score", Type: FieldTypeNumber, Decimal: true},
+			{Name: "long", Type: FieldTypeBoolean},
+		},
+		Hooks: compileHooks(map[string][]HookConfig{
+			HookBeforeCreate: {
+				{Set: "words", Value: "{{len .title}}"},
+				{Set: "score", Value: "{{len .title}}.5"},
+				{Set: "long", Value: "{{gt (len .title) 3}}"},
+			},
+			HookBeforeUpdate: {{Set: "words", Value: "{{.title}}"}},
+		}),
+	}
+	if model.Hooks[HookBeforeCreate][0].tmpl == nil {
+		t.Fatal("Expected the hook templates to be parsed when the model is built")
+	}
+
+	data := map[string]any{"title": "hello"}
+	if err := model.RunHooks(HookBeforeCreate, data, nil); err != nil {
+		t.Fatalf("RunHooks failed: %v", err)
+	}
+	if data["words"] != int64(5) || data["score"] != 5.5 || data["long"] != true {
+		t.Errorf("Expected typed hook values, got %#v", data)
+	}
+
+	if err := model.RunHooks(HookBeforeUpdate, map[string]any{"title": "hello"}, nil); err == nil {
+		t.Error("Expected an error setting a number field to text")
+	}
+}
+
+func TestValidateModel_RequireOneOf(t *testing.T) {
+	fields := map[string]FieldConfig{
+		"id":    {Type: "id", Primary: true},
+		"phone": {Type: "text"},
+		"email": {Type: "email"},
+	}
+
+	if err := validateModel("Contact", ModelConfig{Fields: fields, RequireOneOf: [][]string{{"phone", "email"}}}); err != nil {
+		t.Errorf("Expected valid require_one_of, got: %v", err)
+	}
+	if err := validateModel("Contact", ModelConfig{Fields: fields, RequireOneOf: [][]string{{"phone", "fax"}}}); err == nil {
+		t.Error("Expected error for an unknown field")
+	}
+	if err := validateModel("Contact", ModelConfig{Fields: fields, RequireOneOf: [][]string{{"phone"}}}); err == nil {
+		t.Error("Expected error for a single-field group")
+	}
+}
+
+func TestValidateField_Default(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   FieldConfig
+		wantErr bool
+	}{
+		{"enum option", FieldConfig{Type: "enum", Options: []string{"todo", "done"}, Default: "todo"}, false},
+		{"enum unknown option", FieldConfig{Type: "enum", Options: []string{"todo", "done"}, Default: "archived"}, true},
+		{"number", FieldConfig{Type: "number", Default: 3}, false},
+		{"number from string", FieldConfig{Type: "number", Default: "three"}, true},
+		{"boolean", FieldConfig{Type: "boolean", Default: false}, false},
+		{"boolean from string", FieldConfig{Type: "boolean", Default: "yes"}, true},
+		{"text too long", FieldConfig{Type: "text", Max: 5, Default: "too long"}, true},
+		{"text number", FieldConfig{Type: "text", Default: 42}, true},
+		{"datetime", FieldConfig{Type: "datetime", Default: "CURRENT_TIMESTAMP"}, false},
+	}
+
+	for _, tt := range tests {
+		err := validateField("Task", "field", tt.field)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestValidateConfig_Pagination(t *testing.T) {
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Server.Pagination = PaginationConfig{DefaultPageSize: 200}
+
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected error for a default page size above the maximum")
+	}
+
+	config.Server.Pagination = PaginationConfig{DefaultPageSize: 50, MaxPageSize: 500}
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected valid pagination, got: %v", err)
+	}
+	if def, max := config.Server.Pagination.Limits(); def != 50 || max != 500 {
+		t.Errorf("Expected limits 50/500, got %d/%d", def, max)
+	}
+}
+
+func TestValidateConfig_EmailVerification(t *testing.T) {
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Server.Auth.EmailVerification = &EmailVerificationConfig{
+		SMTPAddr:  "smtp.example.com:587",
+		From:      "noreply@example.com",
+		VerifyURL: "https://app.example.com/verify",
+		Expires:   "24h",
+	}
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected valid email verification config, got: %v", err)
+	}
+
+	for name, verification := range map[string]EmailVerificationConfig{
+		"missing port": {SMTPAddr: "smtp.example.com", From: "noreply@example.com"},
+		"missing from": {SMTPAddr: "smtp.example.com:587"},
+		"bad expires":  {Expires: "soon"},
+		"relative url": {VerifyURL: "/verify"},
+	} {
+		config.Server.Auth.EmailVerification = &verification
+		if err := validateConfig(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestValidateModel_JSONIndexes(t *testing.T) {
+	fields := map[string]FieldConfig{
+		"id":       {Type: "id", Primary: true},
+		"name":     {Type: "text"},
+		"settings": {Type: "json"},
+	}
+
+	valid := ModelConfig{Fields: fields, Indexes: []IndexConfig{{JSONField: "settings", Path: "$.billing.plan"}}}
+	if err := validateModel("Account", valid); err != nil {
+		t.Errorf("Expected valid index, got: %v", err)
+	}
+
+	for name, index := range map[string]IndexConfig{
+		"unknown field":  {JSONField: "missing", Path: "$.plan"},
+		"not json":       {JSONField: "name", Path: "$.plan"},
+		"missing root":   {JSONField: "settings", Path: "plan"},
+		"quoted key":     {JSONField: "settings", Path: "$.plan')--"},
+		"array selector": {JSONField: "settings", Path: "$.plans[0]"},
+	} {
+		if err := validateModel("Account", ModelConfig{Fields: fields, Indexes: []IndexConfig{index}}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if column, path, ok := JSONPath("settings.billing.plan"); !ok || column != "settings" || path != "$.billing.plan" {
+		t.Errorf("Expected settings and $.billing.plan, got %q, %q, %v", column, path, ok)
+	}
+}
+
+func TestValidateModel_CompositePrimaryKey(t *testing.T) {
+	fields := map[string]FieldConfig{
+		"user_id": {Type: "relation", To: "User"},
+		"role_id": {Type: "relation", To: "Role"},
+		"meta":    {Type: "json"},
+	}
+	if err := validateModel("UserRole", ModelConfig{Fields: fields, Primary: []string{"user_id", "role_id"}}); err != nil {
+		t.Errorf("Expected a valid composite key, got: %v", err)
+	}
+
+	for name, primary := range map[string][]string{
+		"single field":  {"user_id"},
+		"unknown field": {"user_id", "missing"},
+		"duplicate":     {"user_id", "user_id"},
+		"json field":    {"user_id", "meta"},
+	} {
+		if err := validateModel("UserRole", ModelConfig{Fields: fields, Primary: primary}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	withID := map[string]FieldConfig{"id": {Type: "id", Primary: true}, "user_id": fields["user_id"], "role_id": fields["role_id"]}
+	if err := validateModel("UserRole", ModelConfig{Fields: withID, Primary: []string{"user_id", "role_id"}}); err == nil {
+		t.Error("Expected an error for an id field alongside a composite key")
+	}
+}
+
+func TestValidateModel_APIVersions(t *testing.T) {
+	fields := map[string]FieldConfig{
+		"id":    {Type: "id", Primary: true},
+		"name":  {Type: "text"},
+		"email": {Type: "email"},
+	}
+	valid := ModelConfig{Fields: fields, API: &ModelAPIConfig{Versions: map[string][]string{"v1": {"name"}, "v2": {"name", "email"}}}}
+	if err := validateModel("User", valid); err != nil {
+		t.Errorf("Expected valid versions, got: %v", err)
+	}
+
+	for name, versions := range map[string]map[string][]string{
+		"bad name":      {"latest": {"name"}},
+		"unknown field": {"v1": {"phone"}},
+	} {
+		if err := validateModel("User", ModelConfig{Fields: fields, API: &ModelAPIConfig{Versions: versions}}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestValidateConfig_RouteCollisions(t *testing.T) {
+	fields := map[string]FieldConfig{"id": {Type: "id", Primary: true}}
+
+	config := DefaultConfig()
+	config.App.Name = "Test"
+	config.Models = map[string]ModelConfig{"User": {Fields: fields}, "user": {Fields: fields}}
+	err := validateConfig(config)
+	if err == nil || !strings.Contains(err.Error(), "/user") {
+		t.Errorf("Expected a route collision error, got: %v", err)
+	}
+
+	config.Models = map[string]ModelConfig{
+		"User": {Fields: fields, API: &ModelAPIConfig{Versions: map[string][]string{"v1": {}}}},
+		"V1":   {Fields: fields},
+	}
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected an error for a model named like an API version")
+	}
+
+	for _, name := range []string{"Login", "logout", "API", "readyz"} {
+		config.Models = map[string]ModelConfig{name: {Fields: fields}}
+		if err := validateConfig(config); err == nil || !strings.Contains(err.Error(), "reserved") {
+			t.Errorf("Expected model %s to be rejected as a reserved route, got: %v", name, err)
+		}
+	}
+
+	config.Models = map[string]ModelConfig{"User": {Fields: fields}, "Users": {Fields: fields}}
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected distinct routes to pass, got: %v", err)
+	}
+}
+
+func TestValidateConfig_Seed(t *testing.T) {
+	config := DefaultConfig()
+	config.Models = map[string]ModelConfig{"User": {Fields: map[string]FieldConfig{"id": {Type: "id", Primary: true}}}}
+
+	config.Seed = map[string][]map[string]any{"User": {{"id": 1}}}
+	if err := validateConfig(config); err != nil {
+		t.Errorf("Expected a seed for a known model to pass, got: %v", err)
+	}
+
+	config.Seed = map[string][]map[string]any{"Post": {{"id": 1}}}
+	if err := validateConfig(config); err == nil {
+		t.Error("Expected an error for a seed of an unknown model")
+	}
+}
+
+func TestValidateModel_UniqueWithin(t *testing.T) {
+	fields := func(slug FieldConfig) map[string]FieldConfig {
+		return map[string]FieldConfig{
+			"id":        {Type: "id", Primary: true},
+			"author_id": {Type: "relation", To: "User"},
+			"tags":      {Type: "array", Items: "text"},
+			"slug":      slug,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		slug    FieldConfig
+		wantErr bool
+	}{
+		{"relation scope", FieldConfig{Type: "slug", UniqueWithin: "author_id"}, false},
+		{"unknown scope", FieldConfig{Type: "slug", UniqueWithin: "blog_id"}, true},
+		{"itself", FieldConfig{Type: "slug", UniqueWithin: "slug"}, true},
+		{"array scope", FieldConfig{Type: "slug", UniqueWithin: "tags"}, true},
+		{"already unique", FieldConfig{Type: "slug", Unique: true, UniqueWithin: "author_id"}, true},
+	}
+
+	for _, tt := range tests {
+		err := validateModel("Post", ModelConfig{Fields: fields(tt.slug)})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
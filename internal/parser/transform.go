@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TransformFunc rewrites a field value before it is validated and stored.
+// Returning an error rejects the write.
+type TransformFunc func(value any) (any, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]TransformFunc{
+		"trim":   stringTransform(strings.TrimSpace),
+		"lower":  stringTransform(strings.ToLower),
+		"upper":  stringTransform(strings.ToUpper),
+		"digits": stringTransform(keepDigits),
+		"sha256": stringTransform(func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		}),
+	}
+)
+
+// RegisterTransform makes fn available to fields as `transform: [name]`.
+// Register custom transforms before loading the config that uses them.
+func RegisterTransform(name string, fn TransformFunc) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = fn
+}
+
+func LookupTransform(name string) (TransformFunc, bool) {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	fn, ok := transforms[name]
+	return fn, ok
+}
+
+// ApplyTransforms runs the named transforms over value in order.
+func ApplyTransforms(names []string, value any) (any, error) {
+	for _, name := range names {
+		fn, ok := LookupTransform(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown transform '%s'", name)
+		}
+		var err error
+		if value, err = fn(value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// stringTransform applies fn to string values and leaves others untouched.
+func stringTransform(fn func(string) string) TransformFunc {
+	return func(value any) (any, error) {
+		if s, ok := value.(string); ok {
+			return fn(s), nil
+		}
+		return value, nil
+	}
+}
+
+func keepDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -1,13 +1,28 @@
 package parser
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
 
 type Config struct {
-	App      AppConfig              `yaml:"app"`
-	Database DatabaseConfig         `yaml:"database"`
-	Server   ServerConfig           `yaml:"server"`
-	UI       UIConfig               `yaml:"ui"`
-	Models   map[string]ModelConfig `yaml:"models"`
+	App         AppConfig              `yaml:"app"`
+	Database    DatabaseConfig         `yaml:"database"`
+	Server      ServerConfig           `yaml:"server"`
+	UI          UIConfig               `yaml:"ui"`
+	Conventions ConventionsConfig      `yaml:"conventions"`
+	Models      map[string]ModelConfig `yaml:"models"`
+	// Seed holds records per model that are inserted on startup into
+	// tables that are still empty, e.g. the output of `yamlforge dump`.
+	Seed map[string][]map[string]any `yaml:"seed"`
+}
+
+type ConventionsConfig struct {
+	EmailUnique bool `yaml:"email_unique"`
+	Timestamps  bool `yaml:"timestamps"`
 }
 
 type AppConfig struct {
@@ -17,16 +32,90 @@ type AppConfig struct {
 }
 
 type DatabaseConfig struct {
-	Type       string `yaml:"type"`
-	Path       string `yaml:"path"`
-	Connection string `yaml:"connection"`
+	Type         string   `yaml:"type"`
+	Path         string   `yaml:"path"`
+	Connection   string   `yaml:"connection"`
+	ReadReplicas []string `yaml:"read_replicas"`
+	MaxInValues  int      `yaml:"max_in_values"`
+	LogQueries   bool     `yaml:"log_queries"`
+	AutoIndex    bool     `yaml:"auto_index"`
+	// HealthCheck is how often a background check pings the database,
+	// e.g. "10s"; /readyz reports its result. Empty disables the check.
+	HealthCheck string `yaml:"health_check"`
 }
 
 type ServerConfig struct {
-	Port int        `yaml:"port"`
-	Host string     `yaml:"host"`
-	CORS CORSConfig `yaml:"cors"`
-	Auth AuthConfig `yaml:"auth"`
+	Port            int               `yaml:"port"`
+	Host            string            `yaml:"host"`
+	CORS            CORSConfig        `yaml:"cors"`
+	Auth            AuthConfig        `yaml:"auth"`
+	StrictFields    bool              `yaml:"strict_fields"`
+	StringIDs       bool              `yaml:"string_ids"`
+	BaseURL         string            `yaml:"base_url"`
+	BulkMaxItems    int               `yaml:"bulk_max_items"`
+	PrettyJSON      bool              `yaml:"pretty_json"`
+	MaxIncludeDepth int               `yaml:"max_include_depth"`
+	DedupeReads     bool              `yaml:"dedupe_reads"`
+	OrderedFields   bool              `yaml:"ordered_fields"`
+	Compression     CompressionConfig `yaml:"compression"`
+	Debug           bool              `yaml:"debug"`
+	PrintRoutes     *bool             `yaml:"print_routes"`
+	RedisURL        string            `yaml:"redis_url"`
+	Audit           AuditConfig       `yaml:"audit"`
+	CoerceTypes     bool              `yaml:"coerce_types"`
+	Pagination      PaginationConfig  `yaml:"pagination"`
+	HeadRequests    bool              `yaml:"head_requests"`
+	OptionsRequests bool              `yaml:"options_requests"`
+	StreamLists     bool              `yaml:"stream_lists"`
+	// StrictContentType rejects writes that send no Content-Type, instead
+	// of reading their body as JSON.
+	StrictContentType bool `yaml:"strict_content_type"`
+}
+
+// RoutesPrinted reports whether the startup route table is logged. Unless
+// print_routes says otherwise it is only printed in debug mode.
+func (c ServerConfig) RoutesPrinted() bool {
+	if c.PrintRoutes != nil {
+		return *c.PrintRoutes
+	}
+	return c.Debug
+}
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// PaginationConfig sets the list page size used when a request doesn't
+// ask for one and the largest page_size a request may ask for.
+type PaginationConfig struct {
+	DefaultPageSize int `yaml:"default_page_size"`
+	MaxPageSize     int `yaml:"max_page_size"`
+}
+
+func (p PaginationConfig) Limits() (defaultSize, maxSize int) {
+	defaultSize, maxSize = p.DefaultPageSize, p.MaxPageSize
+	if defaultSize <= 0 {
+		defaultSize = DefaultPageSize
+	}
+	if maxSize <= 0 {
+		maxSize = MaxPageSize
+	}
+	return defaultSize, maxSize
+}
+
+// AuditConfig records every API write. Entries older than Retention are
+// pruned periodically; an empty Retention keeps them forever.
+type AuditConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Retention string `yaml:"retention"`
+}
+
+// CompressionConfig gzips responses for clients that accept it. Responses
+// shorter than MinSize bytes are sent uncompressed.
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	MinSize int  `yaml:"min_size"`
 }
 
 type CORSConfig struct {
@@ -35,18 +124,60 @@ type CORSConfig struct {
 }
 
 type AuthConfig struct {
-	Type    string       `yaml:"type"`
-	Secret  string       `yaml:"secret"`
-	Expires string       `yaml:"expires"`
-	Users   []UserConfig `yaml:"users"`
+	Type            string         `yaml:"type"`
+	Secret          string         `yaml:"secret"`
+	Expires         string         `yaml:"expires"`
+	Users           []UserConfig   `yaml:"users"`
+	APIUnauthorized string         `yaml:"api_unauthorized"`
+	RequireSecret   bool           `yaml:"require_secret"`
+	CSRF            bool           `yaml:"csrf"`
+	Captcha         *CaptchaConfig `yaml:"captcha"`
+	// DefaultAdmin creates an "admin" user with a generated one-time
+	// password when the auth tables are empty and no users are configured.
+	DefaultAdmin bool `yaml:"default_admin"`
+	// EmailVerification mails a confirmation token to users created at
+	// runtime; they stay unverified until the token is posted back.
+	EmailVerification *EmailVerificationConfig `yaml:"email_verification"`
+	// RequireVerified rejects writes from users who have not verified
+	// their email address yet.
+	RequireVerified bool `yaml:"require_verified"`
+}
+
+// EmailVerificationConfig says how verification mails are sent. Without
+// SMTPAddr the verification link is logged instead of mailed.
+type EmailVerificationConfig struct {
+	SMTPAddr  string `yaml:"smtp_addr"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
+	From      string `yaml:"from"`
+	VerifyURL string `yaml:"verify_url"`
+	Expires   string `yaml:"expires"`
+}
+
+// CaptchaConfig gates logins from an IP behind a CAPTCHA once it has
+// failed After times within Window. Tokens are checked against VerifyURL
+// using the siteverify protocol shared by reCAPTCHA, hCaptcha and Turnstile.
+// SiteKey is the public key the login page renders the widget with.
+type CaptchaConfig struct {
+	After     int    `yaml:"after"`
+	Window    string `yaml:"window"`
+	VerifyURL string `yaml:"verify_url"`
+	Secret    string `yaml:"secret"`
+	SiteKey   string `yaml:"site_key"`
 }
 
+const (
+	APIUnauthorizedJSON = "json"
+	APIUnauthorizedPage = "401page"
+)
+
 type UserConfig struct {
 	Username    string                      `yaml:"username"`
 	Password    string                      `yaml:"password"`
 	Email       string                      `yaml:"email"`
 	Role        string                      `yaml:"role"`
 	Active      bool                        `yaml:"active"`
+	APIKey      string                      `yaml:"api_key"`
 	Permissions map[string]EntityPermission `yaml:"permissions"`
 }
 
@@ -56,35 +187,185 @@ type EntityPermission struct {
 }
 
 type UIConfig struct {
-	Theme  string `yaml:"theme"`
-	Title  string `yaml:"title"`
-	Logo   string `yaml:"logo"`
-	Layout string `yaml:"layout"`
+	Theme     string            `yaml:"theme"`
+	Title     string            `yaml:"title"`
+	Logo      string            `yaml:"logo"`
+	Layout    string            `yaml:"layout"`
+	Locale    string            `yaml:"locale"`
+	Dashboard UIDashboardConfig `yaml:"dashboard"`
+}
+
+type UIDashboardConfig struct {
+	Recent  int              `yaml:"recent"`
+	Widgets []UIWidgetConfig `yaml:"widgets"`
+}
+
+type UIWidgetConfig struct {
+	Title     string         `yaml:"title"`
+	Model     string         `yaml:"model"`
+	Aggregate string         `yaml:"aggregate"`
+	Field     string         `yaml:"field"`
+	Filters   map[string]any `yaml:"filters"`
 }
 
 type ModelConfig struct {
-	Fields      map[string]FieldConfig `yaml:"fields"`
-	UI          *UIModelConfig         `yaml:"ui"`
-	Permissions *PermissionsConfig     `yaml:"permissions"`
+	Fields        map[string]FieldConfig  `yaml:"fields"`
+	UI            *UIModelConfig          `yaml:"ui"`
+	Permissions   *PermissionsConfig      `yaml:"permissions"`
+	SoftDelete    bool                    `yaml:"soft_delete"`
+	RestoreWindow string                  `yaml:"restore_window"`
+	Description   string                  `yaml:"description"`
+	Tag           string                  `yaml:"tag"`
+	API           *ModelAPIConfig         `yaml:"api"`
+	DisplayName   string                  `yaml:"display_name"`
+	Timestamps    *bool                   `yaml:"timestamps"`
+	Webhooks      []WebhookConfig         `yaml:"webhooks"`
+	OnChange      string                  `yaml:"on_change"`
+	RequireOneOf  [][]string              `yaml:"require_one_of"`
+	Hooks         map[string][]HookConfig `yaml:"hooks"`
+	Indexes       []IndexConfig           `yaml:"indexes"`
+	Primary       []string                `yaml:"primary"`
+	Deprecated    bool                    `yaml:"deprecated"`
+	DefaultFilter []FilterConfig          `yaml:"default_filter"`
+	FilterBypass  []string                `yaml:"filter_bypass"`
+	FieldOrder    []string                `yaml:"-"`
+}
+
+// FieldOrder keeps the order fields are declared in the YAML, which the
+// fields map would otherwise lose.
+func (m *ModelConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain ModelConfig
+	if err := value.Decode((*plain)(m)); err != nil {
+		return err
+	}
+
+	m.FieldOrder = nil
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value != "fields" || value.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		fields := value.Content[i+1].Content
+		for j := 0; j+1 < len(fields); j += 2 {
+			m.FieldOrder = append(m.FieldOrder, fields[j].Value)
+		}
+	}
+	return nil
+}
+
+// FieldNames lists fields in declaration order; fields added without a
+// recorded order (e.g. configs built in code) follow alphabetically.
+func (m ModelConfig) FieldNames() []string {
+	names := make([]string, 0, len(m.Fields))
+	seen := make(map[string]bool, len(m.Fields))
+	for _, name := range m.FieldOrder {
+		if _, ok := m.Fields[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+
+	var rest []string
+	for name := range m.Fields {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(names, rest...)
+}
+
+type ModelAPIConfig struct {
+	RateLimit  string `yaml:"rate_limit"`
+	DailyQuota int    `yaml:"daily_quota"`
+	CountLimit int    `yaml:"count_limit"`
+	// ExportRateLimit throttles the export endpoint on its own, apart from
+	// the CRUD traffic counted by RateLimit.
+	ExportRateLimit string `yaml:"export_rate_limit"`
+	// Versions mounts the model again under /api/<version>/, exposing only
+	// the listed fields there, e.g. {v1: [name], v2: [name, email]}.
+	Versions map[string][]string `yaml:"versions"`
+}
+
+// WebhookConfig posts a model's writes to URL. Events defaults to every
+// write and Fields, when set, limits the record keys sent.
+type WebhookConfig struct {
+	URL    string   `yaml:"url"`
+	Events []string `yaml:"events"`
+	Fields []string `yaml:"fields"`
 }
 
+// Normalize modes canonicalize a text value before it is validated and stored.
+const (
+	NormalizeLower = "lower"
+	NormalizeUpper = "upper"
+	NormalizeTrim  = "trim"
+)
+
+const (
+	WebhookCreate  = "create"
+	WebhookUpdate  = "update"
+	WebhookDelete  = "delete"
+	WebhookRestore = "restore"
+)
+
 type FieldConfig struct {
-	Type       string   `yaml:"type"`
-	Primary    bool     `yaml:"primary"`
-	Required   bool     `yaml:"required"`
-	Unique     bool     `yaml:"unique"`
-	Min        int      `yaml:"min"`
-	Max        int      `yaml:"max"`
-	Pattern    string   `yaml:"pattern"`
-	Options    []string `yaml:"options"`
-	Default    any      `yaml:"default"`
-	AutoNow    bool     `yaml:"auto_now"`
-	AutoNowAdd bool     `yaml:"auto_now_add"`
-	Nullable   bool     `yaml:"nullable"`
-	Index      bool     `yaml:"index"`
-	To         string   `yaml:"to"`
-	OnDelete   string   `yaml:"on_delete"`
-	Items      string   `yaml:"items"`
+	Type          string                 `yaml:"type"`
+	Primary       bool                   `yaml:"primary"`
+	Required      bool                   `yaml:"required"`
+	Unique        bool                   `yaml:"unique"`
+	UniqueSet     bool                   `yaml:"-"`
+	IgnoreCase    bool                   `yaml:"-"`
+	Min           int                    `yaml:"min"`
+	Max           int                    `yaml:"max"`
+	Pattern       string                 `yaml:"pattern"`
+	Options       []string               `yaml:"options"`
+	Default       any                    `yaml:"default"`
+	AutoNow       bool                   `yaml:"auto_now"`
+	AutoNowAdd    bool                   `yaml:"auto_now_add"`
+	Decimal       bool                   `yaml:"decimal"`
+	Precision     int                    `yaml:"precision"`
+	Scale         int                    `yaml:"scale"`
+	MaxChangePct  float64                `yaml:"max_change_pct"`
+	Nullable      bool                   `yaml:"nullable"`
+	AllowEmpty    bool                   `yaml:"allow_empty"`
+	Index         bool                   `yaml:"index"`
+	To            string                 `yaml:"to"`
+	OnDelete      string                 `yaml:"on_delete"`
+	RelationType  string                 `yaml:"relation_type"`
+	Display       string                 `yaml:"display"`
+	Label         string                 `yaml:"label"`
+	Help          string                 `yaml:"help"`
+	Placeholder   string                 `yaml:"placeholder"`
+	UniqueMessage string                 `yaml:"unique_message"`
+	UniqueWithin  string                 `yaml:"unique_within"`
+	Mask          string                 `yaml:"mask"`
+	Normalize     string                 `yaml:"normalize"`
+	Transform     []string               `yaml:"transform"`
+	ReadRoles     []string               `yaml:"read_roles"`
+	KeyType       string                 `yaml:"key_type"`
+	Items         string                 `yaml:"items"`
+	Properties    map[string]FieldConfig `yaml:"properties"`
+	List          *bool                  `yaml:"list"`
+	Detail        *bool                  `yaml:"detail"`
+	Form          *bool                  `yaml:"form"`
+	Deprecated    bool                   `yaml:"deprecated"`
+}
+
+// UniqueSet records an explicit `unique:` key so `unique: false` can opt a
+// field out of conventions that would otherwise make it unique.
+func (f *FieldConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain FieldConfig
+	if err := value.Decode((*plain)(f)); err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value == "unique" {
+			f.UniqueSet = true
+		}
+	}
+	return nil
 }
 
 type UIModelConfig struct {
@@ -93,12 +374,87 @@ type UIModelConfig struct {
 }
 
 type UIListConfig struct {
-	Columns    []string `yaml:"columns"`
-	Sortable   []string `yaml:"sortable"`
-	Searchable []string `yaml:"searchable"`
+	Columns       []string       `yaml:"columns"`
+	Sortable      []string       `yaml:"sortable"`
+	Searchable    []string       `yaml:"searchable"`
+	SearchWeights map[string]int `yaml:"-"`
+	Filterable    []string       `yaml:"filterable"`
+}
+
+type searchableEntry struct {
+	Field  string `yaml:"field"`
+	Weight int    `yaml:"weight"`
+}
+
+func (c *UIListConfig) UnmarshalYAML(value *yaml.Node) error {
+	var raw struct {
+		Columns    []string    `yaml:"columns"`
+		Sortable   []string    `yaml:"sortable"`
+		Searchable []yaml.Node `yaml:"searchable"`
+		Filterable []string    `yaml:"filterable"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	c.Columns = raw.Columns
+	c.Sortable = raw.Sortable
+	c.Filterable = raw.Filterable
+	c.Searchable = nil
+	c.SearchWeights = nil
+
+	for _, node := range raw.Searchable {
+		if node.Kind == yaml.ScalarNode {
+			c.Searchable = append(c.Searchable, node.Value)
+			continue
+		}
+
+		var entry searchableEntry
+		if err := node.Decode(&entry); err != nil {
+			return err
+		}
+		if entry.Field == "" {
+			return fmt.Errorf("searchable entry at line %d is missing 'field'", node.Line)
+		}
+		c.Searchable = append(c.Searchable, entry.Field)
+		if entry.Weight != 0 {
+			if c.SearchWeights == nil {
+				c.SearchWeights = make(map[string]int)
+			}
+			c.SearchWeights[entry.Field] = entry.Weight
+		}
+	}
+
+	return nil
+}
+
+func (c UIListConfig) MarshalYAML() (any, error) {
+	searchable := make([]any, 0, len(c.Searchable))
+	for _, field := range c.Searchable {
+		if weight, ok := c.SearchWeights[field]; ok {
+			searchable = append(searchable, searchableEntry{Field: field, Weight: weight})
+		} else {
+			searchable = append(searchable, field)
+		}
+	}
+
+	return struct {
+		Columns    []string `yaml:"columns"`
+		Sortable   []string `yaml:"sortable"`
+		Searchable []any    `yaml:"searchable"`
+		Filterable []string `yaml:"filterable,omitempty"`
+	}{c.Columns, c.Sortable, searchable, c.Filterable}, nil
 }
 
 type UIFormConfig struct {
+	Fields         []string              `yaml:"fields"`
+	Sections       []UIFormSectionConfig `yaml:"sections"`
+	OnSave         string                `yaml:"on_save"`
+	SuccessMessage string                `yaml:"success_message"`
+}
+
+type UIFormSectionConfig struct {
+	Title  string   `yaml:"title"`
 	Fields []string `yaml:"fields"`
 }
 
@@ -137,6 +493,7 @@ const (
 	FieldTypeIP       FieldType = "ip"
 	FieldTypeUUID     FieldType = "uuid"
 	FieldTypeDuration FieldType = "duration"
+	FieldTypeObject   FieldType = "object"
 )
 
 func (f FieldType) String() string {
@@ -151,6 +508,17 @@ func (f FieldType) IsValid() bool {
 		FieldTypeEnum, FieldTypeColor, FieldTypeFile, FieldTypeImage,
 		FieldTypeMarkdown, FieldTypeJSON, FieldTypeArray, FieldTypeRelation,
 		FieldTypeCurrency, FieldTypeLocation, FieldTypeIP, FieldTypeUUID,
+		FieldTypeDuration, FieldTypeObject:
+		return true
+	}
+	return false
+}
+
+func (f FieldType) IsString() bool {
+	switch f {
+	case FieldTypeText, FieldTypeEmail, FieldTypePassword, FieldTypePhone,
+		FieldTypeURL, FieldTypeSlug, FieldTypeEnum, FieldTypeColor,
+		FieldTypeMarkdown, FieldTypeCurrency, FieldTypeIP, FieldTypeUUID,
 		FieldTypeDuration:
 		return true
 	}
@@ -176,7 +544,7 @@ func (f FieldType) SQLType() string {
 		return "TEXT"
 	case FieldTypeRelation:
 		return "INTEGER"
-	case FieldTypeLocation:
+	case FieldTypeLocation, FieldTypeObject:
 		return "TEXT"
 	default:
 		return "TEXT"
@@ -192,32 +560,96 @@ func (e ValidationError) Error() string {
 	return e.Field + ": " + e.Message
 }
 
+const SoftDeleteField = "deleted_at"
+
 type Model struct {
-	Name        string
-	Fields      []Field
-	Permissions Permissions
-	UI          UIModel
+	Name          string
+	Fields        []Field
+	Permissions   Permissions
+	UI            UIModel
+	SoftDelete    bool
+	RestoreWindow time.Duration
+	Description   string
+	Tag           string
+	RateLimit     RateLimit
+	DailyQuota    int
+	CountLimit    int
+	Versions      map[string][]string
+	DisplayName   string
+	Webhooks      []WebhookConfig
+	RequireOneOf  [][]string
+	Hooks         map[string][]HookConfig
+	Indexes       []IndexConfig
+	// PrimaryKey holds the fields of a composite key; models keyed by an
+	// id field leave it empty.
+	PrimaryKey []string
+	// OnChange is a cache purge endpoint told the model and id of every
+	// write, so a CDN can drop what it holds for the record.
+	OnChange   string
+	Deprecated bool
+	// ExportRateLimit, when set, replaces RateLimit for the export
+	// endpoint, which is counted separately either way.
+	ExportRateLimit RateLimit
+	// DefaultFilter is applied to every read of the model, except by users
+	// holding one of the FilterBypass roles.
+	DefaultFilter []Filter
+	FilterBypass  []string
+}
+
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
 }
 
 type Field struct {
-	Name       string
-	Type       FieldType
-	Primary    bool
-	Required   bool
-	Unique     bool
-	Min        *int
-	Max        *int
-	Pattern    string
-	Options    []string
-	Default    any
-	AutoNow    bool
-	AutoNowAdd bool
-	Nullable   bool
-	Index      bool
-	RelatedTo  string
-	OnDelete   string
-	ArrayType  string
-}
+	Name          string
+	Type          FieldType
+	Primary       bool
+	Required      bool
+	Unique        bool
+	Min           *int
+	Max           *int
+	Pattern       string
+	Options       []string
+	Default       any
+	AutoNow       bool
+	AutoNowAdd    bool
+	Decimal       bool
+	Precision     int
+	Scale         int
+	MaxChangePct  float64
+	Nullable      bool
+	AllowEmpty    bool
+	Index         bool
+	RelatedTo     string
+	OnDelete      string
+	RelationType  string
+	DisplayField  string
+	DisplayName   string
+	Label         string
+	Help          string
+	Placeholder   string
+	UniqueMessage string
+	UniqueWithin  string
+	IgnoreCase    bool
+	Mask          string
+	Normalize     string
+	Transform     []string
+	ReadRoles     []string
+	KeyType       KeyType
+	ArrayType     string
+	Properties    []Field
+	HideInList    bool
+	HideInDetail  bool
+	HideInForm    bool
+	Deprecated    bool
+}
+
+func (f Field) IsOneToOne() bool {
+	return f.Type == FieldTypeRelation && RelationKind(f.RelationType) == RelationOneToOne
+}
+
+const PermissionPublic = "public"
 
 type Permissions struct {
 	Create string
@@ -232,12 +664,22 @@ type UIModel struct {
 }
 
 type UIList struct {
-	Columns    []string
-	Sortable   []string
-	Searchable []string
+	Columns       []string
+	Sortable      []string
+	Searchable    []string
+	SearchWeights map[string]int
+	Filterable    []string
 }
 
 type UIForm struct {
+	Fields         []string
+	Sections       []UIFormSection
+	OnSave         string
+	SuccessMessage string
+}
+
+type UIFormSection struct {
+	Title  string
 	Fields []string
 }
 
@@ -259,8 +701,11 @@ func DefaultConfig() *Config {
 				Origins: []string{"*"},
 			},
 			Auth: AuthConfig{
-				Type: "none",
+				Type:         "none",
+				DefaultAdmin: true,
 			},
+			HeadRequests:    true,
+			OptionsRequests: true,
 		},
 		UI: UIConfig{
 			Theme:  "light",
@@ -309,6 +754,33 @@ const (
 	RelationSetNull  RelationType = "set_null"
 )
 
+type RelationKind string
+
+const (
+	RelationManyToOne RelationKind = "many_to_one"
+	RelationOneToOne  RelationKind = "one_to_one"
+)
+
+type KeyType string
+
+const (
+	KeyTypeInt  KeyType = "int"
+	KeyTypeUUID KeyType = "uuid"
+	KeyTypeULID KeyType = "ulid"
+)
+
+func (k KeyType) IsValid() bool {
+	switch k {
+	case KeyTypeInt, KeyTypeUUID, KeyTypeULID:
+		return true
+	}
+	return false
+}
+
+func (k KeyType) IsString() bool {
+	return k == KeyTypeUUID || k == KeyTypeULID
+}
+
 type RequestContext struct {
 	UserID   string
 	Role     string
@@ -341,11 +813,28 @@ type APIResponse struct {
 	Meta    *Meta  `json:"meta,omitempty"`
 }
 
+// Meta describes a page of results. The totals are nil when the count was
+// skipped, and Estimated is set when they stop at the model's count_limit.
 type Meta struct {
-	Page       int   `json:"page"`
-	PageSize   int   `json:"page_size"`
-	TotalCount int64 `json:"total_count"`
-	TotalPages int   `json:"total_pages"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	TotalCount *int64 `json:"total_count"`
+	TotalPages *int   `json:"total_pages"`
+	Estimated  bool   `json:"estimated,omitempty"`
+}
+
+// NewMeta fills in the totals for a page of pageSize records out of total.
+func NewMeta(page, pageSize int, total int64) *Meta {
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+	return &Meta{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: &total,
+		TotalPages: &totalPages,
+	}
 }
 
 type ValidationRule interface {
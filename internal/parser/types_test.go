@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"testing"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestFieldType_String(t *testing.T) {
@@ -268,20 +270,15 @@ func TestAPIResponse(t *testing.T) {
 		Success: true,
 		Data:    map[string]interface{}{"id": 1, "name": "test"},
 		Error:   "",
-		Meta: &Meta{
-			Page:       1,
-			PageSize:   20,
-			TotalCount: 100,
-			TotalPages: 5,
-		},
+		Meta:    NewMeta(1, 20, 100),
 	}
 
 	if !response.Success {
 		t.Error("Expected Success to be true")
 	}
 
-	if response.Meta.TotalPages != 5 {
-		t.Errorf("Expected TotalPages to be 5, got %d", response.Meta.TotalPages)
+	if *response.Meta.TotalPages != 5 {
+		t.Errorf("Expected TotalPages to be 5, got %d", *response.Meta.TotalPages)
 	}
 }
 
@@ -639,4 +636,59 @@ func TestTimestampStructure(t *testing.T) {
 	if parsed.UpdatedAt.IsZero() {
 		t.Error("Expected updated_at to be set")
 	}
-}
\ No newline at end of file
+}
+
+func TestUIListConfig_WeightedSearchable(t *testing.T) {
+	data := `
+columns: [name]
+searchable:
+  - field: name
+    weight: 2
+  - bio
+`
+
+	var list UIListConfig
+	if err := yaml.Unmarshal([]byte(data), &list); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if len(list.Searchable) != 2 || list.Searchable[0] != "name" || list.Searchable[1] != "bio" {
+		t.Errorf("Expected searchable [name bio], got %v", list.Searchable)
+	}
+	if list.SearchWeights["name"] != 2 {
+		t.Errorf("Expected name weight 2, got %v", list.SearchWeights)
+	}
+	if _, ok := list.SearchWeights["bio"]; ok {
+		t.Error("Expected no weight for plain entries")
+	}
+
+	out, err := yaml.Marshal(list)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var roundTrip UIListConfig
+	if err := yaml.Unmarshal(out, &roundTrip); err != nil {
+		t.Fatalf("Failed to unmarshal round trip: %v", err)
+	}
+	if roundTrip.SearchWeights["name"] != 2 || len(roundTrip.Searchable) != 2 {
+		t.Errorf("Expected weights to survive a round trip, got %+v", roundTrip)
+	}
+}
+
+func TestServerConfig_RoutesPrinted(t *testing.T) {
+	for data, want := range map[string]bool{
+		"port: 8080":                       false,
+		"debug: true":                      true,
+		"debug: true\nprint_routes: false": false,
+		"print_routes: true":               true,
+	} {
+		server := DefaultConfig().Server
+		if err := yaml.Unmarshal([]byte(data), &server); err != nil {
+			t.Fatalf("Failed to unmarshal %q: %v", data, err)
+		}
+		if got := server.RoutesPrinted(); got != want {
+			t.Errorf("%q: expected RoutesPrinted %v, got %v", data, want, got)
+		}
+	}
+}
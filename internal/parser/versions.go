@@ -0,0 +1,21 @@
+package parser
+
+import (
+	"regexp"
+	"slices"
+)
+
+// Versions become a path segment, /api/v1/user, so they must not collide
+// with model routes or need escaping.
+var versionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// VersionExposes reports whether field is part of the given API version of
+// the model. Primary keys are always exposed, since clients need them to
+// address records.
+func (m *Model) VersionExposes(version string, field Field) bool {
+	fields, ok := m.Versions[version]
+	if !ok {
+		return true
+	}
+	return field.Primary || slices.Contains(fields, field.Name) || slices.Contains(m.PrimaryKey, field.Name)
+}
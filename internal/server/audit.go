@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/auth"
+	"github.com/yamlforge/yamlforge/internal/database"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const (
+	auditPruneInterval = time.Hour
+	defaultAuditLimit  = 100
+	maxAuditLimit      = 1000
+)
+
+func (s *Server) recordAudit(r *http.Request, modelName, action string, id any) {
+	if !s.config.Server.Audit.Enabled {
+		return
+	}
+	store, ok := s.db.(database.AuditLog)
+	if !ok {
+		return
+	}
+
+	entry := database.AuditEntry{Model: modelName, RecordID: fmt.Sprint(id), Action: action}
+	if user, ok := r.Context().Value("user").(*auth.User); ok {
+		entry.User = user.Username
+	}
+	if err := store.RecordAudit(entry); err != nil {
+		log.Printf("failed to record audit entry: %v", err)
+	}
+}
+
+// handleAudit lists recent audit entries, newest first. With auth enabled
+// only admins may read it.
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if s.authManager != nil && s.authManager.IsEnabled() {
+		user, ok := r.Context().Value("user").(*auth.User)
+		if !ok || user.Role != "admin" {
+			s.sendJSON(w, http.StatusForbidden, map[string]any{
+				"success": false,
+				"error":   "Only admins can read the audit log",
+			})
+			return
+		}
+	}
+
+	store, ok := s.db.(database.AuditLog)
+	if !ok {
+		s.sendJSON(w, http.StatusNotImplemented, map[string]any{
+			"success": false,
+			"error":   "The database does not support an audit log",
+		})
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		s.sendJSON(w, http.StatusBadRequest, map[string]any{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	entries, err := store.QueryAudit(filter)
+	if err != nil {
+		s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, parser.APIResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
+
+func parseAuditFilter(r *http.Request) (database.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := database.AuditFilter{
+		Model: query.Get("model"),
+		User:  query.Get("user"),
+		Limit: defaultAuditLimit,
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxAuditLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxAuditLimit)
+		}
+		filter.Limit = n
+	}
+
+	var err error
+	if filter.Since, err = parseAuditTime(query.Get("since")); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.Until, err = parseAuditTime(query.Get("until")); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+	return filter, nil
+}
+
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// startAuditPruning deletes entries older than server.audit.retention now
+// and then every auditPruneInterval, until the server shuts down. A loop
+// started for an earlier configuration is stopped first.
+func (s *Server) startAuditPruning() {
+	s.pruner.restart(s.ctx, s.auditPruning())
+}
+
+// auditPruning returns the pruning loop for the current configuration, or
+// nil when nothing is to be pruned.
+func (s *Server) auditPruning() func(ctx context.Context) {
+	if !s.config.Server.Audit.Enabled {
+		return nil
+	}
+	retention, err := time.ParseDuration(s.config.Server.Audit.Retention)
+	if err != nil || retention <= 0 {
+		return nil
+	}
+	store, ok := s.db.(database.AuditLog)
+	if !ok {
+		return nil
+	}
+
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(auditPruneInterval)
+		defer ticker.Stop()
+		for {
+			pruneAudit(store, retention)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+func pruneAudit(store database.AuditLog, retention time.Duration) {
+	pruned, err := store.PruneAudit(time.Now().Add(-retention))
+	if err != nil {
+		log.Printf("failed to prune audit log: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("Pruned %d audit entries older than %s", pruned, retention)
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// backgroundLoop owns a goroutine that runs until it is replaced, halted
+// or the server shuts down. It is shared across reloads, so a reload can
+// replace the goroutine with one built from the new configuration.
+type backgroundLoop struct {
+	mu   sync.Mutex
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// restart stops the running goroutine, if any, and waits for it to return.
+// A non-nil run is then started with a context derived from parent.
+func (l *backgroundLoop) restart(parent context.Context, run func(ctx context.Context)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stop != nil {
+		l.stop()
+		<-l.done
+		l.stop, l.done = nil, nil
+	}
+	if run == nil {
+		return
+	}
+
+	ctx, stop := context.WithCancel(parent)
+	done := make(chan struct{})
+	l.stop, l.done = stop, done
+	go func() {
+		defer close(done)
+		run(ctx)
+	}()
+}
+
+func (l *backgroundLoop) halt() {
+	l.restart(nil, nil)
+}
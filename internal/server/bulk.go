@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/yamlforge/yamlforge/internal/api"
+	"github.com/yamlforge/yamlforge/internal/auth"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// handleAPIBulk creates or deletes several records in one transaction.
+// Each item goes through the same checks, hooks and notifications as a
+// single create or delete, and created records are returned the way
+// handleAPICreate returns one.
+func (s *Server) handleAPIBulk(modelName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.authManager != nil && s.authManager.IsEnabled() {
+			user, ok := r.Context().Value("user").(*auth.User)
+			if !ok || !s.authManager.CheckPermission(user.Username, modelName, true) {
+				s.sendJSON(w, http.StatusForbidden, map[string]any{
+					"success": false,
+					"error":   "You don't have permission to write to this resource",
+				})
+				return
+			}
+		}
+
+		if !s.supportedBodyType(r) {
+			s.sendUnsupportedMediaType(w)
+			return
+		}
+
+		limit := api.BulkMaxItems(s.config)
+		request, err := s.decodeBulk(modelName, r, limit)
+		if errors.Is(err, api.ErrBulkTooLarge) {
+			s.sendJSON(w, http.StatusRequestEntityTooLarge, map[string]any{
+				"success": false,
+				"error":   fmt.Sprintf("bulk requests are limited to %d items", limit),
+			})
+			return
+		}
+		if err != nil {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
+				"success": false,
+				"error":   invalidBodyMessage(r),
+			})
+			return
+		}
+
+		switch request.Operation {
+		case "create":
+			s.bulkCreate(w, r, modelName, request.Data)
+		case "delete":
+			s.bulkDelete(w, r, modelName, request.IDs)
+		default:
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
+				"success": false,
+				"error":   "Invalid operation",
+			})
+		}
+	}
+}
+
+func (s *Server) decodeBulk(modelName string, r *http.Request, limit int) (*api.BulkRequest, error) {
+	var request *api.BulkRequest
+	var err error
+	if isXMLRequest(r) {
+		request, err = decodeXMLBulk(r.Body, limit)
+	} else {
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		request, err = api.DecodeBulkRequest(decoder, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range request.Data {
+		if item == nil {
+			return nil, fmt.Errorf("item %d is not an object", i)
+		}
+		s.decodeValues(modelName, r, item)
+	}
+	for i, id := range request.IDs {
+		request.IDs[i] = normalizeNumbers(id)
+	}
+	return request, nil
+}
+
+func (s *Server) bulkCreate(w http.ResponseWriter, r *http.Request, modelName string, items []map[string]any) {
+	for i, item := range items {
+		if rejected := s.prepareCreate(r, modelName, item); rejected != nil {
+			rejected.body["error"] = fmt.Sprintf("item %d: %v", i, rejected.body["error"])
+			s.sendJSON(w, rejected.status, rejected.body)
+			return
+		}
+	}
+
+	ids, records, err := s.createRecords(r, modelName, items)
+	if err != nil {
+		s.sendWriteError(w, err)
+		return
+	}
+
+	for i, id := range ids {
+		s.recordAudit(r, modelName, "create", id)
+		s.notifyWebhooks(modelName, parser.WebhookCreate, records[i])
+		s.purgeCache(modelName, parser.WebhookCreate, id)
+	}
+
+	s.sendJSON(w, http.StatusCreated, parser.APIResponse{
+		Success: true,
+		Data:    s.orderRecords(modelName, s.encodeRecords(modelName, s.hideRestrictedFields(r, modelName, records...))),
+	})
+}
+
+func (s *Server) bulkDelete(w http.ResponseWriter, r *http.Request, modelName string, ids []any) {
+	for _, id := range ids {
+		if err := s.runHooks(modelName, parser.HookBeforeDelete, id, map[string]any{}); err != nil {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
+				"success": false,
+				"error":   fmt.Sprintf("id %v: %v", id, err),
+			})
+			return
+		}
+	}
+
+	if err := s.deleteRecords(r, modelName, ids); err != nil {
+		s.sendWriteError(w, err)
+		return
+	}
+
+	for _, id := range ids {
+		s.recordAudit(r, modelName, "delete", id)
+		s.notifyWebhooks(modelName, parser.WebhookDelete, map[string]any{"id": id})
+		s.purgeCache(modelName, parser.WebhookDelete, id)
+	}
+
+	s.sendJSON(w, http.StatusOK, parser.APIResponse{
+		Success: true,
+	})
+}
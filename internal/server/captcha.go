@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCaptchaAfter  = 3
+	defaultCaptchaWindow = 15 * time.Minute
+	captchaVerifyTimeout = 10 * time.Second
+)
+
+type failureCount struct {
+	count int
+	last  time.Time
+}
+
+// loginFailures counts failed logins per IP. A count is forgotten once no
+// failure has been seen for the configured window.
+type loginFailures struct {
+	mu     sync.Mutex
+	counts map[string]*failureCount
+	now    func() time.Time
+}
+
+func newLoginFailures() *loginFailures {
+	return &loginFailures{
+		counts: make(map[string]*failureCount),
+		now:    time.Now,
+	}
+}
+
+func (f *loginFailures) record(ip string, window time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	c, ok := f.counts[ip]
+	if !ok || now.Sub(c.last) > window {
+		c = &failureCount{}
+		f.counts[ip] = c
+	}
+	c.count++
+	c.last = now
+}
+
+func (f *loginFailures) get(ip string, window time.Duration) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.counts[ip]
+	if !ok {
+		return 0
+	}
+	if f.now().Sub(c.last) > window {
+		delete(f.counts, ip)
+		return 0
+	}
+	return c.count
+}
+
+func (f *loginFailures) reset(ip string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.counts, ip)
+}
+
+func (s *Server) captchaWindow() time.Duration {
+	if captcha := s.config.Server.Auth.Captcha; captcha != nil && captcha.Window != "" {
+		if d, err := time.ParseDuration(captcha.Window); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultCaptchaWindow
+}
+
+func (s *Server) captchaRequired(ip string) bool {
+	captcha := s.config.Server.Auth.Captcha
+	if captcha == nil {
+		return false
+	}
+	after := captcha.After
+	if after == 0 {
+		after = defaultCaptchaAfter
+	}
+	return s.failures.get(ip, s.captchaWindow()) >= after
+}
+
+// checkLoginCaptcha runs before credentials are checked. It writes the
+// response and returns false when the IP must solve a CAPTCHA and did not.
+func (s *Server) checkLoginCaptcha(w http.ResponseWriter, r *http.Request, ip, token string) bool {
+	if !s.captchaRequired(ip) {
+		return true
+	}
+
+	if token == "" {
+		s.sendJSON(w, http.StatusForbidden, map[string]any{
+			"success":          false,
+			"error":            "CAPTCHA required",
+			"captcha_required": true,
+		})
+		return false
+	}
+
+	ok, err := s.verifyCaptcha(r, token, ip)
+	if err != nil {
+		s.sendJSON(w, http.StatusBadGateway, map[string]any{
+			"success": false,
+			"error":   "Failed to verify CAPTCHA",
+		})
+		return false
+	}
+	if !ok {
+		s.sendJSON(w, http.StatusForbidden, map[string]any{
+			"success":          false,
+			"error":            "Invalid CAPTCHA",
+			"captcha_required": true,
+		})
+		return false
+	}
+	return true
+}
+
+func (s *Server) verifyCaptcha(r *http.Request, token, ip string) (bool, error) {
+	captcha := s.config.Server.Auth.Captcha
+	form := url.Values{
+		"secret":   {captcha.Secret},
+		"response": {token},
+		"remoteip": {ip},
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, captcha.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{Timeout: captchaVerifyTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha provider returned %s", resp.Status)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Success, nil
+}
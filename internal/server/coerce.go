@@ -0,0 +1,47 @@
+package server
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+var booleanStrings = map[string]bool{
+	"true": true, "1": true, "on": true, "yes": true,
+	"false": false, "0": false, "off": false, "no": false,
+}
+
+// coerceTypes converts the string spellings form encodings use into the
+// types the validator expects. Values it doesn't recognise are left for
+// validation to reject.
+func coerceTypes(fields []parser.Field, data map[string]any) {
+	for _, field := range fields {
+		if nested, ok := data[field.Name].(map[string]any); ok && field.Type == parser.FieldTypeObject {
+			coerceTypes(field.Properties, nested)
+			continue
+		}
+
+		raw, ok := data[field.Name].(string)
+		if !ok {
+			continue
+		}
+
+		raw = strings.TrimSpace(raw)
+		switch {
+		case field.Type == parser.FieldTypeBoolean:
+			if b, ok := booleanStrings[strings.ToLower(raw)]; ok {
+				data[field.Name] = b
+			}
+		case field.Type == parser.FieldTypeNumber && field.Decimal:
+			if f, err := strconv.ParseFloat(raw, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				data[field.Name] = f
+			}
+		case field.Type == parser.FieldTypeNumber:
+			if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				data[field.Name] = i
+			}
+		}
+	}
+}
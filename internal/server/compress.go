@@ -0,0 +1,116 @@
+package server
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// Below this many bytes gzip framing costs more than it saves.
+const defaultCompressMinSize = 1024
+
+// gzipResponseWriter buffers the start of a response and only switches to
+// gzip once it reaches minSize; smaller responses are written as they are.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+	status  int
+	buf     []byte
+	gz      *gzip.Writer
+	started bool
+}
+
+func (s *Server) compressionMiddleware(next http.Handler) http.Handler {
+	if !s.config.Server.Compression.Enabled {
+		return next
+	}
+	minSize := s.config.Server.Compression.MinSize
+	if minSize <= 0 {
+		minSize = defaultCompressMinSize
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, minSize: minSize, status: http.StatusOK}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if !w.started {
+		w.status = status
+	}
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if w.started {
+		if w.gz != nil {
+			return w.gz.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush is used by streaming handlers, whose output is rarely small, so a
+// pending response is committed to gzip.
+func (w *gzipResponseWriter) Flush() {
+	if !w.started {
+		w.start(true)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *gzipResponseWriter) start(compress bool) error {
+	w.started = true
+	header := w.Header()
+	if compress && header.Get("Content-Encoding") == "" && bodyAllowed(w.status) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.gz != nil {
+		_, err := w.gz.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *gzipResponseWriter) close() {
+	if !w.started {
+		w.start(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
+
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// supportedBodyType reports whether a write's Content-Type is one
+// decodeRecord understands: JSON (including +json types) or XML. A request
+// without the header is read as JSON unless server.strict_content_type is
+// set.
+func (s *Server) supportedBodyType(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return !s.config.Server.StrictContentType
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || isXMLMediaType(mediaType)
+}
+
+func (s *Server) sendUnsupportedMediaType(w http.ResponseWriter) {
+	s.sendJSON(w, http.StatusUnsupportedMediaType, map[string]any{
+		"success": false,
+		"error":   "Content-Type must be application/json or application/xml",
+	})
+}
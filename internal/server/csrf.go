@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/yamlforge/yamlforge/internal/auth"
+)
+
+const (
+	csrfCookie = "csrf_token"
+	csrfHeader = "X-CSRF-Token"
+)
+
+// cookieAuthenticated reports whether the session cookie is what
+// authenticates r. Browsers attach cookies to cross-site requests but never
+// Authorization or X-API-Key headers, so only cookie sessions need CSRF checks.
+func cookieAuthenticated(r *http.Request) bool {
+	if r.Header.Get("Authorization") != "" || r.Header.Get(auth.APIKeyHeader) != "" {
+		return false
+	}
+	cookie, err := r.Cookie("auth_token")
+	return err == nil && cookie.Value != ""
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// checkCSRF implements the double-submit cookie pattern: writes must echo
+// the csrf_token cookie in the X-CSRF-Token header. It writes a 403 and
+// returns false when they don't match.
+func (s *Server) checkCSRF(w http.ResponseWriter, r *http.Request) bool {
+	if !s.config.Server.Auth.CSRF || !cookieAuthenticated(r) {
+		return true
+	}
+
+	cookie, err := r.Cookie(csrfCookie)
+	if safeMethod(r.Method) {
+		if err != nil || cookie.Value == "" {
+			s.setCSRFCookie(w)
+		}
+		return true
+	}
+
+	header := r.Header.Get(csrfHeader)
+	if err != nil || cookie.Value == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) != 1 {
+		s.sendJSON(w, http.StatusForbidden, map[string]any{
+			"success": false,
+			"error":   "Invalid CSRF token",
+		})
+		return false
+	}
+	return true
+}
+
+func (s *Server) setCSRFCookie(w http.ResponseWriter) {
+	if !s.config.Server.Auth.CSRF {
+		return
+	}
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return
+	}
+	// Readable by scripts so the UI can echo it back in the header.
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    hex.EncodeToString(token),
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearCSRFCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   csrfCookie,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/yamlforge/yamlforge/internal/database"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+type listResult struct {
+	records []map[string]any
+	meta    *parser.Meta
+}
+
+// With server.dedupe_reads, concurrent identical reads share one database
+// round-trip. Every caller gets its own copy of the records because the
+// handlers strip and re-encode fields in place.
+func (s *Server) queryList(r *http.Request, modelName string, params parser.QueryParams, countLimit int) ([]map[string]any, *parser.Meta, error) {
+	db := s.dbFor(r)
+	load := func() (any, error) {
+		records, err := db.Query(modelName, params)
+		if err != nil {
+			return nil, err
+		}
+		meta, err := s.countList(db, modelName, params, countLimit)
+		if err != nil {
+			return nil, err
+		}
+		return listResult{records: records, meta: meta}, nil
+	}
+
+	key, err := json.Marshal(params)
+	if !s.config.Server.DedupeReads || err != nil {
+		result, err := load()
+		if err != nil {
+			return nil, nil, err
+		}
+		return result.(listResult).records, result.(listResult).meta, nil
+	}
+
+	result, err, _ := s.reads.Do(fmt.Sprintf("list:%s:%s:%d:%s", requestRole(r), modelName, countLimit, key), load)
+	if err != nil {
+		return nil, nil, err
+	}
+	list := result.(listResult)
+	meta := *list.meta
+	return copyRecords(list.records), &meta, nil
+}
+
+// countList skips the count when countLimit is negative and, when it is
+// positive, stops counting past it and reports the limit as an estimate.
+func (s *Server) countList(db database.Database, modelName string, params parser.QueryParams, countLimit int) (*parser.Meta, error) {
+	if countLimit < 0 {
+		return &parser.Meta{Page: params.Page, PageSize: params.PageSize}, nil
+	}
+
+	counter, ok := db.(database.LimitedCounter)
+	if countLimit == 0 || !ok {
+		total, err := db.Count(modelName, params.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return parser.NewMeta(params.Page, params.PageSize, total), nil
+	}
+
+	total, err := counter.CountUpTo(modelName, params.Filters, countLimit+1)
+	if err != nil {
+		return nil, err
+	}
+	if total <= int64(countLimit) {
+		return parser.NewMeta(params.Page, params.PageSize, total), nil
+	}
+	meta := parser.NewMeta(params.Page, params.PageSize, int64(countLimit))
+	meta.Estimated = true
+	return meta, nil
+}
+
+func (s *Server) getRecord(r *http.Request, modelName string, id any) (map[string]any, error) {
+	db := s.dbFor(r)
+	if !s.config.Server.DedupeReads {
+		return db.Get(modelName, id)
+	}
+
+	result, err, _ := s.reads.Do(fmt.Sprintf("get:%s:%s:%v", requestRole(r), modelName, id), func() (any, error) {
+		return db.Get(modelName, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyRecords([]map[string]any{result.(map[string]any)})[0], nil
+}
+
+func copyRecords(records []map[string]any) []map[string]any {
+	copies := make([]map[string]any, len(records))
+	for i, record := range records {
+		copies[i] = make(map[string]any, len(record))
+		for k, v := range record {
+			copies[i][k] = v
+		}
+	}
+	return copies
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/yamlforge/yamlforge/internal/auth"
+	"github.com/yamlforge/yamlforge/internal/database"
+)
+
+// requestRole is the role default_filter is checked against; anonymous
+// requests have none, so they are always filtered.
+func requestRole(r *http.Request) string {
+	if user, ok := r.Context().Value("user").(*auth.User); ok {
+		return user.Role
+	}
+	return ""
+}
+
+// dbFor returns the database as the request sees it: with each model's
+// default_filter applied to reads and writes alike.
+func (s *Server) dbFor(r *http.Request) database.Database {
+	if scoper, ok := s.db.(database.RoleScoper); ok {
+		return scoper.ForRole(requestRole(r))
+	}
+	return s.db
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/yamlforge/yamlforge/internal/auth"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const exportBatchSize = 500
+
+var exportContentTypes = map[string]string{
+	"json":   "application/json",
+	"ndjson": "application/x-ndjson",
+	"csv":    "text/csv",
+}
+
+func (s *Server) handleAPIExport(modelName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.authManager != nil && s.authManager.IsEnabled() && !s.isPublicRead(modelName) {
+			user, ok := r.Context().Value("user").(*auth.User)
+			if !ok || !s.authManager.CheckPermission(user.Username, modelName, false) {
+				s.sendJSON(w, http.StatusForbidden, map[string]any{
+					"success": false,
+					"error":   "You don't have permission to read this resource",
+				})
+				return
+			}
+		}
+
+		model, ok := s.schema.GetModel(modelName)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+		contentType, ok := exportContentTypes[format]
+		if !ok {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
+				"success": false,
+				"error":   "format must be one of csv, json, ndjson",
+			})
+			return
+		}
+
+		params := s.parseQueryParams(r)
+		if err := s.checkQueryParams(r, modelName, params); err != nil {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		if s.showTrashed(r, model) {
+			params.Filters = append(params.Filters, parser.Filter{
+				Field:    parser.SoftDeleteField,
+				Operator: "not_null",
+			})
+		}
+		if len(params.Sort) == 0 {
+			params.Sort = []parser.SortField{{Field: "id"}}
+		}
+		params.Page = 1
+		params.PageSize = exportBatchSize
+
+		columns, err := s.exportColumns(r, model)
+		if err != nil {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		selected := r.URL.Query().Get("fields") != ""
+
+		var csvWriter *csv.Writer
+		written := 0
+		for {
+			records, err := s.dbFor(r).Query(modelName, params)
+			if err != nil {
+				if params.Page == 1 {
+					s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+						"success": false,
+						"error":   err.Error(),
+					})
+				}
+				return
+			}
+
+			if params.Page == 1 {
+				w.Header().Set("Content-Type", contentType)
+				w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, strings.ToLower(modelName), format))
+				switch format {
+				case "json":
+					fmt.Fprint(w, "[")
+				case "csv":
+					csvWriter = csv.NewWriter(w)
+					csvWriter.Write(columns)
+				}
+			}
+
+			for _, record := range records {
+				record = s.encodeIDs(modelName, s.hideRestrictedField(r, modelName, exportRecord(model, record)))
+				if selected {
+					record = selectColumns(record, columns)
+				}
+				switch format {
+				case "json":
+					if written > 0 {
+						fmt.Fprint(w, ",")
+					}
+					json.NewEncoder(w).Encode(record)
+				case "ndjson":
+					json.NewEncoder(w).Encode(record)
+				case "csv":
+					row := make([]string, len(columns))
+					for i, column := range columns {
+						row[i] = exportCSVValue(record[column])
+					}
+					csvWriter.Write(row)
+				}
+				written++
+			}
+
+			if csvWriter != nil {
+				csvWriter.Flush()
+			}
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+
+			if len(records) < params.PageSize {
+				break
+			}
+			params.Page++
+		}
+
+		if format == "json" {
+			fmt.Fprint(w, "]")
+		}
+	}
+}
+
+// A fields list lets the list UI download exactly the columns on screen;
+// fields the caller may not read are dropped rather than reported.
+func (s *Server) exportColumns(r *http.Request, model *parser.Model) ([]string, error) {
+	var columns []string
+	fields := r.URL.Query().Get("fields")
+	if fields == "" {
+		for _, field := range model.Fields {
+			if field.Type != parser.FieldTypePassword && s.fieldReadable(r, model, field) {
+				columns = append(columns, field.Name)
+			}
+		}
+		return columns, nil
+	}
+
+	for _, name := range strings.Split(fields, ",") {
+		name = strings.TrimSpace(name)
+		field, ok := s.schema.GetField(model.Name, name)
+		if !ok || field.Type == parser.FieldTypePassword {
+			return nil, fmt.Errorf("unknown export field: %s", name)
+		}
+		if s.fieldReadable(r, model, *field) {
+			columns = append(columns, name)
+		}
+	}
+	return columns, nil
+}
+
+func selectColumns(record map[string]any, columns []string) map[string]any {
+	selected := make(map[string]any, len(columns))
+	for _, column := range columns {
+		if value, ok := record[column]; ok {
+			selected[column] = value
+		}
+	}
+	return selected
+}
+
+func exportRecord(model *parser.Model, record map[string]any) map[string]any {
+	for _, field := range model.Fields {
+		if field.Type == parser.FieldTypePassword {
+			delete(record, field.Name)
+		}
+	}
+	return record
+}
+
+func exportCSVValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	default:
+		return fmt.Sprint(v)
+	}
+}
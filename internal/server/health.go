@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/database"
+)
+
+// dbHealth holds the outcome of the last background database check. It is
+// shared across reloads, since the database connection is.
+type dbHealth struct {
+	mu        sync.Mutex
+	monitored bool
+	err       error
+}
+
+func (h *dbHealth) set(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case err != nil && h.err == nil:
+		log.Printf("Database unreachable, reporting not ready: %v", err)
+	case err == nil && h.err != nil:
+		log.Println("Database reachable again, reporting ready")
+	}
+	h.err = err
+}
+
+func (h *dbHealth) status() (monitored bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.monitored, h.err
+}
+
+func (h *dbHealth) setMonitored(monitored bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.monitored = monitored
+}
+
+// startHealthMonitor pings the database every database.health_check and,
+// when a ping fails, tries to reconnect before marking the server not
+// ready. The next successful check marks it ready again. A monitor
+// started for an earlier configuration is stopped first.
+func (s *Server) startHealthMonitor() {
+	run := s.healthMonitor()
+	s.health.setMonitored(run != nil)
+	s.monitor.restart(s.ctx, run)
+}
+
+// healthMonitor returns the check loop for the current configuration, or
+// nil when database.health_check is off.
+func (s *Server) healthMonitor() func(ctx context.Context) {
+	interval, err := time.ParseDuration(s.config.Database.HealthCheck)
+	if err != nil || interval <= 0 {
+		return nil
+	}
+	checker, ok := s.db.(database.HealthChecker)
+	if !ok {
+		return nil
+	}
+
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.health.set(checkDatabase(checker))
+			}
+		}
+	}
+}
+
+func checkDatabase(checker database.HealthChecker) error {
+	if err := checker.Ping(); err == nil {
+		return nil
+	}
+	return checker.Reconnect()
+}
+
+// handleReadyz reports whether the server can reach its database. Without
+// a background check it pings on each request.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	monitored, err := s.health.status()
+	if !monitored {
+		if checker, ok := s.db.(database.HealthChecker); ok {
+			err = checker.Ping()
+		}
+	}
+
+	// The probe is unauthenticated, so the cause only goes to the log.
+	if err != nil {
+		log.Printf("Readiness check failed: %v", err)
+		s.sendJSON(w, http.StatusServiceUnavailable, map[string]any{
+			"status": "unavailable",
+			"error":  "database unavailable",
+		})
+		return
+	}
+	s.sendJSON(w, http.StatusOK, map[string]any{
+		"status": "ready",
+	})
+}
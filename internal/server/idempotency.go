@@ -0,0 +1,82 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/database"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const idempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyStore returns where Idempotency-Keys are kept: redis when
+// server.redis_url is set, otherwise the database.
+func (s *Server) idempotencyStore() database.IdempotencyStore {
+	if s.idempotency != nil {
+		return s.idempotency
+	}
+	store, _ := s.db.(database.IdempotencyStore)
+	return store
+}
+
+// reserveIdempotencyKey claims key before the create runs. When the key
+// is already taken it answers the request itself, replaying the stored
+// record, and returns false.
+func (s *Server) reserveIdempotencyKey(w http.ResponseWriter, r *http.Request, store database.IdempotencyStore, modelName, key string) bool {
+	reserved, err := store.ReserveIdempotencyKey(key, modelName, idempotencyKeyTTL)
+	if err != nil {
+		log.Printf("failed to reserve idempotency key: %v", err)
+		s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+			"success": false,
+			"error":   "Failed to process Idempotency-Key",
+		})
+		return false
+	}
+	if reserved {
+		return true
+	}
+
+	if !s.replayIdempotentCreate(w, r, store, modelName, key) {
+		s.sendJSON(w, http.StatusConflict, map[string]any{
+			"success": false,
+			"error":   "Idempotency-Key is already in use",
+		})
+	}
+	return false
+}
+
+func (s *Server) replayIdempotentCreate(w http.ResponseWriter, r *http.Request, store database.IdempotencyStore, modelName, key string) bool {
+	model, id, found, err := store.LookupIdempotencyKey(key, idempotencyKeyTTL)
+	if err != nil || !found {
+		return false
+	}
+
+	if model != modelName {
+		s.sendJSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"success": false,
+			"error":   "Idempotency-Key was already used for a different resource",
+		})
+		return true
+	}
+	if id == nil {
+		s.sendJSON(w, http.StatusConflict, map[string]any{
+			"success": false,
+			"error":   "A request with this Idempotency-Key is still in progress",
+		})
+		return true
+	}
+
+	result, err := s.db.Get(modelName, id)
+	if err != nil {
+		return false
+	}
+
+	w.Header().Set("Idempotent-Replayed", "true")
+	s.sendJSON(w, http.StatusCreated, parser.APIResponse{
+		Success: true,
+		Data:    s.orderFields(modelName, s.encodeIDs(modelName, s.hideRestrictedField(r, modelName, result))),
+	})
+	return true
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// orderedRecord serializes a record with its keys in schema-declared order
+// instead of the alphabetical order encoding/json uses for maps. Keys that
+// are not model fields, such as includes, follow alphabetically.
+type orderedRecord struct {
+	keys   []string
+	values map[string]any
+}
+
+func (r orderedRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (s *Server) orderFields(modelName string, record map[string]any) any {
+	model, ok := s.schema.GetModel(modelName)
+	if !s.config.Server.OrderedFields || !ok || record == nil {
+		return record
+	}
+
+	keys := make([]string, 0, len(record))
+	seen := make(map[string]bool, len(record))
+	for _, field := range model.Fields {
+		if _, ok := record[field.Name]; ok {
+			keys = append(keys, field.Name)
+			seen[field.Name] = true
+		}
+	}
+
+	var extra []string
+	for key := range record {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+
+	return orderedRecord{keys: append(keys, extra...), values: record}
+}
+
+func (s *Server) orderRecords(modelName string, records []map[string]any) any {
+	if !s.config.Server.OrderedFields {
+		return records
+	}
+
+	ordered := make([]any, len(records))
+	for i, record := range records {
+		ordered[i] = s.orderFields(modelName, record)
+	}
+	return ordered
+}
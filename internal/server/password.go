@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/yamlforge/yamlforge/internal/auth"
+)
+
+// Users flagged to change their password may only reach these paths until
+// they do, so a leaked one-time password can't be used for anything else.
+var passwordChangePaths = map[string]bool{
+	"/api/auth/password": true,
+	"/api/auth/logout":   true,
+	"/logout":            true,
+}
+
+func passwordChangeAllowed(path string) bool {
+	return passwordChangePaths[path]
+}
+
+func (s *Server) handlePasswordChangeRequired(w http.ResponseWriter, r *http.Request) {
+	if !isAPIPath(r.URL.Path) {
+		http.Redirect(w, r, "/login?"+url.Values{"return": {r.URL.RequestURI()}}.Encode(), http.StatusSeeOther)
+		return
+	}
+	s.sendJSON(w, http.StatusForbidden, map[string]any{
+		"success":                  false,
+		"error":                    "Password change required",
+		"password_change_required": true,
+	})
+}
+
+func (s *Server) handleAuthPassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		s.handleAuthError(w, r, "Authentication required")
+		return
+	}
+
+	var request struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.sendJSON(w, http.StatusBadRequest, map[string]any{
+			"success": false,
+			"error":   "Invalid request",
+		})
+		return
+	}
+
+	if err := s.authManager.ChangePassword(user.Username, request.CurrentPassword, request.NewPassword); err != nil {
+		status := http.StatusInternalServerError
+		message := err.Error()
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			status = http.StatusUnauthorized
+			message = "Current password is incorrect"
+		case errors.Is(err, auth.ErrWeakPassword), errors.Is(err, auth.ErrPasswordUnchanged):
+			status = http.StatusBadRequest
+		}
+		s.sendJSON(w, status, map[string]any{
+			"success": false,
+			"error":   message,
+		})
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, map[string]any{
+		"success": true,
+	})
+}
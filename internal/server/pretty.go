@@ -0,0 +1,25 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+)
+
+type prettyResponseWriter struct {
+	http.ResponseWriter
+}
+
+// ?pretty=true|false overrides server.pretty_json for a single request.
+func (s *Server) wantsPrettyJSON(r *http.Request) bool {
+	if value := r.URL.Query().Get("pretty"); value != "" {
+		pretty, err := strconv.ParseBool(value)
+		return err == nil && pretty
+	}
+	return s.config.Server.PrettyJSON
+}
+
+func (w *prettyResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
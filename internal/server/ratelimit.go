@@ -0,0 +1,152 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/auth"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const quotaWindow = 24 * time.Hour
+
+// rateSweepInterval is how often the in-memory limiter drops windows that
+// have ended, so clients seen once don't stay in memory.
+const rateSweepInterval = time.Minute
+
+// limiter counts requests per key in fixed windows. The in-memory
+// rateLimiter is per process; redisLimiter shares counts across instances.
+type limiter interface {
+	allow(key string, limit int, window time.Duration) (bool, time.Duration)
+}
+
+type rateWindow struct {
+	start time.Time
+	end   time.Time
+	count int
+}
+
+type rateLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*rateWindow
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		windows: make(map[string]*rateWindow),
+		now:     time.Now,
+	}
+}
+
+func (l *rateLimiter) allow(key string, limit int, window time.Duration) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= rateSweepInterval {
+		for k, w := range l.windows {
+			if !now.Before(w.end) {
+				delete(l.windows, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	start := now.Truncate(window)
+	w, ok := l.windows[key]
+	if !ok || !w.start.Equal(start) {
+		w = &rateWindow{start: start, end: start.Add(window)}
+		l.windows[key] = w
+	}
+
+	if w.count >= limit {
+		return false, w.end.Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+func (s *Server) rateLimited(modelName string, next http.HandlerFunc) http.HandlerFunc {
+	model, ok := s.schema.GetModel(modelName)
+	if !ok {
+		return next
+	}
+	return s.limitRequests(model, "rate", model.RateLimit, next)
+}
+
+// exportRateLimited counts exports separately from the model's CRUD
+// traffic, under api.export_rate_limit or, without one, api.rate_limit.
+func (s *Server) exportRateLimited(modelName string, next http.HandlerFunc) http.HandlerFunc {
+	model, ok := s.schema.GetModel(modelName)
+	if !ok {
+		return next
+	}
+	limit := model.ExportRateLimit
+	if limit.Requests == 0 {
+		limit = model.RateLimit
+	}
+	return s.limitRequests(model, "export", limit, next)
+}
+
+func (s *Server) limitRequests(model *parser.Model, bucket string, limit parser.RateLimit, next http.HandlerFunc) http.HandlerFunc {
+	if limit.Requests == 0 && model.DailyQuota == 0 {
+		return next
+	}
+	modelName := model.Name
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := clientKey(r)
+
+		if limit.Requests > 0 {
+			allowed, retryAfter := s.limiter.allow(bucket+":"+modelName+":"+client, limit.Requests, limit.Window)
+			if !allowed {
+				s.sendTooManyRequests(w, retryAfter, "Rate limit exceeded")
+				return
+			}
+		}
+
+		if model.DailyQuota > 0 {
+			if user, ok := r.Context().Value("user").(*auth.User); ok {
+				allowed, retryAfter := s.limiter.allow("quota:"+modelName+":"+user.Username, model.DailyQuota, quotaWindow)
+				if !allowed {
+					s.sendTooManyRequests(w, retryAfter, "Daily quota exceeded")
+					return
+				}
+			}
+		}
+
+		next(w, r)
+	}
+}
+
+func (s *Server) sendTooManyRequests(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	seconds := int(retryAfter.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	s.sendJSON(w, http.StatusTooManyRequests, map[string]any{
+		"success": false,
+		"error":   message,
+	})
+}
+
+func clientKey(r *http.Request) string {
+	if user, ok := r.Context().Value("user").(*auth.User); ok {
+		return "user:" + user.Username
+	}
+	return "ip:" + remoteHost(r)
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,351 @@
+package server
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/database"
+)
+
+const (
+	redisTimeout = 2 * time.Second
+	// redisMaxIdle bounds the connections kept open between commands.
+	redisMaxIdle = 8
+	// After a failed dial or a broken connection no new connection is
+	// attempted for redisBackoff, doubling with each further failure up to
+	// redisMaxBackoff, so an outage doesn't add a dial timeout to every
+	// request.
+	redisBackoff    = 100 * time.Millisecond
+	redisMaxBackoff = 30 * time.Second
+)
+
+var errRedisUnavailable = errors.New("redis: unavailable, waiting before reconnecting")
+
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisClient speaks just enough RESP to run single commands over a small
+// pool of connections.
+type redisClient struct {
+	addr     string
+	tls      bool
+	password string
+	db       int
+	now      func() time.Time
+
+	mu       sync.Mutex
+	idle     []*redisConn
+	failures int
+	retryAt  time.Time
+}
+
+type redisConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+func newRedisClient(rawURL string) (*redisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("unsupported redis scheme '%s'", u.Scheme)
+	}
+
+	client := &redisClient{addr: u.Host, tls: u.Scheme == "rediss", now: time.Now}
+	if u.Port() == "" {
+		client.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		client.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if client.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database '%s'", db)
+		}
+	}
+	return client, nil
+}
+
+func (c *redisClient) do(args ...string) (any, error) {
+	conn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.roundTrip(args)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		conn.conn.Close()
+		c.fail()
+		return nil, err
+	}
+	c.put(conn)
+	return reply, err
+}
+
+// get hands out an idle connection or dials a new one, unless the last
+// failure is too recent to try again.
+func (c *redisClient) get() (*redisConn, error) {
+	c.mu.Lock()
+	if n := len(c.idle); n > 0 {
+		conn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return conn, nil
+	}
+	if c.now().Before(c.retryAt) {
+		c.mu.Unlock()
+		return nil, errRedisUnavailable
+	}
+	c.mu.Unlock()
+
+	conn, err := c.connect()
+	if err != nil {
+		c.fail()
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.failures = 0
+	c.retryAt = time.Time{}
+	c.mu.Unlock()
+	return conn, nil
+}
+
+func (c *redisClient) put(conn *redisConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.idle) >= redisMaxIdle {
+		conn.conn.Close()
+		return
+	}
+	c.idle = append(c.idle, conn)
+}
+
+func (c *redisClient) fail() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	backoff := redisBackoff << c.failures
+	if backoff > redisMaxBackoff || backoff <= 0 {
+		backoff = redisMaxBackoff
+	} else {
+		c.failures++
+	}
+	c.retryAt = c.now().Add(backoff)
+}
+
+func (c *redisClient) connect() (*redisConn, error) {
+	dialer := &net.Dialer{Timeout: redisTimeout}
+	var conn net.Conn
+	var err error
+	if c.tls {
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.addr, nil)
+	} else {
+		conn, err = dialer.Dial("tcp", c.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rc := &redisConn{conn: conn, rw: bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))}
+
+	var setup [][]string
+	if c.password != "" {
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	for _, args := range setup {
+		if _, err := rc.roundTrip(args); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+func (c *redisConn) roundTrip(args []string) (any, error) {
+	c.conn.SetDeadline(time.Now().Add(redisTimeout))
+
+	fmt.Fprintf(c.rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := c.rw.Flush(); err != nil {
+		return nil, err
+	}
+	return readRedisReply(c.rw.Reader)
+}
+
+func readRedisReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readRedisReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+// redisLimiter keeps one counter per key and window in redis so every
+// instance sees the same counts. While redis is unreachable it falls back
+// to local counters rather than rejecting or letting through everything.
+type redisLimiter struct {
+	client   *redisClient
+	fallback *rateLimiter
+	now      func() time.Time
+}
+
+func newRedisLimiter(client *redisClient) *redisLimiter {
+	return &redisLimiter{
+		client:   client,
+		fallback: newRateLimiter(),
+		now:      time.Now,
+	}
+}
+
+func (l *redisLimiter) allow(key string, limit int, window time.Duration) (bool, time.Duration) {
+	now := l.now()
+	start := now.Truncate(window)
+	bucket := fmt.Sprintf("yamlforge:%s:%d", key, start.Unix())
+
+	count, err := l.incr(bucket, window)
+	if err != nil {
+		log.Printf("redis rate limit failed, using local counters: %v", err)
+		return l.fallback.allow(key, limit, window)
+	}
+
+	if count > int64(limit) {
+		return false, start.Add(window).Sub(now)
+	}
+	return true, 0
+}
+
+// redisIncr bumps a window's counter and sets its expiry in one step, so
+// a failure in between can't leave a counter that never expires.
+const redisIncr = `local count = redis.call('INCR', KEYS[1])
+if count == 1 then redis.call('PEXPIRE', KEYS[1], ARGV[1]) end
+return count`
+
+func (l *redisLimiter) incr(bucket string, window time.Duration) (int64, error) {
+	reply, err := l.client.do("EVAL", redisIncr, "1", bucket, strconv.FormatInt(window.Milliseconds(), 10))
+	if err != nil {
+		return 0, err
+	}
+	count, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected INCR reply %v", reply)
+	}
+	return count, nil
+}
+
+// redisIdempotencyStore keeps Idempotency-Keys in redis so a retry that
+// lands on another instance is still recognized. While redis is
+// unreachable it falls back to the database's store, when there is one.
+type redisIdempotencyStore struct {
+	client   *redisClient
+	fallback database.IdempotencyStore
+}
+
+func idempotencyRedisKey(key string) string {
+	return "yamlforge:idempotency:" + key
+}
+
+// ReserveIdempotencyKey stores the key with an empty id, marking it
+// pending; SET NX lets only one instance reserve it.
+func (s *redisIdempotencyStore) ReserveIdempotencyKey(key, model string, ttl time.Duration) (bool, error) {
+	reply, err := s.client.do("SET", idempotencyRedisKey(key), model+"\n", "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		if s.fallback == nil {
+			return false, err
+		}
+		log.Printf("redis idempotency reservation failed, using the database: %v", err)
+		return s.fallback.ReserveIdempotencyKey(key, model, ttl)
+	}
+	return reply == "OK", nil
+}
+
+func (s *redisIdempotencyStore) LookupIdempotencyKey(key string, ttl time.Duration) (string, any, bool, error) {
+	reply, err := s.client.do("GET", idempotencyRedisKey(key))
+	if err != nil {
+		if s.fallback == nil {
+			return "", nil, false, err
+		}
+		log.Printf("redis idempotency lookup failed, using the database: %v", err)
+		return s.fallback.LookupIdempotencyKey(key, ttl)
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return "", nil, false, nil
+	}
+	model, id, _ := strings.Cut(value, "\n")
+	if id == "" {
+		return model, nil, true, nil
+	}
+	return model, id, true, nil
+}
+
+func (s *redisIdempotencyStore) SaveIdempotencyKey(key, model string, id any, ttl time.Duration) error {
+	value := model + "\n" + fmt.Sprint(id)
+	_, err := s.client.do("SET", idempotencyRedisKey(key), value, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil && s.fallback != nil {
+		log.Printf("redis idempotency save failed, using the database: %v", err)
+		return s.fallback.SaveIdempotencyKey(key, model, id, ttl)
+	}
+	return err
+}
+
+func (s *redisIdempotencyStore) ReleaseIdempotencyKey(key string) error {
+	_, err := s.client.do("DEL", idempotencyRedisKey(key))
+	if err != nil && s.fallback != nil {
+		return s.fallback.ReleaseIdempotencyKey(key)
+	}
+	return err
+}
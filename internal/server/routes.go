@@ -0,0 +1,65 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gorilla/mux"
+)
+
+type routeInfo struct {
+	methods string
+	path    string
+}
+
+func (s *Server) routes() []routeInfo {
+	var routes []routeInfo
+	s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		routes = append(routes, routeInfo{methods: strings.Join(methods, ","), path: path})
+		return nil
+	})
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].path < routes[j].path
+	})
+	return routes
+}
+
+// printRoutes writes the startup summary shown when server.print_routes is on.
+func (s *Server) printRoutes(w io.Writer, addr string) {
+	models := make([]string, 0, len(s.schema.Models))
+	for name := range s.schema.Models {
+		models = append(models, name)
+	}
+	sort.Strings(models)
+
+	baseURL := "http://" + addr
+	if s.config.Server.BaseURL != "" {
+		baseURL = strings.TrimSuffix(s.config.Server.BaseURL, "/")
+	}
+
+	fmt.Fprintf(w, "\n%s %s\n", s.config.App.Name, s.config.App.Version)
+	fmt.Fprintf(w, "  Models: %s\n", strings.Join(models, ", "))
+	fmt.Fprintf(w, "  Auth:   %s\n", s.config.Server.Auth.Type)
+	fmt.Fprintf(w, "  Docs:   %s/api/docs\n\n", baseURL)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  KIND\tMETHOD\tPATH")
+	for _, route := range s.routes() {
+		kind := "ui"
+		if strings.HasPrefix(route.path, "/api") {
+			kind = "api"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", kind, route.methods, route.path)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
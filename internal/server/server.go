@@ -2,12 +2,19 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/yamlforge/yamlforge/internal/api"
@@ -16,6 +23,7 @@ import (
 	"github.com/yamlforge/yamlforge/internal/parser"
 	"github.com/yamlforge/yamlforge/internal/ui"
 	"github.com/yamlforge/yamlforge/internal/validation"
+	"golang.org/x/sync/singleflight"
 )
 
 type Server struct {
@@ -25,13 +33,36 @@ type Server struct {
 	router      *mux.Router
 	authManager *auth.AuthManager
 	validator   *validation.Validator
+	handler     atomic.Value
+	limiter     limiter
+	failures    *loginFailures
+	reads       singleflight.Group
+	health      *dbHealth
+	idempotency database.IdempotencyStore
+	pruner      *backgroundLoop
+	monitor     *backgroundLoop
+	httpServer  *http.Server
+
+	// ctx lives until Shutdown; background loops derive from it.
+	ctx  context.Context
+	stop context.CancelFunc
 }
 
 func New(config *parser.Config) *Server {
-	return &Server{
-		config: config,
-		router: mux.NewRouter(),
+	ctx, stop := context.WithCancel(context.Background())
+	s := &Server{
+		config:   config,
+		router:   mux.NewRouter(),
+		limiter:  newRateLimiter(),
+		failures: newLoginFailures(),
+		health:   &dbHealth{},
+		pruner:   &backgroundLoop{},
+		monitor:  &backgroundLoop{},
+		ctx:      ctx,
+		stop:     stop,
 	}
+	s.httpServer = &http.Server{Handler: s}
+	return s
 }
 
 func (s *Server) Start(host string, port int) error {
@@ -44,8 +75,72 @@ func (s *Server) Start(host string, port int) error {
 
 	log.Printf("Server starting on http://%s", addr)
 	log.Printf("Routes registered, starting HTTP server...")
+	if s.config.Server.RoutesPrinted() {
+		s.printRoutes(log.Writer(), addr)
+	}
+
+	s.handler.Store(s.router)
+	s.httpServer.Addr = addr
+	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the background work, then stops accepting connections
+// and waits, until ctx is done, for requests in flight to finish.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stop()
+	s.pruner.halt()
+	s.monitor.halt()
+	return s.httpServer.Shutdown(ctx)
+}
 
-	return http.ListenAndServe(addr, s.router)
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if router, ok := s.handler.Load().(*mux.Router); ok {
+		router.ServeHTTP(w, r)
+		return
+	}
+	s.router.ServeHTTP(w, r)
+}
+
+func (s *Server) Reload(config *parser.Config) error {
+	if s.db == nil {
+		return fmt.Errorf("server is not initialized")
+	}
+
+	schema, err := parser.LoadConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	if err := s.db.CreateSchema(schema); err != nil {
+		return fmt.Errorf("failed to migrate database schema: %w", err)
+	}
+
+	next := &Server{
+		config:      config,
+		db:          s.db,
+		schema:      schema,
+		router:      mux.NewRouter(),
+		authManager: s.authManager,
+		validator:   validation.New(schema),
+		limiter:     s.limiter,
+		failures:    s.failures,
+		health:      s.health,
+		idempotency: s.idempotency,
+		pruner:      s.pruner,
+		monitor:     s.monitor,
+		httpServer:  s.httpServer,
+		ctx:         s.ctx,
+		stop:        s.stop,
+	}
+	next.setupRoutes()
+	next.startAuditPruning()
+	next.startHealthMonitor()
+
+	s.handler.Store(next.router)
+	return nil
 }
 
 func (s *Server) initialize() error {
@@ -76,25 +171,55 @@ func (s *Server) initialize() error {
 		return fmt.Errorf("failed to create database schema: %w", err)
 	}
 
+	if len(s.config.Seed) > 0 {
+		log.Println("Seeding database...")
+		if err := database.Seed(db, schema, s.config.Seed); err != nil {
+			return fmt.Errorf("failed to seed database: %w", err)
+		}
+	}
+
 	if s.config.Server.Auth.Type != "none" {
 		log.Println("Initializing authentication...")
-		sqlDB, ok := db.(*database.SQLiteDB)
+		sqlDB, ok := db.(interface{ GetConnection() *sql.DB })
 		if !ok {
-			return fmt.Errorf("authentication requires SQLite database")
+			return fmt.Errorf("authentication requires a SQL database")
 		}
-		
+
 		conn := sqlDB.GetConnection()
 		if conn == nil {
 			return fmt.Errorf("failed to get database connection for auth")
 		}
-		
-		authManager, err := auth.New(&s.config.Server.Auth, conn)
+
+		store, err := auth.NewStore(s.config.Database.Type, conn)
+		if err != nil {
+			return fmt.Errorf("failed to initialize auth: %w", err)
+		}
+
+		authManager, err := auth.NewWithStore(&s.config.Server.Auth, store)
 		if err != nil {
 			return fmt.Errorf("failed to initialize auth: %w", err)
 		}
+		authManager.SetBaseURL(s.config.Server.BaseURL)
 		s.authManager = authManager
 	}
 
+	if s.config.Server.RedisURL != "" {
+		client, err := newRedisClient(s.config.Server.RedisURL)
+		if err != nil {
+			return fmt.Errorf("failed to configure redis: %w", err)
+		}
+		if _, err := client.do("PING"); err != nil {
+			log.Printf("Warning: redis is unreachable, rate limits and idempotency keys fall back to local state until it is: %v", err)
+		}
+		s.limiter = newRedisLimiter(client)
+		fallback, _ := s.db.(database.IdempotencyStore)
+		s.idempotency = &redisIdempotencyStore{client: client, fallback: fallback}
+	}
+
+	s.startAuditPruning()
+
+	s.startHealthMonitor()
+
 	log.Println("Templates loaded (hard-coded)")
 
 	log.Println("Setting up routes...")
@@ -111,6 +236,8 @@ func (s *Server) setupRoutes() {
 	if s.authManager != nil {
 		s.router.Use(s.globalAuthMiddleware())
 	}
+	s.router.Use(s.compressionMiddleware)
+	s.router.Use(s.contentNegotiationMiddleware)
 
 	if s.authManager != nil {
 		s.router.HandleFunc("/login", s.handleLogin).Methods("GET")
@@ -120,11 +247,20 @@ func (s *Server) setupRoutes() {
 	if s.authManager != nil {
 		s.router.HandleFunc("/logout", s.handleLogout).Methods("GET", "POST")
 		s.router.HandleFunc("/api/auth/logout", s.handleAuthLogout).Methods("POST")
+		s.router.HandleFunc("/api/auth/password", s.handleAuthPassword).Methods("POST")
+		s.router.HandleFunc("/api/auth/users", s.handleAuthCreateUser).Methods("POST")
+		if s.authManager.VerificationEnabled() {
+			s.router.HandleFunc(auth.VerifyEmailPath, s.handleAuthVerifyEmail).Methods("GET", "POST")
+		}
 	}
 
+	s.router.HandleFunc("/readyz", s.handleReadyz).Methods("GET")
 	s.router.HandleFunc("/api/openapi", s.handleOpenAPI).Methods("GET")
 	s.router.HandleFunc("/api/openapi.json", s.handleOpenAPI).Methods("GET")
 	s.router.HandleFunc("/api/docs", s.handleSwaggerUI).Methods("GET")
+	if s.config.Server.Audit.Enabled {
+		s.router.HandleFunc("/api/_audit", s.handleAudit).Methods("GET")
+	}
 
 	for modelName := range s.schema.Models {
 		s.setupAPIRoutes(modelName)
@@ -147,25 +283,120 @@ func (s *Server) setupModelRoutes(modelName string) {
 
 func (s *Server) setupAPIRoutes(modelName string) {
 	basePath := "/api/" + strings.ToLower(modelName)
+	s.mountAPIRoutes(modelName, basePath, func(h http.HandlerFunc) http.HandlerFunc { return h })
+
+	model, ok := s.schema.GetModel(modelName)
+	if !ok {
+		return
+	}
+	for version := range model.Versions {
+		s.mountAPIRoutes(modelName, "/api/"+version+"/"+strings.ToLower(modelName), withAPIVersion(version))
+	}
+}
 
-	s.router.HandleFunc(basePath, s.handleAPIList(modelName)).Methods("GET")
-	s.router.HandleFunc(basePath, s.handleAPICreate(modelName)).Methods("POST")
-	s.router.HandleFunc(basePath+"/{id}", s.handleAPIGet(modelName)).Methods("GET")
-	s.router.HandleFunc(basePath+"/{id}", s.handleAPIUpdate(modelName)).Methods("PUT")
-	s.router.HandleFunc(basePath+"/{id}", s.handleAPIDelete(modelName)).Methods("DELETE")
+// withAPIVersion tags requests with the API version whose routes they came
+// in on, so responses carry only that version's fields.
+func withAPIVersion(version string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			next(w, r.WithContext(context.WithValue(r.Context(), "api_version", version)))
+		}
+	}
+}
+
+func apiVersion(r *http.Request) string {
+	version, _ := r.Context().Value("api_version").(string)
+	return version
+}
+
+func (s *Server) mountAPIRoutes(modelName, basePath string, wrap func(http.HandlerFunc) http.HandlerFunc) {
+	route := func(h http.HandlerFunc) http.HandlerFunc {
+		return wrap(s.rateLimited(modelName, h))
+	}
+
+	// HEAD runs the GET handler; net/http drops the body but keeps the
+	// headers, including X-Total-Count on lists.
+	readMethods := []string{"GET"}
+	if s.config.Server.HeadRequests {
+		readMethods = append(readMethods, "HEAD")
+	}
+
+	s.router.HandleFunc(basePath, route(s.handleAPIList(modelName))).Methods(readMethods...)
+	s.router.HandleFunc(basePath, route(s.handleAPICreate(modelName))).Methods("POST")
+	s.router.HandleFunc(basePath+"/export", wrap(s.exportRateLimited(modelName, s.handleAPIExport(modelName)))).Methods("GET")
+	s.router.HandleFunc(basePath+"/bulk", route(s.handleAPIBulk(modelName))).Methods("POST")
+	s.router.HandleFunc(basePath+"/{id}", route(s.handleAPIGet(modelName))).Methods(readMethods...)
+	s.router.HandleFunc(basePath+"/{id}", route(s.handleAPIUpdate(modelName))).Methods("PUT")
+	s.router.HandleFunc(basePath+"/{id}", route(s.handleAPIDelete(modelName))).Methods("DELETE")
+	s.router.HandleFunc(basePath+"/{id}/restore", route(s.handleAPIRestore(modelName))).Methods("POST")
+	s.router.HandleFunc(basePath+"/{id}/delete-preview", route(s.handleAPIDeletePreview(modelName))).Methods("GET")
+
+	if s.config.Server.OptionsRequests {
+		// Sub-resources go first so /{id} doesn't claim /export.
+		s.router.HandleFunc(basePath+"/export", handleOptions("GET")).Methods("OPTIONS")
+		s.router.HandleFunc(basePath+"/{id}/restore", handleOptions("POST")).Methods("OPTIONS")
+		s.router.HandleFunc(basePath+"/{id}/delete-preview", handleOptions("GET")).Methods("OPTIONS")
+		s.router.HandleFunc(basePath, handleOptions(append(slices.Clip(readMethods), "POST")...)).Methods("OPTIONS")
+		s.router.HandleFunc(basePath+"/{id}", handleOptions(append(slices.Clip(readMethods), "PUT", "DELETE")...)).Methods("OPTIONS")
+	}
+}
+
+// handleOptions answers OPTIONS with the methods a route supports in the
+// Allow header and an empty body.
+func handleOptions(methods ...string) http.HandlerFunc {
+	allow := strings.Join(methods, ", ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		w.WriteHeader(http.StatusNoContent)
+	}
 }
 
 func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 	modelPermissions := make(map[string]bool)
+	readable := make(map[string]bool)
 	if s.authManager != nil && s.authManager.IsEnabled() {
 		if user, ok := r.Context().Value("user").(*auth.User); ok {
 			for modelName := range s.schema.Models {
 				modelPermissions[modelName] = s.authManager.CheckPermission(user.Username, modelName, true)
+				readable[modelName] = s.authManager.CheckPermission(user.Username, modelName, false)
 			}
 		}
 	} else {
 		for modelName := range s.schema.Models {
 			modelPermissions[modelName] = true
+			readable[modelName] = true
+		}
+	}
+
+	db := s.dbFor(r)
+	modelCounts := make(map[string]int64)
+	recentRecords := make(map[string][]map[string]any)
+	for modelName, model := range s.schema.Models {
+		if db == nil || !readable[modelName] {
+			continue
+		}
+		if count, err := db.Count(modelName, nil); err == nil {
+			modelCounts[modelName] = count
+		}
+		if s.config.UI.Dashboard.Recent > 0 {
+			records, err := db.Query(modelName, parser.QueryParams{
+				Page:     1,
+				PageSize: s.config.UI.Dashboard.Recent,
+				Sort:     []parser.SortField{{Field: recentSortField(model), Desc: true}},
+			})
+			if err == nil {
+				recentRecords[modelName] = s.hideRestrictedFields(r, modelName, records...)
+			}
+		}
+	}
+	
+	widgetValues := make(map[int]float64)
+	for i, widget := range s.config.UI.Dashboard.Widgets {
+		if db == nil || !readable[widget.Model] || s.widgetHidden(r, widget) {
+			continue
+		}
+		if value, err := db.Aggregate(widget.Model, widget.Aggregate, widget.Field, widgetFilters(widget)); err == nil {
+			widgetValues[i] = value
 		}
 	}
 	
@@ -174,16 +405,63 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 		Config           *parser.Config
 		Models           map[string]*parser.Model
 		ModelPermissions map[string]bool
+		ModelCounts      map[string]int64
+		RecentRecords    map[string][]map[string]any
+		WidgetValues     map[int]float64
 	}{
 		Title:            s.config.UI.Title,
 		Config:           s.config,
 		Models:           s.schema.Models,
 		ModelPermissions: modelPermissions,
+		ModelCounts:      modelCounts,
+		RecentRecords:    recentRecords,
+		WidgetValues:     widgetValues,
 	}
 
 	s.render(w, "home", data)
 }
 
+func widgetFilters(widget parser.UIWidgetConfig) []parser.Filter {
+	fields := make([]string, 0, len(widget.Filters))
+	for field := range widget.Filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	filters := make([]parser.Filter, 0, len(fields))
+	for _, field := range fields {
+		filters = append(filters, parser.Filter{
+			Field:    field,
+			Operator: "=",
+			Value:    widget.Filters[field],
+		})
+	}
+	return filters
+}
+
+// widgetHidden reports whether the widget aggregates or filters on a
+// field read_roles hides from the caller.
+func (s *Server) widgetHidden(r *http.Request, widget parser.UIWidgetConfig) bool {
+	if widget.Field != "" && !s.queryableField(r, widget.Model, widget.Field) {
+		return true
+	}
+	for field := range widget.Filters {
+		if !s.queryableField(r, widget.Model, field) {
+			return true
+		}
+	}
+	return false
+}
+
+func recentSortField(model *parser.Model) string {
+	for _, field := range model.Fields {
+		if field.Name == "created_at" {
+			return field.Name
+		}
+	}
+	return "id"
+}
+
 func (s *Server) handleModelList(modelName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		model, ok := s.schema.GetModel(modelName)
@@ -256,11 +534,12 @@ func (s *Server) handleModelView(modelName string) http.HandlerFunc {
 			return
 		}
 
-		record, err := s.db.Get(modelName, id)
+		record, err := s.dbFor(r).Get(modelName, id)
 		if err != nil {
 			http.NotFound(w, r)
 			return
 		}
+		s.hideRestrictedField(r, modelName, record)
 
 		data := struct {
 			Title     string
@@ -269,7 +548,7 @@ func (s *Server) handleModelView(modelName string) http.HandlerFunc {
 			Model     *parser.Model
 			Record    map[string]any
 		}{
-			Title:     fmt.Sprintf("%s #%s - %s", modelName, id, s.config.UI.Title),
+			Title:     fmt.Sprintf("%s - %s", model.RecordTitle(record), s.config.UI.Title),
 			Config:    s.config,
 			ModelName: modelName,
 			Model:     model,
@@ -291,11 +570,12 @@ func (s *Server) handleModelEdit(modelName string) http.HandlerFunc {
 			return
 		}
 
-		record, err := s.db.Get(modelName, id)
+		record, err := s.dbFor(r).Get(modelName, id)
 		if err != nil {
 			http.NotFound(w, r)
 			return
 		}
+		s.hideRestrictedField(r, modelName, record)
 
 		data := struct {
 			Title     string
@@ -305,7 +585,7 @@ func (s *Server) handleModelEdit(modelName string) http.HandlerFunc {
 			Record    map[string]any
 			Action    string
 		}{
-			Title:     fmt.Sprintf("Edit %s #%s - %s", modelName, id, s.config.UI.Title),
+			Title:     fmt.Sprintf("Edit %s - %s", model.RecordTitle(record), s.config.UI.Title),
 			Config:    s.config,
 			ModelName: modelName,
 			Model:     model,
@@ -323,16 +603,25 @@ func (s *Server) render(w http.ResponseWriter, name string, data any) {
 	switch name {
 	case "home":
 		modelPermissions := make(map[string]bool)
+		var modelCounts map[string]int64
+		var recentRecords map[string][]map[string]any
+		var widgetValues map[int]float64
 		switch d := data.(type) {
 		case struct {
 			Title            string
 			Config           *parser.Config
 			Models           map[string]*parser.Model
 			ModelPermissions map[string]bool
+			ModelCounts      map[string]int64
+			RecentRecords    map[string][]map[string]any
+			WidgetValues     map[int]float64
 		}:
 			modelPermissions = d.ModelPermissions
+			modelCounts = d.ModelCounts
+			recentRecords = d.RecentRecords
+			widgetValues = d.WidgetValues
 		}
-		html = ui.GetHomeHTML(s.config, s.schema, modelPermissions)
+		html = ui.GetHomeHTML(s.config, s.schema, modelPermissions, modelCounts, recentRecords, widgetValues)
 	case "list":
 		canWrite := false
 		modelName := ""
@@ -428,8 +717,8 @@ func (s *Server) render(w http.ResponseWriter, name string, data any) {
 			Record    map[string]any
 		}:
 			if d.Record != nil {
-				if id, ok := d.Record["id"].(string); ok {
-					recordID = id
+				if id, ok := d.Record["id"]; ok {
+					recordID = fmt.Sprintf("%v", id)
 				}
 			}
 		}
@@ -440,7 +729,7 @@ func (s *Server) render(w http.ResponseWriter, name string, data any) {
 			Model     *parser.Model
 			Record    map[string]any
 		})
-		html = ui.GetViewHTML(s.config, s.schema, viewData.ModelName, viewData.Model, recordID, s.extractRecordAsJSON(data))
+		html = ui.GetViewHTML(s.config, s.schema, viewData.ModelName, viewData.Model, recordID, viewData.Model.RecordTitle(viewData.Record), s.extractRecordAsJSON(data))
 	default:
 		log.Printf("Unknown template: %s", name)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -461,7 +750,7 @@ func (s *Server) extractRecordAsJSON(data any) string {
 		Record    map[string]any
 	}:
 		if d.Record != nil {
-			if jsonBytes, err := json.Marshal(d.Record); err == nil {
+			if jsonBytes, err := json.Marshal(s.encodeIDs(d.ModelName, d.Record)); err == nil {
 				return string(jsonBytes)
 			}
 		}
@@ -474,7 +763,7 @@ func (s *Server) extractRecordAsJSON(data any) string {
 		Action    string
 	}:
 		if d.Record != nil {
-			if jsonBytes, err := json.Marshal(d.Record); err == nil {
+			if jsonBytes, err := json.Marshal(s.encodeIDs(d.ModelName, d.Record)); err == nil {
 				return string(jsonBytes)
 			}
 		}
@@ -498,7 +787,7 @@ func (s *Server) debugMiddleware(next http.Handler) http.Handler {
 }
 
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
-	html := ui.GetLoginHTML(s.config)
+	html := ui.GetLoginHTML(s.config, safeReturnURL(r.URL.Query().Get("return")))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Write([]byte(html))
 }
@@ -506,14 +795,16 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 	if s.authManager != nil {
 		s.authManager.ClearAuthCookie(w)
+		clearCSRFCookie(w)
 	}
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
 func (s *Server) handleAuthLogin(w http.ResponseWriter, r *http.Request) {
 	var loginRequest struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
+		Username     string `json:"username"`
+		Password     string `json:"password"`
+		CaptchaToken string `json:"captcha_token"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&loginRequest); err != nil {
@@ -524,14 +815,25 @@ func (s *Server) handleAuthLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ip := remoteHost(r)
+	if !s.checkLoginCaptcha(w, r, ip, loginRequest.CaptchaToken) {
+		return
+	}
+
 	user, err := s.authManager.Authenticate(loginRequest.Username, loginRequest.Password)
 	if err != nil {
-		s.sendJSON(w, http.StatusUnauthorized, map[string]any{
+		response := map[string]any{
 			"success": false,
 			"error":   "Invalid credentials",
-		})
+		}
+		if s.config.Server.Auth.Captcha != nil {
+			s.failures.record(ip, s.captchaWindow())
+			response["captcha_required"] = s.captchaRequired(ip)
+		}
+		s.sendJSON(w, http.StatusUnauthorized, response)
 		return
 	}
+	s.failures.reset(ip)
 
 	token, err := s.authManager.GenerateToken(user)
 	if err != nil {
@@ -543,10 +845,13 @@ func (s *Server) handleAuthLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.authManager.SetAuthCookie(w, token)
+	s.setCSRFCookie(w)
 
 	s.sendJSON(w, http.StatusOK, map[string]any{
-		"success": true,
-		"token":   token,
+		"success":                  true,
+		"token":                    token,
+		"password_change_required": user.MustChangePassword,
+		"email_verified":           user.EmailVerified,
 		"user": map[string]any{
 			"id":       user.ID,
 			"username": user.Username,
@@ -559,6 +864,7 @@ func (s *Server) handleAuthLogin(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleAuthLogout(w http.ResponseWriter, r *http.Request) {
 	if s.authManager != nil {
 		s.authManager.ClearAuthCookie(w)
+		clearCSRFCookie(w)
 	}
 	
 	s.sendJSON(w, http.StatusOK, map[string]any{
@@ -572,6 +878,8 @@ func (s *Server) globalAuthMiddleware() func(http.Handler) http.Handler {
 			publicPaths := []string{
 				"/login",
 				"/api/auth/login",
+				"/api/auth/verify-email",
+				"/readyz",
 				"/api/docs",
 				"/api/openapi.json",
 			}
@@ -589,6 +897,24 @@ func (s *Server) globalAuthMiddleware() func(http.Handler) http.Handler {
 				return
 			}
 
+			if s.isPublicReadRequest(r) {
+				if user, err := s.authManager.GetUserFromToken(r); err == nil {
+					r = r.WithContext(context.WithValue(r.Context(), "user", user))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if key := r.Header.Get(auth.APIKeyHeader); key != "" && s.authManager.UsesAPIKeys() {
+				user, err := s.authManager.GetUserFromAPIKey(key)
+				if err != nil {
+					s.handleAuthError(w, r, "Invalid API key")
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "user", user)))
+				return
+			}
+
 			token, err := s.authManager.GetTokenFromRequest(r)
 			if err != nil {
 				s.handleAuthError(w, r, "Authentication required")
@@ -607,19 +933,81 @@ func (s *Server) globalAuthMiddleware() func(http.Handler) http.Handler {
 				return
 			}
 
+			if !s.checkCSRF(w, r) {
+				return
+			}
+
+			if user.MustChangePassword && !passwordChangeAllowed(r.URL.Path) {
+				s.handlePasswordChangeRequired(w, r)
+				return
+			}
+
+			if s.authManager.RequiresVerification(user) && verificationBlocks(r) {
+				s.handleVerificationRequired(w)
+				return
+			}
+
 			ctx := context.WithValue(r.Context(), "user", user)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-func (s *Server) handleAuthError(w http.ResponseWriter, r *http.Request, message string) {
-	if strings.Contains(r.Header.Get("Accept"), "text/html") {
-		returnURL := r.URL.Path
-		if r.URL.RawQuery != "" {
-			returnURL += "?" + r.URL.RawQuery
+func (s *Server) isPublicReadRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] == "api" {
+		parts = parts[1:]
+		// Versioned routes put the version before the model: /api/v1/user.
+		if len(parts) > 1 {
+			if model, ok := s.schema.GetModelByRoute(parts[1]); ok {
+				if _, versioned := model.Versions[parts[0]]; versioned {
+					parts = parts[1:]
+				}
+			}
 		}
-		http.Redirect(w, r, "/login?return="+returnURL, http.StatusSeeOther)
+	}
+	if len(parts) == 0 || len(parts) > 2 || (len(parts) == 2 && parts[1] == "new") {
+		return false
+	}
+
+	model, ok := s.schema.GetModelByRoute(parts[0])
+	return ok && model.PublicRead()
+}
+
+func (s *Server) isPublicRead(modelName string) bool {
+	model, ok := s.schema.GetModel(modelName)
+	return ok && model.PublicRead()
+}
+
+// showTrashed reports whether the request asked for soft-deleted records
+// with ?trashed=true and may see them. Deleted rows are only listed to
+// callers who could restore them, so public reads never expose them.
+func (s *Server) showTrashed(r *http.Request, model *parser.Model) bool {
+	if !model.SoftDelete || r.URL.Query().Get("trashed") != "true" {
+		return false
+	}
+	if s.authManager == nil || !s.authManager.IsEnabled() {
+		return true
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	return ok && s.authManager.CheckPermission(user.Username, model.Name, true)
+}
+
+// API routes never redirect: fetch/XHR callers send browser Accept headers
+// too and would otherwise silently follow the redirect to the login page.
+func (s *Server) handleAuthError(w http.ResponseWriter, r *http.Request, message string) {
+	if !isAPIPath(r.URL.Path) {
+		http.Redirect(w, r, "/login?"+url.Values{"return": {r.URL.RequestURI()}}.Encode(), http.StatusSeeOther)
+		return
+	}
+	if s.config.Server.Auth.APIUnauthorized == parser.APIUnauthorizedPage && isBrowserNavigation(r) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(ui.GetLoginHTML(s.config, safeReturnURL(r.URL.RequestURI()))))
 		return
 	}
 	s.sendJSON(w, http.StatusUnauthorized, map[string]any{
@@ -628,6 +1016,28 @@ func (s *Server) handleAuthError(w http.ResponseWriter, r *http.Request, message
 	})
 }
 
+// safeReturnURL keeps a post-login return target only when it is a path on
+// this site, so the login page can't be used as an open redirect.
+func safeReturnURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Opaque != "" ||
+		!strings.HasPrefix(raw, "/") || strings.HasPrefix(raw, "//") || strings.Contains(raw, "\\") {
+		return "/"
+	}
+	return raw
+}
+
+func isAPIPath(path string) bool {
+	return path == "/api" || strings.HasPrefix(path, "/api/")
+}
+
+func isBrowserNavigation(r *http.Request) bool {
+	if r.Header.Get("X-Requested-With") == "XMLHttpRequest" {
+		return false
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
 func (s *Server) authMiddleware(handler http.HandlerFunc) http.HandlerFunc {
 	return handler
 }
@@ -641,15 +1051,82 @@ func (s *Server) setupProtectedModelRoutes(modelName string) {
 	s.router.HandleFunc(basePath+"/{id}", s.authMiddleware(s.handleModelView(modelName))).Methods("GET")
 }
 
+func (s *Server) sendValidationError(w http.ResponseWriter, err error) {
+	rejected := validationRejection(err)
+	s.sendJSON(w, rejected.status, rejected.body)
+}
+
+func (s *Server) sendWriteError(w http.ResponseWriter, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		s.sendJSON(w, http.StatusNotFound, map[string]any{
+			"success": false,
+			"error":   "Record not found",
+		})
+		return
+	}
+
+	if errors.Is(err, database.ErrDatabaseBusy) {
+		w.Header().Set("Retry-After", "1")
+		s.sendJSON(w, http.StatusServiceUnavailable, map[string]any{
+			"success":   false,
+			"error":     "Database is busy, please retry",
+			"retryable": true,
+		})
+		return
+	}
+
+	if errors.Is(err, database.ErrUniqueViolation) {
+		response := map[string]any{
+			"success": false,
+			"error":   "A record with the same unique value already exists",
+		}
+		var violation *database.UniqueViolation
+		if errors.As(err, &violation) && violation.Field != "" {
+			response["field"] = violation.Field
+			if message := s.uniqueMessage(violation.Table, violation.Field); message != "" {
+				response["error"] = message
+			}
+		}
+		s.sendJSON(w, http.StatusConflict, response)
+		return
+	}
+
+	s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
+
+func (s *Server) uniqueMessage(table, fieldName string) string {
+	model, ok := s.schema.GetModelByRoute(table)
+	if !ok {
+		return ""
+	}
+	for _, field := range model.Fields {
+		if field.Name == fieldName {
+			return field.UniqueMessage
+		}
+	}
+	return ""
+}
+
 func (s *Server) sendJSON(w http.ResponseWriter, status int, data any) {
+	if _, ok := w.(*xmlResponseWriter); ok {
+		s.sendXML(w, status, data)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
+	encoder := json.NewEncoder(w)
+	if _, ok := w.(*prettyResponseWriter); ok {
+		encoder.SetIndent("", "  ")
+	}
+	encoder.Encode(data)
 }
 
 func (s *Server) handleAPIList(modelName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if s.authManager != nil && s.authManager.IsEnabled() {
+		if s.authManager != nil && s.authManager.IsEnabled() && !s.isPublicRead(modelName) {
 			user, ok := r.Context().Value("user").(*auth.User)
 			if !ok || !s.authManager.CheckPermission(user.Username, modelName, false) {
 				s.sendJSON(w, http.StatusForbidden, map[string]any{
@@ -661,16 +1138,32 @@ func (s *Server) handleAPIList(modelName string) http.HandlerFunc {
 		}
 
 		params := s.parseQueryParams(r)
-		results, err := s.db.Query(modelName, params)
-		if err != nil {
-			s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+		if err := s.checkQueryParams(r, modelName, params); err != nil {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
 				"success": false,
 				"error":   err.Error(),
 			})
 			return
 		}
+		countLimit := 0
+		if model, ok := s.schema.GetModel(modelName); ok {
+			if s.showTrashed(r, model) {
+				params.Filters = append(params.Filters, parser.Filter{
+					Field:    parser.SoftDeleteField,
+					Operator: "not_null",
+				})
+			}
+			countLimit = model.CountLimit
+		}
+		if r.URL.Query().Get("count") == "false" {
+			countLimit = -1
+		}
+
+		if s.streamList(w, r, modelName, params, countLimit) {
+			return
+		}
 
-		total, err := s.db.Count(modelName, params.Filters)
+		results, meta, err := s.queryList(r, modelName, params, countLimit)
 		if err != nil {
 			s.sendJSON(w, http.StatusInternalServerError, map[string]any{
 				"success": false,
@@ -679,27 +1172,43 @@ func (s *Server) handleAPIList(modelName string) http.HandlerFunc {
 			return
 		}
 
-		totalPages := int(total) / params.PageSize
-		if int(total)%params.PageSize > 0 {
-			totalPages++
-		}
-
+		setRangeHeaders(w, strings.ToLower(modelName), params, len(results), meta)
 		s.sendJSON(w, http.StatusOK, parser.APIResponse{
 			Success: true,
-			Data:    results,
-			Meta: &parser.Meta{
-				Page:       params.Page,
-				PageSize:   params.PageSize,
-				TotalCount: total,
-				TotalPages: totalPages,
-			},
+			Data:    s.orderRecords(modelName, s.encodeRecords(modelName, s.hideRestrictedFields(r, modelName, results...))),
+			Meta:    meta,
 		})
 	}
 }
 
+// setRangeHeaders mirrors the list meta in X-Total-Count and Content-Range
+// ("user 0-19/42") for table libraries that read the total from headers.
+// The total is "*" when counting was skipped; a negative n (rows not known
+// up front) leaves Content-Range out.
+func setRangeHeaders(w http.ResponseWriter, unit string, params parser.QueryParams, n int, meta *parser.Meta) {
+	total := "*"
+	if meta.TotalCount != nil {
+		total = strconv.FormatInt(*meta.TotalCount, 10)
+		w.Header().Set("X-Total-Count", total)
+	}
+
+	if n < 0 {
+		return
+	}
+	if n == 0 {
+		w.Header().Set("Content-Range", unit+" */"+total)
+		return
+	}
+	start := 0
+	if params.Page > 1 {
+		start = (params.Page - 1) * params.PageSize
+	}
+	w.Header().Set("Content-Range", fmt.Sprintf("%s %d-%d/%s", unit, start, start+n-1, total))
+}
+
 func (s *Server) handleAPIGet(modelName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if s.authManager != nil && s.authManager.IsEnabled() {
+		if s.authManager != nil && s.authManager.IsEnabled() && !s.isPublicRead(modelName) {
 			user, ok := r.Context().Value("user").(*auth.User)
 			if !ok || !s.authManager.CheckPermission(user.Username, modelName, false) {
 				s.sendJSON(w, http.StatusForbidden, map[string]any{
@@ -713,7 +1222,7 @@ func (s *Server) handleAPIGet(modelName string) http.HandlerFunc {
 		vars := mux.Vars(r)
 		id := vars["id"]
 
-		result, err := s.db.Get(modelName, id)
+		result, err := s.getRecord(r, modelName, id)
 		if err != nil {
 			if err.Error() == "sql: no rows in result set" {
 				s.sendJSON(w, http.StatusNotFound, map[string]any{
@@ -729,13 +1238,118 @@ func (s *Server) handleAPIGet(modelName string) http.HandlerFunc {
 			return
 		}
 
+		if include := r.URL.Query().Get("include"); include != "" {
+			if err := s.includeRelated(r, modelName, result, include); err != nil {
+				s.sendJSON(w, http.StatusBadRequest, map[string]any{
+					"success": false,
+					"error":   err.Error(),
+				})
+				return
+			}
+		}
+
 		s.sendJSON(w, http.StatusOK, parser.APIResponse{
 			Success: true,
-			Data:    result,
+			Data:    s.orderFields(modelName, s.encodeIDs(modelName, s.hideRestrictedField(r, modelName, result))),
 		})
 	}
 }
 
+const defaultMaxIncludeDepth = 3
+
+func (s *Server) maxIncludeDepth() int {
+	if s.config.Server.MaxIncludeDepth > 0 {
+		return s.config.Server.MaxIncludeDepth
+	}
+	return defaultMaxIncludeDepth
+}
+
+// Nested includes such as include=comment.reply are cut off at
+// server.max_include_depth, and a record already on the current path is
+// not expanded again so self-referential data cannot loop.
+func (s *Server) includeRelated(r *http.Request, modelName string, record map[string]any, include string) error {
+	for _, path := range strings.Split(include, ",") {
+		segments := strings.Split(strings.TrimSpace(path), ".")
+		if len(segments) > s.maxIncludeDepth() {
+			segments = segments[:s.maxIncludeDepth()]
+		}
+
+		ancestors := map[string]bool{recordKey(modelName, record): true}
+		if err := s.includePath(r, modelName, record, segments, ancestors); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) includePath(r *http.Request, modelName string, record map[string]any, segments []string, ancestors map[string]bool) error {
+	name := strings.TrimSpace(segments[0])
+	if name == "" {
+		return nil
+	}
+
+	related, ok := s.schema.GetModelByRoute(name)
+	if !ok {
+		return fmt.Errorf("unknown include: %s", name)
+	}
+
+	fields := s.schema.ReverseRelations(modelName)[related.Name]
+	if len(fields) == 0 {
+		return fmt.Errorf("%s is not related to %s", related.Name, modelName)
+	}
+
+	if s.authManager != nil && s.authManager.IsEnabled() && !related.PublicRead() {
+		user, ok := r.Context().Value("user").(*auth.User)
+		if !ok || !s.authManager.CheckPermission(user.Username, related.Name, false) {
+			return fmt.Errorf("you don't have permission to read %s", related.Name)
+		}
+	}
+
+	field := fields[0]
+	filters := []parser.Filter{{Field: field.Name, Operator: "=", Value: record["id"]}}
+	results, err := s.dbFor(r).Query(related.Name, parser.QueryParams{
+		Filters: filters,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(segments) > 1 {
+		for _, child := range results {
+			key := recordKey(related.Name, child)
+			if ancestors[key] {
+				continue
+			}
+			ancestors[key] = true
+			err := s.includePath(r, related.Name, child, segments[1:], ancestors)
+			delete(ancestors, key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	s.encodeRecords(related.Name, s.hideRestrictedFields(r, related.Name, results...))
+
+	key := strings.ToLower(related.Name)
+	if field.IsOneToOne() {
+		if len(results) > 0 {
+			record[key] = results[0]
+		} else {
+			record[key] = nil
+		}
+	} else {
+		record[key] = results
+	}
+
+	return nil
+}
+
+func recordKey(modelName string, record map[string]any) string {
+	return modelName + ":" + fmt.Sprint(record["id"])
+}
+
 func (s *Server) handleAPICreate(modelName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if s.authManager != nil && s.authManager.IsEnabled() {
@@ -749,44 +1363,62 @@ func (s *Server) handleAPICreate(modelName string) http.HandlerFunc {
 			}
 		}
 
-		var data map[string]any
-		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-			s.sendJSON(w, http.StatusBadRequest, map[string]any{
-				"success": false,
-				"error":   "Invalid JSON",
-			})
+		idempotencyKey := ""
+		store := s.idempotencyStore()
+		if key := r.Header.Get("Idempotency-Key"); key != "" && store != nil {
+			idempotencyKey = clientKey(r) + ":" + key
+			if !s.reserveIdempotencyKey(w, r, store, modelName, idempotencyKey) {
+				return
+			}
+			// Any response but a created record frees the key for a retry.
+			defer func() {
+				if idempotencyKey != "" {
+					if err := store.ReleaseIdempotencyKey(idempotencyKey); err != nil {
+						log.Printf("failed to release idempotency key: %v", err)
+					}
+				}
+			}()
+		}
+
+		if !s.supportedBodyType(r) {
+			s.sendUnsupportedMediaType(w)
 			return
 		}
 
-		if err := s.validator.ValidateCreate(modelName, data); err != nil {
+		data, err := s.decodeRecord(modelName, r)
+		if err != nil {
 			s.sendJSON(w, http.StatusBadRequest, map[string]any{
 				"success": false,
-				"error":   err.Error(),
+				"error":   invalidBodyMessage(r),
 			})
 			return
 		}
 
-		id, err := s.db.Create(modelName, data)
-		if err != nil {
-			s.sendJSON(w, http.StatusInternalServerError, map[string]any{
-				"success": false,
-				"error":   err.Error(),
-			})
+		if rejected := s.prepareCreate(r, modelName, data); rejected != nil {
+			s.sendJSON(w, rejected.status, rejected.body)
 			return
 		}
 
-		result, err := s.db.Get(modelName, id)
+		id, result, err := s.createRecord(r, modelName, data)
 		if err != nil {
-			s.sendJSON(w, http.StatusInternalServerError, map[string]any{
-				"success": false,
-				"error":   err.Error(),
-			})
+			s.sendWriteError(w, err)
 			return
 		}
 
+		if idempotencyKey != "" {
+			if err := store.SaveIdempotencyKey(idempotencyKey, modelName, id, idempotencyKeyTTL); err != nil {
+				log.Printf("failed to save idempotency key: %v", err)
+			}
+			idempotencyKey = ""
+		}
+
+		s.recordAudit(r, modelName, "create", id)
+		s.notifyWebhooks(modelName, parser.WebhookCreate, result)
+		s.purgeCache(modelName, parser.WebhookCreate, id)
+
 		s.sendJSON(w, http.StatusCreated, parser.APIResponse{
 			Success: true,
-			Data:    result,
+			Data:    s.orderFields(modelName, s.encodeIDs(modelName, s.hideRestrictedField(r, modelName, result))),
 		})
 	}
 }
@@ -807,45 +1439,72 @@ func (s *Server) handleAPIUpdate(modelName string) http.HandlerFunc {
 		vars := mux.Vars(r)
 		id := vars["id"]
 
-		var data map[string]any
-		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		if !s.supportedBodyType(r) {
+			s.sendUnsupportedMediaType(w)
+			return
+		}
+
+		data, err := s.decodeRecord(modelName, r)
+		if err != nil {
 			s.sendJSON(w, http.StatusBadRequest, map[string]any{
 				"success": false,
-				"error":   "Invalid JSON",
+				"error":   invalidBodyMessage(r),
 			})
 			return
 		}
 
+		// Forms never show hidden fields, so their blank values must not
+		// overwrite what is stored.
+		s.hideRestrictedField(r, modelName, data)
+
 		data = s.filterEmptyPasswordFields(modelName, data)
 
-		if err := s.validator.ValidateUpdate(modelName, data); err != nil {
+		if err := s.validator.Transform(modelName, data); err != nil {
 			s.sendJSON(w, http.StatusBadRequest, map[string]any{
 				"success": false,
 				"error":   err.Error(),
 			})
 			return
 		}
-
-		if err := s.db.Update(modelName, id, data); err != nil {
-			s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+		if err := s.runHooks(modelName, parser.HookBeforeUpdate, id, data); err != nil {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
 				"success": false,
 				"error":   err.Error(),
 			})
 			return
 		}
-
-		result, err := s.db.Get(modelName, id)
-		if err != nil {
-			s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+		s.validator.Normalize(modelName, data)
+		if err := s.validator.ValidateUpdate(modelName, data); err != nil {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
 				"success": false,
 				"error":   err.Error(),
 			})
 			return
 		}
 
+		if err := s.validator.ValidateRelations(modelName, data, s.dbFor(r)); err != nil {
+			s.sendValidationError(w, err)
+			return
+		}
+
+		if err := s.validator.ValidateChanges(modelName, id, data, s.db); err != nil {
+			s.sendValidationError(w, err)
+			return
+		}
+
+		result, err := s.updateRecord(r, modelName, id, data)
+		if err != nil {
+			s.sendWriteError(w, err)
+			return
+		}
+
+		s.recordAudit(r, modelName, "update", id)
+		s.notifyWebhooks(modelName, parser.WebhookUpdate, result)
+		s.purgeCache(modelName, parser.WebhookUpdate, id)
+
 		s.sendJSON(w, http.StatusOK, parser.APIResponse{
 			Success: true,
-			Data:    result,
+			Data:    s.orderFields(modelName, s.encodeIDs(modelName, s.hideRestrictedField(r, modelName, result))),
 		})
 	}
 }
@@ -866,24 +1525,211 @@ func (s *Server) handleAPIDelete(modelName string) http.HandlerFunc {
 		vars := mux.Vars(r)
 		id := vars["id"]
 
-		if err := s.db.Delete(modelName, id); err != nil {
+		if err := s.runHooks(modelName, parser.HookBeforeDelete, id, map[string]any{}); err != nil {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		if err := s.dbFor(r).Delete(modelName, id); err != nil {
+			s.sendWriteError(w, err)
+			return
+		}
+
+		s.recordAudit(r, modelName, "delete", id)
+		s.notifyWebhooks(modelName, parser.WebhookDelete, map[string]any{"id": id})
+		s.purgeCache(modelName, parser.WebhookDelete, id)
+
+		s.sendJSON(w, http.StatusOK, parser.APIResponse{
+			Success: true,
+		})
+	}
+}
+
+func (s *Server) handleAPIDeletePreview(modelName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.authManager != nil && s.authManager.IsEnabled() {
+			user, ok := r.Context().Value("user").(*auth.User)
+			if !ok || !s.authManager.CheckPermission(user.Username, modelName, false) {
+				s.sendJSON(w, http.StatusForbidden, map[string]any{
+					"success": false,
+					"error":   "You don't have permission to read this resource",
+				})
+				return
+			}
+		}
+
+		vars := mux.Vars(r)
+		id := vars["id"]
+
+		if _, err := s.dbFor(r).Get(modelName, id); err != nil {
+			if err == sql.ErrNoRows {
+				s.sendJSON(w, http.StatusNotFound, map[string]any{
+					"success": false,
+					"error":   "Record not found",
+				})
+			} else {
+				s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+					"success": false,
+					"error":   err.Error(),
+				})
+			}
+			return
+		}
+
+		reverse := s.schema.ReverseRelations(modelName)
+		names := make([]string, 0, len(reverse))
+		for name := range reverse {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		user, _ := r.Context().Value("user").(*auth.User)
+		effects := []map[string]any{}
+		for _, name := range names {
+			// A count of records the caller can't read would reveal them.
+			if s.authManager != nil && s.authManager.IsEnabled() && !s.isPublicRead(name) &&
+				(user == nil || !s.authManager.CheckPermission(user.Username, name, false)) {
+				continue
+			}
+			for _, field := range reverse[name] {
+				count, err := s.dbFor(r).Count(name, []parser.Filter{{Field: field.Name, Operator: "=", Value: id}})
+				if err != nil {
+					s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+						"success": false,
+						"error":   err.Error(),
+					})
+					return
+				}
+				if count == 0 {
+					continue
+				}
+
+				action := field.OnDelete
+				if action == "" {
+					action = "none"
+				}
+				effects = append(effects, map[string]any{
+					"model":  strings.ToLower(name),
+					"field":  field.Name,
+					"action": action,
+					"count":  count,
+				})
+			}
+		}
+
+		s.sendJSON(w, http.StatusOK, parser.APIResponse{
+			Success: true,
+			Data:    effects,
+		})
+	}
+}
+
+func (s *Server) handleAPIRestore(modelName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.authManager != nil && s.authManager.IsEnabled() {
+			user, ok := r.Context().Value("user").(*auth.User)
+			if !ok || !s.authManager.CheckPermission(user.Username, modelName, true) {
+				s.sendJSON(w, http.StatusForbidden, map[string]any{
+					"success": false,
+					"error":   "You don't have permission to restore this resource",
+				})
+				return
+			}
+		}
+
+		model, ok := s.schema.GetModel(modelName)
+		if !ok || !model.SoftDelete {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
+				"success": false,
+				"error":   "Model does not support restore",
+			})
+			return
+		}
+
+		vars := mux.Vars(r)
+		id := vars["id"]
+
+		deleted, err := s.dbFor(r).Query(modelName, parser.QueryParams{
+			Filters: []parser.Filter{
+				{Field: "id", Operator: "=", Value: id},
+				{Field: parser.SoftDeleteField, Operator: "not_null"},
+			},
+		})
+		if err != nil {
 			s.sendJSON(w, http.StatusInternalServerError, map[string]any{
 				"success": false,
 				"error":   err.Error(),
 			})
 			return
 		}
+		if len(deleted) == 0 {
+			s.sendJSON(w, http.StatusNotFound, map[string]any{
+				"success": false,
+				"error":   "Record not found",
+			})
+			return
+		}
+
+		if model.RestoreWindow > 0 {
+			deletedAt, ok := parseTimestamp(deleted[0][parser.SoftDeleteField])
+			if !ok || time.Since(deletedAt) > model.RestoreWindow {
+				s.sendJSON(w, http.StatusGone, map[string]any{
+					"success": false,
+					"error":   "Restore window has expired",
+				})
+				return
+			}
+		}
+
+		result, err := s.restoreRecord(r, modelName, id)
+		if err != nil {
+			s.sendWriteError(w, err)
+			return
+		}
+		s.recordAudit(r, modelName, "restore", id)
+		s.purgeCache(modelName, parser.WebhookRestore, id)
+		s.notifyWebhooks(modelName, parser.WebhookRestore, result)
 
 		s.sendJSON(w, http.StatusOK, parser.APIResponse{
 			Success: true,
+			Data:    s.orderFields(modelName, s.encodeIDs(modelName, s.hideRestrictedField(r, modelName, result))),
 		})
 	}
 }
 
+func parseTimestamp(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+var filterOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "!=",
+	"gt":   ">",
+	"gte":  ">=",
+	"lt":   "<",
+	"lte":  "<=",
+	"like": "like",
+	"in":   "in",
+}
+
 func (s *Server) parseQueryParams(r *http.Request) parser.QueryParams {
+	defaultSize, maxSize := s.config.Server.Pagination.Limits()
 	params := parser.QueryParams{
 		Page:     1,
-		PageSize: 20,
+		PageSize: defaultSize,
 	}
 
 	if page := r.URL.Query().Get("page"); page != "" {
@@ -893,7 +1739,7 @@ func (s *Server) parseQueryParams(r *http.Request) parser.QueryParams {
 	}
 
 	if pageSize := r.URL.Query().Get("page_size"); pageSize != "" {
-		if ps, err := strconv.Atoi(pageSize); err == nil && ps > 0 && ps <= 100 {
+		if ps, err := strconv.Atoi(pageSize); err == nil && ps > 0 && ps <= maxSize {
 			params.PageSize = ps
 		}
 	}
@@ -914,13 +1760,42 @@ func (s *Server) parseQueryParams(r *http.Request) parser.QueryParams {
 
 	params.Search = r.URL.Query().Get("search")
 
+	if values := splitIDs(r.URL.Query().Get("ids")); len(values) > 0 {
+		params.Filters = append(params.Filters, parser.Filter{
+			Field:    "id",
+			Operator: "in",
+			Value:    values,
+		})
+		params.Page = 1
+		params.PageSize = len(values)
+		if params.PageSize > maxSize {
+			params.PageSize = maxSize
+		}
+	}
+
 	for key, values := range r.URL.Query() {
 		if strings.HasPrefix(key, "filter.") && len(values) > 0 {
 			field := strings.TrimPrefix(key, "filter.")
+			operator := "="
+			if name, op, ok := strings.Cut(field, "__"); ok {
+				if mapped, known := filterOperators[op]; known {
+					field, operator = name, mapped
+				}
+			}
+
+			var value any = values[0]
+			if operator == "in" {
+				items := []any{}
+				for _, item := range strings.Split(values[0], ",") {
+					items = append(items, strings.TrimSpace(item))
+				}
+				value = items
+			}
+
 			params.Filters = append(params.Filters, parser.Filter{
 				Field:    field,
-				Operator: "=",
-				Value:    values[0],
+				Operator: operator,
+				Value:    value,
 			})
 		}
 	}
@@ -928,6 +1803,208 @@ func (s *Server) parseQueryParams(r *http.Request) parser.QueryParams {
 	return params
 }
 
+// splitIDs parses a comma-separated ?ids= value, skipping empty entries.
+func splitIDs(ids string) []any {
+	values := []any{}
+	for _, id := range strings.Split(ids, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			values = append(values, id)
+		}
+	}
+	return values
+}
+
+// checkQueryParams rejects sort and filter fields the model doesn't
+// declare, so only known column names ever reach the SQL builder. Fields
+// hidden from the caller by read_roles are reported as unknown too, since
+// filtering or sorting on them would reveal their values.
+func (s *Server) checkQueryParams(r *http.Request, modelName string, params parser.QueryParams) error {
+	// ?ids= returns every listed record on one page, so it is held to the
+	// same limit as page_size.
+	if _, maxSize := s.config.Server.Pagination.Limits(); len(splitIDs(r.URL.Query().Get("ids"))) > maxSize {
+		return fmt.Errorf("ids lists more than the maximum of %d records", maxSize)
+	}
+	for _, sort := range params.Sort {
+		if !s.queryableField(r, modelName, sort.Field) {
+			return fmt.Errorf("unknown sort field '%s'", sort.Field)
+		}
+	}
+	model, _ := s.schema.GetModel(modelName)
+	for _, filter := range params.Filters {
+		name := filter.Field
+		if model != nil && model.HasJSONPath(filter.Field) {
+			name, _, _ = parser.JSONPath(filter.Field)
+		}
+		if !s.queryableField(r, modelName, name) {
+			return fmt.Errorf("unknown filter field '%s'", filter.Field)
+		}
+	}
+	return nil
+}
+
+func (s *Server) queryableField(r *http.Request, modelName, name string) bool {
+	if name == "id" {
+		return true
+	}
+	model, ok := s.schema.GetModel(modelName)
+	if !ok {
+		return false
+	}
+	field, ok := s.schema.GetField(modelName, name)
+	return ok && s.fieldReadable(r, model, *field)
+}
+
+func (s *Server) decodeRecord(modelName string, r *http.Request) (map[string]any, error) {
+	var data map[string]any
+	if isXMLRequest(r) {
+		record, err := decodeXMLRecord(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		data = record
+	} else {
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&data); err != nil {
+			return nil, err
+		}
+	}
+
+	s.decodeValues(modelName, r, data)
+	return data, nil
+}
+
+// decodeValues gives the values of a decoded record the types its fields
+// expect: XML text is parsed, JSON numbers become int64 or float64, and
+// coerce_types accepts string spellings.
+func (s *Server) decodeValues(modelName string, r *http.Request, data map[string]any) {
+	model, ok := s.schema.GetModel(modelName)
+	if isXMLRequest(r) {
+		if ok {
+			coerceXMLValues(model, data)
+		}
+		return
+	}
+
+	for key, value := range data {
+		data[key] = normalizeNumbers(value)
+	}
+
+	if s.config.Server.CoerceTypes && ok {
+		coerceTypes(model.Fields, data)
+	}
+}
+
+func invalidBodyMessage(r *http.Request) string {
+	if isXMLRequest(r) {
+		return "Invalid XML"
+	}
+	return "Invalid JSON"
+}
+
+func normalizeNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []any:
+		for i := range v {
+			v[i] = normalizeNumbers(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = normalizeNumbers(v[key])
+		}
+	}
+	return value
+}
+
+func (s *Server) encodeIDs(modelName string, record map[string]any) map[string]any {
+	if !s.config.Server.StringIDs || record == nil {
+		return record
+	}
+
+	model, ok := s.schema.GetModel(modelName)
+	if !ok {
+		return record
+	}
+
+	for _, field := range model.Fields {
+		if field.Type != parser.FieldTypeID && field.Type != parser.FieldTypeRelation {
+			continue
+		}
+		switch v := record[field.Name].(type) {
+		case int64:
+			record[field.Name] = strconv.FormatInt(v, 10)
+		case int:
+			record[field.Name] = strconv.Itoa(v)
+		}
+	}
+
+	return record
+}
+
+func (s *Server) hideRestrictedFields(r *http.Request, modelName string, records ...map[string]any) []map[string]any {
+	model, ok := s.schema.GetModel(modelName)
+	if !ok {
+		return records
+	}
+
+	for _, field := range model.Fields {
+		if s.fieldReadable(r, model, field) {
+			continue
+		}
+		for _, record := range records {
+			delete(record, field.Name)
+		}
+	}
+	return records
+}
+
+// fieldReadable reports whether the caller may read field through the
+// route the request came in on: both read_roles and the API version apply.
+func (s *Server) fieldReadable(r *http.Request, model *parser.Model, field parser.Field) bool {
+	return !s.fieldHidden(r, field) && model.VersionExposes(apiVersion(r), field)
+}
+
+func (s *Server) fieldHidden(r *http.Request, field parser.Field) bool {
+	if len(field.ReadRoles) == 0 || s.authManager == nil || !s.authManager.IsEnabled() {
+		return false
+	}
+	if user, ok := r.Context().Value("user").(*auth.User); ok {
+		return !hasRole(field.ReadRoles, user.Role)
+	}
+	return true
+}
+
+func (s *Server) hideRestrictedField(r *http.Request, modelName string, record map[string]any) map[string]any {
+	if record == nil {
+		return nil
+	}
+	return s.hideRestrictedFields(r, modelName, record)[0]
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, candidate := range roles {
+		if candidate == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) encodeRecords(modelName string, records []map[string]any) []map[string]any {
+	for _, record := range records {
+		s.encodeIDs(modelName, record)
+	}
+	return records
+}
+
 func (s *Server) filterEmptyPasswordFields(modelName string, data map[string]any) map[string]any {
 	model, exists := s.schema.GetModel(modelName)
 	if !exists {
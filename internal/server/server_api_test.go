@@ -1,14 +1,29 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/yamlforge/yamlforge/internal/auth"
+	"github.com/yamlforge/yamlforge/internal/database"
 	"github.com/yamlforge/yamlforge/internal/parser"
 	"github.com/yamlforge/yamlforge/internal/validation"
 )
@@ -441,4 +456,3324 @@ func TestServer_Render_UnknownTemplate(t *testing.T) {
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("Expected status 500, got %d", w.Code)
 	}
-}
\ No newline at end of file
+}
+
+func TestServer_HandleAPIGet_IncludeOneToOne(t *testing.T) {
+	config := createTestConfig()
+	server := New(config)
+	server.schema = createTestSchema()
+	server.schema.Models["Profile"] = &parser.Model{
+		Name: "Profile",
+		Fields: []parser.Field{
+			{Name: "id", Type: parser.FieldTypeID, Primary: true},
+			{Name: "bio", Type: parser.FieldTypeText},
+			{Name: "user_id", Type: parser.FieldTypeRelation, RelatedTo: "User", RelationType: "one_to_one", Unique: true},
+		},
+	}
+	server.db = NewMockDatabase()
+
+	mockDB := server.db.(*MockDatabase)
+	id, _ := mockDB.Create("User", map[string]interface{}{"name": "John Doe", "email": "john@example.com"})
+	mockDB.Create("Profile", map[string]interface{}{"bio": "Hello", "user_id": id})
+
+	req := httptest.NewRequest("GET", "/api/user/1?include=profile", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": toString(id)})
+	w := httptest.NewRecorder()
+
+	server.handleAPIGet("User")(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	var profile map[string]interface{}
+	if err := json.Unmarshal(response.Data["profile"], &profile); err != nil {
+		t.Fatalf("Expected profile to be a single object, got %s", response.Data["profile"])
+	}
+	if profile["bio"] != "Hello" {
+		t.Errorf("Expected included profile bio, got %v", profile["bio"])
+	}
+}
+
+func TestServer_HandleAPIGet_IncludeUnrelated(t *testing.T) {
+	config := createTestConfig()
+	server := New(config)
+	server.schema = createTestSchema()
+	server.db = NewMockDatabase()
+
+	mockDB := server.db.(*MockDatabase)
+	id, _ := mockDB.Create("User", map[string]interface{}{"name": "John Doe", "email": "john@example.com"})
+
+	req := httptest.NewRequest("GET", "/api/user/1?include=user", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": toString(id)})
+	w := httptest.NewRecorder()
+
+	server.handleAPIGet("User")(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestServer_HandleAPIGet_NestedIncludeDepth(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Category": {
+				Name: "Category",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "parent_id", Type: parser.FieldTypeRelation, RelatedTo: "Category"},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.config.Server.MaxIncludeDepth = 2
+
+	// Two categories that are each other's parent form a cycle.
+	a, _ := db.Create("Category", map[string]any{"name": "a"})
+	b, _ := db.Create("Category", map[string]any{"name": "b", "parent_id": a})
+	if err := db.Update("Category", a, map[string]any{"parent_id": b}); err != nil {
+		t.Fatalf("Failed to link categories: %v", err)
+	}
+
+	get := func(include string) map[string]any {
+		req := httptest.NewRequest("GET", "/api/category/1?include="+include, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(a)})
+		w := httptest.NewRecorder()
+		server.handleAPIGet("Category")(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return response.Data
+	}
+
+	record := get("category.category.category.category")
+	children := record["category"].([]any)
+	if len(children) != 1 || children[0].(map[string]any)["name"] != "b" {
+		t.Fatalf("Expected b as the child of a, got %v", record["category"])
+	}
+	if grandchildren, ok := children[0].(map[string]any)["category"]; !ok || len(grandchildren.([]any)) != 1 {
+		t.Fatalf("Expected second level to be expanded, got %v", children[0])
+	}
+	if _, ok := children[0].(map[string]any)["category"].([]any)[0].(map[string]any)["category"]; ok {
+		t.Error("Expected expansion to stop at the ancestor record and max_include_depth")
+	}
+
+	server.config.Server.MaxIncludeDepth = 5
+	record = get("category.category.category.category")
+	grandchild := record["category"].([]any)[0].(map[string]any)["category"].([]any)[0].(map[string]any)
+	if _, ok := grandchild["category"]; ok {
+		t.Error("Expected a record already on the include path not to be expanded again")
+	}
+}
+
+func TestServer_HandleAPICreate_EmptyRequiredString(t *testing.T) {
+	config := createTestConfig()
+	server := New(config)
+	server.schema = createTestSchema()
+	server.db = NewMockDatabase()
+	server.validator = validation.New(server.schema)
+
+	req := httptest.NewRequest("POST", "/api/user", strings.NewReader(`{"name": "", "email": "jane@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	server.handleAPICreate("User")(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func createTestSQLiteServer(t *testing.T, schema *parser.Schema) (*Server, database.Database) {
+	db, err := database.NewSQLite(&parser.DatabaseConfig{Type: "sqlite", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.CreateSchema(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	server := New(createTestConfig())
+	server.schema = schema
+	server.db = db
+	server.validator = validation.New(schema)
+
+	return server, db
+}
+
+func TestServer_HandleAPIRestore(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name:          "Note",
+				SoftDelete:    true,
+				RestoreWindow: time.Hour,
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: parser.SoftDeleteField, Type: parser.FieldTypeDatetime, Nullable: true},
+				},
+			},
+		},
+	}
+
+	server, db := createTestSQLiteServer(t, schema)
+
+	id, _ := db.Create("Note", map[string]any{"title": "Draft"})
+	vars := map[string]string{"id": fmt.Sprint(id)}
+
+	req := mux.SetURLVars(httptest.NewRequest("DELETE", "/api/note/1", nil), vars)
+	w := httptest.NewRecorder()
+	server.handleAPIDelete("Note")(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected delete to succeed, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/api/note?trashed=true", nil)
+	w = httptest.NewRecorder()
+	server.handleAPIList("Note")(w, req)
+	if !strings.Contains(w.Body.String(), `"total_count":1`) {
+		t.Errorf("Expected deleted note in trash, got: %s", w.Body.String())
+	}
+
+	req = mux.SetURLVars(httptest.NewRequest("POST", "/api/note/1/restore", nil), vars)
+	w = httptest.NewRecorder()
+	server.handleAPIRestore("Note")(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected restore to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/note", nil)
+	w = httptest.NewRecorder()
+	server.handleAPIList("Note")(w, req)
+	if !strings.Contains(w.Body.String(), `"total_count":1`) {
+		t.Errorf("Expected restored note in list, got: %s", w.Body.String())
+	}
+
+	conn := db.(*database.SQLiteDB).GetConnection()
+	if _, err := conn.Exec(`UPDATE "Note" SET deleted_at = datetime('now', '-2 hours') WHERE id = ?`, id); err != nil {
+		t.Fatalf("Failed to age deletion: %v", err)
+	}
+
+	req = mux.SetURLVars(httptest.NewRequest("POST", "/api/note/1/restore", nil), vars)
+	w = httptest.NewRecorder()
+	server.handleAPIRestore("Note")(w, req)
+	if w.Code != http.StatusGone {
+		t.Errorf("Expected status 410 after the restore window, got %d", w.Code)
+	}
+}
+
+func TestServer_HandleAPICreate_StrictFields(t *testing.T) {
+	config := createTestConfig()
+	server := New(config)
+	server.schema = createTestSchema()
+	server.db = NewMockDatabase()
+	server.validator = validation.New(server.schema)
+
+	body := `{"name": "Xavier", "email": "x@example.com", "hacker": "y"}`
+
+	req := httptest.NewRequest("POST", "/api/user", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleAPICreate("User")(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected extra fields to be accepted by default, got %d", w.Code)
+	}
+
+	config.Server.StrictFields = true
+	req = httptest.NewRequest("POST", "/api/user", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	server.handleAPICreate("User")(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 in strict mode, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "unknown fields: hacker") {
+		t.Errorf("Expected unknown field to be listed, got: %s", w.Body.String())
+	}
+}
+
+func TestServer_HandleAPIDeletePreview(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+				},
+			},
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "user_id", Type: parser.FieldTypeRelation, RelatedTo: "User", OnDelete: "cascade"},
+				},
+			},
+		},
+	}
+
+	server, db := createTestSQLiteServer(t, schema)
+
+	userID, _ := db.Create("User", map[string]any{"name": "John"})
+	for i := 0; i < 5; i++ {
+		db.Create("Post", map[string]any{"user_id": userID})
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/api/user/1/delete-preview", nil), map[string]string{"id": fmt.Sprint(userID)})
+	w := httptest.NewRecorder()
+	server.handleAPIDeletePreview("User")(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data []struct {
+			Model  string `json:"model"`
+			Action string `json:"action"`
+			Count  int    `json:"count"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if len(response.Data) != 1 || response.Data[0].Model != "post" || response.Data[0].Action != "cascade" || response.Data[0].Count != 5 {
+		t.Errorf("Expected 5 cascading posts, got %+v", response.Data)
+	}
+
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/api/user/99/delete-preview", nil), map[string]string{"id": "99"})
+	w = httptest.NewRecorder()
+	server.handleAPIDeletePreview("User")(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing record, got %d", w.Code)
+	}
+
+	authManager, err := auth.New(&parser.AuthConfig{
+		Type:   "jwt",
+		Secret: "test-secret",
+		Users: []parser.UserConfig{
+			{Username: "bob", Password: "secret", Role: "user", Permissions: map[string]parser.EntityPermission{"User": {Read: true}}},
+		},
+	}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/api/user/1/delete-preview", nil), map[string]string{"id": fmt.Sprint(userID)})
+	req = req.WithContext(context.WithValue(req.Context(), "user", &auth.User{Username: "bob", Role: "user"}))
+	w = httptest.NewRecorder()
+	server.handleAPIDeletePreview("User")(w, req)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "post") {
+		t.Errorf("Expected posts the caller can't read to be left out, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleHome_RecentRecords(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name: "Note",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.config.UI.Dashboard.Recent = 5
+
+	for i := 1; i <= 6; i++ {
+		if _, err := db.Create("Note", map[string]any{"title": fmt.Sprintf("Note %d", i)}); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	server.handleHome(w, httptest.NewRequest("GET", "/", nil))
+
+	body := w.Body.String()
+	if count := strings.Count(body, `<li><a href="/note/`); count != 5 {
+		t.Errorf("Expected 5 recent notes, got %d", count)
+	}
+	if !strings.Contains(body, `<a href="/note/6">Note 6</a>`) {
+		t.Error("Expected the newest note to be listed")
+	}
+	if strings.Contains(body, `>Note 1</a>`) {
+		t.Error("Expected the oldest note to be left out")
+	}
+}
+
+func TestServer_HandleAPIGet_XML(t *testing.T) {
+	server := New(createTestConfig())
+	server.schema = createTestSchema()
+	server.db = NewMockDatabase()
+
+	id, _ := server.db.Create("User", map[string]any{"name": "John Doe", "email": "john@example.com"})
+
+	req := httptest.NewRequest("GET", "/api/user/1", nil)
+	req.Header.Set("Accept", "application/xml")
+	req = mux.SetURLVars(req, map[string]string{"id": toString(id)})
+	w := httptest.NewRecorder()
+
+	server.contentNegotiationMiddleware(server.handleAPIGet("User")).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml" {
+		t.Errorf("Expected application/xml content type, got %s", ct)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "<success>true</success>") {
+		t.Errorf("Expected success element, got %s", body)
+	}
+	if !strings.Contains(body, "<name>John Doe</name>") || !strings.Contains(body, "<email>john@example.com</email>") {
+		t.Errorf("Expected record fields as elements, got %s", body)
+	}
+
+	req = httptest.NewRequest("GET", "/api/user/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": toString(id)})
+	w = httptest.NewRecorder()
+	server.contentNegotiationMiddleware(server.handleAPIGet("User")).ServeHTTP(w, req)
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON by default, got %s", ct)
+	}
+}
+
+func TestServer_HandleAPIGet_PrettyJSON(t *testing.T) {
+	server := New(createTestConfig())
+	server.schema = createTestSchema()
+	server.db = NewMockDatabase()
+
+	id, _ := server.db.Create("User", map[string]any{"name": "John Doe", "email": "john@example.com"})
+
+	get := func(path string) string {
+		req := mux.SetURLVars(httptest.NewRequest("GET", path, nil), map[string]string{"id": toString(id)})
+		w := httptest.NewRecorder()
+		server.contentNegotiationMiddleware(server.handleAPIGet("User")).ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	if body := get("/api/user/1?pretty=true"); !strings.Contains(body, "\n  \"data\": {") {
+		t.Errorf("Expected indented JSON, got %s", body)
+	}
+	if body := get("/api/user/1"); strings.Count(body, "\n") != 1 {
+		t.Errorf("Expected compact JSON by default, got %s", body)
+	}
+
+	server.config.Server.PrettyJSON = true
+	if body := get("/api/user/1?pretty=false"); strings.Count(body, "\n") != 1 {
+		t.Errorf("Expected ?pretty=false to override server.pretty_json, got %s", body)
+	}
+}
+
+type slowQueryDatabase struct {
+	*MockDatabase
+	queries atomic.Int32
+}
+
+func (db *slowQueryDatabase) Query(model string, params parser.QueryParams) ([]map[string]any, error) {
+	db.queries.Add(1)
+	time.Sleep(50 * time.Millisecond)
+	return db.MockDatabase.Query(model, params)
+}
+
+func TestServer_HandleAPIList_DedupeReads(t *testing.T) {
+	config := createTestConfig()
+	config.Server.DedupeReads = true
+	server := New(config)
+	server.schema = createTestSchema()
+	db := &slowQueryDatabase{MockDatabase: NewMockDatabase()}
+	db.Create("User", map[string]any{"name": "John Doe", "email": "john@example.com"})
+	server.db = db
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	codes := make([]int, 10)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			w := httptest.NewRecorder()
+			server.handleAPIList("User")(w, httptest.NewRequest("GET", "/api/user", nil))
+			codes[i] = w.Code
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("request %d: expected status 200, got %d", i, code)
+		}
+	}
+	if n := db.queries.Load(); n != 1 {
+		t.Errorf("Expected identical concurrent lists to share 1 query, got %d", n)
+	}
+}
+
+func TestServer_HandleAPICreate_XML(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Item": {
+				Name: "Item",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: "quantity", Type: parser.FieldTypeNumber},
+					{Name: "active", Type: parser.FieldTypeBoolean},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	body := `<?xml version="1.0"?><item><title>Widget</title><quantity>3</quantity><active>true</active></item>`
+	req := httptest.NewRequest("POST", "/api/item", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/xml")
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+
+	server.contentNegotiationMiddleware(server.handleAPICreate("Item")).ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "<quantity>3</quantity>") {
+		t.Errorf("Expected XML response with the created record, got %s", w.Body.String())
+	}
+
+	record, err := db.Get("Item", 1)
+	if err != nil {
+		t.Fatalf("Failed to load created item: %v", err)
+	}
+	if record["quantity"] != int64(3) {
+		t.Errorf("Expected quantity to be stored as a number, got %v (%T)", record["quantity"], record["quantity"])
+	}
+
+	req = httptest.NewRequest("POST", "/api/item", strings.NewReader("<item><title>"))
+	req.Header.Set("Content-Type", "application/xml")
+	w = httptest.NewRecorder()
+	server.handleAPICreate("Item")(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Invalid XML") {
+		t.Errorf("Expected 400 Invalid XML, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_Reload(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name:   "User",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/api/post", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected unknown model to 404 before reload, got %d", w.Code)
+	}
+
+	config := createTestConfig()
+	config.Models = map[string]parser.ModelConfig{
+		"User": {Fields: map[string]parser.FieldConfig{"id": {Type: "id", Primary: true}}},
+		"Post": {Fields: map[string]parser.FieldConfig{
+			"id":    {Type: "id", Primary: true},
+			"title": {Type: "text"},
+		}},
+	}
+	if err := server.Reload(config); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/api/post", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected new model route after reload, got %d: %s", w.Code, w.Body.String())
+	}
+
+	config.Models["User"].Fields["nickname"] = parser.FieldConfig{Type: "text"}
+	if err := server.Reload(config); err != nil {
+		t.Fatalf("Expected a new optional field to be added on reload: %v", err)
+	}
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/user", strings.NewReader(`{"nickname": "ann"}`))
+	req.Header.Set("Content-Type", "application/json")
+	server.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"nickname":"ann"`) {
+		t.Errorf("Expected the added column to be writable, got %d: %s", w.Code, w.Body.String())
+	}
+
+	config.Models["User"].Fields["handle"] = parser.FieldConfig{Type: "text", Required: true}
+	err := server.Reload(config)
+	if err == nil || !strings.Contains(err.Error(), "User.handle") {
+		t.Errorf("Expected a required field without a default to be refused, got %v", err)
+	}
+}
+
+func TestServer_RateLimitedModel(t *testing.T) {
+	server := New(createTestConfig())
+	server.schema = createTestSchema()
+	server.schema.Models["User"].RateLimit = parser.RateLimit{Requests: 2, Window: time.Minute}
+	server.db = NewMockDatabase()
+
+	limited := server.rateLimited("User", server.handleAPIList("User"))
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		limited(w, httptest.NewRequest("GET", "/api/user", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected request %d to pass, got %d", i+1, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	limited(w, httptest.NewRequest("GET", "/api/user", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once the limit is reached, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+
+	req := httptest.NewRequest("GET", "/api/user", nil)
+	req.RemoteAddr = "198.51.100.7:4321"
+	w = httptest.NewRecorder()
+	limited(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected other clients to be unaffected, got %d", w.Code)
+	}
+}
+
+func TestServer_DailyQuota(t *testing.T) {
+	server := New(createTestConfig())
+	server.schema = createTestSchema()
+	server.schema.Models["User"].DailyQuota = 1
+	server.db = NewMockDatabase()
+
+	limited := server.rateLimited("User", server.handleAPIList("User"))
+	request := func(username string) int {
+		req := httptest.NewRequest("GET", "/api/user", nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user", &auth.User{Username: username}))
+		w := httptest.NewRecorder()
+		limited(w, req)
+		return w.Code
+	}
+
+	if code := request("alice"); code != http.StatusOK {
+		t.Fatalf("Expected first request within quota, got %d", code)
+	}
+	if code := request("alice"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 after the daily quota, got %d", code)
+	}
+	if code := request("bob"); code != http.StatusOK {
+		t.Errorf("Expected quotas to be tracked per user, got %d", code)
+	}
+}
+
+func TestServer_ExportRateLimit(t *testing.T) {
+	server := New(createTestConfig())
+	server.schema = createTestSchema()
+	server.schema.Models["User"].RateLimit = parser.RateLimit{Requests: 2, Window: time.Minute}
+	server.schema.Models["User"].ExportRateLimit = parser.RateLimit{Requests: 1, Window: time.Hour}
+	server.db = NewMockDatabase()
+
+	list := server.rateLimited("User", server.handleAPIList("User"))
+	export := server.exportRateLimited("User", server.handleAPIExport("User"))
+	request := func(h http.HandlerFunc, path string) int {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("GET", path, nil))
+		return w.Code
+	}
+
+	if code := request(export, "/api/user/export"); code != http.StatusOK {
+		t.Fatalf("Expected the first export to pass, got %d", code)
+	}
+	if code := request(export, "/api/user/export"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 once the export limit is reached, got %d", code)
+	}
+	for i := 0; i < 2; i++ {
+		if code := request(list, "/api/user"); code != http.StatusOK {
+			t.Errorf("Expected CRUD traffic to be counted apart from exports, got %d", code)
+		}
+	}
+}
+
+func TestRateLimiter_EvictsExpiredWindows(t *testing.T) {
+	limiter := newRateLimiter()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 100; i++ {
+		limiter.allow(fmt.Sprintf("rate:User:ip:%d", i), 10, time.Second)
+	}
+	limiter.allow("quota:User:alice", 10, quotaWindow)
+
+	now = now.Add(2 * rateSweepInterval)
+	limiter.allow("rate:User:ip:new", 10, time.Second)
+	if len(limiter.windows) != 2 {
+		t.Errorf("Expected ended windows to be dropped, %d left", len(limiter.windows))
+	}
+}
+
+// startFakeRedis serves the EVAL (as the limiter's INCR script), GET, SET,
+// DEL and PING commands and returns a redis:// URL for it along with the
+// values SET stored.
+func startFakeRedis(t *testing.T) (string, map[string]string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	counters := map[string]int64{}
+	values := map[string]string{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					reply, err := readRedisReply(r)
+					if err != nil {
+						return
+					}
+					args := reply.([]any)
+					mu.Lock()
+					switch args[0] {
+					case "EVAL":
+						counters[args[3].(string)]++
+						fmt.Fprintf(conn, ":%d\r\n", counters[args[3].(string)])
+					case "SET":
+						if _, exists := values[args[1].(string)]; exists && len(args) > 3 && args[3] == "NX" {
+							fmt.Fprint(conn, "$-1\r\n")
+							break
+						}
+						values[args[1].(string)] = args[2].(string)
+						fmt.Fprint(conn, "+OK\r\n")
+					case "DEL":
+						delete(values, args[1].(string))
+						fmt.Fprint(conn, ":1\r\n")
+					case "GET":
+						if value, ok := values[args[1].(string)]; ok {
+							fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+						} else {
+							fmt.Fprint(conn, "$-1\r\n")
+						}
+					default:
+						fmt.Fprint(conn, "+PONG\r\n")
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+	}()
+
+	return "redis://" + listener.Addr().String(), values
+}
+
+func TestServer_RateLimitSharedThroughRedis(t *testing.T) {
+	redisURL, _ := startFakeRedis(t)
+
+	var handlers []http.HandlerFunc
+	for i := 0; i < 2; i++ {
+		server := New(createTestConfig())
+		server.schema = createTestSchema()
+		server.schema.Models["User"].RateLimit = parser.RateLimit{Requests: 2, Window: time.Minute}
+		server.db = NewMockDatabase()
+		client, err := newRedisClient(redisURL)
+		if err != nil {
+			t.Fatalf("Failed to create redis client: %v", err)
+		}
+		server.limiter = newRedisLimiter(client)
+		handlers = append(handlers, server.rateLimited("User", server.handleAPIList("User")))
+	}
+
+	codes := make([]int, 3)
+	for i := range codes {
+		w := httptest.NewRecorder()
+		handlers[i%2](w, httptest.NewRequest("GET", "/api/user", nil))
+		codes[i] = w.Code
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("Expected both instances to share one limit of 2, got %v", codes)
+	}
+}
+
+func TestServer_IdempotencyKeySharedThroughRedis(t *testing.T) {
+	redisURL, values := startFakeRedis(t)
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Order": {
+				Name: "Order",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "total", Type: parser.FieldTypeNumber},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	// Each create goes through a fresh client, as if another instance
+	// handled the retry.
+	create := func() *httptest.ResponseRecorder {
+		client, err := newRedisClient(redisURL)
+		if err != nil {
+			t.Fatalf("Failed to create redis client: %v", err)
+		}
+		server.idempotency = &redisIdempotencyStore{client: client}
+		req := httptest.NewRequest("POST", "/api/order", strings.NewReader(`{"total": 10}`))
+		req.Header.Set("Idempotency-Key", "abc")
+		w := httptest.NewRecorder()
+		server.handleAPICreate("Order")(w, req)
+		return w
+	}
+
+	if w := create(); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(values) != 1 {
+		t.Errorf("Expected the key to be stored in redis, got %v", values)
+	}
+	if w := create(); w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected the retry to be replayed, got %d: %s", w.Code, w.Body.String())
+	}
+	if count, _ := db.Count("Order", nil); count != 1 {
+		t.Errorf("Expected a single order, got %d", count)
+	}
+
+	// A reloaded configuration keeps using redis for the keys.
+	config := createTestConfig()
+	config.Models = map[string]parser.ModelConfig{"Order": {Fields: map[string]parser.FieldConfig{
+		"id":    {Type: "id", Primary: true},
+		"total": {Type: "number"},
+	}}}
+	if err := server.Reload(config); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	req := httptest.NewRequest("POST", "/api/order", strings.NewReader(`{"total": 10}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "abc")
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected the retry after a reload to be replayed, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRedisClient_BacksOffAfterFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client, err := newRedisClient("redis://" + addr)
+	if err != nil {
+		t.Fatalf("Failed to create redis client: %v", err)
+	}
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	if _, err := client.do("PING"); err == nil || errors.Is(err, errRedisUnavailable) {
+		t.Fatalf("Expected the dial to fail, got %v", err)
+	}
+	if _, err := client.do("PING"); !errors.Is(err, errRedisUnavailable) {
+		t.Errorf("Expected no redial right after a failure, got %v", err)
+	}
+
+	now = now.Add(redisBackoff)
+	if _, err := client.do("PING"); err == nil || errors.Is(err, errRedisUnavailable) {
+		t.Errorf("Expected a redial once the backoff passed, got %v", err)
+	}
+	now = now.Add(redisBackoff)
+	if _, err := client.do("PING"); !errors.Is(err, errRedisUnavailable) {
+		t.Errorf("Expected the backoff to grow after repeated failures, got %v", err)
+	}
+}
+
+func TestServer_HandleAPICreate_NormalizesSlug(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "slug", Type: parser.FieldTypeSlug, Required: true, Unique: true},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	create := func(slug string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]any{"slug": slug})
+		w := httptest.NewRecorder()
+		server.handleAPICreate("Post")(w, httptest.NewRequest("POST", "/api/post", bytes.NewReader(body)))
+		return w
+	}
+
+	if w := create(" Hello World "); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	record, err := db.Get("Post", 1)
+	if err != nil {
+		t.Fatalf("Failed to load post: %v", err)
+	}
+	if record["slug"] != "hello-world" {
+		t.Errorf("Expected normalized slug 'hello-world', got %v", record["slug"])
+	}
+
+	if w := create("HELLO   world"); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a duplicate slug, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleAPICreate_ObjectField(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "address", Type: parser.FieldTypeObject, Properties: []parser.Field{
+						{Name: "city", Type: parser.FieldTypeText, Required: true},
+						{Name: "zip", Type: parser.FieldTypeText},
+					}},
+				},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+
+	w := httptest.NewRecorder()
+	body := `{"address":{"city":"NYC","zip":"10001"}}`
+	server.handleAPICreate("User")(w, httptest.NewRequest("POST", "/api/user", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data struct {
+			Address map[string]any `json:"address"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Data.Address["city"] != "NYC" || response.Data.Address["zip"] != "10001" {
+		t.Errorf("Expected address to round-trip, got %v", response.Data.Address)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleAPICreate("User")(w, httptest.NewRequest("POST", "/api/user", strings.NewReader(`{"address":{"zip":"10001"}}`)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "address.city") {
+		t.Errorf("Expected 400 for missing address.city, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleAPIExport(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "role", Type: parser.FieldTypeText},
+					{Name: "password", Type: parser.FieldTypePassword},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	for i := 0; i < exportBatchSize+5; i++ {
+		role := "member"
+		if i%2 == 0 {
+			role = "admin"
+		}
+		if _, err := db.Create("User", map[string]any{"name": fmt.Sprintf("user%d", i), "role": role, "password": "secret"}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	server.handleAPIExport("User")(w, httptest.NewRequest("GET", "/api/user/export?format=ndjson&filter.role=admin", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expected application/x-ndjson, got %q", ct)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if want := (exportBatchSize + 6) / 2; len(lines) != want {
+		t.Fatalf("Expected %d lines, got %d", want, len(lines))
+	}
+	for _, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to parse line %q: %v", line, err)
+		}
+		if record["role"] != "admin" {
+			t.Errorf("Expected only admins, got %v", record)
+		}
+		if _, ok := record["password"]; ok {
+			t.Errorf("Expected password to be omitted, got %v", record)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	server.handleAPIExport("User")(w, httptest.NewRequest("GET", "/api/user/export?format=csv&search=user1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.HasPrefix(w.Body.String(), "id,name,role\n") {
+		t.Errorf("Expected CSV header without password, got %q", strings.SplitN(w.Body.String(), "\n", 2)[0])
+	}
+
+	w = httptest.NewRecorder()
+	server.handleAPIExport("User")(w, httptest.NewRequest("GET", "/api/user/export", nil))
+	var records []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+		t.Fatalf("Failed to parse JSON export: %v", err)
+	}
+	if len(records) != exportBatchSize+5 {
+		t.Errorf("Expected %d records, got %d", exportBatchSize+5, len(records))
+	}
+
+	w = httptest.NewRecorder()
+	server.handleAPIExport("User")(w, httptest.NewRequest("GET", "/api/user/export?format=xlsx", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown format, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleAPIExport("User")(w, httptest.NewRequest("GET", "/api/user/export?format=csv&fields=name,role&filter.name__in=user3,user1&sort=-name", nil))
+	if want := "name,role\nuser3,member\nuser1,member\n"; w.Body.String() != want {
+		t.Errorf("Expected CSV %q, got %q", want, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	server.handleAPIExport("User")(w, httptest.NewRequest("GET", "/api/user/export?fields=name,password", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 when exporting a password field, got %d", w.Code)
+	}
+}
+
+func TestServer_PublicReadModel(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Post": {
+				Name:        "Post",
+				Fields:      []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+				Permissions: parser.Permissions{Read: parser.PermissionPublic},
+				Versions:    map[string][]string{"v1": {}},
+			},
+			"User": {
+				Name:   "User",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	authManager, err := auth.New(&parser.AuthConfig{Type: "jwt", Secret: "test-secret"}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+	server.setupRoutes()
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{"GET", "/api/post", http.StatusOK},
+		{"GET", "/api/post/export?format=ndjson", http.StatusOK},
+		{"GET", "/api/v1/post", http.StatusOK},
+		{"GET", "/api/v1/post/export?format=ndjson", http.StatusOK},
+		{"POST", "/api/post", http.StatusUnauthorized},
+		{"POST", "/api/v1/post", http.StatusUnauthorized},
+		{"GET", "/api/post/1/delete-preview", http.StatusUnauthorized},
+		{"GET", "/api/user", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}")))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected status %d, got %d: %s", tt.method, tt.path, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestServer_PublicReadHidesTrashed(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Post": {
+				Name:        "Post",
+				SoftDelete:  true,
+				Permissions: parser.Permissions{Read: parser.PermissionPublic},
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: parser.SoftDeleteField, Type: parser.FieldTypeDatetime, Nullable: true},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	authManager, err := auth.New(&parser.AuthConfig{Type: "jwt", Secret: "test-secret"}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+	server.setupRoutes()
+
+	db.Create("Post", map[string]any{"title": "Live"})
+	id, _ := db.Create("Post", map[string]any{"title": "Removed"})
+	if err := db.Delete("Post", id); err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+
+	for _, path := range []string{"/api/post?trashed=true", "/api/post/export?format=ndjson&trashed=true"} {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected status 200, got %d: %s", path, w.Code, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "Removed") {
+			t.Errorf("GET %s: expected anonymous caller not to see deleted posts, got: %s", path, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), "Live") {
+			t.Errorf("GET %s: expected live posts, got: %s", path, w.Body.String())
+		}
+	}
+}
+
+func TestServer_HandleAuthError(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name:   "User",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	authManager, err := auth.New(&parser.AuthConfig{Type: "jwt", Secret: "test-secret"}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+	server.setupRoutes()
+
+	tests := []struct {
+		name        string
+		mode        string
+		path        string
+		xhr         bool
+		want        int
+		contentType string
+	}{
+		{"api route with html accept", "", "/api/user", false, http.StatusUnauthorized, "application/json"},
+		{"page route", "", "/user", false, http.StatusSeeOther, ""},
+		{"401page browser navigation", parser.APIUnauthorizedPage, "/api/user", false, http.StatusUnauthorized, "text/html"},
+		{"401page xhr", parser.APIUnauthorizedPage, "/api/user", true, http.StatusUnauthorized, "application/json"},
+	}
+	for _, tt := range tests {
+		server.config.Server.Auth.APIUnauthorized = tt.mode
+
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*")
+		if tt.xhr {
+			req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+		}
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), tt.contentType) {
+			t.Errorf("%s: expected content type %q, got %q", tt.name, tt.contentType, w.Header().Get("Content-Type"))
+		}
+		if tt.want == http.StatusSeeOther && w.Header().Get("Location") != "/login?return=%2Fuser" {
+			t.Errorf("%s: expected redirect to login, got %q", tt.name, w.Header().Get("Location"))
+		}
+	}
+
+	// The 401 login page only returns to same-site paths.
+	server.config.Server.Auth.APIUnauthorized = parser.APIUnauthorizedPage
+	req := httptest.NewRequest("GET", "/api/user?page=2", nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+	server.handleAuthError(w, req, "Authentication required")
+	if !strings.Contains(w.Body.String(), "let returnUrl = '/api/user?page=2';") {
+		t.Error("Expected the login page to return to the requested path")
+	}
+
+	req.URL.Opaque = "//evil.example/api/user"
+	w = httptest.NewRecorder()
+	server.handleAuthError(w, req, "Authentication required")
+	if !strings.Contains(w.Body.String(), "let returnUrl = '/';") {
+		t.Error("Expected an off-site return target to be dropped")
+	}
+}
+
+func TestServer_APIKeyAuth(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name:   "User",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	authManager, err := auth.New(&parser.AuthConfig{
+		Type:   "apikey",
+		Secret: "test-secret",
+		Users:  []parser.UserConfig{{Username: "bot", Password: "bot", Role: "admin", APIKey: "key-123"}},
+	}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+	server.setupRoutes()
+
+	for key, want := range map[string]int{"key-123": http.StatusOK, "wrong": http.StatusUnauthorized} {
+		req := httptest.NewRequest("GET", "/api/user", nil)
+		req.Header.Set(auth.APIKeyHeader, key)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Errorf("key %q: expected status %d, got %d: %s", key, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestServer_RelationIntegrity(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+				},
+			},
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "author_id", Type: parser.FieldTypeRelation, RelatedTo: "User"},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	authorID, err := db.Create("User", map[string]any{"name": "Jane"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("POST", "/api/post", strings.NewReader(`{"author_id": 999}`)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "referenced User not found") {
+		t.Errorf("Expected 400 for a missing author, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("POST", "/api/post", strings.NewReader(fmt.Sprintf(`{"author_id": %v}`, authorID))))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 for an existing author, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("PUT", "/api/post/1", strings.NewReader(`{"author_id": 999}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 when updating to a missing author, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleAPICreate_IdempotencyKey(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Order": {
+				Name: "Order",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "total", Type: parser.FieldTypeNumber},
+				},
+			},
+			"Invoice": {
+				Name:   "Invoice",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	create := func(modelName, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/"+strings.ToLower(modelName), strings.NewReader(`{"total": 10}`))
+		req.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		server.handleAPICreate(modelName)(w, req)
+		return w
+	}
+
+	first := create("Order", "abc")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", first.Code, first.Body.String())
+	}
+	retry := create("Order", "abc")
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("Expected replayed 201, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the original record, got %s", retry.Body.String())
+	}
+
+	if count, _ := db.Count("Order", nil); count != 1 {
+		t.Errorf("Expected a single order, got %d", count)
+	}
+
+	if w := create("Order", "def"); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Expected a new key to create a record, got %d", w.Code)
+	}
+	if w := create("Invoice", "abc"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 when reusing a key on another model, got %d", w.Code)
+	}
+
+	// A request still holding its key makes a concurrent retry wait.
+	if reserved, err := db.(database.IdempotencyStore).ReserveIdempotencyKey("ip:192.0.2.1:pending", "Order", time.Hour); !reserved || err != nil {
+		t.Fatalf("Failed to reserve key: %v", err)
+	}
+	if w := create("Order", "pending"); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while the key is pending, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A failed create frees its key.
+	req := httptest.NewRequest("POST", "/api/order", strings.NewReader(`{"total": "ten"}`))
+	req.Header.Set("Idempotency-Key", "retry")
+	w := httptest.NewRecorder()
+	server.handleAPICreate("Order")(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for an invalid order, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := create("Order", "retry"); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Expected the key to be usable after a failed create, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_ReadRolesHideFields(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Employee": {
+				Name: "Employee",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "salary", Type: parser.FieldTypeNumber, ReadRoles: []string{"admin"}},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	authManager, err := auth.New(&parser.AuthConfig{
+		Type:   "jwt",
+		Secret: "test-secret",
+		Users: []parser.UserConfig{
+			{Username: "alice", Password: "secret", Role: "admin"},
+			{Username: "bob", Password: "secret", Role: "user", Permissions: map[string]parser.EntityPermission{"Employee": {Read: true, Write: true}}},
+		},
+	}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+
+	id, _ := db.Create("Employee", map[string]any{"name": "Jane", "salary": 5000})
+
+	get := func(user *auth.User) map[string]any {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/employee/%v", id), nil)
+		req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(id)})
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		w := httptest.NewRecorder()
+		server.handleAPIGet("Employee")(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response struct {
+			Data map[string]any `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response.Data
+	}
+
+	if data := get(&auth.User{Username: "bob", Role: "user"}); data["name"] != "Jane" || data["salary"] != nil {
+		t.Errorf("Expected salary to be hidden from non-admins, got %v", data)
+	}
+	if _, ok := get(&auth.User{Username: "bob", Role: "user"})["salary"]; ok {
+		t.Error("Expected salary key to be omitted")
+	}
+	if data := get(&auth.User{Username: "alice", Role: "admin"}); data["salary"] != float64(5000) {
+		t.Errorf("Expected admins to see salary, got %v", data)
+	}
+
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/api/employee/%v", id), strings.NewReader(`{"name": "Janet", "salary": null}`))
+	req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(id)})
+	req = req.WithContext(context.WithValue(req.Context(), "user", &auth.User{Username: "bob", Role: "user"}))
+	w := httptest.NewRecorder()
+	server.handleAPIUpdate("Employee")(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	record, _ := db.Get("Employee", id)
+	if record["salary"] != int64(5000) {
+		t.Errorf("Expected hidden salary to be left untouched, got %v", record["salary"])
+	}
+
+	list := func(user *auth.User, query string) int {
+		req := httptest.NewRequest("GET", "/api/employee?"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		w := httptest.NewRecorder()
+		server.handleAPIList("Employee")(w, req)
+		return w.Code
+	}
+	for _, query := range []string{"filter.salary=5000", "filter.salary__gt=1000", "sort=salary", "sort=-salary"} {
+		if code := list(&auth.User{Username: "bob", Role: "user"}, query); code != http.StatusBadRequest {
+			t.Errorf("Expected ?%s to be rejected for non-admins, got %d", query, code)
+		}
+		if code := list(&auth.User{Username: "alice", Role: "admin"}, query); code != http.StatusOK {
+			t.Errorf("Expected ?%s to be allowed for admins, got %d", query, code)
+		}
+	}
+
+	req = httptest.NewRequest("POST", "/api/employee", strings.NewReader(`{"name": "Sam", "salary": 9000}`))
+	req = req.WithContext(context.WithValue(req.Context(), "user", &auth.User{Username: "bob", Role: "user"}))
+	w = httptest.NewRecorder()
+	server.handleAPICreate("Employee")(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	created, _ := db.Query("Employee", parser.QueryParams{Filters: []parser.Filter{{Field: "name", Operator: "=", Value: "Sam"}}})
+	if len(created) != 1 || created[0]["salary"] != nil {
+		t.Errorf("Expected a hidden field to be dropped on create, got %v", created)
+	}
+
+	server.config.UI.Dashboard.Recent = 5
+	server.config.UI.Dashboard.Widgets = []parser.UIWidgetConfig{{Title: "Payroll", Model: "Employee", Aggregate: "sum", Field: "salary"}}
+	home := func(user *auth.User) string {
+		req := httptest.NewRequest("GET", "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		w := httptest.NewRecorder()
+		server.handleHome(w, req)
+		return w.Body.String()
+	}
+	if body := home(&auth.User{Username: "bob", Role: "user"}); strings.Contains(body, "5000") || strings.Contains(body, "5,000") {
+		t.Error("Expected the dashboard to hide salary from non-admins")
+	}
+	if body := home(&auth.User{Username: "alice", Role: "admin"}); !strings.Contains(body, "5000") && !strings.Contains(body, "5,000") {
+		t.Error("Expected the dashboard to show salary to admins")
+	}
+}
+
+func TestServer_HandleAPIGet_OrderedFields(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "email", Type: parser.FieldTypeEmail},
+					{Name: "age", Type: parser.FieldTypeNumber},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.config.Server.OrderedFields = true
+
+	id, _ := db.Create("User", map[string]any{"name": "Ann", "email": "ann@example.com", "age": 30})
+
+	req := httptest.NewRequest("GET", "/api/user/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(id)})
+	w := httptest.NewRecorder()
+	server.handleAPIGet("User")(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body := w.Body.String()
+	positions := []int{
+		strings.Index(body, `"id":`),
+		strings.Index(body, `"name":`),
+		strings.Index(body, `"email":`),
+		strings.Index(body, `"age":`),
+	}
+	for i := 1; i < len(positions); i++ {
+		if positions[i-1] < 0 || positions[i] < positions[i-1] {
+			t.Fatalf("Expected fields in declaration order, got %s", body)
+		}
+	}
+}
+
+func TestServer_HandleAuthLogin_Captcha(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		ok := r.PostForm.Get("secret") == "captcha-secret" && r.PostForm.Get("response") == "solved"
+		json.NewEncoder(w).Encode(map[string]any{"success": ok})
+	}))
+	defer provider.Close()
+
+	schema := &parser.Schema{Models: map[string]*parser.Model{}}
+	server, db := createTestSQLiteServer(t, schema)
+	server.config.Server.Auth.Captcha = &parser.CaptchaConfig{After: 2, VerifyURL: provider.URL, Secret: "captcha-secret"}
+
+	authManager, err := auth.New(&parser.AuthConfig{
+		Type:   "jwt",
+		Secret: "test-secret",
+		Users:  []parser.UserConfig{{Username: "ann", Password: "right", Role: "admin"}},
+	}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+
+	login := func(password, token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"username": "ann", "password": password, "captcha_token": token})
+		req := httptest.NewRequest("POST", "/api/auth/login", bytes.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:1234"
+		w := httptest.NewRecorder()
+		server.handleAuthLogin(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := login("wrong", ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected status 401, got %d: %s", i+1, w.Code, w.Body.String())
+		}
+	}
+
+	if w := login("right", ""); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "captcha_required") {
+		t.Fatalf("Expected a CAPTCHA to be required, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := login("right", "bogus"); w.Code != http.StatusForbidden {
+		t.Fatalf("Expected an invalid CAPTCHA to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := login("right", "solved"); w.Code != http.StatusOK {
+		t.Fatalf("Expected login with a solved CAPTCHA to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := login("right", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected a successful login to clear the failures, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_DefaultFilter(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: "published", Type: parser.FieldTypeBoolean},
+				},
+				DefaultFilter: []parser.Filter{{Field: "published", Operator: "=", Value: true}},
+				FilterBypass:  []string{"admin"},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	db.Create("Post", map[string]any{"title": "Live", "published": true})
+	draft, _ := db.Create("Post", map[string]any{"title": "Draft", "published": false})
+
+	withUser := func(req *http.Request, user *auth.User) *http.Request {
+		if user == nil {
+			return req
+		}
+		return req.WithContext(context.WithValue(req.Context(), "user", user))
+	}
+	list := func(user *auth.User) []any {
+		w := httptest.NewRecorder()
+		server.handleAPIList("Post")(w, withUser(httptest.NewRequest("GET", "/api/post", nil), user))
+		var response struct {
+			Data []any        `json:"data"`
+			Meta *parser.Meta `json:"meta"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if response.Meta == nil || *response.Meta.TotalCount != int64(len(response.Data)) {
+			t.Errorf("Expected the total to match the filtered list, got %s", w.Body.String())
+		}
+		return response.Data
+	}
+	get := func(user *auth.User) int {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/post/%v", draft), nil)
+		req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(draft)})
+		w := httptest.NewRecorder()
+		server.handleAPIGet("Post")(w, withUser(req, user))
+		return w.Code
+	}
+
+	if posts := list(nil); len(posts) != 1 || posts[0].(map[string]any)["title"] != "Live" {
+		t.Errorf("Expected anonymous users to see only published posts, got %v", posts)
+	}
+	if posts := list(&auth.User{Username: "bob", Role: "user"}); len(posts) != 1 {
+		t.Errorf("Expected non-admins to see only published posts, got %v", posts)
+	}
+	if posts := list(&auth.User{Username: "alice", Role: "admin"}); len(posts) != 2 {
+		t.Errorf("Expected admins to see every post, got %v", posts)
+	}
+
+	if code := get(&auth.User{Username: "bob", Role: "user"}); code != http.StatusNotFound {
+		t.Errorf("Expected a filtered-out post to be 404 for non-admins, got %d", code)
+	}
+	if code := get(&auth.User{Username: "alice", Role: "admin"}); code != http.StatusOK {
+		t.Errorf("Expected admins to get a draft, got %d", code)
+	}
+
+	view := func(user *auth.User) int {
+		req := mux.SetURLVars(httptest.NewRequest("GET", fmt.Sprintf("/post/%v", draft), nil), map[string]string{"id": fmt.Sprint(draft)})
+		w := httptest.NewRecorder()
+		server.handleModelView("Post")(w, withUser(req, user))
+		return w.Code
+	}
+	if code := view(&auth.User{Username: "bob", Role: "user"}); code != http.StatusNotFound {
+		t.Errorf("Expected the view page of a filtered-out post to be 404, got %d", code)
+	}
+	if code := view(&auth.User{Username: "alice", Role: "admin"}); code != http.StatusOK {
+		t.Errorf("Expected admins to view a draft, got %d", code)
+	}
+
+	bob := &auth.User{Username: "bob", Role: "user"}
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/api/post/%v", draft), strings.NewReader(`{"title": "Hijacked"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(draft)})
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	server.handleAPIUpdate("Post")(w, withUser(req, bob))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected updating a filtered-out post to be 404, got %d: %s", w.Code, w.Body.String())
+	}
+	if record, _ := db.Get("Post", draft); record["title"] != "Draft" {
+		t.Errorf("Expected the filtered-out post to be unchanged, got %v", record["title"])
+	}
+
+	req = mux.SetURLVars(httptest.NewRequest("DELETE", fmt.Sprintf("/api/post/%v", draft), nil), map[string]string{"id": fmt.Sprint(draft)})
+	w = httptest.NewRecorder()
+	server.handleAPIDelete("Post")(w, withUser(req, bob))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected deleting a filtered-out post to be 404, got %d", w.Code)
+	}
+	if _, err := db.Get("Post", draft); err != nil {
+		t.Errorf("Expected the filtered-out post to survive, got %v", err)
+	}
+}
+
+func TestServer_ContentTypeEnforcement(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name: "Note",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "body", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+
+	create := func(contentType, body string) int {
+		req := httptest.NewRequest("POST", "/api/note", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		server.handleAPICreate("Note")(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		contentType string
+		body        string
+		want        int
+	}{
+		{"text/plain", `{"body": "hi"}`, http.StatusUnsupportedMediaType},
+		{"application/x-www-form-urlencoded", "body=hi", http.StatusUnsupportedMediaType},
+		{"application/json; charset=utf-8", `{"body": "hi"}`, http.StatusCreated},
+		{"application/merge-patch+json", `{"body": "hi"}`, http.StatusCreated},
+		{"application/xml", "<note><body>hi</body></note>", http.StatusCreated},
+		{"", `{"body": "hi"}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		if code := create(tt.contentType, tt.body); code != tt.want {
+			t.Errorf("Content-Type %q: expected status %d, got %d", tt.contentType, tt.want, code)
+		}
+	}
+
+	server.config.Server.StrictContentType = true
+	if code := create("", `{"body": "hi"}`); code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected a missing Content-Type to be rejected in strict mode, got %d", code)
+	}
+}
+
+func TestServer_UniqueWithin(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "author_id", Type: parser.FieldTypeNumber},
+					{Name: "slug", Type: parser.FieldTypeSlug, UniqueWithin: "author_id", UniqueMessage: "You already have a post with this slug"},
+				},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/post", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.handleAPICreate("Post")(w, req)
+		return w
+	}
+
+	if w := create(`{"author_id": 1, "slug": "intro"}`); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := create(`{"author_id": 2, "slug": "intro"}`); w.Code != http.StatusCreated {
+		t.Errorf("Expected another author to reuse the slug, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := create(`{"author_id": 1, "slug": "intro"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+	var response map[string]any
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["field"] != "slug" || response["error"] != "You already have a post with this slug" {
+		t.Errorf("Expected the slug's unique_message, got %v", response)
+	}
+}
+
+func TestServer_Webhooks(t *testing.T) {
+	payloads := make(chan map[string]any, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		payloads <- payload
+	}))
+	defer receiver.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "email", Type: parser.FieldTypeEmail},
+					{Name: "password", Type: parser.FieldTypePassword},
+				},
+				Webhooks: []parser.WebhookConfig{
+					{URL: receiver.URL},
+					{URL: receiver.URL, Events: []string{parser.WebhookCreate}, Fields: []string{"id", "email"}},
+					{URL: receiver.URL, Events: []string{parser.WebhookDelete}},
+				},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+
+	body := `{"name": "Ann", "email": "ann@example.com", "password": "hunter22"}`
+	req := httptest.NewRequest("POST", "/api/user", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	server.handleAPICreate("User")(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var full, limited map[string]any
+	for i := 0; i < 2; i++ {
+		select {
+		case payload := <-payloads:
+			if payload["event"] != "create" || payload["model"] != "User" {
+				t.Errorf("Unexpected payload envelope: %v", payload)
+			}
+			data := payload["data"].(map[string]any)
+			if _, ok := data["name"]; ok {
+				full = data
+			} else {
+				limited = data
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for webhooks")
+		}
+	}
+
+	if full == nil || limited == nil {
+		t.Fatalf("Expected one full and one limited payload, got %v and %v", full, limited)
+	}
+	if _, ok := full["password"]; ok {
+		t.Error("Expected password to be stripped from the payload")
+	}
+	if len(limited) != 2 || limited["email"] != "ann@example.com" || limited["id"] == nil {
+		t.Errorf("Expected only id and email, got %v", limited)
+	}
+	select {
+	case payload := <-payloads:
+		t.Errorf("Expected the delete-only webhook to stay quiet, got %v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestServer_RestoreWebhook(t *testing.T) {
+	payloads := make(chan map[string]any, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		payloads <- payload
+	}))
+	defer receiver.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name:       "Note",
+				SoftDelete: true,
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: parser.SoftDeleteField, Type: parser.FieldTypeDatetime, Nullable: true},
+				},
+				Webhooks: []parser.WebhookConfig{{URL: receiver.URL, Events: []string{parser.WebhookRestore}}},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	id, _ := db.Create("Note", map[string]any{"title": "Draft"})
+	if err := db.Delete("Note", id); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest("POST", "/api/note/1/restore", nil), map[string]string{"id": fmt.Sprint(id)})
+	w := httptest.NewRecorder()
+	server.handleAPIRestore("Note")(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected restore to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	select {
+	case payload := <-payloads:
+		data, _ := payload["data"].(map[string]any)
+		if payload["event"] != "restore" || payload["model"] != "Note" || data["title"] != "Draft" {
+			t.Errorf("Expected a restore payload with the record, got %v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the restore webhook")
+	}
+}
+
+func TestServer_OnChangePurge(t *testing.T) {
+	purges := make(chan map[string]any, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		purges <- payload
+	}))
+	defer receiver.Close()
+
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Page": {
+				Name: "Page",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+				},
+				OnChange: receiver.URL,
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	id, _ := db.Create("Page", map[string]any{"title": "Home"})
+
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/api/page/%v", id), strings.NewReader(`{"title": "Welcome"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	select {
+	case payload := <-purges:
+		want := map[string]any{"event": "update", "model": "Page", "id": fmt.Sprint(id)}
+		if len(payload) != len(want) {
+			t.Errorf("Expected only event, model and id, got %v", payload)
+		}
+		for key, value := range want {
+			if payload[key] != value {
+				t.Errorf("Expected %s %v, got %v", key, value, payload[key])
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the cache purge")
+	}
+}
+
+func TestServer_Compression(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name: "Note",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "body", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.config.Server.Compression = parser.CompressionConfig{Enabled: true}
+	server.setupRoutes()
+
+	var first any
+	for i := 0; i < 50; i++ {
+		id, _ := db.Create("Note", map[string]any{"body": strings.Repeat("note ", 10)})
+		if first == nil {
+			first = id
+		}
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", path, w.Code)
+		}
+		return w
+	}
+
+	small := get(fmt.Sprintf("/api/note/%v", first))
+	if small.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected a small response to skip gzip")
+	}
+	if !strings.Contains(small.Body.String(), `"success":true`) {
+		t.Errorf("Expected a plain JSON body, got %s", small.Body.String())
+	}
+
+	large := get("/api/note?page_size=50")
+	if large.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatal("Expected a large list response to be gzipped")
+	}
+	reader, err := gzip.NewReader(large.Body)
+	if err != nil {
+		t.Fatalf("Failed to open gzip body: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read gzip body: %v", err)
+	}
+	var response struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || len(response.Data) != 50 {
+		t.Errorf("Expected 50 records in the decompressed body, got %d (%v)", len(response.Data), err)
+	}
+}
+
+func TestServer_HandleAPICreate_UniqueMessage(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "email", Type: parser.FieldTypeEmail, Unique: true, UniqueMessage: "This email is already registered"},
+				},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+
+	create := func() *httptest.ResponseRecorder {
+		body := strings.NewReader(`{"email": "ann@example.com"}`)
+		w := httptest.NewRecorder()
+		server.handleAPICreate("User")(w, httptest.NewRequest("POST", "/api/user", body))
+		return w
+	}
+
+	if w := create(); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := create()
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+	var response map[string]any
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["error"] != "This email is already registered" || response["field"] != "email" {
+		t.Errorf("Expected the configured unique message, got %v", response)
+	}
+}
+
+func TestServer_CSRF(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name: "Note",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "body", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.config.Server.Auth.CSRF = true
+
+	authManager, err := auth.New(&parser.AuthConfig{
+		Type:   "jwt",
+		Secret: "test-secret",
+		Users:  []parser.UserConfig{{Username: "ann", Password: "pw", Role: "admin"}},
+	}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+	server.setupRoutes()
+
+	user, err := authManager.Authenticate("ann", "pw")
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	token, _ := authManager.GenerateToken(user)
+
+	create := func(csrfCookie, csrfHeader string, bearer bool) int {
+		req := httptest.NewRequest("POST", "/api/note", strings.NewReader(`{"body": "hi"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if bearer {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else {
+			req.AddCookie(&http.Cookie{Name: "auth_token", Value: token})
+		}
+		if csrfCookie != "" {
+			req.AddCookie(&http.Cookie{Name: "csrf_token", Value: csrfCookie})
+		}
+		if csrfHeader != "" {
+			req.Header.Set("X-CSRF-Token", csrfHeader)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := create("", "", false); code != http.StatusForbidden {
+		t.Errorf("Expected 403 without a CSRF token, got %d", code)
+	}
+	if code := create("abc", "xyz", false); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a mismatched CSRF token, got %d", code)
+	}
+	if code := create("abc", "abc", false); code != http.StatusCreated {
+		t.Errorf("Expected 201 with a matching CSRF token, got %d", code)
+	}
+	if code := create("", "", true); code != http.StatusCreated {
+		t.Errorf("Expected bearer requests to skip the CSRF check, got %d", code)
+	}
+
+	req := httptest.NewRequest("GET", "/api/note", nil)
+	req.AddCookie(&http.Cookie{Name: "auth_token", Value: token})
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if !strings.Contains(w.Header().Get("Set-Cookie"), "csrf_token=") {
+		t.Error("Expected a cookie session without a CSRF cookie to be issued one")
+	}
+}
+
+func TestServer_PrintRoutes(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name:   "Note",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	var buf bytes.Buffer
+	server.printRoutes(&buf, "localhost:8080")
+	output := buf.String()
+
+	for _, want := range []string{
+		"Models: Note",
+		"Auth:   none",
+		"Docs:   http://localhost:8080/api/docs",
+		"/api/note/{id}",
+		"/note/{id}/edit",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected route summary to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestServer_HandleAPIList_RejectsUnknownQueryFields(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Item": {
+				Name: "Item",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	db.Create("Item", map[string]any{"name": "widget"})
+
+	for _, query := range []string{
+		"sort=" + url.QueryEscape("name;DROP TABLE item"),
+		"sort=" + url.QueryEscape(`-name" DESC; DROP TABLE item; --`),
+		url.QueryEscape(`filter.name" = name OR "1`) + "=1",
+		"filter.name__bogus=1",
+	} {
+		w := httptest.NewRecorder()
+		server.handleAPIList("Item")(w, httptest.NewRequest("GET", "/api/item?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d: %s", query, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	server.handleAPIList("Item")(w, httptest.NewRequest("GET", "/api/item?sort=-name&filter.name=widget", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "widget") {
+		t.Errorf("Expected known fields to still work, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleAPICreate_NormalizeLower(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "username", Type: parser.FieldTypeText, Unique: true, Normalize: parser.NormalizeLower},
+				},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+
+	create := func(username string) *httptest.ResponseRecorder {
+		body := strings.NewReader(`{"username": "` + username + `"}`)
+		w := httptest.NewRecorder()
+		server.handleAPICreate("User")(w, httptest.NewRequest("POST", "/api/user", body))
+		return w
+	}
+
+	w := create("JohnDoe")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"username":"johndoe"`) {
+		t.Errorf("Expected the username to be stored lowercased, got %s", w.Body.String())
+	}
+
+	if w := create("JOHNDOE"); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for the same username in another case, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleAPIList_CountOptions(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Event": {
+				Name: "Event",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	for i := 0; i < 3; i++ {
+		db.Create("Event", map[string]any{"name": fmt.Sprint("event ", i)})
+	}
+
+	list := func(query string) map[string]any {
+		w := httptest.NewRecorder()
+		server.handleAPIList("Event")(w, httptest.NewRequest("GET", "/api/event?"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response map[string]any
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response["meta"].(map[string]any)
+	}
+
+	meta := list("count=false")
+	if meta["total_count"] != nil || meta["total_pages"] != nil {
+		t.Errorf("Expected null totals with count=false, got %v", meta)
+	}
+
+	schema.Models["Event"].CountLimit = 2
+	meta = list("page_size=1")
+	if meta["total_count"] != float64(2) || meta["estimated"] != true {
+		t.Errorf("Expected the total to stop at count_limit, got %v", meta)
+	}
+
+	schema.Models["Event"].CountLimit = 3
+	meta = list("page_size=1")
+	if meta["total_count"] != float64(3) || meta["estimated"] != nil {
+		t.Errorf("Expected an exact total within count_limit, got %v", meta)
+	}
+}
+
+func TestServer_HandleAPICreate_Transform(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Contact": {
+				Name: "Contact",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText, Transform: []string{"trim"}},
+					{Name: "phone", Type: parser.FieldTypeText, Transform: []string{"digits"}},
+				},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+
+	body := strings.NewReader(`{"name": "  Ann Lee ", "phone": "(555) 010-2030"}`)
+	w := httptest.NewRecorder()
+	server.handleAPICreate("Contact")(w, httptest.NewRequest("POST", "/api/contact", body))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"name":"Ann Lee"`) || !strings.Contains(w.Body.String(), `"phone":"5550102030"`) {
+		t.Errorf("Expected transformed values to be stored, got %s", w.Body.String())
+	}
+}
+
+func TestServer_AuditLog(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name: "Note",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "body", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.config.Server.Audit.Enabled = true
+
+	authManager, err := auth.New(&parser.AuthConfig{
+		Type:   "jwt",
+		Secret: "test-secret",
+		Users: []parser.UserConfig{
+			{Username: "ann", Password: "pw", Role: "admin"},
+			{Username: "bob", Password: "pw", Role: "user"},
+		},
+	}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+	server.setupRoutes()
+
+	tokens := map[string]string{}
+	for _, username := range []string{"ann", "bob"} {
+		user, err := authManager.Authenticate(username, "pw")
+		if err != nil {
+			t.Fatalf("Failed to authenticate %s: %v", username, err)
+		}
+		tokens[username], _ = authManager.GenerateToken(user)
+	}
+
+	request := func(username, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+tokens[username])
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("ann", "POST", "/api/note", `{"body": "hi"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := request("ann", "DELETE", "/api/note/1", ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := request("bob", "GET", "/api/_audit", ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected non-admins to get 403, got %d", w.Code)
+	}
+
+	w = request("ann", "GET", "/api/_audit?model=Note&user=ann&since=2000-01-01", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data []database.AuditEntry `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Data) != 2 || response.Data[0].Action != "delete" || response.Data[1].Action != "create" || response.Data[1].RecordID != "1" {
+		t.Errorf("Expected delete and create entries newest first, got %+v", response.Data)
+	}
+
+	if w := request("ann", "GET", "/api/_audit?since=yesterday", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid since, got %d", w.Code)
+	}
+
+	store := db.(database.AuditLog)
+	pruneAudit(store, time.Nanosecond)
+	if entries, err := store.QueryAudit(database.AuditFilter{}); err != nil || len(entries) != 0 {
+		t.Errorf("Expected entries past the retention to be pruned, got %v (%v)", entries, err)
+	}
+}
+
+// pruneLog stands in for the audit log and reports how far back each
+// prune cuts.
+type pruneLog struct {
+	database.Database
+	retentions chan time.Duration
+}
+
+func (l *pruneLog) RecordAudit(database.AuditEntry) error { return nil }
+
+func (l *pruneLog) QueryAudit(database.AuditFilter) ([]database.AuditEntry, error) {
+	return nil, nil
+}
+
+func (l *pruneLog) PruneAudit(before time.Time) (int64, error) {
+	l.retentions <- time.Since(before).Round(time.Hour)
+	return 0, nil
+}
+
+func TestServer_AuditPruningFollowsReloadAndShutdown(t *testing.T) {
+	server, db := createTestSQLiteServer(t, createTestSchema())
+	store := &pruneLog{Database: db, retentions: make(chan time.Duration, 4)}
+	server.db = store
+	server.config.Server.Audit = parser.AuditConfig{Enabled: true, Retention: "1h"}
+
+	server.startAuditPruning()
+	if got := <-store.retentions; got != time.Hour {
+		t.Fatalf("Expected a prune with the 1h retention, got %s", got)
+	}
+	first := server.pruner.done
+
+	config := createTestConfig()
+	config.Server.Audit = parser.AuditConfig{Enabled: true, Retention: "2h"}
+	if err := server.Reload(config); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := <-store.retentions; got != 2*time.Hour {
+		t.Errorf("Expected the reload to prune with the new 2h retention, got %s", got)
+	}
+	select {
+	case <-first:
+	default:
+		t.Error("Expected the reload to stop the previous pruning loop")
+	}
+
+	second := server.pruner.done
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	select {
+	case <-second:
+	default:
+		t.Error("Expected shutdown to stop the pruning loop")
+	}
+}
+
+func TestServer_HandleAPICreate_CoerceTypes(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Member": {
+				Name: "Member",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "active", Type: parser.FieldTypeBoolean},
+					{Name: "age", Type: parser.FieldTypeNumber},
+					{Name: "balance", Type: parser.FieldTypeNumber, Decimal: true},
+				},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+
+	create := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleAPICreate("Member")(w, httptest.NewRequest("POST", "/api/member", strings.NewReader(body)))
+		return w
+	}
+
+	if w := create(`{"active": "true"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected string booleans to be rejected without coerce_types, got %d: %s", w.Code, w.Body.String())
+	}
+
+	server.config.Server.CoerceTypes = true
+	for body, expected := range map[string]string{
+		`{"active": "true"}`: `"active":true`,
+		`{"active": "on"}`:   `"active":true`,
+		`{"active": "0"}`:    `"active":false`,
+		`{"age": "30"}`:      `"age":30`,
+		`{"balance": "9.5"}`: `"balance":9.5`,
+	} {
+		w := create(body)
+		if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), expected) {
+			t.Errorf("%s: expected 201 with %s, got %d: %s", body, expected, w.Code, w.Body.String())
+		}
+	}
+
+	for _, body := range []string{`{"active": "maybe"}`, `{"age": "30.5"}`, `{"balance": "NaN"}`} {
+		if w := create(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected unrecognised strings to still be rejected, got %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestServer_LoginReturnURL(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name:   "User",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	authManager, err := auth.New(&parser.AuthConfig{Type: "jwt", Secret: "test-secret"}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+	server.setupRoutes()
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/user?page=2&sort=-id", nil))
+	location, _ := url.Parse(w.Header().Get("Location"))
+	returnURL := location.Query().Get("return")
+	if location.Path != "/login" || returnURL != "/user?page=2&sort=-id" {
+		t.Fatalf("Expected the deep link to survive the redirect, got %q", w.Header().Get("Location"))
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", location.String(), nil))
+	if !strings.Contains(w.Body.String(), `let returnUrl = '/user?page=2\u0026sort=-id';`) {
+		t.Errorf("Expected the login page to return to the deep link, got %s", w.Body.String())
+	}
+
+	for _, external := range []string{"https://evil.example", "//evil.example", "/\\evil.example", "javascript:alert(1)", "user"} {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("GET", "/login?"+url.Values{"return": {external}}.Encode(), nil))
+		if !strings.Contains(w.Body.String(), "let returnUrl = '/';") {
+			t.Errorf("Expected %q to be replaced with /", external)
+		}
+	}
+}
+
+func TestServer_ModelHooks(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: "slug", Type: parser.FieldTypeSlug},
+					{Name: "locked", Type: parser.FieldTypeBoolean},
+					{Name: "length", Type: parser.FieldTypeNumber},
+				},
+				Hooks: map[string][]parser.HookConfig{
+					parser.HookBeforeCreate: {
+						{Reject: "{{if not .title}}title is required{{end}}"},
+						{Set: "slug", Value: "{{.title}}"},
+						{Set: "length", Value: "{{len .title}}"},
+						{Set: "locked", Value: "{{gt (len .title) 20}}"},
+					},
+					parser.HookBeforeUpdate: {{Set: "slug", Value: "{{.title}}"}},
+					parser.HookBeforeDelete: {{Reject: "{{if .locked}}locked posts can't be deleted{{end}}"}},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	w := httptest.NewRecorder()
+	server.handleAPICreate("Post")(w, httptest.NewRequest("POST", "/api/post", strings.NewReader(`{"title": "Hello World"}`)))
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"slug":"hello-world"`) {
+		t.Fatalf("Expected the hook to set the slug from the title, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"length":11`) || !strings.Contains(w.Body.String(), `"locked":false`) {
+		t.Errorf("Expected the hooks to set a number and a boolean, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	server.handleAPICreate("Post")(w, httptest.NewRequest("POST", "/api/post", strings.NewReader(`{"slug": "untitled"}`)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "title is required") {
+		t.Errorf("Expected the reject step to stop the create, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The update doesn't send the title, so the hook reads the stored one.
+	req := mux.SetURLVars(httptest.NewRequest("PUT", "/api/post/1", strings.NewReader(`{"slug": "changed", "locked": true}`)), map[string]string{"id": "1"})
+	w = httptest.NewRecorder()
+	server.handleAPIUpdate("Post")(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"slug":"hello-world"`) {
+		t.Errorf("Expected the update hook to use the stored title, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = mux.SetURLVars(httptest.NewRequest("DELETE", "/api/post/1", nil), map[string]string{"id": "1"})
+	w = httptest.NewRecorder()
+	server.handleAPIDelete("Post")(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "locked posts can't be deleted") {
+		t.Errorf("Expected the delete hook to reject locked posts, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := db.Get("Post", 1); err != nil {
+		t.Errorf("Expected the post to survive the rejected delete: %v", err)
+	}
+}
+
+func TestServer_PasswordChangeRequired(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name:   "User",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	store := auth.NewSQLiteStore(db.(*database.SQLiteDB).GetConnection())
+	authManager, err := auth.NewWithStore(&parser.AuthConfig{
+		Type:   "jwt",
+		Secret: "test-secret",
+		Users:  []parser.UserConfig{{Username: "ann", Password: "one-time", Role: "admin"}},
+	}, store)
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	if err := store.RequirePasswordChange("ann"); err != nil {
+		t.Fatalf("Failed to flag user: %v", err)
+	}
+	server.authManager = authManager
+	server.setupRoutes()
+
+	send := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/auth/login", "", map[string]string{"username": "ann", "password": "one-time"})
+	var login struct {
+		Token                  string `json:"token"`
+		PasswordChangeRequired bool   `json:"password_change_required"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &login)
+	if w.Code != http.StatusOK || !login.PasswordChangeRequired {
+		t.Fatalf("Expected login to report a required password change, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := send("GET", "/api/user", login.Token, nil); w.Code != http.StatusForbidden {
+		t.Fatalf("Expected API access to be blocked until the password changes, got %d", w.Code)
+	}
+
+	if w := send("POST", "/api/auth/password", login.Token, map[string]string{"current_password": "wrong", "new_password": "a-new-secret"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong current password to be rejected, got %d", w.Code)
+	}
+	if w := send("POST", "/api/auth/password", login.Token, map[string]string{"current_password": "one-time", "new_password": "short"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a short password to be rejected, got %d", w.Code)
+	}
+	if w := send("POST", "/api/auth/password", login.Token, map[string]string{"current_password": "one-time", "new_password": "a-new-secret"}); w.Code != http.StatusOK {
+		t.Fatalf("Expected password change to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := send("GET", "/api/user", login.Token, nil); w.Code != http.StatusOK {
+		t.Errorf("Expected API access after the password change, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+type tokenMailer struct {
+	tokens []string
+}
+
+func (m *tokenMailer) Send(to, subject, body string) error {
+	m.tokens = append(m.tokens, strings.TrimSpace(body[strings.LastIndex(body, "\n\n"):]))
+	return nil
+}
+
+func TestServer_EmailVerification(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+
+	store := auth.NewSQLiteStore(db.(*database.SQLiteDB).GetConnection())
+	authManager, err := auth.NewWithStore(&parser.AuthConfig{
+		Type:              "jwt",
+		Secret:            "test-secret",
+		Users:             []parser.UserConfig{{Username: "admin", Password: "admin-password", Role: "admin"}},
+		EmailVerification: &parser.EmailVerificationConfig{},
+		RequireVerified:   true,
+	}, store)
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	mailer := &tokenMailer{}
+	authManager.SetMailer(mailer)
+	server.authManager = authManager
+	server.setupRoutes()
+
+	send := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/auth/login", "", map[string]string{"username": "admin", "password": "admin-password"})
+	var admin struct {
+		Token string `json:"token"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &admin)
+	annUser := map[string]string{"username": "ann", "email": "ann@example.com", "password": "ann-password", "role": "admin"}
+	if w := send("POST", "/api/auth/users", admin.Token, map[string]string{"username": "bob", "email": "bob@example.com"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a user without a password to be rejected, got %d", w.Code)
+	}
+	if w := send("POST", "/api/auth/users", "", annUser); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected anonymous user creation to be rejected, got %d", w.Code)
+	}
+	if w := send("POST", "/api/auth/users", admin.Token, annUser); w.Code != http.StatusCreated || len(mailer.tokens) != 1 {
+		t.Fatalf("Expected the admin to create a user and a mail to be sent, got %d and %d mails: %s", w.Code, len(mailer.tokens), w.Body.String())
+	}
+	if w := send("POST", "/api/auth/users", admin.Token, annUser); w.Code != http.StatusConflict {
+		t.Errorf("Expected a duplicate user to conflict, got %d", w.Code)
+	}
+
+	w = send("POST", "/api/auth/login", "", map[string]string{"username": "ann", "password": "ann-password"})
+	var login struct {
+		Token         string `json:"token"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &login)
+	if w.Code != http.StatusOK || login.EmailVerified {
+		t.Fatalf("Expected login to report an unverified email, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := send("GET", "/api/user", login.Token, nil); w.Code != http.StatusOK {
+		t.Errorf("Expected reads to stay open while unverified, got %d", w.Code)
+	}
+	if w := send("POST", "/api/user", login.Token, map[string]any{"name": "x"}); w.Code != http.StatusForbidden {
+		t.Errorf("Expected writes to be blocked while unverified, got %d", w.Code)
+	}
+
+	if w := send("POST", "/api/auth/verify-email", login.Token, map[string]any{}); w.Code != http.StatusOK || len(mailer.tokens) != 2 {
+		t.Fatalf("Expected a tokenless request to resend the mail, got %d and %d mails", w.Code, len(mailer.tokens))
+	}
+	if w := send("POST", "/api/auth/verify-email", "", map[string]string{"token": "bogus"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown token to be rejected, got %d", w.Code)
+	}
+	if w := send("GET", "/api/auth/verify-email", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a link without a token to be rejected, got %d", w.Code)
+	}
+	if w := send("GET", "/api/auth/verify-email?token="+url.QueryEscape(mailer.tokens[0]), "", nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected the mailed link to verify, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := send("POST", "/api/user", login.Token, map[string]any{"name": "x"}); w.Code != http.StatusCreated {
+		t.Errorf("Expected writes after verification, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HeadRequests(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.config.Server.HeadRequests = true
+	server.setupRoutes()
+
+	for _, name := range []string{"ann", "bob"} {
+		if _, err := db.Create("User", map[string]any{"name": name}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	for _, path := range []string{"/api/user", "/api/user/1"} {
+		resp, err := http.Head(ts.URL + path)
+		if err != nil {
+			t.Fatalf("HEAD %s failed: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || len(body) != 0 {
+			t.Errorf("HEAD %s: expected 200 without a body, got %d with %q", path, resp.StatusCode, body)
+		}
+		if path == "/api/user" && resp.Header.Get("X-Total-Count") != "2" {
+			t.Errorf("Expected X-Total-Count 2, got %q", resp.Header.Get("X-Total-Count"))
+		}
+	}
+
+	disabled, _ := createTestSQLiteServer(t, schema)
+	disabled.setupRoutes()
+	w := httptest.NewRecorder()
+	disabled.ServeHTTP(w, httptest.NewRequest("HEAD", "/api/user", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected HEAD to be rejected when disabled, got %d", w.Code)
+	}
+}
+
+func TestServer_ListCountHeaders(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	for _, name := range []string{"ann", "bob", "cid"} {
+		if _, err := db.Create("User", map[string]any{"name": name}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query        string
+		totalCount   string
+		contentRange string
+	}{
+		{"", "3", "user 0-2/3"},
+		{"?page=2&page_size=2", "3", "user 2-2/3"},
+		{"?page=3&page_size=2", "3", "user */3"},
+		{"?page_size=2&count=false", "", "user 0-1/*"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("GET", "/api/user"+tt.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d: %s", tt.query, w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("X-Total-Count"); got != tt.totalCount {
+			t.Errorf("%q: expected X-Total-Count %q, got %q", tt.query, tt.totalCount, got)
+		}
+		if got := w.Header().Get("Content-Range"); got != tt.contentRange {
+			t.Errorf("%q: expected Content-Range %q, got %q", tt.query, tt.contentRange, got)
+		}
+	}
+}
+
+func TestServer_OptionsRequests(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name:   "User",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+	server.config.Server.OptionsRequests = true
+	server.setupRoutes()
+
+	for path, allow := range map[string]string{
+		"/api/user":                  "GET, POST",
+		"/api/user/1":                "GET, PUT, DELETE",
+		"/api/user/export":           "GET",
+		"/api/user/1/restore":        "POST",
+		"/api/user/1/delete-preview": "GET",
+	} {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("OPTIONS", path, nil))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("OPTIONS %s: expected status 204, got %d", path, w.Code)
+		}
+		if got := w.Header().Get("Allow"); got != allow {
+			t.Errorf("OPTIONS %s: expected Allow %q, got %q", path, allow, got)
+		}
+	}
+
+	withHead, _ := createTestSQLiteServer(t, schema)
+	withHead.config.Server.OptionsRequests = true
+	withHead.config.Server.HeadRequests = true
+	withHead.setupRoutes()
+	w := httptest.NewRecorder()
+	withHead.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/api/user", nil))
+	if got := w.Header().Get("Allow"); got != "GET, HEAD, POST" {
+		t.Errorf("Expected HEAD to be listed when enabled, got %q", got)
+	}
+}
+
+func TestServer_StreamLists(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	for i := 0; i < 5; i++ {
+		if _, err := db.Create("User", map[string]any{"name": fmt.Sprintf("user%d", i)}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	list := func(query string) (*httptest.ResponseRecorder, any) {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("GET", "/api/user"+query, nil))
+		var body any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%q: invalid JSON %q: %v", query, w.Body.String(), err)
+		}
+		return w, body
+	}
+
+	for _, query := range []string{"", "?page=2&page_size=2", "?page=9", "?count=false", "?filter.name=nobody"} {
+		server.config.Server.StreamLists = false
+		buffered, want := list(query)
+		server.config.Server.StreamLists = true
+		streamed, got := list(query)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: streamed body %s differs from buffered %s", query, streamed.Body.String(), buffered.Body.String())
+		}
+		for _, header := range []string{"Content-Type", "X-Total-Count", "Content-Range"} {
+			if streamed.Header().Get(header) != buffered.Header().Get(header) && !(header == "Content-Range" && query == "?count=false") {
+				t.Errorf("%q: streamed %s %q differs from buffered %q", query, header, streamed.Header().Get(header), buffered.Header().Get(header))
+			}
+		}
+	}
+}
+
+func TestServer_CompositePrimaryKey(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Membership": {
+				Name: "Membership",
+				Fields: []parser.Field{
+					{Name: "user_id", Type: parser.FieldTypeNumber, Required: true},
+					{Name: "role_id", Type: parser.FieldTypeNumber, Required: true},
+					{Name: "note", Type: parser.FieldTypeText},
+				},
+				PrimaryKey: []string{"user_id", "role_id"},
+			},
+		},
+	}
+	server, _ := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("POST", "/api/membership", map[string]any{"user_id": 5, "role_id": 3, "note": "a"}); w.Code != http.StatusCreated {
+		t.Fatalf("Expected create to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := send("GET", "/api/membership/5,3", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"5,3"`) {
+		t.Fatalf("Expected the record under its composite id, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := send("PUT", "/api/membership/5,3", map[string]any{"note": "b"}); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"note":"b"`) {
+		t.Errorf("Expected update by composite id, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := send("GET", "/api/membership/5", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected a partial id to be not found, got %d", w.Code)
+	}
+
+	if w := send("DELETE", "/api/membership/5,3", nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected delete by composite id, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := send("GET", "/api/membership/5,3", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected the deleted record to be gone, got %d", w.Code)
+	}
+}
+
+func TestServer_APIVersions(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name: "User",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "email", Type: parser.FieldTypeEmail},
+				},
+				Versions: map[string][]string{
+					"v1": {"name"},
+					"v2": {"name", "email"},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	id, err := db.Create("User", map[string]any{"name": "Ann", "email": "ann@example.com"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	get := func(path string) map[string]any {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200 from %s, got %d: %s", path, w.Code, w.Body.String())
+		}
+		var response struct {
+			Data json.RawMessage `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		var record map[string]any
+		if err := json.Unmarshal(response.Data, &record); err != nil {
+			var records []map[string]any
+			json.Unmarshal(response.Data, &records)
+			record = records[0]
+		}
+		return record
+	}
+
+	v1 := get(fmt.Sprintf("/api/v1/user/%v", id))
+	if _, ok := v1["email"]; ok || v1["name"] != "Ann" || v1["id"] == nil {
+		t.Errorf("Expected v1 to expose id and name only, got %v", v1)
+	}
+	if list := get("/api/v1/user"); list["email"] != nil {
+		t.Errorf("Expected v1 lists to omit email, got %v", list)
+	}
+
+	v2 := get(fmt.Sprintf("/api/v2/user/%v", id))
+	if v2["email"] != "ann@example.com" || v2["name"] != "Ann" {
+		t.Errorf("Expected v2 to expose name and email, got %v", v2)
+	}
+
+	if all := get(fmt.Sprintf("/api/user/%v", id)); all["email"] != "ann@example.com" {
+		t.Errorf("Expected the unversioned route to expose every field, got %v", all)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v3/user", nil)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected an undeclared version to be not found, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/user/export?format=csv", nil))
+	if header, _, _ := strings.Cut(w.Body.String(), "\n"); header != "id,name" {
+		t.Errorf("Expected v1 exports to carry only v1 columns, got %q", header)
+	}
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/user/export?format=csv&fields=name,email", nil))
+	if header, _, _ := strings.Cut(w.Body.String(), "\n"); header != "name" {
+		t.Errorf("Expected v1 exports to drop requested fields outside v1, got %q", header)
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/user?filter.email=ann@example.com", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected v1 to refuse filtering on email, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/user", strings.NewReader(`{"name": "Bo", "email": "bo@example.com"}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected v1 create to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Data map[string]any `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if record, _ := db.Get("User", created.Data["id"]); record["email"] != nil {
+		t.Errorf("Expected v1 create to ignore email, got %v", record["email"])
+	}
+}
+
+type flakyDB struct {
+	database.Database
+	down atomic.Bool
+}
+
+func (db *flakyDB) Ping() error {
+	if db.down.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (db *flakyDB) Reconnect() error {
+	return db.Ping()
+}
+
+func TestServer_ReadyzRecoversAfterOutage(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"User": {
+				Name:   "User",
+				Fields: []parser.Field{{Name: "id", Type: parser.FieldTypeID, Primary: true}},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	flaky := &flakyDB{Database: db}
+	server.db = flaky
+	server.config.Database.HealthCheck = "5ms"
+	server.startHealthMonitor()
+	server.setupRoutes()
+
+	readyz := func() int {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+		return w.Code
+	}
+	waitFor := func(code int) {
+		deadline := time.Now().Add(2 * time.Second)
+		for readyz() != code {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected /readyz to return %d", code)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor(http.StatusOK)
+	flaky.down.Store(true)
+	waitFor(http.StatusServiceUnavailable)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if strings.Contains(w.Body.String(), "connection refused") {
+		t.Errorf("Expected the failure cause to stay out of the response, got %s", w.Body.String())
+	}
+	flaky.down.Store(false)
+	waitFor(http.StatusOK)
+}
+
+func TestServer_HealthMonitorFollowsReloadAndShutdown(t *testing.T) {
+	server, db := createTestSQLiteServer(t, createTestSchema())
+	server.db = &flakyDB{Database: db}
+	server.config.Database.HealthCheck = "1h"
+	server.startHealthMonitor()
+	first := server.monitor.done
+
+	config := createTestConfig()
+	config.Database.HealthCheck = "2h"
+	if err := server.Reload(config); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	select {
+	case <-first:
+	default:
+		t.Error("Expected the reload to stop the previous monitor")
+	}
+	if server.monitor.done == nil {
+		t.Fatal("Expected the reload to start a monitor with the new interval")
+	}
+
+	config.Database.HealthCheck = ""
+	if err := server.Reload(config); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if monitored, _ := server.health.status(); monitored || server.monitor.done != nil {
+		t.Error("Expected no monitor once health_check is removed")
+	}
+
+	config.Database.HealthCheck = "1h"
+	if err := server.Reload(config); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	last := server.monitor.done
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	select {
+	case <-last:
+	default:
+		t.Error("Expected shutdown to stop the monitor")
+	}
+}
+
+func TestServer_MaxChangePct(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Product": {
+				Name: "Product",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "price", Type: parser.FieldTypeNumber, Decimal: true, MaxChangePct: 50},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	id, err := db.Create("Product", map[string]any{"price": 10.0})
+	if err != nil {
+		t.Fatalf("Failed to create product: %v", err)
+	}
+
+	update := func(price float64) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]any{"price": price})
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/product/%v", id), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := update(20); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "50%") {
+		t.Errorf("Expected a 100%% rise to be rejected with 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := update(12); w.Code != http.StatusOK {
+		t.Errorf("Expected a 20%% rise to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_BulkRoute(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name: "Note",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "body", Type: parser.FieldTypeText},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.setupRoutes()
+
+	bulk := func(body string) int {
+		req := httptest.NewRequest("POST", "/api/note/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := bulk(`{"operation": "create", "data": [{"body": "a"}, {"body": "b"}]}`); code != http.StatusCreated {
+		t.Fatalf("Expected the bulk route to create, got %d", code)
+	}
+	if count, _ := db.Count("Note", nil); count != 2 {
+		t.Fatalf("Expected two notes, got %d", count)
+	}
+	if code := bulk(`{"operation": "delete", "ids": [1, 2]}`); code != http.StatusOK {
+		t.Fatalf("Expected the bulk route to delete, got %d", code)
+	}
+	if count, _ := db.Count("Note", nil); count != 0 {
+		t.Errorf("Expected the notes to be deleted, got %d", count)
+	}
+}
+
+func TestServer_BulkUsesWritePipeline(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Post": {
+				Name: "Post",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "title", Type: parser.FieldTypeText},
+					{Name: "slug", Type: parser.FieldTypeSlug},
+					{Name: "score", Type: parser.FieldTypeNumber, ReadRoles: []string{"admin"}},
+				},
+				Hooks: map[string][]parser.HookConfig{
+					parser.HookBeforeCreate: {{Set: "slug", Value: "{{.title}}"}},
+					parser.HookBeforeDelete: {{Reject: "{{if eq .title \"Pinned\"}}pinned posts stay{{end}}"}},
+				},
+			},
+		},
+	}
+	server, db := createTestSQLiteServer(t, schema)
+	server.config.Server.Audit.Enabled = true
+
+	authManager, err := auth.New(&parser.AuthConfig{
+		Type:   "jwt",
+		Secret: "test-secret",
+		Users: []parser.UserConfig{
+			{Username: "bob", Password: "secret", Role: "user", Permissions: map[string]parser.EntityPermission{"Post": {Read: true, Write: true}}},
+		},
+	}, db.(*database.SQLiteDB).GetConnection())
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	server.authManager = authManager
+
+	bulk := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/post/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req = req.WithContext(context.WithValue(req.Context(), "user", &auth.User{Username: "bob", Role: "user"}))
+		w := httptest.NewRecorder()
+		server.handleAPIBulk("Post")(w, req)
+		return w
+	}
+
+	w := bulk("application/json", `{"operation": "create", "data": [{"title": "Hello World", "score": 10}, {"title": "Pinned"}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Data []map[string]any `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if len(created.Data) != 2 || created.Data[0]["slug"] != "hello-world" {
+		t.Errorf("Expected the before_create hook to set the slug, got %v", created.Data)
+	}
+	if _, ok := created.Data[0]["score"]; ok {
+		t.Errorf("Expected score to be hidden from the caller, got %v", created.Data[0])
+	}
+	if record, _ := db.Get("Post", 1); record["score"] != nil {
+		t.Errorf("Expected the caller not to set a hidden field, got %v", record["score"])
+	}
+
+	entries, err := db.(database.AuditLog).QueryAudit(database.AuditFilter{})
+	if err != nil || len(entries) != 2 || entries[0].Action != "create" || entries[0].User != "bob" {
+		t.Errorf("Expected an audit entry per created post, got %+v (%v)", entries, err)
+	}
+
+	w = bulk("application/json", `{"operation": "delete", "ids": [1, 2]}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "pinned posts stay") {
+		t.Errorf("Expected the before_delete hook to reject the batch, got %d: %s", w.Code, w.Body.String())
+	}
+	if count, _ := db.Count("Post", nil); count != 2 {
+		t.Errorf("Expected the rejected batch to delete nothing, got %d posts", count)
+	}
+
+	w = bulk("application/xml", `<request><operation>create</operation><data><item><title>From XML</title></item></data></request>`)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"slug":"from-xml"`) {
+		t.Errorf("Expected an XML bulk create, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = bulk("application/xml", `<request><operation>delete</operation><ids><item>1</item><item>3</item></ids></request>`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if count, _ := db.Count("Post", nil); count != 1 {
+		t.Errorf("Expected one post left, got %d", count)
+	}
+	entries, _ = db.(database.AuditLog).QueryAudit(database.AuditFilter{})
+	if len(entries) != 5 || entries[0].Action != "delete" {
+		t.Errorf("Expected audit entries for the deletes, got %+v", entries)
+	}
+}
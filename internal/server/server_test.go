@@ -2,11 +2,14 @@ package server
 
 import (
 	"database/sql"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
+	"github.com/yamlforge/yamlforge/internal/database"
 	"github.com/yamlforge/yamlforge/internal/parser"
 )
 
@@ -131,6 +134,41 @@ func (m *MockDatabase) Delete(model string, id interface{}) error {
 	return nil
 }
 
+func (m *MockDatabase) Restore(model string, id interface{}) error {
+	if m.shouldError {
+		return parser.ValidationError{Message: "restore failed"}
+	}
+	return nil
+}
+
+func (m *MockDatabase) Aggregate(model string, function string, field string, filters []parser.Filter) (float64, error) {
+	if m.shouldError {
+		return 0, parser.ValidationError{Message: "aggregate failed"}
+	}
+	
+	var total, count float64
+	for _, record := range m.data {
+		if record["_model"] != model {
+			continue
+		}
+		count++
+		if value, ok := record[field].(float64); ok {
+			total += value
+		}
+	}
+	
+	switch function {
+	case "count":
+		return count, nil
+	case "avg":
+		if count == 0 {
+			return 0, nil
+		}
+		return total / count, nil
+	}
+	return total, nil
+}
+
 func (m *MockDatabase) Count(model string, filters []parser.Filter) (int64, error) {
 	if m.shouldError {
 		return 0, parser.ValidationError{Message: "count failed"}
@@ -379,6 +417,29 @@ func TestServer_HandleModelView(t *testing.T) {
 	}
 }
 
+func TestServer_HandleModelView_DisplayName(t *testing.T) {
+	schema := createTestSchema()
+	schema.Models["User"].DisplayName = "{{.name}} ({{.email}})"
+	server, db := createTestSQLiteServer(t, schema)
+
+	id, _ := db.Create("User", map[string]interface{}{
+		"name":  "John Doe",
+		"email": "john@example.com",
+	})
+
+	req := httptest.NewRequest("GET", "/user/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(id)})
+	w := httptest.NewRecorder()
+	server.handleModelView("User")(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "<h2>John Doe (john@example.com)</h2>") {
+		t.Error("Expected the view page to be titled by display_name")
+	}
+}
+
 func TestServer_LoggingMiddleware(t *testing.T) {
 	config := createTestConfig()
 	server := New(config)
@@ -606,4 +667,174 @@ func TestServer_HandleSwaggerUI(t *testing.T) {
 	if !strings.Contains(body, config.App.Name) {
 		t.Error("Expected response to contain app name in title")
 	}
-}
\ No newline at end of file
+}
+
+func TestServer_ParseQueryParams_IDs(t *testing.T) {
+	config := createTestConfig()
+	server := New(config)
+
+	req := httptest.NewRequest("GET", "/test?ids=1,2,,3", nil)
+
+	params := server.parseQueryParams(req)
+
+	if len(params.Filters) != 1 {
+		t.Fatalf("Expected 1 filter, got %d", len(params.Filters))
+	}
+
+	filter := params.Filters[0]
+	if filter.Field != "id" || filter.Operator != "in" {
+		t.Errorf("Expected id in filter, got %+v", filter)
+	}
+	if values, ok := filter.Value.([]any); !ok || len(values) != 3 {
+		t.Errorf("Expected 3 ids, got %v", filter.Value)
+	}
+	if params.PageSize != 3 {
+		t.Errorf("Expected page size to match the id count, got %d", params.PageSize)
+	}
+
+	config.Server.Pagination.MaxPageSize = 2
+	if params := server.parseQueryParams(req); params.PageSize != 2 {
+		t.Errorf("Expected page size to be capped at max_page_size, got %d", params.PageSize)
+	}
+
+	server.schema = createTestSchema()
+	server.db = NewMockDatabase()
+	w := httptest.NewRecorder()
+	server.handleAPIList("User")(w, httptest.NewRequest("GET", "/api/user?ids=1,2,3", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for more ids than max_page_size, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	server.handleAPIList("User")(w, httptest.NewRequest("GET", "/api/user?ids=1,2", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected ids within max_page_size to be listed, got %d", w.Code)
+	}
+}
+
+func TestServer_ParseQueryParams_FilterOperators(t *testing.T) {
+	config := createTestConfig()
+	server := New(config)
+
+	req := httptest.NewRequest("GET", "/test?filter.age__gte=18&filter.age__lte=65&filter.role=admin&filter.status__in=a,b", nil)
+
+	params := server.parseQueryParams(req)
+
+	got := make(map[string]parser.Filter)
+	for _, filter := range params.Filters {
+		got[filter.Field+" "+filter.Operator] = filter
+	}
+	if got["age >="].Value != "18" || got["age <="].Value != "65" {
+		t.Errorf("Expected age range filters, got %+v", params.Filters)
+	}
+	if got["role ="].Value != "admin" {
+		t.Errorf("Expected plain equality filter for role, got %+v", params.Filters)
+	}
+	if values, ok := got["status in"].Value.([]any); !ok || len(values) != 2 {
+		t.Errorf("Expected status in filter with 2 values, got %+v", params.Filters)
+	}
+}
+
+func TestServer_HandleHome_ModelCounts(t *testing.T) {
+	config := createTestConfig()
+	server := New(config)
+	server.schema = createTestSchema()
+	server.db = NewMockDatabase()
+
+	mockDB := server.db.(*MockDatabase)
+	mockDB.Create("User", map[string]interface{}{"name": "John"})
+	mockDB.Create("User", map[string]interface{}{"name": "Jane"})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	server.handleHome(w, req)
+
+	if !strings.Contains(w.Body.String(), `User <span class="stat-count">(2)</span>`) {
+		t.Error("Expected dashboard card to show the User record count")
+	}
+}
+
+func TestNormalizeNumbers(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/post", strings.NewReader(`{"user_id": 9007199254740993, "score": 1.5, "tags": [1, 2]}`))
+
+	server := New(createTestConfig())
+	server.schema = createTestSchema()
+
+	data, err := server.decodeRecord("Post", req)
+	if err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+
+	if data["user_id"] != int64(9007199254740993) {
+		t.Errorf("Expected large integer to survive decoding, got %v (%T)", data["user_id"], data["user_id"])
+	}
+	if data["score"] != 1.5 {
+		t.Errorf("Expected float to decode as float64, got %v (%T)", data["score"], data["score"])
+	}
+	if tags := data["tags"].([]any); tags[0] != int64(1) {
+		t.Errorf("Expected nested numbers to be normalized, got %T", tags[0])
+	}
+}
+
+func TestServer_EncodeIDs(t *testing.T) {
+	config := createTestConfig()
+	server := New(config)
+	server.schema = createTestSchema()
+
+	record := map[string]any{"id": int64(9007199254740993), "name": "John"}
+	server.encodeIDs("User", record)
+	if record["id"] != int64(9007199254740993) {
+		t.Errorf("Expected ids to stay numeric by default, got %T", record["id"])
+	}
+
+	config.Server.StringIDs = true
+	server.encodeIDs("User", record)
+	if record["id"] != "9007199254740993" {
+		t.Errorf("Expected id to be serialized as a string, got %v (%T)", record["id"], record["id"])
+	}
+}
+
+func TestServer_HandleModelView_StringIDs(t *testing.T) {
+	server, db := createTestSQLiteServer(t, createTestSchema())
+	server.config.Server.StringIDs = true
+
+	if _, err := db.Create("User", map[string]any{"id": int64(9007199254740993), "name": "John", "email": "john@example.com"}); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/user/9007199254740993", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "9007199254740993"})
+	w := httptest.NewRecorder()
+	server.handleModelView("User")(w, req)
+
+	body := w.Body.String()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !strings.Contains(body, `href="/user/9007199254740993/edit"`) || !strings.Contains(body, "const recordId = '9007199254740993'") {
+		t.Error("Expected the view page to link to the record by its full id")
+	}
+	if !strings.Contains(body, `"id":"9007199254740993"`) {
+		t.Error("Expected the page's record to carry the id as a string")
+	}
+}
+
+func TestServer_SendWriteError_Busy(t *testing.T) {
+	server := New(createTestConfig())
+
+	w := httptest.NewRecorder()
+	server.sendWriteError(w, fmt.Errorf("%w: locked", database.ErrDatabaseBusy))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+
+	w = httptest.NewRecorder()
+	server.sendWriteError(w, fmt.Errorf("constraint failed"))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 for other errors, got %d", w.Code)
+	}
+}
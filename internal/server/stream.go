@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/yamlforge/yamlforge/internal/database"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// streamList serves a list page straight from a database cursor when
+// server.stream_lists is on, encoding one record at a time instead of
+// buffering the page. It returns false when the response has to go
+// through the buffered path: XML and pretty output, shared reads, or a
+// database that can't stream.
+func (s *Server) streamList(w http.ResponseWriter, r *http.Request, modelName string, params parser.QueryParams, countLimit int) bool {
+	if !s.config.Server.StreamLists || s.config.Server.DedupeReads {
+		return false
+	}
+	switch w.(type) {
+	case *xmlResponseWriter, *prettyResponseWriter:
+		return false
+	}
+	db := s.dbFor(r)
+	streamer, ok := db.(database.RecordStreamer)
+	if !ok {
+		return false
+	}
+
+	// Count before opening the cursor: with a single connection the count
+	// would otherwise wait on the rows being streamed.
+	meta, err := s.countList(db, modelName, params, countLimit)
+	if err != nil {
+		s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return true
+	}
+
+	rows, err := streamer.QueryStream(modelName, params)
+	if err != nil {
+		s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return true
+	}
+	defer rows.Close()
+
+	setRangeHeaders(w, strings.ToLower(modelName), params, pageRows(params, meta), meta)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	io.WriteString(w, `{"success":true,"data":[`)
+	for i := 0; rows.Next(); i++ {
+		record := s.encodeRecords(modelName, s.hideRestrictedFields(r, modelName, rows.Record()))[0]
+		data, err := json.Marshal(s.orderFields(modelName, record))
+		if err != nil {
+			log.Printf("Error streaming %s list: %v", modelName, err)
+			return true
+		}
+		if i > 0 {
+			io.WriteString(w, ",")
+		}
+		w.Write(data)
+	}
+	if err := rows.Err(); err != nil {
+		// The status is already sent; a truncated body is all that's left.
+		log.Printf("Error streaming %s list: %v", modelName, err)
+		return true
+	}
+
+	data, _ := json.Marshal(meta)
+	io.WriteString(w, `],"meta":`)
+	w.Write(data)
+	io.WriteString(w, "}\n")
+	return true
+}
+
+// pageRows works out how many rows a page holds from the total, or -1
+// when the count was skipped.
+func pageRows(params parser.QueryParams, meta *parser.Meta) int {
+	if meta.TotalCount == nil {
+		return -1
+	}
+	n := int(*meta.TotalCount)
+	if params.Page > 1 {
+		n -= (params.Page - 1) * params.PageSize
+	}
+	if params.PageSize > 0 && n > params.PageSize {
+		n = params.PageSize
+	}
+	if n < 0 {
+		n = 0
+	}
+	return n
+}
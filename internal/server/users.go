@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/yamlforge/yamlforge/internal/auth"
+)
+
+// handleAuthCreateUser lets admins add users at runtime. With email
+// verification on, the new user starts unverified and is mailed a token.
+func (s *Server) handleAuthCreateUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		s.handleAuthError(w, r, "Authentication required")
+		return
+	}
+	if user.Role != "admin" {
+		s.sendJSON(w, http.StatusForbidden, map[string]any{
+			"success": false,
+			"error":   "Only admins can create users",
+		})
+		return
+	}
+
+	var request struct {
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Username == "" || request.Email == "" || request.Password == "" {
+		s.sendJSON(w, http.StatusBadRequest, map[string]any{
+			"success": false,
+			"error":   "username, email and password are required",
+		})
+		return
+	}
+	if request.Role == "" {
+		request.Role = "user"
+	}
+
+	if err := s.authManager.CreateUser(request.Username, request.Email, request.Password, request.Role); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, auth.ErrWeakPassword):
+			status = http.StatusBadRequest
+		case errors.Is(err, auth.ErrUserExists):
+			status = http.StatusConflict
+		}
+		s.sendJSON(w, status, map[string]any{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	s.sendJSON(w, http.StatusCreated, map[string]any{
+		"success":        true,
+		"email_verified": !s.authManager.VerificationEnabled(),
+	})
+}
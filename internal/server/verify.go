@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/yamlforge/yamlforge/internal/auth"
+)
+
+// verificationBlocks reports whether an unverified user may not make this
+// request: reads and the auth endpoints stay open, other writes do not.
+func verificationBlocks(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return !passwordChangeAllowed(r.URL.Path) && r.URL.Path != auth.VerifyEmailPath
+}
+
+func (s *Server) handleVerificationRequired(w http.ResponseWriter) {
+	s.sendJSON(w, http.StatusForbidden, map[string]any{
+		"success":                     false,
+		"error":                       "Email verification required",
+		"email_verification_required": true,
+	})
+}
+
+// handleAuthVerifyEmail confirms a mailed token, posted back or opened
+// from the link as ?token=.... Posted without a token by a logged-in
+// user, it mails that user a new one instead.
+func (s *Server) handleAuthVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Token string `json:"token"`
+	}
+	if r.Method == http.MethodGet {
+		request.Token = r.URL.Query().Get("token")
+		if request.Token == "" {
+			s.sendJSON(w, http.StatusBadRequest, map[string]any{
+				"success": false,
+				"error":   auth.ErrInvalidVerificationToken.Error(),
+			})
+			return
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.sendJSON(w, http.StatusBadRequest, map[string]any{
+			"success": false,
+			"error":   "Invalid request",
+		})
+		return
+	}
+
+	if request.Token == "" {
+		user, err := s.authManager.GetUserFromToken(r)
+		if err != nil {
+			s.handleAuthError(w, r, "Authentication required")
+			return
+		}
+		if !user.EmailVerified {
+			if err := s.authManager.SendVerification(user.Username, user.Email); err != nil {
+				s.sendJSON(w, http.StatusInternalServerError, map[string]any{
+					"success": false,
+					"error":   "Failed to send verification email",
+				})
+				return
+			}
+		}
+		s.sendJSON(w, http.StatusOK, map[string]any{
+			"success":        true,
+			"email_verified": user.EmailVerified,
+		})
+		return
+	}
+
+	if err := s.authManager.VerifyEmail(request.Token); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrInvalidVerificationToken) {
+			status = http.StatusBadRequest
+		}
+		s.sendJSON(w, status, map[string]any{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, map[string]any{
+		"success":        true,
+		"email_verified": true,
+	})
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const webhookTimeout = 10 * time.Second
+
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+type webhookPayload struct {
+	Event string         `json:"event"`
+	Model string         `json:"model"`
+	Data  map[string]any `json:"data"`
+}
+
+// notifyWebhooks posts a write to the model's webhooks in the background.
+// The payload is built up front because callers go on to mutate record.
+func (s *Server) notifyWebhooks(modelName, event string, record map[string]any) {
+	model, ok := s.schema.GetModel(modelName)
+	if !ok {
+		return
+	}
+
+	for _, webhook := range model.Webhooks {
+		if !webhookWants(webhook, event) {
+			continue
+		}
+
+		body, err := json.Marshal(webhookPayload{
+			Event: event,
+			Model: modelName,
+			Data:  webhookData(model, webhook, record),
+		})
+		if err != nil {
+			log.Printf("failed to encode webhook for %s: %v", modelName, err)
+			continue
+		}
+
+		go func(url string) {
+			resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+			if err != nil {
+				log.Printf("webhook %s failed: %v", url, err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				log.Printf("webhook %s returned %s", url, resp.Status)
+			}
+		}(webhook.URL)
+	}
+}
+
+func webhookWants(webhook parser.WebhookConfig, event string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, e := range webhook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookData copies the record without password fields. Fields limited by
+// read_roles are only sent when the webhook lists them explicitly.
+func webhookData(model *parser.Model, webhook parser.WebhookConfig, record map[string]any) map[string]any {
+	listed := make(map[string]bool, len(webhook.Fields))
+	for _, name := range webhook.Fields {
+		listed[name] = true
+	}
+
+	data := make(map[string]any, len(record))
+	for key, value := range record {
+		if len(listed) > 0 && !listed[key] {
+			continue
+		}
+		data[key] = value
+	}
+
+	for _, field := range model.Fields {
+		if field.Type == parser.FieldTypePassword || (len(field.ReadRoles) > 0 && !listed[field.Name]) {
+			delete(data, field.Name)
+		}
+	}
+	return data
+}
+
+type purgePayload struct {
+	Event string `json:"event"`
+	Model string `json:"model"`
+	ID    string `json:"id"`
+}
+
+// purgeCache tells the model's on_change endpoint which record a write
+// touched. Unlike webhooks it carries no record data, only enough for a
+// cache to drop its copy. The id is sent as it appears in record paths.
+func (s *Server) purgeCache(modelName, event string, id any) {
+	model, ok := s.schema.GetModel(modelName)
+	if !ok || model.OnChange == "" {
+		return
+	}
+
+	body, err := json.Marshal(purgePayload{Event: event, Model: modelName, ID: fmt.Sprint(id)})
+	if err != nil {
+		log.Printf("failed to encode cache purge for %s: %v", modelName, err)
+		return
+	}
+
+	go func(url string) {
+		resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("cache purge %s failed: %v", url, err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Printf("cache purge %s returned %s", url, resp.Status)
+		}
+	}(model.OnChange)
+}
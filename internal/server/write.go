@@ -0,0 +1,186 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/yamlforge/yamlforge/internal/database"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+// writeRejection is a write refused before it reached the database, with
+// the status and body to answer it with.
+type writeRejection struct {
+	status int
+	body   map[string]any
+}
+
+func badRequest(err error) *writeRejection {
+	return &writeRejection{
+		status: http.StatusBadRequest,
+		body:   map[string]any{"success": false, "error": err.Error()},
+	}
+}
+
+// validationRejection answers a failed validation with 400 and any other
+// error, e.g. a failed relation lookup, with 500.
+func validationRejection(err error) *writeRejection {
+	var validationErr parser.ValidationError
+	if errors.As(err, &validationErr) {
+		return badRequest(err)
+	}
+	return &writeRejection{
+		status: http.StatusInternalServerError,
+		body:   map[string]any{"success": false, "error": err.Error()},
+	}
+}
+
+// prepareCreate runs a decoded record through everything a create checks
+// before it is written: strict_fields, read_roles, transforms, the
+// before_create hooks and validation. data is changed in place.
+func (s *Server) prepareCreate(r *http.Request, modelName string, data map[string]any) *writeRejection {
+	if s.config.Server.StrictFields {
+		if unknown := s.validator.UnknownFields(modelName, data); len(unknown) > 0 {
+			return &writeRejection{
+				status: http.StatusBadRequest,
+				body: map[string]any{
+					"success": false,
+					"error":   "unknown fields: " + strings.Join(unknown, ", "),
+					"fields":  unknown,
+				},
+			}
+		}
+	}
+
+	// Callers can't read fields read_roles hides from them, so they
+	// can't set them either; the same holds on update.
+	s.hideRestrictedField(r, modelName, data)
+
+	if err := s.validator.Transform(modelName, data); err != nil {
+		return badRequest(err)
+	}
+	if err := s.runHooks(modelName, parser.HookBeforeCreate, nil, data); err != nil {
+		return badRequest(err)
+	}
+	s.validator.Normalize(modelName, data)
+	if err := s.validator.ValidateCreate(modelName, data); err != nil {
+		return badRequest(err)
+	}
+
+	if err := s.validator.ValidateRelations(modelName, data, s.dbFor(r)); err != nil {
+		return validationRejection(err)
+	}
+	return nil
+}
+
+// createRecord inserts data and returns the stored record. Databases that
+// support it do both in one transaction, so the response can't pick up a
+// concurrent write or a lagging read replica.
+func (s *Server) createRecord(r *http.Request, modelName string, data map[string]any) (any, map[string]any, error) {
+	db := s.dbFor(r)
+	if writer, ok := db.(database.RecordWriter); ok {
+		return writer.CreateRecord(modelName, data)
+	}
+
+	id, err := db.Create(modelName, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	record, err := s.db.Get(modelName, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, record, nil
+}
+
+func (s *Server) updateRecord(r *http.Request, modelName string, id any, data map[string]any) (map[string]any, error) {
+	db := s.dbFor(r)
+	if writer, ok := db.(database.RecordWriter); ok {
+		return writer.UpdateRecord(modelName, id, data)
+	}
+
+	if err := db.Update(modelName, id, data); err != nil {
+		return nil, err
+	}
+	return s.db.Get(modelName, id)
+}
+
+func (s *Server) restoreRecord(r *http.Request, modelName string, id any) (map[string]any, error) {
+	db := s.dbFor(r)
+	if writer, ok := db.(database.RecordWriter); ok {
+		return writer.RestoreRecord(modelName, id)
+	}
+
+	if err := db.Restore(modelName, id); err != nil {
+		return nil, err
+	}
+	return s.db.Get(modelName, id)
+}
+
+// createRecords inserts every item or, when one fails, none of them, and
+// returns the stored records in order.
+func (s *Server) createRecords(r *http.Request, modelName string, items []map[string]any) ([]any, []map[string]any, error) {
+	db := s.dbFor(r)
+	if writer, ok := db.(database.RecordWriter); ok {
+		return writer.CreateRecords(modelName, items)
+	}
+
+	var ids []any
+	if batch, ok := db.(database.BatchCreator); ok {
+		var err error
+		if ids, err = batch.CreateBatch(modelName, items); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		for i, item := range items {
+			id, err := db.Create(modelName, item)
+			if err != nil {
+				return nil, nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	records := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		record, err := s.db.Get(modelName, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		records = append(records, record)
+	}
+	return ids, records, nil
+}
+
+// deleteRecords deletes every id, in one transaction where the database
+// supports it.
+func (s *Server) deleteRecords(r *http.Request, modelName string, ids []any) error {
+	db := s.dbFor(r)
+	if writer, ok := db.(database.RecordWriter); ok {
+		return writer.DeleteRecords(modelName, ids)
+	}
+
+	for _, id := range ids {
+		if err := db.Delete(modelName, id); err != nil {
+			return fmt.Errorf("id %v: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// runHooks runs the model's hooks for event. For updates and deletes id
+// names the stored record, which the hook templates can read.
+func (s *Server) runHooks(modelName, event string, id any, data map[string]any) error {
+	model, ok := s.schema.GetModel(modelName)
+	if !ok || len(model.Hooks[event]) == 0 {
+		return nil
+	}
+
+	var current map[string]any
+	if id != nil {
+		current, _ = s.db.Get(modelName, id)
+	}
+	return model.RunHooks(event, data, current)
+}
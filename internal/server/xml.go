@@ -0,0 +1,307 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yamlforge/yamlforge/internal/api"
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const xmlRootElement = "response"
+
+type xmlResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (s *Server) contentNegotiationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") && acceptsXML(r) {
+			w = &xmlResponseWriter{ResponseWriter: w}
+		} else if s.wantsPrettyJSON(r) {
+			w = &prettyResponseWriter{ResponseWriter: w}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func acceptsXML(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if mediaType == "application/json" {
+			return false
+		}
+		if isXMLMediaType(mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+func isXMLRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && isXMLMediaType(mediaType)
+}
+
+func isXMLMediaType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml"
+}
+
+func (s *Server) sendXML(w http.ResponseWriter, status int, data any) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := encodeXML(&buf, data); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+func encodeXML(w io.Writer, data any) error {
+	// Round-trip through JSON so XML documents share the field names and
+	// shapes of the JSON envelope, including struct tags.
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	if err := writeXMLElement(enc, xmlRootElement, value); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func writeXMLElement(enc *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := writeXMLElement(enc, key, v[key]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := writeXMLElement(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+func decodeXMLRecord(r io.Reader) (map[string]any, error) {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			value, err := readXMLValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			record, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("expected record element")
+			}
+			return record, nil
+		}
+	}
+}
+
+// decodeXMLBulk reads a bulk request whose data and ids hold one <item>
+// per record or id, the way lists are encoded in responses.
+func decodeXMLBulk(r io.Reader, limit int) (*api.BulkRequest, error) {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			break
+		}
+	}
+
+	request := &api.BulkRequest{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "operation":
+				value, err := readXMLValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				request.Operation, _ = value.(string)
+			case "data":
+				items, err := readXMLItems(decoder, limit)
+				if err != nil {
+					return nil, err
+				}
+				for _, item := range items {
+					record, ok := item.(map[string]any)
+					if !ok {
+						return nil, fmt.Errorf("expected record element")
+					}
+					request.Data = append(request.Data, record)
+				}
+			case "ids":
+				if request.IDs, err = readXMLItems(decoder, limit); err != nil {
+					return nil, err
+				}
+			default:
+				if err := decoder.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			return request, nil
+		}
+	}
+}
+
+func readXMLItems(decoder *xml.Decoder, limit int) ([]any, error) {
+	var items []any
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			if len(items) == limit {
+				return nil, api.ErrBulkTooLarge
+			}
+			value, err := readXMLValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		case xml.EndElement:
+			return items, nil
+		}
+	}
+}
+
+func readXMLValue(decoder *xml.Decoder) (any, error) {
+	var text strings.Builder
+	var children map[string]any
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := readXMLValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if children == nil {
+				children = make(map[string]any)
+			}
+			children[t.Name.Local] = child
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if children != nil {
+				return children, nil
+			}
+			return strings.TrimSpace(text.String()), nil
+		}
+	}
+}
+
+func xmlTypedField(field parser.Field) bool {
+	switch field.Type {
+	case parser.FieldTypeNumber, parser.FieldTypeBoolean:
+		return true
+	case parser.FieldTypeID, parser.FieldTypeRelation:
+		return !field.KeyType.IsString()
+	}
+	return false
+}
+
+func coerceXMLValues(model *parser.Model, data map[string]any) {
+	coerceXMLFields(model.Fields, data)
+}
+
+func coerceXMLFields(fields []parser.Field, data map[string]any) {
+	for _, field := range fields {
+		if nested, ok := data[field.Name].(map[string]any); ok && field.Type == parser.FieldTypeObject {
+			coerceXMLFields(field.Properties, nested)
+			continue
+		}
+
+		raw, ok := data[field.Name].(string)
+		if !ok {
+			continue
+		}
+
+		if raw == "" && xmlTypedField(field) {
+			data[field.Name] = nil
+			continue
+		}
+
+		switch {
+		case field.Type == parser.FieldTypeNumber && field.Decimal:
+			if f, err := strconv.ParseFloat(raw, 64); err == nil {
+				data[field.Name] = f
+			}
+		case field.Type == parser.FieldTypeNumber,
+			(field.Type == parser.FieldTypeID || field.Type == parser.FieldTypeRelation) && !field.KeyType.IsString():
+			if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				data[field.Name] = i
+			} else if f, err := strconv.ParseFloat(raw, 64); err == nil {
+				data[field.Name] = f
+			}
+		case field.Type == parser.FieldTypeBoolean:
+			if b, err := strconv.ParseBool(raw); err == nil {
+				data[field.Name] = b
+			}
+		}
+	}
+}
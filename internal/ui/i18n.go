@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"encoding/json"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+const defaultLocale = "en"
+
+var messages = map[string]map[string]string{
+	"en": {
+		"dashboard":    "Dashboard",
+		"add_new":      "Add New",
+		"view_all":     "View All",
+		"logout":       "Logout",
+		"search":       "Search...",
+		"actions":      "Actions",
+		"loading":      "Loading...",
+		"new":          "New",
+		"edit":         "Edit",
+		"create":       "Create",
+		"update":       "Update",
+		"save":         "Save",
+		"cancel":       "Cancel",
+		"delete":       "Delete",
+		"back_to_list": "Back to List",
+		"trash":        "Trash",
+		"all":          "All",
+		"yes":          "Yes",
+		"no":           "No",
+		"min":          "Min",
+		"max":          "Max",
+		"export_csv":   "Export CSV",
+		"saved":        "Saved.",
+		"deleted":      "Record deleted.",
+	},
+	"es": {
+		"dashboard":    "Panel",
+		"add_new":      "Añadir nuevo",
+		"view_all":     "Ver todo",
+		"logout":       "Cerrar sesión",
+		"search":       "Buscar...",
+		"actions":      "Acciones",
+		"loading":      "Cargando...",
+		"new":          "Nuevo",
+		"edit":         "Editar",
+		"create":       "Crear",
+		"update":       "Actualizar",
+		"save":         "Guardar",
+		"cancel":       "Cancelar",
+		"delete":       "Eliminar",
+		"back_to_list": "Volver a la lista",
+		"trash":        "Papelera",
+		"all":          "Todos",
+		"yes":          "Sí",
+		"no":           "No",
+		"min":          "Mín.",
+		"max":          "Máx.",
+		"export_csv":   "Exportar CSV",
+		"saved":        "Guardado.",
+		"deleted":      "Registro eliminado.",
+	},
+	"pt": {
+		"dashboard":    "Painel",
+		"add_new":      "Adicionar novo",
+		"view_all":     "Ver todos",
+		"logout":       "Sair",
+		"search":       "Pesquisar...",
+		"actions":      "Ações",
+		"loading":      "Carregando...",
+		"new":          "Novo",
+		"edit":         "Editar",
+		"create":       "Criar",
+		"update":       "Atualizar",
+		"save":         "Salvar",
+		"cancel":       "Cancelar",
+		"delete":       "Excluir",
+		"back_to_list": "Voltar para a lista",
+		"trash":        "Lixeira",
+		"all":          "Todos",
+		"yes":          "Sim",
+		"no":           "Não",
+		"min":          "Mín.",
+		"max":          "Máx.",
+		"export_csv":   "Exportar CSV",
+		"saved":        "Salvo.",
+		"deleted":      "Registro excluído.",
+	},
+}
+
+func translate(locale, key string) string {
+	if catalog, ok := messages[locale]; ok {
+		if msg, ok := catalog[key]; ok {
+			return msg
+		}
+	}
+	if msg, ok := messages[defaultLocale][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// jsMessageKeys are the strings the shared JS sets itself rather than
+// finding them in the rendered page.
+var jsMessageKeys = []string{"back_to_list", "trash", "deleted", "saved"}
+
+// jsMessages defines the uiMessages table read by the JS uiText helper.
+func jsMessages(locale string) string {
+	table := make(map[string]string, len(jsMessageKeys))
+	for _, key := range jsMessageKeys {
+		table[key] = translate(locale, key)
+	}
+	data, _ := json.Marshal(table)
+	return "\nconst uiMessages = " + string(data) + ";\n"
+}
+
+func htmlLang(locale string) string {
+	if _, ok := messages[locale]; ok {
+		return locale
+	}
+	return defaultLocale
+}
+
+func fieldLabel(field parser.Field) string {
+	if field.Label != "" {
+		return field.Label
+	}
+	return formatFieldName(field.Name)
+}
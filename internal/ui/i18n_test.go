@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yamlforge/yamlforge/internal/parser"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		locale   string
+		key      string
+		expected string
+	}{
+		{"en", "save", "Save"},
+		{"es", "save", "Guardar"},
+		{"", "dashboard", "Dashboard"},
+		{"xx", "dashboard", "Dashboard"},
+		{"es", "missing_key", "missing_key"},
+	}
+
+	for _, test := range tests {
+		if result := translate(test.locale, test.key); result != test.expected {
+			t.Errorf("translate(%q, %q) = %q, expected %q", test.locale, test.key, result, test.expected)
+		}
+	}
+}
+
+func TestLocalizedShell(t *testing.T) {
+	config := createTestConfig()
+	config.UI.Locale = "es"
+	schema := createTestSchema()
+
+	html := GetHomeHTML(config, schema, nil, nil, nil, nil)
+	if !strings.Contains(html, `<html lang="es">`) {
+		t.Error("Expected html lang to follow the locale")
+	}
+	if !strings.Contains(html, "<h2>Panel</h2>") {
+		t.Error("Expected dashboard heading in Spanish")
+	}
+	if !strings.Contains(html, "Añadir nuevo") {
+		t.Error("Expected Add New in Spanish")
+	}
+
+	html = GetListHTML(config, schema, "User", schema.Models["User"], true)
+	if !strings.Contains(html, `placeholder="Buscar..."`) {
+		t.Error("Expected search placeholder in Spanish")
+	}
+	if !strings.Contains(html, `"back_to_list":"Volver a la lista"`) || !strings.Contains(html, `"trash":"Papelera"`) || !strings.Contains(html, `"deleted":"Registro eliminado."`) {
+		t.Error("Expected the JS messages in Spanish")
+	}
+	if !strings.Contains(html, `"saved":"Guardado."`) {
+		t.Error("Expected the save toast fallback in Spanish")
+	}
+	if js := getJS(); strings.Contains(js, "'Back to List'") || strings.Contains(js, "'Record deleted.'") || strings.Contains(js, "'Saved.'") {
+		t.Error("Expected the JS to read its strings from uiMessages")
+	}
+}
+
+func TestFieldLabel(t *testing.T) {
+	if label := fieldLabel(parser.Field{Name: "first_name"}); label != "First Name" {
+		t.Errorf("Expected derived label, got %s", label)
+	}
+	if label := fieldLabel(parser.Field{Name: "first_name", Label: "Nombre"}); label != "Nombre" {
+		t.Errorf("Expected label override, got %s", label)
+	}
+
+	html := generateFormField(&parser.Field{Name: "first_name", Type: parser.FieldTypeText, Label: "Nombre"})
+	if !strings.Contains(html, `<label for="first_name">Nombre`) {
+		t.Errorf("Expected form label override, got: %s", html)
+	}
+}
@@ -321,6 +321,38 @@ body {
     font-weight: 600;
 }
 
+.stat-count {
+    color: var(--gray-500);
+    font-weight: 400;
+}
+
+.widget-grid {
+    margin-bottom: 1.5rem;
+}
+
+.widget-value {
+    color: var(--gray-800);
+    font-size: 2rem;
+    font-weight: 700;
+}
+
+.recent-list {
+    list-style: none;
+    margin: 0 0 1rem;
+    padding: 0;
+    font-size: 0.875rem;
+}
+
+.recent-list li {
+    padding: 0.25rem 0;
+    border-bottom: 1px solid var(--gray-100);
+}
+
+.recent-list a {
+    color: var(--gray-700);
+    text-decoration: none;
+}
+
 .stat-actions {
     display: flex;
     gap: 0.75rem;
@@ -356,6 +388,29 @@ body {
     box-shadow: 0 0 0 3px rgba(102, 126, 234, 0.1);
 }
 
+.filter-bar {
+    display: flex;
+    flex-wrap: wrap;
+    gap: 1rem;
+    margin-bottom: 1rem;
+}
+
+.filter-control {
+    display: flex;
+    align-items: center;
+    gap: 0.5rem;
+    font-size: 0.875rem;
+    color: var(--gray-700);
+}
+
+.filter-control .form-control {
+    width: auto;
+}
+
+.filter-range .form-control {
+    max-width: 8rem;
+}
+
 /* Table */
 .data-table-container {
     overflow-x: auto;
@@ -380,6 +435,19 @@ body {
     letter-spacing: 0.5px;
 }
 
+.data-table th.sortable {
+    cursor: pointer;
+    user-select: none;
+}
+
+.data-table th.sortable:hover {
+    color: var(--gray-900);
+}
+
+.sort-indicator {
+    margin-left: 0.25rem;
+}
+
 .data-table td {
     padding: 1rem;
     border-bottom: 1px solid var(--gray-100);
@@ -422,6 +490,26 @@ body {
     margin-bottom: 1.5rem;
 }
 
+.form-section {
+    border: 1px solid var(--gray-200);
+    border-radius: 0.5rem;
+    padding: 1.25rem 1.5rem 0.25rem;
+    margin: 0 0 1.5rem;
+}
+
+.form-section legend {
+    padding: 0 0.5rem;
+    font-weight: 600;
+    color: var(--gray-700);
+}
+
+.form-help {
+    display: block;
+    margin-top: 0.375rem;
+    color: var(--gray-500);
+    font-size: 0.8125rem;
+}
+
 .form-group label {
     display: block;
     margin-bottom: 0.5rem;
@@ -649,6 +737,51 @@ select.form-control {
     border-left: 4px solid var(--success-color);
 }
 
+.toast-container {
+    position: fixed;
+    right: 1.5rem;
+    bottom: 1.5rem;
+    display: flex;
+    flex-direction: column;
+    gap: 0.5rem;
+    z-index: 1000;
+}
+
+.toast {
+    display: flex;
+    align-items: center;
+    gap: 1rem;
+    min-width: 240px;
+    max-width: 400px;
+    padding: 0.75rem 1rem;
+    border-radius: var(--radius);
+    background: white;
+    box-shadow: 0 10px 25px rgba(0, 0, 0, 0.15);
+    animation: fadeIn 0.2s ease-out;
+}
+
+.toast span {
+    flex: 1;
+}
+
+.toast-success {
+    border-left: 4px solid var(--success-color);
+}
+
+.toast-error {
+    border-left: 4px solid var(--danger-color);
+    color: var(--danger-color);
+}
+
+.toast-close {
+    background: none;
+    border: none;
+    font-size: 1.25rem;
+    line-height: 1;
+    cursor: pointer;
+    color: inherit;
+}
+
 /* Animations */
 @keyframes fadeIn {
     from {
@@ -720,15 +853,31 @@ select.form-control {
 func getJS() string {
 	return `const API_BASE = '/api';
 
+// Echo the csrf_token cookie on writes so cookie sessions pass the
+// server's double-submit check.
+function csrfToken() {
+    const match = document.cookie.match(/(?:^|;\s*)csrf_token=([^;]*)/);
+    return match ? decodeURIComponent(match[1]) : '';
+}
+
+const nativeFetch = window.fetch.bind(window);
+window.fetch = (input, init = {}) => {
+    const method = (init.method || 'GET').toUpperCase();
+    const token = csrfToken();
+    if (token && !['GET', 'HEAD', 'OPTIONS'].includes(method)) {
+        init = { ...init, headers: new Headers(init.headers || {}) };
+        init.headers.set('X-CSRF-Token', token);
+    }
+    return nativeFetch(input, init);
+};
+
 let currentPage = 1;
 let currentSearch = '';
 let currentSort = [];
+let showTrash = false;
 
-async function loadList(modelName, columns, searchable, sortable, modelInfo) {
-    const params = new URLSearchParams({
-        page: currentPage,
-        page_size: 20
-    });
+function listQueryParams() {
+    const params = new URLSearchParams();
 
     if (currentSearch) {
         params.append('search', currentSearch);
@@ -738,11 +887,38 @@ async function loadList(modelName, columns, searchable, sortable, modelInfo) {
         params.append('sort', currentSort.join(','));
     }
 
+    if (showTrash) {
+        params.append('trashed', 'true');
+    }
+
+    document.querySelectorAll('[data-filter]').forEach(control => {
+        if (control.value !== '') {
+            const value = control.hasAttribute('data-day-end') ? nextDay(control.value) : control.value;
+            params.append(` + "`filter.${control.getAttribute('data-filter')}__${control.getAttribute('data-op')}`" + `, value);
+        }
+    });
+
+    return params;
+}
+
+// nextDay turns a YYYY-MM-DD date into the following day.
+function nextDay(value) {
+    const date = new Date(value + 'T00:00:00Z');
+    date.setUTCDate(date.getUTCDate() + 1);
+    return date.toISOString().slice(0, 10);
+}
+
+async function loadList(modelName, columns, searchable, sortable, modelInfo) {
+    const params = listQueryParams();
+    params.set('page', currentPage);
+    params.set('page_size', 20);
+
     try {
         const response = await fetch(` + "`${API_BASE}/${modelName}?${params}`" + `);
         const data = await response.json();
 
         if (data.success) {
+            await loadRelationLabels(data.data, columns, modelInfo || window.modelInfo);
             renderTable(data.data, columns, modelName, modelInfo || window.modelInfo);
             renderPagination(data.meta);
         } else {
@@ -754,13 +930,80 @@ async function loadList(modelName, columns, searchable, sortable, modelInfo) {
 }
 
 
+const relationLabels = {};
+
+async function loadRelationLabels(records, columns, modelInfo) {
+    if (!modelInfo || !modelInfo.fields) return;
+
+    for (const col of columns) {
+        const fieldInfo = modelInfo.fields[col];
+        if (!fieldInfo || !fieldInfo.relation) continue;
+
+        const labels = relationLabels[col] || (relationLabels[col] = {});
+        const ids = [...new Set(records
+            .map(record => record[col])
+            .filter(id => id !== null && id !== undefined && labels[String(id)] === undefined)
+            .map(String))];
+        if (ids.length === 0) continue;
+
+        try {
+            const response = await fetch(` + "`${API_BASE}/${fieldInfo.relation.model}?ids=${encodeURIComponent(ids.join(','))}`" + `);
+            const data = await response.json();
+            if (data.success) {
+                data.data.forEach(related => {
+                    labels[String(related.id)] = relationLabel(related, fieldInfo.relation);
+                });
+            }
+        } catch (error) {
+            // Fall back to raw ids when the related model can't be read.
+        }
+    }
+}
+
+// Renders the related model's display_name; only {{.field}} placeholders
+// are understood in the browser.
+function relationLabel(record, relation) {
+    if (relation.template) {
+        const label = relation.template.replace(/\{\{\s*\.(\w+)\s*\}\}/g, (match, key) => {
+            const value = record[key];
+            return value === null || value === undefined ? '' : String(value);
+        });
+        if (label.trim()) return label;
+    }
+    return record[relation.display] || record.id;
+}
+
+// Lines the mask up with the end of the value: '#' shows a character and
+// '*' hides it, and the mask's first symbol covers any longer prefix.
+function maskValue(value, mask) {
+    const text = String(value);
+    let masked = '';
+    for (let i = 0; i < text.length; i++) {
+        const j = mask.length - (text.length - i);
+        masked += (j >= 0 ? mask[j] : mask[0]) === '#' ? text[i] : '*';
+    }
+    return masked;
+}
+
+
 function formatFieldValue(fieldName, value, modelInfo) {
     if (modelInfo && modelInfo.fields && modelInfo.fields[fieldName]) {
         const fieldInfo = modelInfo.fields[fieldName];
         
+        if (fieldInfo.relation && value !== null && value !== undefined) {
+            const labels = relationLabels[fieldName];
+            if (labels && labels[String(value)] !== undefined) {
+                return labels[String(value)];
+            }
+        }
+        
         if (fieldInfo.type === 'password') {
             return '********';
         }
+
+        if (fieldInfo.mask && value !== null && value !== undefined && value !== '') {
+            return maskValue(value, fieldInfo.mask);
+        }
         
         if (fieldInfo.options && fieldInfo.options[value] !== undefined) {
             return fieldInfo.options[value];
@@ -802,10 +1045,17 @@ function renderTable(data, columns, modelName, modelInfo) {
 
         const actionsCell = document.createElement('td');
         actionsCell.className = 'actions';
-        let actionsHTML = ` + "`" + `<a href="/${modelName}/${record.id}" class="btn btn-sm btn-secondary">View</a>` + "`" + `;
+        // Ids may be strings (server.string_ids), so they're encoded for URLs
+        // rather than assumed numeric.
+        const recordPath = encodeURIComponent(String(record.id));
+        let actionsHTML = ` + "`" + `<a href="/${modelName}/${recordPath}" class="btn btn-sm btn-secondary">View</a>` + "`" + `;
         
-        if (typeof canWrite !== 'undefined' && canWrite) {
-            actionsHTML += ` + "`" + ` <a href="/${modelName}/${record.id}/edit" class="btn btn-sm btn-primary">Edit</a>` + "`" + `;
+        if (showTrash) {
+            actionsHTML = (typeof canWrite !== 'undefined' && canWrite)
+                ? ` + "`" + `<button onclick="restoreRecord('${modelName}', '${record.id}')" class="btn btn-sm btn-primary">Restore</button>` + "`" + `
+                : '';
+        } else if (typeof canWrite !== 'undefined' && canWrite) {
+            actionsHTML += ` + "`" + ` <a href="/${modelName}/${recordPath}/edit" class="btn btn-sm btn-primary">Edit</a>` + "`" + `;
             actionsHTML += ` + "`" + ` <button onclick="deleteRecord('${modelName}', '${record.id}')" class="btn btn-sm btn-danger">Delete</button>` + "`" + `;
         }
         
@@ -864,6 +1114,19 @@ function renderPagination(meta) {
 }
 
 
+function flattenRecord(record, prefix = '') {
+    const values = {};
+    Object.keys(record).forEach(key => {
+        const value = record[key];
+        if (value && typeof value === 'object' && !Array.isArray(value)) {
+            Object.assign(values, flattenRecord(value, prefix + key + '.'));
+        } else {
+            values[prefix + key] = value;
+        }
+    });
+    return values;
+}
+
 async function handleForm(modelName, action, recordId) {
     const form = document.getElementById('modelForm');
 
@@ -896,6 +1159,18 @@ async function handleForm(modelName, action, recordId) {
             }
         }
 
+        for (const key of Object.keys(data)) {
+            if (!key.includes('.')) continue;
+            const path = key.split('.');
+            let target = data;
+            for (const part of path.slice(0, -1)) {
+                target[part] = target[part] || {};
+                target = target[part];
+            }
+            target[path[path.length - 1]] = data[key];
+            delete data[key];
+        }
+
         try {
             let response;
             if (action === 'create') {
@@ -905,7 +1180,7 @@ async function handleForm(modelName, action, recordId) {
                     body: JSON.stringify(data)
                 });
             } else if (action === 'edit' && recordId) {
-                response = await fetch(` + "`${API_BASE}/${modelName}/${recordId}`" + `, {
+                response = await fetch(` + "`${API_BASE}/${modelName}/${encodeURIComponent(recordId)}`" + `, {
                     method: 'PUT',
                     headers: {'Content-Type': 'application/json'},
                     body: JSON.stringify(data)
@@ -918,7 +1193,7 @@ async function handleForm(modelName, action, recordId) {
             const result = await response.json();
 
             if (result.success) {
-                window.location.href = ` + "`/${modelName}`" + `;
+                afterSave(modelName, action, result.data, form);
             } else {
                 showError(result.error);
             }
@@ -928,21 +1203,163 @@ async function handleForm(modelName, action, recordId) {
     });
 }
 
+function afterSave(modelName, action, record, form) {
+    const onSave = typeof formOnSave !== 'undefined' && formOnSave ? formOnSave : 'list';
+    const message = typeof formSuccessMessage !== 'undefined' && formSuccessMessage ? formSuccessMessage : uiText('saved');
+    const id = record && record.id !== undefined ? encodeURIComponent(record.id) : null;
+
+    if (onSave === 'new' && action === 'create') {
+        form.reset();
+        applyFormDefaults();
+        showToast(message);
+        return;
+    }
+    if (onSave === 'stay' && !(action === 'create' && id !== null)) {
+        showToast(message);
+        return;
+    }
+
+    queueToast(message);
+    if (onSave === 'view' && id !== null) {
+        window.location.href = ` + "`/${modelName}/${id}`" + `;
+    } else if (onSave === 'new') {
+        window.location.href = ` + "`/${modelName}/new`" + `;
+    } else if (onSave === 'stay') {
+        window.location.href = ` + "`/${modelName}/${id}/edit`" + `;
+    } else {
+        window.location.href = ` + "`/${modelName}`" + `;
+    }
+}
+
+function applyFormDefaults() {
+    document.querySelectorAll('[data-default]').forEach(elem => {
+        const defaultValue = elem.getAttribute('data-default');
+        if (defaultValue !== null && defaultValue !== '') {
+            if (elem.type === 'checkbox') {
+                elem.checked = (defaultValue === 'true' || defaultValue === '1');
+            } else if (elem.type === 'number') {
+                elem.value = parseFloat(defaultValue) || 0;
+            } else {
+                elem.value = defaultValue;
+            }
+        }
+    });
+}
+
+// Relation fields can be preset from the query string, e.g.
+// /post/new?author_id=5 when adding a post from its author's page;
+// fields named in lock= become read-only.
+function applyRelationPrefill(modelInfo) {
+    const params = new URLSearchParams(window.location.search);
+    const locked = (params.get('lock') || '').split(',');
+    Object.keys(modelInfo.fields).forEach(name => {
+        const elem = document.getElementById(name);
+        if (!elem || !modelInfo.fields[name].relation || !params.has(name)) return;
+        elem.value = params.get(name);
+        if (locked.includes(name)) {
+            elem.readOnly = true;
+        }
+    });
+}
+
+function showToast(message, type = 'success') {
+    let container = document.getElementById('toastContainer');
+    if (!container) {
+        container = document.createElement('div');
+        container.id = 'toastContainer';
+        container.className = 'toast-container';
+        container.setAttribute('aria-live', 'polite');
+        document.body.appendChild(container);
+    }
+
+    const toast = document.createElement('div');
+    toast.className = 'toast toast-' + type;
+    toast.setAttribute('role', type === 'error' ? 'alert' : 'status');
+
+    const text = document.createElement('span');
+    text.textContent = message;
+
+    const close = document.createElement('button');
+    close.type = 'button';
+    close.className = 'toast-close';
+    close.setAttribute('aria-label', 'Dismiss');
+    close.textContent = '\u00D7';
+    close.addEventListener('click', () => toast.remove());
+
+    toast.append(text, close);
+    container.appendChild(toast);
+
+    // Errors stay until dismissed so they are not missed.
+    if (type !== 'error') {
+        setTimeout(() => toast.remove(), 4000);
+    }
+}
+
+// Toasts queued before a navigation are shown on the next page.
+function queueToast(message, type = 'success') {
+    try {
+        sessionStorage.setItem('pendingToast', JSON.stringify({message, type}));
+    } catch (error) {
+        // storage may be unavailable (private mode); the toast is skipped
+    }
+}
+
+function showQueuedToast() {
+    try {
+        const pending = sessionStorage.getItem('pendingToast');
+        if (pending) {
+            sessionStorage.removeItem('pendingToast');
+            const toast = JSON.parse(pending);
+            showToast(toast.message, toast.type);
+        }
+    } catch (error) {
+        // ignore malformed or unavailable storage
+    }
+}
+
+
+async function deletePreviewMessage(modelName, recordId) {
+    try {
+        const response = await fetch(` + "`${API_BASE}/${modelName}/${encodeURIComponent(recordId)}/delete-preview`" + `);
+        const result = await response.json();
+        if (!result.success) {
+            return '';
+        }
+
+        const lines = [];
+        result.data.forEach(effect => {
+            const noun = effect.count === 1 ? effect.model : effect.model + 's';
+            if (effect.action === 'cascade') {
+                lines.push(` + "`This will also delete ${effect.count} ${noun}.`" + `);
+            } else if (effect.action === 'set_null') {
+                lines.push(` + "`${effect.count} ${noun} will be unlinked.`" + `);
+            } else if (effect.action === 'restrict') {
+                lines.push(` + "`${effect.count} ${noun} reference this record and will block the delete.`" + `);
+            }
+        });
+        return lines.join('\n');
+    } catch (error) {
+        return '';
+    }
+}
 
 async function deleteRecord(modelName, recordId) {
-    if (!confirm('Are you sure you want to delete this record?')) {
+    const preview = await deletePreviewMessage(modelName, recordId);
+    const message = 'Are you sure you want to delete this record?' + (preview ? '\n\n' + preview : '');
+    if (!confirm(message)) {
         return;
     }
 
     try {
-        const response = await fetch(` + "`${API_BASE}/${modelName}/${recordId}`" + `, {
+        const response = await fetch(` + "`${API_BASE}/${modelName}/${encodeURIComponent(recordId)}`" + `, {
             method: 'DELETE'
         });
 
         const result = await response.json();
 
         if (result.success) {
-            if (window.location.pathname.includes('/' + recordId)) {
+            queueToast(uiText('deleted'));
+            if (window.location.pathname.includes('/' + encodeURIComponent(recordId))) {
                 window.location.href = ` + "`/${modelName}`" + `;
             } else {
                 window.location.reload();
@@ -956,12 +1373,103 @@ async function deleteRecord(modelName, recordId) {
 }
 
 
+function exportList() {
+    const params = listQueryParams();
+    params.set('format', 'csv');
+    params.set('fields', columns.join(','));
+    window.location.href = ` + "`${API_BASE}/${modelName}/export?${params}`" + `;
+}
+
+// uiText looks a string up in the page's uiMessages table, which the
+// templates fill in for the configured locale.
+function uiText(key) {
+    return typeof uiMessages !== 'undefined' && uiMessages[key] ? uiMessages[key] : key;
+}
+
+function toggleTrash() {
+    showTrash = !showTrash;
+    currentPage = 1;
+
+    const toggle = document.getElementById('trashToggle');
+    if (toggle) {
+        toggle.textContent = showTrash ? uiText('back_to_list') : uiText('trash');
+    }
+
+    loadList(modelName, columns, searchable, sortable, window.modelInfo);
+}
+
+async function restoreRecord(modelName, recordId) {
+    try {
+        const response = await fetch(` + "`${API_BASE}/${modelName}/${encodeURIComponent(recordId)}/restore`" + `, {
+            method: 'POST'
+        });
+
+        const result = await response.json();
+
+        if (result.success) {
+            loadList(modelName, columns, searchable, sortable, window.modelInfo);
+        } else {
+            showError(result.error);
+        }
+    } catch (error) {
+        showError('Failed to restore record');
+    }
+}
+
+
+function toggleSort(field) {
+    if (currentSort[0] === field) {
+        currentSort = ['-' + field];
+    } else {
+        currentSort = [field];
+    }
+    currentPage = 1;
+    updateSortIndicators();
+
+    loadList(modelName, columns, searchable, sortable, window.modelInfo);
+}
+
+function updateSortIndicators() {
+    document.querySelectorAll('th[data-sort]').forEach(th => {
+        const field = th.getAttribute('data-sort');
+        const indicator = th.querySelector('.sort-indicator');
+        if (!indicator) return;
+
+        if (currentSort[0] === field) {
+            indicator.textContent = '\u25B2';
+            th.setAttribute('aria-sort', 'ascending');
+        } else if (currentSort[0] === '-' + field) {
+            indicator.textContent = '\u25BC';
+            th.setAttribute('aria-sort', 'descending');
+        } else {
+            indicator.textContent = '';
+            th.removeAttribute('aria-sort');
+        }
+    });
+}
+
+
 function showError(message) {
-    alert('Error: ' + message);
+    showToast(message, 'error');
 }
 
 
 document.addEventListener('DOMContentLoaded', () => {
+    showQueuedToast();
+
+    document.querySelectorAll('th[data-sort]').forEach(th => {
+        th.addEventListener('click', () => toggleSort(th.getAttribute('data-sort')));
+    });
+
+    document.querySelectorAll('[data-filter]').forEach(control => {
+        control.addEventListener('change', () => {
+            currentPage = 1;
+            if (typeof modelName !== 'undefined' && typeof columns !== 'undefined') {
+                loadList(modelName, columns, searchable, sortable, window.modelInfo);
+            }
+        });
+    });
+
     const searchInput = document.getElementById('search');
     if (searchInput) {
         let searchTimeout;
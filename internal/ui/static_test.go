@@ -366,4 +366,37 @@ func TestJS_SearchFunctionality(t *testing.T) {
 			t.Errorf("Expected JavaScript to contain search functionality '%s'", rule)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestJS_RelationLabels(t *testing.T) {
+	js := getJS()
+
+	if !strings.Contains(js, "async function loadRelationLabels") {
+		t.Error("Expected JS to define loadRelationLabels")
+	}
+	if !strings.Contains(js, "?ids=") {
+		t.Error("Expected relation labels to be batched through the ids parameter")
+	}
+}
+
+func TestJS_TrashAndRestore(t *testing.T) {
+	js := getJS()
+
+	if !strings.Contains(js, "params.append('trashed', 'true')") {
+		t.Error("Expected trash mode to request trashed records")
+	}
+	if !strings.Contains(js, "/restore`") {
+		t.Error("Expected JS to call the restore endpoint")
+	}
+}
+
+func TestJS_DeletePreview(t *testing.T) {
+	js := getJS()
+
+	if !strings.Contains(js, "/delete-preview`") {
+		t.Error("Expected deleteRecord to fetch the delete preview")
+	}
+	if !strings.Contains(js, "This will also delete ${effect.count} ${noun}.") {
+		t.Error("Expected cascade count in the confirmation message")
+	}
+}
@@ -3,6 +3,11 @@ package ui
 import (
 	"encoding/json"
 	"fmt"
+	"html"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"github.com/yamlforge/yamlforge/internal/parser"
 )
@@ -33,14 +38,40 @@ func formatFieldName(fieldName string) string {
 	return strings.ToUpper(string(fieldName[0])) + fieldName[1:]
 }
 
-func GetHomeHTML(config *parser.Config, schema *parser.Schema, modelPermissions map[string]bool) string {
+func modelPath(modelName string) string {
+	return html.EscapeString(url.PathEscape(strings.ToLower(modelName)))
+}
+
+func escapeJS(s string) string {
+	b, _ := json.Marshal(s)
+	return strings.ReplaceAll(string(b[1:len(b)-1]), "'", `\u0027`)
+}
+
+func GetHomeHTML(config *parser.Config, schema *parser.Schema, modelPermissions map[string]bool, modelCounts map[string]int64, recentRecords map[string][]map[string]any, widgetValues map[int]float64) string {
+	locale := config.UI.Locale
 	modelsMenu := ""
 	for modelName := range schema.Models {
-		modelsMenu += fmt.Sprintf(`<li><a href="/%s">%s</a></li>`, strings.ToLower(modelName), modelName)
+		modelsMenu += fmt.Sprintf(`<li><a href="/%s">%s</a></li>`, modelPath(modelName), html.EscapeString(modelName))
 	}
 	
 	if config.Server.Auth.Type != "none" {
-		modelsMenu += `<li style="margin-top: auto;"><a href="/logout" style="color: #e53e3e;">Logout</a></li>`
+		modelsMenu += fmt.Sprintf(`<li style="margin-top: auto;"><a href="/logout" style="color: #e53e3e;">%s</a></li>`, translate(locale, "logout"))
+	}
+
+	widgetCards := ""
+	for i, widget := range config.UI.Dashboard.Widgets {
+		value, ok := widgetValues[i]
+		if !ok {
+			continue
+		}
+		widgetCards += fmt.Sprintf(`
+		<div class="stat-card widget-card">
+			<h3>%s</h3>
+			<div class="widget-value">%s</div>
+		</div>`, html.EscapeString(widget.Title), formatWidgetValue(value))
+	}
+	if widgetCards != "" {
+		widgetCards = fmt.Sprintf(`<div class="stats-grid widget-grid">%s</div>`, widgetCards)
 	}
 
 	modelCards := ""
@@ -54,25 +85,49 @@ func GetHomeHTML(config *parser.Config, schema *parser.Schema, modelPermissions
 		
 		addNewButton := ""
 		if canWrite {
-			addNewButton = fmt.Sprintf(`<a href="/%s/new" class="btn btn-secondary">Add New</a>`, strings.ToLower(modelName))
+			addNewButton = fmt.Sprintf(`<a href="/%s/new" class="btn btn-secondary">%s</a>`, modelPath(modelName), translate(locale, "add_new"))
+		}
+		
+		title := html.EscapeString(modelName)
+		if count, ok := modelCounts[modelName]; ok {
+			title = fmt.Sprintf(`%s <span class="stat-count">(%d)</span>`, html.EscapeString(modelName), count)
+		}
+
+		recentList := ""
+		if records := recentRecords[modelName]; len(records) > 0 {
+			model := schema.Models[modelName]
+			displayField := model.DefaultDisplayField()
+			items := ""
+			for _, record := range records {
+				label := fmt.Sprintf("%v", record[displayField])
+				switch {
+				case model.DisplayName != "":
+					label = model.RecordTitle(record)
+				case record[displayField] == nil:
+					label = fmt.Sprintf("#%v", record["id"])
+				}
+				items += fmt.Sprintf(`<li><a href="/%s/%s">%s</a></li>`, modelPath(modelName), html.EscapeString(url.PathEscape(fmt.Sprint(record["id"]))), html.EscapeString(label))
+			}
+			recentList = fmt.Sprintf(`<ul class="recent-list">%s</ul>`, items)
 		}
 		
 		modelCards += fmt.Sprintf(`
 		<div class="stat-card">
 			<h3>%s</h3>
+			%s
 			<div class="stat-actions">
-				<a href="/%s" class="btn btn-primary">View All</a>
+				<a href="/%s" class="btn btn-primary">%s</a>
 				%s
 			</div>
-		</div>`, modelName, strings.ToLower(modelName), addNewButton)
+		</div>`, title, recentList, modelPath(modelName), translate(locale, "view_all"), addNewButton)
 	}
 
 	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="en">
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>%s - Dashboard</title>
+    <title>%s - %s</title>
     <style>%s</style>
 </head>
 <body>
@@ -82,15 +137,16 @@ func GetHomeHTML(config *parser.Config, schema *parser.Schema, modelPermissions
                 <h1>%s</h1>
             </div>
             <ul class="nav-menu">
-                <li><a href="/">Dashboard</a></li>
+                <li><a href="/">%s</a></li>
                 %s
             </ul>
         </nav>
         <main class="main-content">
             <div class="page-header">
-                <h2>Dashboard</h2>
+                <h2>%s</h2>
             </div>
             <div class="dashboard">
+                %s
                 <div class="stats-grid">
                     %s
                 </div>
@@ -99,33 +155,48 @@ func GetHomeHTML(config *parser.Config, schema *parser.Schema, modelPermissions
     </div>
     <script>%s</script>
 </body>
-</html>`, config.App.Name, getCSS(), config.App.Name, modelsMenu, modelCards, getJS())
+</html>`, htmlLang(locale), html.EscapeString(config.App.Name), translate(locale, "dashboard"), getCSS(), html.EscapeString(config.App.Name),
+		translate(locale, "dashboard"), modelsMenu, translate(locale, "dashboard"), widgetCards, modelCards, getJS()+jsMessages(locale))
+}
+
+func formatWidgetValue(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
 }
 
 func GetListHTML(config *parser.Config, schema *parser.Schema, modelName string, model *parser.Model, canWrite bool) string {
+	locale := config.UI.Locale
 	modelsMenu := ""
 	for mName := range schema.Models {
 		activeClass := ""
 		if strings.ToLower(mName) == strings.ToLower(modelName) {
 			activeClass = ` class="active"`
 		}
-		modelsMenu += fmt.Sprintf(`<li><a href="/%s"%s>%s</a></li>`, strings.ToLower(mName), activeClass, mName)
+		modelsMenu += fmt.Sprintf(`<li><a href="/%s"%s>%s</a></li>`, modelPath(mName), activeClass, html.EscapeString(mName))
 	}
 	
 	if config.Server.Auth.Type != "none" {
-		modelsMenu += `<li style="margin-top: auto;"><a href="/logout" style="color: #e53e3e;">Logout</a></li>`
+		modelsMenu += fmt.Sprintf(`<li style="margin-top: auto;"><a href="/logout" style="color: #e53e3e;">%s</a></li>`, translate(locale, "logout"))
 	}
 
 	addNewButton := ""
 	if canWrite {
-		addNewButton = fmt.Sprintf(`<a href="/%s/new" class="btn btn-primary">Add New</a>`, strings.ToLower(modelName))
+		addNewButton = fmt.Sprintf(`<a href="/%s/new" class="btn btn-primary">%s</a>`, modelPath(modelName), translate(locale, "add_new"))
+	}
+	addNewButton = fmt.Sprintf(`<button type="button" id="exportList" class="btn btn-secondary" onclick="exportList()">%s</button> `, translate(locale, "export_csv")) + addNewButton
+	if model.SoftDelete {
+		addNewButton = fmt.Sprintf(`<button type="button" id="trashToggle" class="btn btn-secondary" onclick="toggleTrash()">%s</button> `, translate(locale, "trash")) + addNewButton
 	}
 	
 	columnHeaders := ""
-	columns := model.UI.List.Columns
-	if len(columns) == 0 {
+	var columns []string
+	for _, col := range model.UI.List.Columns {
+		if field := findField(model, col); field == nil || !field.HideInList {
+			columns = append(columns, col)
+		}
+	}
+	if len(model.UI.List.Columns) == 0 {
 		for _, field := range model.Fields {
-			if !field.AutoNow && !field.AutoNowAdd && field.Name != "id" {
+			if !field.AutoNow && !field.AutoNowAdd && field.Name != "id" && field.Name != parser.SoftDeleteField && !field.HideInList {
 				columns = append(columns, field.Name)
 				if len(columns) >= 4 {
 					break
@@ -135,7 +206,19 @@ func GetListHTML(config *parser.Config, schema *parser.Schema, modelName string,
 	}
 
 	for _, col := range columns {
-		columnHeaders += fmt.Sprintf("<th>%s</th>", formatFieldName(col))
+		label := formatFieldName(col)
+		for _, field := range model.Fields {
+			if field.Name == col {
+				label = fieldLabel(field)
+				break
+			}
+		}
+		if isSortable(model, col) {
+			columnHeaders += fmt.Sprintf(`<th class="sortable" data-sort="%s">%s<span class="sort-indicator"></span></th>`,
+				html.EscapeString(col), html.EscapeString(label))
+		} else {
+			columnHeaders += fmt.Sprintf("<th>%s</th>", html.EscapeString(label))
+		}
 	}
 
 	columnsJSON, _ := json.Marshal(columns)
@@ -145,7 +228,7 @@ func GetListHTML(config *parser.Config, schema *parser.Schema, modelName string,
 	modelInfo := buildModelInfoJSON(model)
 
 	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="en">
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -159,7 +242,7 @@ func GetListHTML(config *parser.Config, schema *parser.Schema, modelName string,
                 <h1>%s</h1>
             </div>
             <ul class="nav-menu">
-                <li><a href="/">Dashboard</a></li>
+                <li><a href="/">%s</a></li>
                 %s
             </ul>
         </nav>
@@ -172,18 +255,19 @@ func GetListHTML(config *parser.Config, schema *parser.Schema, modelName string,
             </div>
             <div class="list-container">
                 <div class="search-bar">
-                    <input type="text" id="search" placeholder="Search..." class="form-control">
+                    <input type="text" id="search" placeholder="%s" class="form-control">
                 </div>
+                %s
                 <div class="data-table-container">
                     <table class="data-table" id="dataTable">
                         <thead>
                             <tr>
                                 %s
-                                <th>Actions</th>
+                                <th>%s</th>
                             </tr>
                         </thead>
                         <tbody id="tableBody">
-                            <tr><td colspan="%d" class="loading">Loading...</td></tr>
+                            <tr><td colspan="%d" class="loading">%s</td></tr>
                         </tbody>
                     </table>
                 </div>
@@ -198,27 +282,79 @@ func GetListHTML(config *parser.Config, schema *parser.Schema, modelName string,
     const sortable = %s;
     const modelInfo = %s;
     const canWrite = %t;
+    window.modelInfo = modelInfo;
 
     document.addEventListener('DOMContentLoaded', () => {
-        loadList(modelName, columns, searchable, sortable);
+        loadList(modelName, columns, searchable, sortable, modelInfo);
     });
     </script>
 </body>
-</html>`, modelName, config.App.Name, getCSS(), config.App.Name, modelsMenu, modelName, 
-		addNewButton, columnHeaders, len(columns)+1, getJS(), 
-		strings.ToLower(modelName), string(columnsJSON), string(searchableJSON), 
+</html>`, htmlLang(locale), html.EscapeString(modelName), html.EscapeString(config.App.Name), getCSS(), html.EscapeString(config.App.Name),
+		translate(locale, "dashboard"), modelsMenu, html.EscapeString(modelName), addNewButton, translate(locale, "search"), generateFilterControls(model, locale),
+		columnHeaders, translate(locale, "actions"), len(columns)+1, translate(locale, "loading"), getJS()+jsMessages(locale), 
+		escapeJS(strings.ToLower(modelName)), string(columnsJSON), string(searchableJSON), 
 		string(sortableJSON), modelInfo, canWrite)
 }
 
+func generateFilterControls(model *parser.Model, locale string) string {
+	controls := ""
+	for _, name := range model.UI.List.Filterable {
+		var field *parser.Field
+		for i := range model.Fields {
+			if model.Fields[i].Name == name {
+				field = &model.Fields[i]
+				break
+			}
+		}
+		if field == nil {
+			continue
+		}
+
+		label := html.EscapeString(fieldLabel(*field))
+		fieldName := html.EscapeString(field.Name)
+		switch field.Type {
+		case parser.FieldTypeEnum, parser.FieldTypeBoolean:
+			options := fmt.Sprintf(`<option value="">%s</option>`, translate(locale, "all"))
+			if field.Type == parser.FieldTypeBoolean {
+				options += fmt.Sprintf(`<option value="1">%s</option><option value="0">%s</option>`, translate(locale, "yes"), translate(locale, "no"))
+			}
+			for _, option := range field.Options {
+				options += fmt.Sprintf(`<option value="%s">%s</option>`, html.EscapeString(option), html.EscapeString(option))
+			}
+			controls += fmt.Sprintf(`<label class="filter-control"><span>%s</span><select class="form-control" data-filter="%s" data-op="eq">%s</select></label>`,
+				label, fieldName, options)
+		case parser.FieldTypeNumber, parser.FieldTypeDate, parser.FieldTypeDatetime:
+			inputType := "number"
+			if field.Type != parser.FieldTypeNumber {
+				inputType = "date"
+			}
+			// A datetime on the max day is later than the bare date, so the
+			// max is sent as "before the next day" instead.
+			maxOp := `data-op="lte"`
+			if field.Type == parser.FieldTypeDatetime {
+				maxOp = `data-op="lt" data-day-end`
+			}
+			controls += fmt.Sprintf(`<div class="filter-control filter-range"><span>%s</span><input type="%s" class="form-control" data-filter="%s" data-op="gte" placeholder="%s"><input type="%s" class="form-control" data-filter="%s" %s placeholder="%s"></div>`,
+				label, inputType, fieldName, translate(locale, "min"), inputType, fieldName, maxOp, translate(locale, "max"))
+		}
+	}
+
+	if controls == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<div class="filter-bar" id="filterBar">%s</div>`, controls)
+}
+
 func GetFormHTML(config *parser.Config, schema *parser.Schema, modelName string, model *parser.Model, action string, recordId string, recordJSON string) string {
+	locale := config.UI.Locale
 	isEdit := action == "edit"
-	pageTitle := fmt.Sprintf("%s %s", "New", modelName)
-	pageHeader := fmt.Sprintf("%s %s", "New", modelName)
-	submitText := "Create"
+	pageTitle := fmt.Sprintf("%s %s", translate(locale, "new"), html.EscapeString(modelName))
+	pageHeader := pageTitle
+	submitText := translate(locale, "create")
 	if isEdit {
-		pageTitle = fmt.Sprintf("Edit %s %s", modelName, recordId)
-		pageHeader = fmt.Sprintf("Edit %s %s", modelName, recordId)
-		submitText = "Update"
+		pageTitle = fmt.Sprintf("%s %s %s", translate(locale, "edit"), html.EscapeString(modelName), html.EscapeString(recordId))
+		pageHeader = pageTitle
+		submitText = translate(locale, "update")
 	}
 
 	modelsMenu := ""
@@ -227,11 +363,11 @@ func GetFormHTML(config *parser.Config, schema *parser.Schema, modelName string,
 		if strings.ToLower(mName) == strings.ToLower(modelName) {
 			activeClass = ` class="active"`
 		}
-		modelsMenu += fmt.Sprintf(`<li><a href="/%s"%s>%s</a></li>`, strings.ToLower(mName), activeClass, mName)
+		modelsMenu += fmt.Sprintf(`<li><a href="/%s"%s>%s</a></li>`, modelPath(mName), activeClass, html.EscapeString(mName))
 	}
 	
 	if config.Server.Auth.Type != "none" {
-		modelsMenu += `<li style="margin-top: auto;"><a href="/logout" style="color: #e53e3e;">Logout</a></li>`
+		modelsMenu += fmt.Sprintf(`<li style="margin-top: auto;"><a href="/logout" style="color: #e53e3e;">%s</a></li>`, translate(locale, "logout"))
 	}
 
 	formFields := ""
@@ -244,25 +380,50 @@ func GetFormHTML(config *parser.Config, schema *parser.Schema, modelName string,
 		}
 	}
 
-	for _, fieldName := range formFieldNames {
-		var field *parser.Field
-		for i := range model.Fields {
-			if model.Fields[i].Name == fieldName {
-				field = &model.Fields[i]
-				break
+	renderFields := func(names []string) string {
+		out := ""
+		for _, fieldName := range names {
+			field := findField(model, fieldName)
+			if field == nil || field.HideInForm {
+				continue
 			}
+
+			out += generateFormField(field)
 		}
-		if field == nil {
-			continue
+		return out
+	}
+
+	if len(model.UI.Form.Sections) == 0 {
+		formFields = renderFields(formFieldNames)
+	} else {
+		sectioned := make(map[string]bool)
+		for _, section := range model.UI.Form.Sections {
+			for _, fieldName := range section.Fields {
+				sectioned[fieldName] = true
+			}
+			formFields += fmt.Sprintf(`<fieldset class="form-section"><legend>%s</legend>%s</fieldset>`,
+				html.EscapeString(section.Title), renderFields(section.Fields))
 		}
 
-		formFields += generateFormField(field)
+		var remaining []string
+		for _, fieldName := range formFieldNames {
+			if !sectioned[fieldName] {
+				remaining = append(remaining, fieldName)
+			}
+		}
+		if len(remaining) > 0 {
+			formFields += fmt.Sprintf(`<fieldset class="form-section form-section-default">%s</fieldset>`, renderFields(remaining))
+		}
 	}
 
 	modelInfo := buildModelInfoJSON(model)
+	successMessage := model.UI.Form.SuccessMessage
+	if successMessage == "" {
+		successMessage = translate(locale, "saved")
+	}
 
 	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="en">
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -276,7 +437,7 @@ func GetFormHTML(config *parser.Config, schema *parser.Schema, modelName string,
                 <h1>%s</h1>
             </div>
             <ul class="nav-menu">
-                <li><a href="/">Dashboard</a></li>
+                <li><a href="/">%s</a></li>
                 %s
             </ul>
         </nav>
@@ -289,7 +450,7 @@ func GetFormHTML(config *parser.Config, schema *parser.Schema, modelName string,
                     %s
                     <div class="form-actions">
                         <button type="submit" class="btn btn-primary">%s</button>
-                        <a href="/%s" class="btn btn-secondary">Cancel</a>
+                        <a href="/%s" class="btn btn-secondary">%s</a>
                     </div>
                 </form>
             </div>
@@ -301,85 +462,79 @@ func GetFormHTML(config *parser.Config, schema *parser.Schema, modelName string,
     const recordData = %s;
     const recordId = recordData ? recordData.id : null;
     const modelInfo = %s;
+    const formOnSave = '%s';
+    const formSuccessMessage = '%s';
 
     document.addEventListener('DOMContentLoaded', () => {
         if (action === 'edit' && recordData) {
-            Object.keys(recordData).forEach(key => {
+            const values = flattenRecord(recordData);
+            Object.keys(values).forEach(key => {
                 const elem = document.getElementById(key);
                 if (elem) {
                     if (elem.type === 'password') {
                         elem.placeholder = '(unchanged if empty)';
                     } else if (elem.type === 'checkbox') {
-                        elem.checked = recordData[key];
-                    } else if (elem.type === 'date' && recordData[key]) {
-                        const dateValue = recordData[key].includes('T')
-                            ? recordData[key].split('T')[0]
-                            : recordData[key];
+                        elem.checked = values[key];
+                    } else if (elem.type === 'date' && values[key]) {
+                        const dateValue = values[key].includes('T')
+                            ? values[key].split('T')[0]
+                            : values[key];
                         elem.value = dateValue;
                     } else {
-                        elem.value = recordData[key] || '';
+                        elem.value = values[key] || '';
                     }
                 }
             });
         } else if (action === 'create') {
-            const formElements = document.querySelectorAll('[data-default]');
-            formElements.forEach(elem => {
-                const defaultValue = elem.getAttribute('data-default');
-                if (defaultValue !== null && defaultValue !== '') {
-                    if (elem.type === 'checkbox') {
-                        elem.checked = (defaultValue === 'true' || defaultValue === '1');
-                    } else if (elem.type === 'number') {
-                        elem.value = parseFloat(defaultValue) || 0;
-                    } else if (elem.tagName === 'SELECT') {
-                        elem.value = defaultValue;
-                    } else if (elem.tagName === 'TEXTAREA') {
-                        elem.value = defaultValue;
-                    } else {
-                        elem.value = defaultValue;
-                    }
-                }
-            });
+            applyFormDefaults();
+            applyRelationPrefill(modelInfo);
         }
 
         handleForm(modelName, action, recordId);
     });
     </script>
 </body>
-</html>`, pageTitle, config.App.Name, getCSS(), config.App.Name, modelsMenu,
-		pageHeader, formFields, submitText, strings.ToLower(modelName),
-		getJS(), strings.ToLower(modelName), action, recordJSON, modelInfo)
+</html>`, htmlLang(locale), pageTitle, html.EscapeString(config.App.Name), getCSS(), html.EscapeString(config.App.Name),
+		translate(locale, "dashboard"), modelsMenu, pageHeader, formFields, submitText,
+		modelPath(modelName), translate(locale, "cancel"),
+		getJS()+jsMessages(locale), escapeJS(strings.ToLower(modelName)), escapeJS(action), recordJSON, modelInfo, escapeJS(model.UI.Form.OnSave), escapeJS(successMessage))
 }
 
-func GetViewHTML(config *parser.Config, schema *parser.Schema, modelName string, model *parser.Model, recordId string, recordJSON string) string {
+func GetViewHTML(config *parser.Config, schema *parser.Schema, modelName string, model *parser.Model, recordId string, recordTitle string, recordJSON string) string {
+	locale := config.UI.Locale
 	modelsMenu := ""
 	for mName := range schema.Models {
 		activeClass := ""
 		if strings.ToLower(mName) == strings.ToLower(modelName) {
 			activeClass = ` class="active"`
 		}
-		modelsMenu += fmt.Sprintf(`<li><a href="/%s"%s>%s</a></li>`, strings.ToLower(mName), activeClass, mName)
+		modelsMenu += fmt.Sprintf(`<li><a href="/%s"%s>%s</a></li>`, modelPath(mName), activeClass, html.EscapeString(mName))
 	}
 	
 	if config.Server.Auth.Type != "none" {
-		modelsMenu += `<li style="margin-top: auto;"><a href="/logout" style="color: #e53e3e;">Logout</a></li>`
+		modelsMenu += fmt.Sprintf(`<li style="margin-top: auto;"><a href="/logout" style="color: #e53e3e;">%s</a></li>`, translate(locale, "logout"))
 	}
 
 	modelInfo := buildModelInfoJSON(model)
 
 	fieldDisplayLogic := ""
 	for _, field := range model.Fields {
+		if field.HideInDetail {
+			continue
+		}
+		name := escapeJS(field.Name)
 		fieldDisplayLogic += fmt.Sprintf(`
-                    if (record.%s !== undefined && record.%s !== null && record.%s !== '') {
-                        html += '<div class="detail-row"><div class="detail-label">%s</div><div class="detail-value">' + escapeHtml(formatDetailValue('%s', record.%s, modelInfo)) + '</div></div>';
-                    }`, field.Name, field.Name, field.Name, formatFieldName(field.Name), field.Name, field.Name)
+                    if (record['%s'] !== undefined && record['%s'] !== null && record['%s'] !== '') {
+                        html += '<div class="detail-row"><div class="detail-label">%s</div><div class="detail-value">' + escapeHtml(formatDetailValue('%s', record['%s'], modelInfo)) + '</div></div>';
+                    }`, name, name, name, escapeJS(html.EscapeString(fieldLabel(field))), name, name)
 	}
 
 	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="en">
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>%s Details - %s</title>
+    <title>%s - %s</title>
     <style>%s</style>
 </head>
 <body>
@@ -389,22 +544,23 @@ func GetViewHTML(config *parser.Config, schema *parser.Schema, modelName string,
                 <h1>%s</h1>
             </div>
             <ul class="nav-menu">
-                <li><a href="/">Dashboard</a></li>
+                <li><a href="/">%s</a></li>
                 %s
             </ul>
         </nav>
         <main class="main-content">
             <div class="page-header">
-                <h2>%s Details</h2>
+                <h2>%s</h2>
                 <div class="page-actions">
-                    <a href="/%s/%s/edit" class="btn btn-primary">Edit</a>
-                    <button onclick="deleteRecord('%s', '%s')" class="btn btn-danger">Delete</button>
-                    <a href="/%s" class="btn btn-secondary">Back to List</a>
+                    %s
+                    <a href="/%s/%s/edit" class="btn btn-primary">%s</a>
+                    <button onclick="deleteRecord('%s', '%s')" class="btn btn-danger">%s</button>
+                    <a href="/%s" class="btn btn-secondary">%s</a>
                 </div>
             </div>
             <div class="detail-container">
                 <div class="detail-card" id="detailCard">
-                    <div class="loading">Loading...</div>
+                    <div class="loading">%s</div>
                 </div>
             </div>
         </main>
@@ -417,6 +573,10 @@ func GetViewHTML(config *parser.Config, schema *parser.Schema, modelName string,
         if (modelInfo.fields[fieldName] && modelInfo.fields[fieldName].type === 'password') {
             return '********';
         }
+
+        if (modelInfo.fields[fieldName] && modelInfo.fields[fieldName].mask) {
+            return maskValue(value, modelInfo.fields[fieldName].mask);
+        }
         
         if (modelInfo.fields[fieldName] && modelInfo.fields[fieldName].options) {
             const options = modelInfo.fields[fieldName].options;
@@ -458,12 +618,54 @@ func GetViewHTML(config *parser.Config, schema *parser.Schema, modelName string,
     }
     </script>
 </body>
-</html>`, modelName, config.App.Name, getCSS(), config.App.Name, modelsMenu, modelName,
-		strings.ToLower(modelName), recordId, strings.ToLower(modelName), recordId,
-		strings.ToLower(modelName), getJS(), recordId, modelInfo, recordJSON, fieldDisplayLogic)
+</html>`, htmlLang(locale), html.EscapeString(recordTitle), html.EscapeString(config.App.Name), getCSS(),
+		html.EscapeString(config.App.Name), translate(locale, "dashboard"), modelsMenu, html.EscapeString(recordTitle),
+		childLinks(schema, modelName, recordId, locale),
+		modelPath(modelName), html.EscapeString(url.PathEscape(recordId)), translate(locale, "edit"),
+		html.EscapeString(escapeJS(strings.ToLower(modelName))), html.EscapeString(escapeJS(recordId)), translate(locale, "delete"),
+		modelPath(modelName), translate(locale, "back_to_list"), translate(locale, "loading"),
+		getJS()+jsMessages(locale), escapeJS(recordId), modelInfo, recordJSON, fieldDisplayLogic)
+}
+
+// childLinks offers an "Add" button for every model with a relation to
+// modelName; the new form opens with that relation preset and locked.
+func childLinks(schema *parser.Schema, modelName, recordId, locale string) string {
+	if recordId == "" {
+		return ""
+	}
+
+	names := make([]string, 0, len(schema.Models))
+	for name := range schema.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	links := ""
+	for _, name := range names {
+		for _, field := range schema.Models[name].Fields {
+			if field.Type != parser.FieldTypeRelation || field.HideInForm || !strings.EqualFold(field.RelatedTo, modelName) {
+				continue
+			}
+			query := url.Values{field.Name: {recordId}, "lock": {field.Name}}
+			links += fmt.Sprintf(`<a href="/%s/new?%s" class="btn btn-secondary">%s %s</a>`,
+				modelPath(name), html.EscapeString(query.Encode()), translate(locale, "add_new"), html.EscapeString(name))
+		}
+	}
+	return links
 }
 
 func generateFormField(field *parser.Field) string {
+	name := html.EscapeString(field.Name)
+	label := html.EscapeString(fieldLabel(*field))
+	placeholder := ""
+	if field.Placeholder != "" {
+		placeholder = fmt.Sprintf(` placeholder="%s"`, html.EscapeString(field.Placeholder))
+	}
+	help := ""
+	if field.Help != "" {
+		help = fmt.Sprintf(`
+        <small class="form-help">%s</small>`, html.EscapeString(field.Help))
+	}
 	required := ""
 	if field.Required {
 		required = " required"
@@ -493,37 +695,37 @@ func generateFormField(field *parser.Field) string {
 		
 		defaultValue := ""
 		if field.Default != nil && field.Type != parser.FieldTypePassword {
-			defaultValue = fmt.Sprintf(` data-default="%v"`, field.Default)
+			defaultValue = fmt.Sprintf(` data-default="%s"`, html.EscapeString(fmt.Sprint(field.Default)))
 		}
 		
 		return fmt.Sprintf(`<div class="form-group">
         <label for="%s">%s%s</label>
-        <input type="%s" id="%s" name="%s" class="form-control"%s%s%s%s>
-    </div>`, field.Name, formatFieldName(field.Name), requiredStar(field.Required), inputType, field.Name, field.Name, required, minAttr, maxAttr, defaultValue)
+        <input type="%s" id="%s" name="%s" class="form-control"%s%s%s%s%s>%s
+    </div>`, name, label, requiredStar(field.Required), inputType, name, name, required, minAttr, maxAttr, defaultValue, placeholder, help)
 	
 	case parser.FieldTypeMarkdown, parser.FieldTypeJSON:
 		defaultValue := ""
 		if field.Default != nil {
-			defaultValue = fmt.Sprintf(` data-default="%v"`, field.Default)
+			defaultValue = fmt.Sprintf(` data-default="%s"`, html.EscapeString(fmt.Sprint(field.Default)))
 		}
 		
 		return fmt.Sprintf(`<div class="form-group">
         <label for="%s">%s%s</label>
-        <textarea id="%s" name="%s" class="form-control" rows="5"%s%s></textarea>
-    </div>`, field.Name, formatFieldName(field.Name), requiredStar(field.Required), field.Name, field.Name, required, defaultValue)
+        <textarea id="%s" name="%s" class="form-control" rows="5"%s%s%s></textarea>%s
+    </div>`, name, label, requiredStar(field.Required), name, name, required, defaultValue, placeholder, help)
 	
 	case parser.FieldTypeBoolean:
 		defaultValue := ""
 		if field.Default != nil {
-			defaultValue = fmt.Sprintf(` data-default="%v"`, field.Default)
+			defaultValue = fmt.Sprintf(` data-default="%s"`, html.EscapeString(fmt.Sprint(field.Default)))
 		}
 		
 		return fmt.Sprintf(`<div class="form-group">
         <label for="%s">
             <input type="checkbox" id="%s" name="%s"%s>
             %s
-        </label>
-    </div>`, field.Name, field.Name, field.Name, defaultValue, formatFieldName(field.Name))
+        </label>%s
+    </div>`, name, name, name, defaultValue, label, help)
 	
 	case parser.FieldTypeNumber:
 		minAttr := ""
@@ -536,32 +738,53 @@ func generateFormField(field *parser.Field) string {
 		}
 		defaultVal := ""
 		if field.Default != nil {
-			defaultVal = fmt.Sprintf(` data-default="%v"`, field.Default)
+			defaultVal = fmt.Sprintf(` data-default="%s"`, html.EscapeString(fmt.Sprint(field.Default)))
+		}
+		stepAttr := ""
+		if field.Decimal {
+			stepAttr = ` step="any"`
+			if field.Scale > 0 {
+				stepAttr = fmt.Sprintf(` step="%s"`, strconv.FormatFloat(math.Pow(10, -float64(field.Scale)), 'f', field.Scale, 64))
+			}
 		}
 		
 		return fmt.Sprintf(`<div class="form-group">
         <label for="%s">%s%s</label>
-        <input type="number" id="%s" name="%s" class="form-control"%s%s%s%s>
-    </div>`, field.Name, formatFieldName(field.Name), requiredStar(field.Required), field.Name, field.Name, required, minAttr, maxAttr, defaultVal)
+        <input type="number" id="%s" name="%s" class="form-control"%s%s%s%s%s%s>%s
+    </div>`, name, label, requiredStar(field.Required), name, name, required, minAttr, maxAttr, stepAttr, defaultVal, placeholder, help)
 	
 	case parser.FieldTypeEnum:
 		options := ""
 		defaultAttr := ""
 		for _, opt := range field.Options {
 			displayName := strings.ReplaceAll(strings.Title(strings.ReplaceAll(opt, "_", " ")), " ", " ")
-			options += fmt.Sprintf(`<option value="%s">%s</option>`, opt, displayName)
+			options += fmt.Sprintf(`<option value="%s">%s</option>`, html.EscapeString(opt), html.EscapeString(displayName))
 		}
 		
 		if field.Default != nil {
-			defaultAttr = fmt.Sprintf(` data-default="%v"`, field.Default)
+			defaultAttr = fmt.Sprintf(` data-default="%s"`, html.EscapeString(fmt.Sprint(field.Default)))
 		}
 		
 		return fmt.Sprintf(`<div class="form-group">
         <label for="%s">%s%s</label>
         <select id="%s" name="%s" class="form-control"%s%s>
             %s
-        </select>
-    </div>`, field.Name, formatFieldName(field.Name), requiredStar(field.Required), field.Name, field.Name, required, defaultAttr, options)
+        </select>%s
+    </div>`, name, label, requiredStar(field.Required), name, name, required, defaultAttr, options, help)
+	
+	case parser.FieldTypeObject:
+		inputs := ""
+		for _, property := range field.Properties {
+			nested := property
+			nested.Label = fieldLabel(property)
+			nested.Name = field.Name + "." + property.Name
+			inputs += generateFormField(&nested)
+		}
+
+		return fmt.Sprintf(`<fieldset class="form-section form-object" id="%s">
+        <legend>%s%s</legend>
+        %s%s
+    </fieldset>`, name, label, requiredStar(field.Required), inputs, help)
 	
 	case parser.FieldTypeDate, parser.FieldTypeDatetime, parser.FieldTypeTime:
 		inputType := "date"
@@ -573,15 +796,33 @@ func generateFormField(field *parser.Field) string {
 		
 		return fmt.Sprintf(`<div class="form-group">
         <label for="%s">%s%s</label>
-        <input type="%s" id="%s" name="%s" class="form-control"%s>
-    </div>`, field.Name, formatFieldName(field.Name), requiredStar(field.Required), inputType, field.Name, field.Name, required)
+        <input type="%s" id="%s" name="%s" class="form-control"%s%s>%s
+    </div>`, name, label, requiredStar(field.Required), inputType, name, name, required, placeholder, help)
 	
 	default:
 		return fmt.Sprintf(`<div class="form-group">
         <label for="%s">%s%s</label>
-        <input type="text" id="%s" name="%s" class="form-control"%s>
-    </div>`, field.Name, formatFieldName(field.Name), requiredStar(field.Required), field.Name, field.Name, required)
+        <input type="text" id="%s" name="%s" class="form-control"%s%s>%s
+    </div>`, name, label, requiredStar(field.Required), name, name, required, placeholder, help)
+	}
+}
+
+func findField(model *parser.Model, name string) *parser.Field {
+	for i := range model.Fields {
+		if model.Fields[i].Name == name {
+			return &model.Fields[i]
+		}
+	}
+	return nil
+}
+
+func isSortable(model *parser.Model, fieldName string) bool {
+	for _, name := range model.UI.List.Sortable {
+		if name == fieldName {
+			return true
+		}
 	}
+	return false
 }
 
 func requiredStar(required bool) string {
@@ -597,6 +838,21 @@ func buildModelInfoJSON(model *parser.Model) string {
 		info := make(map[string]any)
 		info["type"] = string(field.Type)
 		
+		if field.Type == parser.FieldTypeRelation && field.RelatedTo != "" {
+			relation := map[string]string{
+				"model":   strings.ToLower(field.RelatedTo),
+				"display": field.DisplayField,
+			}
+			if field.DisplayName != "" {
+				relation["template"] = field.DisplayName
+			}
+			info["relation"] = relation
+		}
+
+		if field.Mask != "" {
+			info["mask"] = field.Mask
+		}
+
 		if field.Type == parser.FieldTypeEnum && len(field.Options) > 0 {
 			options := make(map[string]string)
 			for _, opt := range field.Options {
@@ -617,9 +873,29 @@ func buildModelInfoJSON(model *parser.Model) string {
 	return string(jsonBytes)
 }
 
-func GetLoginHTML(config *parser.Config) string {
+// loginCaptcha renders the CAPTCHA widget, hidden until the server
+// reports that this client has to solve one, and the provider's script.
+func loginCaptcha(captcha *parser.CaptchaConfig) (string, string) {
+	if captcha == nil {
+		return "", ""
+	}
+	widget, ok := captcha.Widget()
+	if !ok {
+		return "", ""
+	}
+	markup := fmt.Sprintf(`<div class="form-group" id="captchaGroup" data-global="%s" style="display: none;">
+                    <div class="%s" data-sitekey="%s" data-callback="onCaptcha" data-expired-callback="onCaptchaExpired"></div>
+                </div>`, html.EscapeString(widget.Global), html.EscapeString(widget.Class), html.EscapeString(captcha.SiteKey))
+	script := fmt.Sprintf(`<script src="%s" async defer></script>`, html.EscapeString(widget.Script))
+	return markup, script
+}
+
+// GetLoginHTML renders the login page. After signing in the browser goes
+// to returnURL, which callers must have checked is a same-origin path.
+func GetLoginHTML(config *parser.Config, returnURL string) string {
+	captchaMarkup, captchaScript := loginCaptcha(config.Server.Auth.Captcha)
 	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="en">
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -813,14 +1089,66 @@ func GetLoginHTML(config *parser.Config) string {
                     <label for="password">Password</label>
                     <input type="password" id="password" name="password" required>
                 </div>
-                
+                %s
                 <button type="submit" class="login-btn" id="loginBtn"><span>Sign In</span></button>
             </form>
             
+            <form id="passwordForm" class="login-form" style="display: none;">
+                <div class="form-group">
+                    <label for="newPassword">New Password</label>
+                    <input type="password" id="newPassword" name="new_password" minlength="8" required>
+                </div>
+                
+                <button type="submit" class="login-btn" id="passwordBtn"><span>Change Password</span></button>
+            </form>
+            
         </div>
     </div>
     
+    %s
     <script>
+        let loginToken = '';
+        let captchaToken = '';
+        
+        function onCaptcha(token) {
+            captchaToken = token;
+        }
+        
+        function onCaptchaExpired() {
+            captchaToken = '';
+        }
+        
+        // Tokens are single use, so a failed attempt needs a fresh one.
+        function resetCaptcha(required) {
+            const group = document.getElementById('captchaGroup');
+            if (!group) {
+                return;
+            }
+            if (required) {
+                group.style.display = 'block';
+            }
+            if (captchaToken && window[group.dataset.global]) {
+                window[group.dataset.global].reset();
+            }
+            captchaToken = '';
+        }
+        
+        function redirectAfterLogin() {
+            const successMsg = document.getElementById('successMessage');
+            successMsg.textContent = 'Login successful! Redirecting...';
+            successMsg.style.display = 'block';
+            
+            // Fragments never reach the server, but browsers keep
+            // them across the redirect to this page.
+            let returnUrl = '%s';
+            if (!returnUrl.includes('#')) {
+                returnUrl += window.location.hash;
+            }
+            setTimeout(() => {
+                window.location.href = returnUrl;
+            }, 1000);
+        }
+        
         document.getElementById('loginForm').addEventListener('submit', async (e) => {
             e.preventDefault();
             
@@ -843,20 +1171,22 @@ func GetLoginHTML(config *parser.Config) string {
                     headers: {
                         'Content-Type': 'application/json',
                     },
-                    body: JSON.stringify({ username, password }),
+                    body: JSON.stringify({ username, password, captcha_token: captchaToken }),
                 });
                 
                 const data = await response.json();
                 
-                if (response.ok && data.success) {
-                    successMsg.textContent = 'Login successful! Redirecting...';
+                if (response.ok && data.success && data.password_change_required) {
+                    loginToken = data.token;
+                    successMsg.textContent = 'Choose a new password to continue.';
                     successMsg.style.display = 'block';
-                    
-                    const returnUrl = new URLSearchParams(window.location.search).get('return') || '/';
-                    setTimeout(() => {
-                        window.location.href = returnUrl;
-                    }, 1000);
+                    document.getElementById('loginForm').style.display = 'none';
+                    document.getElementById('passwordForm').style.display = 'block';
+                    document.getElementById('newPassword').focus();
+                } else if (response.ok && data.success) {
+                    redirectAfterLogin();
                 } else {
+                    resetCaptcha(data.captcha_required);
                     errorMsg.textContent = data.error || 'Invalid credentials';
                     errorMsg.style.display = 'block';
                     loginBtn.disabled = false;
@@ -869,7 +1199,45 @@ func GetLoginHTML(config *parser.Config) string {
                 loginBtn.textContent = 'Sign In';
             }
         });
+        
+        document.getElementById('passwordForm').addEventListener('submit', async (e) => {
+            e.preventDefault();
+            
+            const errorMsg = document.getElementById('errorMessage');
+            const passwordBtn = document.getElementById('passwordBtn');
+            
+            errorMsg.style.display = 'none';
+            passwordBtn.disabled = true;
+            
+            try {
+                const response = await fetch('/api/auth/password', {
+                    method: 'POST',
+                    headers: {
+                        'Content-Type': 'application/json',
+                        'Authorization': 'Bearer ' + loginToken,
+                    },
+                    body: JSON.stringify({
+                        current_password: document.getElementById('password').value,
+                        new_password: document.getElementById('newPassword').value,
+                    }),
+                });
+                
+                const data = await response.json();
+                
+                if (response.ok && data.success) {
+                    redirectAfterLogin();
+                } else {
+                    errorMsg.textContent = data.error || 'Could not change password';
+                    errorMsg.style.display = 'block';
+                    passwordBtn.disabled = false;
+                }
+            } catch (error) {
+                errorMsg.textContent = 'An error occurred. Please try again.';
+                errorMsg.style.display = 'block';
+                passwordBtn.disabled = false;
+            }
+        });
     </script>
 </body>
-</html>`, config.App.Name, config.App.Name)
+</html>`, htmlLang(config.UI.Locale), html.EscapeString(config.App.Name), html.EscapeString(config.App.Name), captchaMarkup, captchaScript, escapeJS(returnURL))
 }
\ No newline at end of file
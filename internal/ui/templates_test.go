@@ -87,7 +87,7 @@ func TestGetHomeHTML(t *testing.T) {
 		"Post": false,
 	}
 
-	html := GetHomeHTML(config, schema, modelPermissions)
+	html := GetHomeHTML(config, schema, modelPermissions, nil, nil, nil)
 
 	// Check basic structure
 	if !strings.Contains(html, "<!DOCTYPE html>") {
@@ -119,12 +119,27 @@ func TestGetHomeHTML(t *testing.T) {
 	}
 }
 
+func TestGetHomeHTML_ModelCounts(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+
+	html := GetHomeHTML(config, schema, nil, map[string]int64{"User": 42}, nil, nil)
+	if !strings.Contains(html, `User <span class="stat-count">(42)</span>`) {
+		t.Error("Expected User card to show its record count")
+	}
+
+	html = GetHomeHTML(config, schema, nil, nil, nil, nil)
+	if strings.Contains(html, `<span class="stat-count">`) {
+		t.Error("Expected no counts without readable models")
+	}
+}
+
 func TestGetHomeHTML_NoAuth(t *testing.T) {
 	config := createTestConfig()
 	config.Server.Auth.Type = "none"
 	schema := createTestSchema()
 
-	html := GetHomeHTML(config, schema, nil)
+	html := GetHomeHTML(config, schema, nil, nil, nil, nil)
 
 	// Should not contain logout link without auth
 	if strings.Contains(html, "Logout") {
@@ -169,6 +184,37 @@ func TestGetListHTML(t *testing.T) {
 	}
 }
 
+func TestGetListHTML_ExportButton(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+	model := schema.Models["User"]
+
+	html := GetListHTML(config, schema, "User", model, false)
+	if !strings.Contains(html, `onclick="exportList()"`) {
+		t.Error("Expected an export button on the list page")
+	}
+
+	js := getJS()
+	if !strings.Contains(js, "params.set('fields', columns.join(','))") {
+		t.Error("Expected export to request only the visible columns")
+	}
+}
+
+func TestGetListHTML_SoftDeleteTrash(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+	model := schema.Models["User"]
+
+	if html := GetListHTML(config, schema, "User", model, true); strings.Contains(html, `id="trashToggle"`) {
+		t.Error("Expected no Trash toggle for models without soft delete")
+	}
+
+	model.SoftDelete = true
+	if html := GetListHTML(config, schema, "User", model, true); !strings.Contains(html, `id="trashToggle"`) {
+		t.Error("Expected Trash toggle for soft-delete model")
+	}
+}
+
 func TestGetListHTML_NoWritePermission(t *testing.T) {
 	config := createTestConfig()
 	schema := createTestSchema()
@@ -258,14 +304,14 @@ func TestGetViewHTML(t *testing.T) {
 	model := schema.Models["User"]
 	recordJSON := `{"id": 1, "name": "John Doe", "email": "john@example.com"}`
 
-	html := GetViewHTML(config, schema, "User", model, "1", recordJSON)
+	html := GetViewHTML(config, schema, "User", model, "1", "User #1", recordJSON)
 
 	// Check basic structure
 	if !strings.Contains(html, "<!DOCTYPE html>") {
 		t.Error("Expected HTML to contain DOCTYPE")
 	}
-	if !strings.Contains(html, "User Details") {
-		t.Error("Expected HTML to contain User Details title")
+	if !strings.Contains(html, "<h2>User #1</h2>") {
+		t.Error("Expected HTML to contain the record title")
 	}
 
 	// Check action buttons
@@ -378,6 +424,23 @@ func TestGenerateFormField_Number(t *testing.T) {
 	}
 }
 
+func TestGenerateFormField_DecimalNumber(t *testing.T) {
+	field := &parser.Field{Name: "price", Type: parser.FieldTypeNumber, Decimal: true, Scale: 2}
+	if html := generateFormField(field); !strings.Contains(html, `step="0.01"`) {
+		t.Errorf("Expected step derived from scale, got: %s", html)
+	}
+
+	field = &parser.Field{Name: "ratio", Type: parser.FieldTypeNumber, Decimal: true}
+	if html := generateFormField(field); !strings.Contains(html, `step="any"`) {
+		t.Errorf("Expected step=any for decimal field, got: %s", html)
+	}
+
+	field = &parser.Field{Name: "age", Type: parser.FieldTypeNumber}
+	if html := generateFormField(field); strings.Contains(html, "step=") {
+		t.Errorf("Expected no step for integer field, got: %s", html)
+	}
+}
+
 func TestGenerateFormField_Boolean(t *testing.T) {
 	field := &parser.Field{
 		Name:    "active",
@@ -512,7 +575,7 @@ func TestBuildModelInfoJSON(t *testing.T) {
 func TestGetLoginHTML(t *testing.T) {
 	config := createTestConfig()
 
-	html := GetLoginHTML(config)
+	html := GetLoginHTML(config, "/")
 
 	// Check basic structure
 	if !strings.Contains(html, "<!DOCTYPE html>") {
@@ -597,4 +660,380 @@ func TestGetFormHTML_DefaultFields(t *testing.T) {
 	if strings.Contains(html, `id="created_at"`) {
 		t.Error("Expected HTML to not contain auto field")
 	}
-}
\ No newline at end of file
+}
+
+func TestBuildModelInfoJSON_Relation(t *testing.T) {
+	model := &parser.Model{
+		Fields: []parser.Field{
+			{Name: "id", Type: parser.FieldTypeID},
+			{Name: "author_id", Type: parser.FieldTypeRelation, RelatedTo: "User", DisplayField: "name"},
+		},
+	}
+
+	jsonStr := buildModelInfoJSON(model)
+
+	if !strings.Contains(jsonStr, `"relation":{"display":"name","model":"user"}`) {
+		t.Errorf("Expected JSON to contain relation info, got %s", jsonStr)
+	}
+}
+
+func TestBuildModelInfoJSON_Mask(t *testing.T) {
+	model := &parser.Model{
+		Fields: []parser.Field{
+			{Name: "id", Type: parser.FieldTypeID},
+			{Name: "phone", Type: parser.FieldTypeText, Mask: "*####"},
+		},
+	}
+
+	jsonStr := buildModelInfoJSON(model)
+
+	if !strings.Contains(jsonStr, `"phone":{"mask":"*####","type":"text"}`) {
+		t.Errorf("Expected JSON to contain the mask, got %s", jsonStr)
+	}
+	if !strings.Contains(getJS(), "return maskValue(value, fieldInfo.mask);") {
+		t.Error("Expected list cells to apply the mask")
+	}
+}
+
+func TestGetFormHTML_Sections(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+	model := schema.Models["User"]
+	model.UI.Form.Sections = []parser.UIFormSection{
+		{Title: "Account", Fields: []string{"name", "email"}},
+		{Title: "Profile", Fields: []string{"age", "bio"}},
+	}
+
+	html := GetFormHTML(config, schema, "User", model, "create", "", "null")
+
+	account := strings.Index(html, "<legend>Account</legend>")
+	profile := strings.Index(html, "<legend>Profile</legend>")
+	if account == -1 || profile == -1 {
+		t.Fatal("Expected Account and Profile sections")
+	}
+	if email := strings.Index(html, `id="email"`); email < account || email > profile {
+		t.Error("Expected email input in the Account section")
+	}
+	if bio := strings.Index(html, `id="bio"`); bio < profile {
+		t.Error("Expected bio input in the Profile section")
+	}
+
+	defaultGroup := strings.Index(html, `form-section-default`)
+	if defaultGroup == -1 {
+		t.Fatal("Expected unsectioned fields in a default group")
+	}
+	if role := strings.Index(html, `id="role"`); role < defaultGroup {
+		t.Error("Expected role input in the default group")
+	}
+}
+
+func TestGetHomeHTML_RecentRecords(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+
+	recent := map[string][]map[string]any{
+		"User": {{"id": 2, "name": "Jane <admin>"}, {"id": 1, "name": nil}},
+	}
+	html := GetHomeHTML(config, schema, nil, nil, recent, nil)
+
+	if !strings.Contains(html, `<li><a href="/user/2">Jane &lt;admin&gt;</a></li>`) {
+		t.Error("Expected recent record link with escaped label")
+	}
+	if !strings.Contains(html, `<li><a href="/user/1">#1</a></li>`) {
+		t.Error("Expected fallback label for record without display value")
+	}
+}
+
+func TestGetListHTML_FilterControls(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+	model := schema.Models["User"]
+	model.Fields = append(model.Fields,
+		parser.Field{Name: "role", Type: parser.FieldTypeEnum, Options: []string{"user", "admin"}},
+		parser.Field{Name: "age", Type: parser.FieldTypeNumber},
+	)
+	model.UI.List.Filterable = []string{"role", "age"}
+
+	html := GetListHTML(config, schema, "User", model, true)
+
+	if !strings.Contains(html, `<select class="form-control" data-filter="role" data-op="eq"><option value="">All</option><option value="user">user</option><option value="admin">admin</option></select>`) {
+		t.Error("Expected a role dropdown filter")
+	}
+	if !strings.Contains(html, `data-filter="age" data-op="gte"`) || !strings.Contains(html, `data-filter="age" data-op="lte"`) {
+		t.Error("Expected min/max inputs for the age range filter")
+	}
+
+	model.UI.List.Filterable = []string{"created_at"}
+	html = GetListHTML(config, schema, "User", model, true)
+	if !strings.Contains(html, `data-filter="created_at" data-op="gte"`) || !strings.Contains(html, `data-filter="created_at" data-op="lt" data-day-end`) {
+		t.Error("Expected a datetime max to filter before the following day")
+	}
+	if !strings.Contains(getJS(), "control.hasAttribute('data-day-end') ? nextDay(control.value)") {
+		t.Error("Expected JS to send the day after a datetime max")
+	}
+
+	model.UI.List.Filterable = nil
+	if strings.Contains(GetListHTML(config, schema, "User", model, true), `id="filterBar"`) {
+		t.Error("Expected no filter bar without filterable fields")
+	}
+}
+
+func TestGetFormHTML_OnSave(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+	model := schema.Models["User"]
+	model.UI.Form.OnSave = "new"
+
+	html := GetFormHTML(config, schema, "User", model, "create", "", "null")
+
+	if !strings.Contains(html, "const formOnSave = 'new';") {
+		t.Error("Expected form to carry the on_save behavior")
+	}
+	if !strings.Contains(html, "const formSuccessMessage = 'Saved.';") {
+		t.Error("Expected form to default to the translated success message")
+	}
+
+	model.UI.Form.SuccessMessage = "User added"
+	html = GetFormHTML(config, schema, "User", model, "create", "", "null")
+	if !strings.Contains(html, "const formSuccessMessage = 'User added';") {
+		t.Error("Expected form to carry the configured success message")
+	}
+}
+
+func TestJS_Toasts(t *testing.T) {
+	js := getJS()
+
+	if strings.Contains(js, "alert(") {
+		t.Error("Expected errors to use toasts instead of alert()")
+	}
+	if !strings.Contains(js, "function showToast(message, type = 'success')") {
+		t.Error("Expected a shared showToast helper")
+	}
+	if !strings.Contains(js, "queueToast(message);") {
+		t.Error("Expected saves that redirect to queue a toast for the next page")
+	}
+}
+
+func TestJS_CSRFHeader(t *testing.T) {
+	js := getJS()
+
+	if !strings.Contains(js, "csrf_token=") || !strings.Contains(js, "init.headers.set('X-CSRF-Token', token);") {
+		t.Error("Expected writes to echo the csrf_token cookie in X-CSRF-Token")
+	}
+}
+
+func TestGetHomeHTML_Widgets(t *testing.T) {
+	config := createTestConfig()
+	config.UI.Dashboard.Widgets = []parser.UIWidgetConfig{
+		{Title: "Total Revenue", Model: "User", Aggregate: "sum", Field: "age"},
+		{Title: "Hidden", Model: "User", Aggregate: "count"},
+	}
+	schema := createTestSchema()
+
+	html := GetHomeHTML(config, schema, nil, nil, nil, map[int]float64{0: 1234.5})
+
+	if !strings.Contains(html, "<h3>Total Revenue</h3>") || !strings.Contains(html, `<div class="widget-value">1234.5</div>`) {
+		t.Error("Expected widget card with its aggregate value")
+	}
+	if strings.Contains(html, "Hidden") {
+		t.Error("Expected widget without a value to be omitted")
+	}
+}
+
+func TestGenerateFormField_Object(t *testing.T) {
+	field := &parser.Field{
+		Name: "address",
+		Type: parser.FieldTypeObject,
+		Properties: []parser.Field{
+			{Name: "city", Type: parser.FieldTypeText, Required: true},
+			{Name: "zip", Type: parser.FieldTypeText},
+		},
+	}
+
+	html := generateFormField(field)
+
+	if !strings.Contains(html, "<legend>Address</legend>") {
+		t.Error("Expected object field to render as a fieldset with a legend")
+	}
+	if !strings.Contains(html, `name="address.city"`) || !strings.Contains(html, `name="address.zip"`) {
+		t.Error("Expected nested inputs named by property path")
+	}
+	if !strings.Contains(html, ">City*</label>") {
+		t.Error("Expected nested label to use the property name")
+	}
+}
+
+func TestTemplates_EscapeConfigNames(t *testing.T) {
+	config := createTestConfig()
+	config.App.Name = "<script>alert('app')</script>"
+	evil := "<script>alert('x')</script>"
+	model := &parser.Model{
+		Name: evil,
+		Fields: []parser.Field{
+			{Name: "id", Type: parser.FieldTypeID, Primary: true},
+			{Name: evil, Type: parser.FieldTypeText},
+			{Name: "kind", Type: parser.FieldTypeEnum, Options: []string{evil}},
+		},
+	}
+	schema := &parser.Schema{Models: map[string]*parser.Model{evil: model}}
+
+	pages := map[string]string{
+		"home":  GetHomeHTML(config, schema, nil, map[string]int64{evil: 1}, nil, nil),
+		"list":  GetListHTML(config, schema, evil, model, true),
+		"form":  GetFormHTML(config, schema, evil, model, "edit", evil, "null"),
+		"view":  GetViewHTML(config, schema, evil, model, evil, evil, "null"),
+		"login": GetLoginHTML(config, "/"),
+	}
+
+	for name, page := range pages {
+		if strings.Contains(page, "<script>alert(") {
+			t.Errorf("Expected %s page to escape config-derived names", name)
+		}
+	}
+}
+
+func TestGetListHTML_SortableHeaders(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+
+	html := GetListHTML(config, schema, "User", schema.Models["User"], true)
+
+	if !strings.Contains(html, `<th class="sortable" data-sort="name">Name<span class="sort-indicator"></span></th>`) {
+		t.Error("Expected sortable Name header with an indicator")
+	}
+	if !strings.Contains(html, `<th>Email</th>`) {
+		t.Error("Expected Email header to stay static because it is not sortable")
+	}
+	if !strings.Contains(html, "function toggleSort(field)") {
+		t.Error("Expected list script to include toggleSort")
+	}
+}
+
+func TestGenerateFormField_HelpAndPlaceholder(t *testing.T) {
+	field := &parser.Field{
+		Name:        "password",
+		Type:        parser.FieldTypePassword,
+		Help:        "At least 8 characters",
+		Placeholder: "Choose a password",
+	}
+
+	html := generateFormField(field)
+
+	if !strings.Contains(html, `<small class="form-help">At least 8 characters</small>`) {
+		t.Error("Expected help text below the input")
+	}
+	if !strings.Contains(html, `placeholder="Choose a password"`) {
+		t.Error("Expected placeholder attribute on the input")
+	}
+
+	html = generateFormField(&parser.Field{Name: "bio", Type: parser.FieldTypeText})
+	if strings.Contains(html, "form-help") || strings.Contains(html, "placeholder=") {
+		t.Error("Expected no help or placeholder markup when unset")
+	}
+}
+
+func TestFieldVisibilityFlags(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+	model := schema.Models["User"]
+	for i := range model.Fields {
+		switch model.Fields[i].Name {
+		case "email":
+			model.Fields[i].HideInList = true
+		case "bio":
+			model.Fields[i].HideInForm = true
+		case "age":
+			model.Fields[i].HideInDetail = true
+		}
+	}
+
+	if html := GetListHTML(config, schema, "User", model, true); !strings.Contains(html, `const columns = ["name"];`) {
+		t.Error("Expected email to be dropped from the explicit list columns")
+	}
+
+	model.UI.List.Columns = nil
+	if html := GetListHTML(config, schema, "User", model, true); !strings.Contains(html, `const columns = ["name","password","age","active"];`) {
+		t.Error("Expected email to be left out of auto-generated list columns")
+	}
+
+	if html := GetFormHTML(config, schema, "User", model, "create", "", "{}"); strings.Contains(html, `name="bio"`) || !strings.Contains(html, `name="age"`) {
+		t.Error("Expected only bio to be hidden from the form")
+	}
+
+	html := GetViewHTML(config, schema, "User", model, "1", "User #1", "{}")
+	if strings.Contains(html, "record['age']") || !strings.Contains(html, "record['bio']") {
+		t.Error("Expected only age to be hidden from the detail view")
+	}
+}
+
+func TestRelationPrefill(t *testing.T) {
+	config := createTestConfig()
+	schema := createTestSchema()
+	schema.Models["Post"].Fields = append(schema.Models["Post"].Fields,
+		parser.Field{Name: "author_id", Type: parser.FieldTypeRelation, RelatedTo: "User"})
+
+	html := GetViewHTML(config, schema, "User", schema.Models["User"], "5", "User #5", "{}")
+	if !strings.Contains(html, `href="/post/new?author_id=5&amp;lock=author_id"`) {
+		t.Error("Expected the user page to offer adding a post with the author preset")
+	}
+	if html := GetViewHTML(config, schema, "Post", schema.Models["Post"], "1", "Post #1", "{}"); strings.Contains(html, "&amp;lock=") {
+		t.Error("Expected no child links for a model nothing relates to")
+	}
+
+	if html := GetFormHTML(config, schema, "Post", schema.Models["Post"], "create", "", "null"); !strings.Contains(html, "applyRelationPrefill(modelInfo)") {
+		t.Error("Expected the create form to apply relation prefills")
+	}
+	if !strings.Contains(getJS(), "function applyRelationPrefill") {
+		t.Error("Expected JS to define applyRelationPrefill")
+	}
+}
+
+func TestGetLoginHTML_Captcha(t *testing.T) {
+	config := createTestConfig()
+	if html := GetLoginHTML(config, "/"); strings.Contains(html, `id="captchaGroup"`) {
+		t.Error("Expected no CAPTCHA widget without captcha config")
+	}
+
+	config.Server.Auth.Captcha = &parser.CaptchaConfig{
+		VerifyURL: "https://hcaptcha.com/siteverify",
+		SiteKey:   "site-key",
+	}
+	html := GetLoginHTML(config, "/")
+	if !strings.Contains(html, `<div class="h-captcha" data-sitekey="site-key"`) {
+		t.Error("Expected the hCaptcha widget with the site key")
+	}
+	if !strings.Contains(html, `<script src="https://js.hcaptcha.com/1/api.js" async defer></script>`) {
+		t.Error("Expected the hCaptcha script")
+	}
+	if !strings.Contains(html, "captcha_token: captchaToken") {
+		t.Error("Expected login to send the CAPTCHA token")
+	}
+	if !strings.Contains(html, "resetCaptcha(data.captcha_required)") {
+		t.Error("Expected failed logins to show and reset the widget")
+	}
+}
+
+func TestTemplates_StringIDs(t *testing.T) {
+	config := createTestConfig()
+	config.Server.StringIDs = true
+	schema := createTestSchema()
+
+	html := GetViewHTML(config, schema, "User", schema.Models["User"], "9007199254740993", "User", `{"id":"9007199254740993","name":"John"}`)
+	if !strings.Contains(html, `href="/user/9007199254740993/edit"`) {
+		t.Error("Expected the edit link to use the string id")
+	}
+	if !strings.Contains(html, "const recordId = '9007199254740993'") {
+		t.Error("Expected the record id to be passed to JS as a string")
+	}
+
+	js := getJS()
+	for _, want := range []string{
+		"const recordPath = encodeURIComponent(String(record.id))",
+		"${API_BASE}/${modelName}/${encodeURIComponent(recordId)}`",
+		"${API_BASE}/${modelName}/${encodeURIComponent(recordId)}/restore`",
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("Expected JS to encode ids in URLs: %s", want)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package validation
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"math"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/yamlforge/yamlforge/internal/parser"
 )
@@ -12,6 +17,11 @@ type Validator struct {
 	schema *parser.Schema
 }
 
+var (
+	slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+	slugFormat     = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
 func New(schema *parser.Schema) *Validator {
 	return &Validator{
 		schema: schema,
@@ -45,9 +55,118 @@ func (v *Validator) ValidateCreate(modelName string, data map[string]any) error
 		}
 	}
 
+	return v.validateRequireOneOf(model, data, false)
+}
+
+type RecordGetter interface {
+	Get(model string, id any) (map[string]any, error)
+}
+
+// ValidateRelations checks that every relation value in data points at an
+// existing record, since SQLite foreign keys may be disabled.
+func (v *Validator) ValidateRelations(modelName string, data map[string]any, db RecordGetter) error {
+	model, ok := v.schema.GetModel(modelName)
+	if !ok {
+		return fmt.Errorf("model %s not found", modelName)
+	}
+
+	for _, field := range model.Fields {
+		if field.Type != parser.FieldTypeRelation {
+			continue
+		}
+		value, exists := data[field.Name]
+		if !exists || value == nil || value == "" {
+			continue
+		}
+
+		related, ok := v.schema.GetModelByRoute(field.RelatedTo)
+		if !ok {
+			continue
+		}
+
+		if _, err := db.Get(related.Name, value); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return parser.ValidationError{
+					Field:   field.Name,
+					Message: fmt.Sprintf("referenced %s not found", related.Name),
+				}
+			}
+			return err
+		}
+	}
+
 	return nil
 }
 
+func (v *Validator) Normalize(modelName string, data map[string]any) {
+	model, ok := v.schema.GetModel(modelName)
+	if !ok {
+		return
+	}
+
+	for _, field := range model.Fields {
+		str, ok := data[field.Name].(string)
+		if !ok {
+			continue
+		}
+		switch field.Normalize {
+		case parser.NormalizeLower:
+			str = strings.ToLower(str)
+		case parser.NormalizeUpper:
+			str = strings.ToUpper(str)
+		case parser.NormalizeTrim:
+			str = strings.TrimSpace(str)
+		}
+		if field.Type == parser.FieldTypeSlug {
+			str = NormalizeSlug(str)
+		}
+		data[field.Name] = str
+	}
+}
+
+// Transform runs each field's configured transforms over its value.
+func (v *Validator) Transform(modelName string, data map[string]any) error {
+	model, ok := v.schema.GetModel(modelName)
+	if !ok {
+		return nil
+	}
+
+	for _, field := range model.Fields {
+		value, ok := data[field.Name]
+		if !ok || value == nil || len(field.Transform) == 0 {
+			continue
+		}
+		transformed, err := parser.ApplyTransforms(field.Transform, value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+		data[field.Name] = transformed
+	}
+	return nil
+}
+
+func NormalizeSlug(value string) string {
+	slug := slugSeparators.ReplaceAllString(strings.ToLower(strings.TrimSpace(value)), "-")
+	return strings.Trim(slug, "-")
+}
+
+func (v *Validator) UnknownFields(modelName string, data map[string]any) []string {
+	model, ok := v.schema.GetModel(modelName)
+	if !ok {
+		return nil
+	}
+
+	var unknown []string
+	for fieldName := range data {
+		if _, found := v.getField(model, fieldName); !found {
+			unknown = append(unknown, fieldName)
+		}
+	}
+	sort.Strings(unknown)
+
+	return unknown
+}
+
 func (v *Validator) ValidateUpdate(modelName string, data map[string]any) error {
 	model, ok := v.schema.GetModel(modelName)
 	if !ok {
@@ -75,6 +194,90 @@ func (v *Validator) ValidateUpdate(modelName string, data map[string]any) error
 		}
 	}
 
+	return v.validateRequireOneOf(model, data, true)
+}
+
+// ValidateChanges rejects updates that move a max_change_pct field
+// further from its stored value than the configured percentage. Stored
+// zeros and blanks are not limited, since no percentage applies to them.
+func (v *Validator) ValidateChanges(modelName string, id any, data map[string]any, db RecordGetter) error {
+	model, ok := v.schema.GetModel(modelName)
+	if !ok {
+		return fmt.Errorf("model %s not found", modelName)
+	}
+
+	var current map[string]any
+	for _, field := range model.Fields {
+		if field.MaxChangePct <= 0 {
+			continue
+		}
+		next, ok := numberValue(data[field.Name])
+		if !ok {
+			continue
+		}
+
+		if current == nil {
+			record, err := db.Get(modelName, id)
+			if errors.Is(err, sql.ErrNoRows) {
+				// The update itself reports the missing record.
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			current = record
+		}
+		previous, ok := numberValue(current[field.Name])
+		if !ok || previous == 0 {
+			continue
+		}
+
+		if change := math.Abs(next-previous) / math.Abs(previous) * 100; change > field.MaxChangePct {
+			return parser.ValidationError{
+				Field:   field.Name,
+				Message: fmt.Sprintf("cannot change by more than %g%% in one update", field.MaxChangePct),
+			}
+		}
+	}
+	return nil
+}
+
+func numberValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// validateRequireOneOf checks that every require_one_of group has a
+// non-blank value. Updates are partial, so a group is only enforced there
+// when the payload carries all of its fields.
+func (v *Validator) validateRequireOneOf(model *parser.Model, data map[string]any, partial bool) error {
+	for _, group := range model.RequireOneOf {
+		satisfied := false
+		for _, fieldName := range group {
+			value, exists := data[fieldName]
+			if partial && !exists {
+				satisfied = true
+				break
+			}
+			if str, ok := value.(string); value != nil && (!ok || strings.TrimSpace(str) != "") {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			return parser.ValidationError{
+				Field:   strings.Join(group, ", "),
+				Message: "at least one of these fields is required",
+			}
+		}
+	}
 	return nil
 }
 
@@ -92,6 +295,15 @@ func (v *Validator) validateField(field parser.Field, value any) error {
 		return nil
 	}
 
+	if field.Required && !field.AllowEmpty && field.Type.IsString() {
+		if str, ok := value.(string); ok && strings.TrimSpace(str) == "" {
+			return parser.ValidationError{
+				Field:   field.Name,
+				Message: "field is required",
+			}
+		}
+	}
+
 	switch field.Type {
 	case parser.FieldTypeText, parser.FieldTypePassword:
 		return v.validateText(field, value)
@@ -99,14 +311,22 @@ func (v *Validator) validateField(field parser.Field, value any) error {
 		return v.validateNumber(field, value)
 	case parser.FieldTypeBoolean:
 		return v.validateBoolean(field, value)
+	case parser.FieldTypeSlug:
+		return v.validateSlug(field, value)
+	case parser.FieldTypeObject:
+		return v.validateObject(field, value)
 	case parser.FieldTypeEmail:
 		return v.validateEmail(field, value)
 	case parser.FieldTypeURL:
 		return v.validateURL(field, value)
 	case parser.FieldTypeEnum:
 		return v.validateEnum(field, value)
-	case parser.FieldTypeDatetime, parser.FieldTypeDate, parser.FieldTypeTime:
+	case parser.FieldTypeDatetime:
 		return v.validateDatetime(field, value)
+	case parser.FieldTypeDate:
+		return v.validateLayout(field, value, "must be a date in YYYY-MM-DD format", "2006-01-02")
+	case parser.FieldTypeTime:
+		return v.validateLayout(field, value, "must be a time in HH:MM or HH:MM:SS format", "15:04", "15:04:05")
 	}
 
 	return nil
@@ -171,6 +391,17 @@ func (v *Validator) validateNumber(field parser.Field, value any) error {
 		}
 	}
 
+	if field.Decimal && field.Scale > 0 {
+		factor := math.Pow(10, float64(field.Scale))
+		scaled := num * factor
+		if math.Abs(scaled-math.Round(scaled)) > 1e-9*math.Max(1, math.Abs(scaled)) {
+			return parser.ValidationError{
+				Field:   field.Name,
+				Message: fmt.Sprintf("must have at most %d decimal places", field.Scale),
+			}
+		}
+	}
+
 	if field.Min != nil && num < float64(*field.Min) {
 		return parser.ValidationError{
 			Field:   field.Name,
@@ -221,6 +452,25 @@ func (v *Validator) validateEmail(field parser.Field, value any) error {
 	return nil
 }
 
+func (v *Validator) validateSlug(field parser.Field, value any) error {
+	if err := v.validateText(field, value); err != nil {
+		return err
+	}
+
+	str := value.(string)
+	if str == "" && (!field.Required || field.AllowEmpty) {
+		return nil
+	}
+	if !slugFormat.MatchString(str) {
+		return parser.ValidationError{
+			Field:   field.Name,
+			Message: "must contain only lowercase letters, numbers and single hyphens",
+		}
+	}
+
+	return nil
+}
+
 func (v *Validator) validateURL(field parser.Field, value any) error {
 	str, ok := value.(string)
 	if !ok {
@@ -263,6 +513,51 @@ func (v *Validator) validateEnum(field parser.Field, value any) error {
 	}
 }
 
+func (v *Validator) validateObject(field parser.Field, value any) error {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return parser.ValidationError{
+			Field:   field.Name,
+			Message: "must be an object",
+		}
+	}
+
+	known := make(map[string]bool, len(field.Properties))
+	for _, property := range field.Properties {
+		known[property.Name] = true
+
+		propertyValue, exists := obj[property.Name]
+		if !exists {
+			if property.Required {
+				return parser.ValidationError{
+					Field:   field.Name + "." + property.Name,
+					Message: "field is required",
+				}
+			}
+			continue
+		}
+
+		if err := v.validateField(property, propertyValue); err != nil {
+			if validationErr, ok := err.(parser.ValidationError); ok {
+				validationErr.Field = field.Name + "." + validationErr.Field
+				return validationErr
+			}
+			return err
+		}
+	}
+
+	for key := range obj {
+		if !known[key] {
+			return parser.ValidationError{
+				Field:   field.Name + "." + key,
+				Message: "field does not exist",
+			}
+		}
+	}
+
+	return nil
+}
+
 func (v *Validator) validateDatetime(field parser.Field, value any) error {
 	_, ok := value.(string)
 	if !ok {
@@ -275,6 +570,32 @@ func (v *Validator) validateDatetime(field parser.Field, value any) error {
 	return nil
 }
 
+// Layouts mirror what the form's <input type="date"> and
+// <input type="time"> controls submit.
+func (v *Validator) validateLayout(field parser.Field, value any, message string, layouts ...string) error {
+	str, ok := value.(string)
+	if !ok {
+		return parser.ValidationError{
+			Field:   field.Name,
+			Message: "must be a string",
+		}
+	}
+	if str == "" {
+		return nil
+	}
+
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, str); err == nil {
+			return nil
+		}
+	}
+
+	return parser.ValidationError{
+		Field:   field.Name,
+		Message: message,
+	}
+}
+
 func (v *Validator) getField(model *parser.Model, fieldName string) (*parser.Field, bool) {
 	for _, field := range model.Fields {
 		if field.Name == fieldName {
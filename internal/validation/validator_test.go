@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
@@ -328,6 +329,30 @@ func TestValidateField_Number_ValidNumber(t *testing.T) {
 	}
 }
 
+func TestValidateField_Number_DecimalScale(t *testing.T) {
+	schema := createTestSchema()
+	validator := New(schema)
+
+	field := parser.Field{
+		Name:    "price",
+		Type:    parser.FieldTypeNumber,
+		Decimal: true,
+		Scale:   2,
+	}
+
+	if err := validator.validateField(field, 29.99); err != nil {
+		t.Errorf("Expected 29.99 to be valid, got: %v", err)
+	}
+
+	err := validator.validateField(field, 29.999)
+	if err == nil {
+		t.Fatal("Expected error for too many decimal places")
+	}
+	if validationErr, ok := err.(parser.ValidationError); !ok || validationErr.Message != "must have at most 2 decimal places" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func TestValidateField_Number_TooSmall(t *testing.T) {
 	schema := createTestSchema()
 	validator := New(schema)
@@ -671,6 +696,39 @@ func TestValidateField_Datetime_NotString(t *testing.T) {
 	}
 }
 
+func TestValidateField_DateAndTime(t *testing.T) {
+	validator := New(createTestSchema())
+
+	date := parser.Field{Name: "birthday", Type: parser.FieldTypeDate}
+	time := parser.Field{Name: "opens_at", Type: parser.FieldTypeTime}
+
+	tests := []struct {
+		field parser.Field
+		value string
+		valid bool
+	}{
+		{date, "2023-06-15", true},
+		{date, "2024-02-29", true},
+		{date, "2023-13-40", false},
+		{date, "2023-02-30", false},
+		{date, "15/06/2023", false},
+		{time, "09:30", true},
+		{time, "23:59:59", true},
+		{time, "25:99", false},
+		{time, "9am", false},
+	}
+
+	for _, tt := range tests {
+		err := validator.validateField(tt.field, tt.value)
+		if tt.valid && err != nil {
+			t.Errorf("Expected %s %q to be valid, got: %v", tt.field.Type, tt.value, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("Expected %s %q to be rejected", tt.field.Type, tt.value)
+		}
+	}
+}
+
 func TestValidateField_NullableField(t *testing.T) {
 	schema := createTestSchema()
 	validator := New(schema)
@@ -874,4 +932,215 @@ models:
 	if err == nil {
 		t.Error("Expected error for invalid URL")
 	}
-}
\ No newline at end of file
+}
+
+func TestValidateCreate_RequiredEmptyString(t *testing.T) {
+	schema := createTestSchema()
+	validator := New(schema)
+
+	data := map[string]interface{}{
+		"name":     "   ",
+		"email":    "john@example.com",
+		"password": "password123",
+	}
+
+	err := validator.ValidateCreate("User", data)
+	if err == nil {
+		t.Fatal("Expected error for blank required string")
+	}
+	if err.Error() != "name: field is required" {
+		t.Errorf("Expected 'name: field is required', got: %s", err.Error())
+	}
+
+	err = validator.ValidateUpdate("User", map[string]interface{}{"name": ""})
+	if err == nil {
+		t.Error("Expected error for empty required string on update")
+	}
+}
+
+func TestValidateCreate_RequiredAllowEmpty(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Note": {
+				Name: "Note",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "body", Type: parser.FieldTypeText, Required: true, AllowEmpty: true},
+				},
+			},
+		},
+	}
+	validator := New(schema)
+
+	if err := validator.ValidateCreate("Note", map[string]interface{}{"body": ""}); err != nil {
+		t.Errorf("Expected empty string to pass with allow_empty, got: %v", err)
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	schema := createTestSchema()
+	validator := New(schema)
+
+	unknown := validator.UnknownFields("User", map[string]any{
+		"name":   "John",
+		"zeta":   1,
+		"hacker": "y",
+	})
+
+	if len(unknown) != 2 || unknown[0] != "hacker" || unknown[1] != "zeta" {
+		t.Errorf("Expected sorted unknown fields [hacker zeta], got %v", unknown)
+	}
+}
+
+func TestNormalizeSlug(t *testing.T) {
+	tests := map[string]string{
+		" Hello World ":       "hello-world",
+		"Hello---World":       "hello-world",
+		"  multiple   spaces": "multiple-spaces",
+		"Rock & Roll!":        "rock-roll",
+		"already-a-slug":      "already-a-slug",
+		"--":                  "",
+	}
+
+	for input, expected := range tests {
+		if got := NormalizeSlug(input); got != expected {
+			t.Errorf("NormalizeSlug(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestValidateField_Slug(t *testing.T) {
+	validator := New(createTestSchema())
+	field := parser.Field{Name: "slug", Type: parser.FieldTypeSlug, Required: true}
+
+	if err := validator.validateField(field, "hello-world"); err != nil {
+		t.Errorf("Expected valid slug, got %v", err)
+	}
+	if err := validator.validateField(field, "Hello World"); err == nil {
+		t.Error("Expected error for unnormalized slug")
+	}
+	if err := validator.validateField(field, ""); err == nil {
+		t.Error("Expected error for empty required slug")
+	}
+}
+
+func TestValidateField_Object(t *testing.T) {
+	validator := New(createTestSchema())
+	max := 5
+	field := parser.Field{
+		Name: "address",
+		Type: parser.FieldTypeObject,
+		Properties: []parser.Field{
+			{Name: "city", Type: parser.FieldTypeText, Required: true},
+			{Name: "zip", Type: parser.FieldTypeText, Max: &max},
+		},
+	}
+
+	if err := validator.validateField(field, map[string]any{"city": "NYC", "zip": "10001"}); err != nil {
+		t.Errorf("Expected valid object, got %v", err)
+	}
+
+	err := validator.validateField(field, map[string]any{"city": "NYC", "zip": "100010"})
+	if verr, ok := err.(parser.ValidationError); !ok || verr.Field != "address.zip" {
+		t.Errorf("Expected address.zip validation error, got %v", err)
+	}
+
+	if err := validator.validateField(field, map[string]any{"zip": "10001"}); err == nil {
+		t.Error("Expected error for missing required property")
+	}
+	if err := validator.validateField(field, map[string]any{"city": "NYC", "state": "NY"}); err == nil {
+		t.Error("Expected error for unknown property")
+	}
+	if err := validator.validateField(field, "NYC"); err == nil {
+		t.Error("Expected error for non-object value")
+	}
+}
+
+func TestValidateRequireOneOf(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Contact": {
+				Name: "Contact",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "name", Type: parser.FieldTypeText},
+					{Name: "phone", Type: parser.FieldTypeText},
+					{Name: "email", Type: parser.FieldTypeEmail},
+				},
+				RequireOneOf: [][]string{{"phone", "email"}},
+			},
+		},
+	}
+	validator := New(schema)
+
+	if err := validator.ValidateCreate("Contact", map[string]any{"name": "Ann"}); err == nil {
+		t.Error("Expected error without phone or email")
+	}
+	if err := validator.ValidateCreate("Contact", map[string]any{"name": "Ann", "phone": " ", "email": ""}); err == nil {
+		t.Error("Expected error when phone and email are blank")
+	}
+	if err := validator.ValidateCreate("Contact", map[string]any{"name": "Ann", "phone": "555-1234"}); err != nil {
+		t.Errorf("Expected phone alone to pass, got %v", err)
+	}
+	if err := validator.ValidateCreate("Contact", map[string]any{"name": "Ann", "email": "ann@example.com"}); err != nil {
+		t.Errorf("Expected email alone to pass, got %v", err)
+	}
+
+	if err := validator.ValidateUpdate("Contact", map[string]any{"name": "Bea"}); err != nil {
+		t.Errorf("Expected an update that leaves the group alone to pass, got %v", err)
+	}
+	if err := validator.ValidateUpdate("Contact", map[string]any{"phone": "", "email": ""}); err == nil {
+		t.Error("Expected an update blanking the whole group to fail")
+	}
+}
+
+type recordsByID map[any]map[string]any
+
+func (r recordsByID) Get(model string, id any) (map[string]any, error) {
+	record, ok := r[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return record, nil
+}
+
+func TestValidateChanges_MaxChangePct(t *testing.T) {
+	schema := &parser.Schema{
+		Models: map[string]*parser.Model{
+			"Product": {
+				Name: "Product",
+				Fields: []parser.Field{
+					{Name: "id", Type: parser.FieldTypeID, Primary: true},
+					{Name: "price", Type: parser.FieldTypeNumber, Decimal: true, MaxChangePct: 50},
+					{Name: "stock", Type: parser.FieldTypeNumber},
+				},
+			},
+		},
+	}
+	validator := New(schema)
+	db := recordsByID{
+		int64(1): {"id": int64(1), "price": 10.0, "stock": int64(5)},
+		int64(2): {"id": int64(2), "price": int64(0)},
+	}
+
+	tests := []struct {
+		name    string
+		id      any
+		data    map[string]any
+		wantErr bool
+	}{
+		{"within limit", int64(1), map[string]any{"price": 14.99}, false},
+		{"exact limit", int64(1), map[string]any{"price": 5.0}, false},
+		{"too large a rise", int64(1), map[string]any{"price": 15.01}, true},
+		{"too large a drop", int64(1), map[string]any{"price": 4}, true},
+		{"unlimited field", int64(1), map[string]any{"stock": 500}, false},
+		{"stored zero", int64(2), map[string]any{"price": 100.0}, false},
+		{"missing record", int64(3), map[string]any{"price": 100.0}, false},
+	}
+	for _, tt := range tests {
+		err := validator.ValidateChanges("Product", tt.id, tt.data, db)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}